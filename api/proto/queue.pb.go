@@ -13,6 +13,8 @@ import (
 
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
@@ -22,16 +24,136 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// MisfirePolicy 周期任务错过触发时刻 (超过 scheduler.misfire_threshold) 后的处理方式。
+type MisfirePolicy int32
+
+const (
+	MisfirePolicy_MISFIRE_POLICY_UNSPECIFIED MisfirePolicy = 0 // 未设置：同 FIRE_ONCE
+	MisfirePolicy_MISFIRE_POLICY_SKIP        MisfirePolicy = 1 // 丢弃错过的触发，等待下一个未来时刻
+	MisfirePolicy_MISFIRE_POLICY_FIRE_ONCE   MisfirePolicy = 2 // 将错过的触发合并为一次立即投递
+	MisfirePolicy_MISFIRE_POLICY_FIRE_ALL    MisfirePolicy = 3 // 逐一补发每个错过的触发
+)
+
+// Enum value maps for MisfirePolicy.
+var (
+	MisfirePolicy_name = map[int32]string{
+		0: "MISFIRE_POLICY_UNSPECIFIED",
+		1: "MISFIRE_POLICY_SKIP",
+		2: "MISFIRE_POLICY_FIRE_ONCE",
+		3: "MISFIRE_POLICY_FIRE_ALL",
+	}
+	MisfirePolicy_value = map[string]int32{
+		"MISFIRE_POLICY_UNSPECIFIED": 0,
+		"MISFIRE_POLICY_SKIP":        1,
+		"MISFIRE_POLICY_FIRE_ONCE":   2,
+		"MISFIRE_POLICY_FIRE_ALL":    3,
+	}
+)
+
+func (x MisfirePolicy) Enum() *MisfirePolicy {
+	p := new(MisfirePolicy)
+	*p = x
+	return p
+}
+
+func (x MisfirePolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MisfirePolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_queue_proto_enumTypes[0].Descriptor()
+}
+
+func (MisfirePolicy) Type() protoreflect.EnumType {
+	return &file_api_proto_queue_proto_enumTypes[0]
+}
+
+func (x MisfirePolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MisfirePolicy.Descriptor instead.
+func (MisfirePolicy) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{0}
+}
+
+// RetryStrategy 失败重试的退避算法。
+type RetryStrategy int32
+
+const (
+	RetryStrategy_RETRY_STRATEGY_UNSPECIFIED         RetryStrategy = 0 // 未设置：立即重试
+	RetryStrategy_RETRY_STRATEGY_FIXED               RetryStrategy = 1 // 固定等待 base
+	RetryStrategy_RETRY_STRATEGY_LINEAR              RetryStrategy = 2 // 第 n 次重试等待 base * n
+	RetryStrategy_RETRY_STRATEGY_EXPONENTIAL         RetryStrategy = 3 // 第 n 次重试等待 base * multiplier^(n-1)
+	RetryStrategy_RETRY_STRATEGY_FULL_JITTER         RetryStrategy = 4 // 在 [0, base * 2^(n-1)] 内随机
+	RetryStrategy_RETRY_STRATEGY_DECORRELATED_JITTER RetryStrategy = 5 // 在 [base, 上次等待 * 3] 内随机
+	RetryStrategy_RETRY_STRATEGY_SCHEDULE            RetryStrategy = 6 // 按 schedule 列表逐次取值，超出后重复最后一项
+)
+
+// Enum value maps for RetryStrategy.
+var (
+	RetryStrategy_name = map[int32]string{
+		0: "RETRY_STRATEGY_UNSPECIFIED",
+		1: "RETRY_STRATEGY_FIXED",
+		2: "RETRY_STRATEGY_LINEAR",
+		3: "RETRY_STRATEGY_EXPONENTIAL",
+		4: "RETRY_STRATEGY_FULL_JITTER",
+		5: "RETRY_STRATEGY_DECORRELATED_JITTER",
+		6: "RETRY_STRATEGY_SCHEDULE",
+	}
+	RetryStrategy_value = map[string]int32{
+		"RETRY_STRATEGY_UNSPECIFIED":         0,
+		"RETRY_STRATEGY_FIXED":               1,
+		"RETRY_STRATEGY_LINEAR":              2,
+		"RETRY_STRATEGY_EXPONENTIAL":         3,
+		"RETRY_STRATEGY_FULL_JITTER":         4,
+		"RETRY_STRATEGY_DECORRELATED_JITTER": 5,
+		"RETRY_STRATEGY_SCHEDULE":            6,
+	}
+)
+
+func (x RetryStrategy) Enum() *RetryStrategy {
+	p := new(RetryStrategy)
+	*p = x
+	return p
+}
+
+func (x RetryStrategy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RetryStrategy) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_queue_proto_enumTypes[1].Descriptor()
+}
+
+func (RetryStrategy) Type() protoreflect.EnumType {
+	return &file_api_proto_queue_proto_enumTypes[1]
+}
+
+func (x RetryStrategy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RetryStrategy.Descriptor instead.
+func (RetryStrategy) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{1}
+}
+
 // EnqueueRequest 任务提交请求参数。
 type EnqueueRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`                                    // 业务主题 (如 "order_cancel")
-	Payload       string                 `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`                                // 任务载荷 (JSON string)
-	DelaySeconds  int64                  `protobuf:"varint,3,opt,name=delay_seconds,json=delaySeconds,proto3" json:"delay_seconds,omitempty"` // 延迟时间 (秒)
-	Id            string                 `protobuf:"bytes,4,opt,name=id,proto3" json:"id,omitempty"`                                          // 客户端指定的唯一ID，若为空则由服务端生成
-	MaxRetries    int32                  `protobuf:"varint,5,opt,name=max_retries,json=maxRetries,proto3" json:"max_retries,omitempty"`       // 允许客户端指定最大重试次数，如果不传则使用系统默认
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Topic             string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`                                                   // 业务主题 (如 "order_cancel")
+	Payload           string                 `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`                                               // 任务载荷 (JSON string)
+	DelaySeconds      int64                  `protobuf:"varint,3,opt,name=delay_seconds,json=delaySeconds,proto3" json:"delay_seconds,omitempty"`                // 延迟时间 (秒)，已被 delay 取代，保留兼容旧客户端
+	Id                string                 `protobuf:"bytes,4,opt,name=id,proto3" json:"id,omitempty"`                                                         // 客户端指定的唯一ID，若为空则由服务端生成
+	MaxRetries        int32                  `protobuf:"varint,5,opt,name=max_retries,json=maxRetries,proto3" json:"max_retries,omitempty"`                      // 允许客户端指定最大重试次数，如果不传则使用系统默认
+	VisibilityTimeout int64                  `protobuf:"varint,6,opt,name=visibility_timeout,json=visibilityTimeout,proto3" json:"visibility_timeout,omitempty"` // 单次执行的可见性超时 (秒)，不传则使用 queue.visibility_timeout
+	RetryPolicy       *RetryPolicy           `protobuf:"bytes,7,opt,name=retry_policy,json=retryPolicy,proto3" json:"retry_policy,omitempty"`                    // 失败重试的退避策略，不传则依次使用 Topic 配置、全局配置
+	Priority          int32                  `protobuf:"varint,8,opt,name=priority,proto3" json:"priority,omitempty"`                                            // 优先级 [0, 1000]，越大越先被拉取，默认 0；只影响已到期任务之间的顺序
+	ExecuteAt         *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=execute_at,json=executeAt,proto3" json:"execute_at,omitempty"`                          // 绝对执行时间 (毫秒精度)，与 delay / delay_seconds 互斥
+	Delay             *durationpb.Duration   `protobuf:"bytes,10,opt,name=delay,proto3" json:"delay,omitempty"`                                                  // 相对延迟 (毫秒精度)，与 execute_at / delay_seconds 互斥
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *EnqueueRequest) Reset() {
@@ -99,6 +221,41 @@ func (x *EnqueueRequest) GetMaxRetries() int32 {
 	return 0
 }
 
+func (x *EnqueueRequest) GetVisibilityTimeout() int64 {
+	if x != nil {
+		return x.VisibilityTimeout
+	}
+	return 0
+}
+
+func (x *EnqueueRequest) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
+}
+
+func (x *EnqueueRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *EnqueueRequest) GetExecuteAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExecuteAt
+	}
+	return nil
+}
+
+func (x *EnqueueRequest) GetDelay() *durationpb.Duration {
+	if x != nil {
+		return x.Delay
+	}
+	return nil
+}
+
 type EnqueueResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
@@ -343,34 +500,29 @@ func (x *DeleteResponse) GetSuccess() bool {
 	return false
 }
 
-// Task 核心任务模型
-type Task struct {
+type AckRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Topic         string                 `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
-	Payload       string                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
-	ExecuteTime   int64                  `protobuf:"varint,4,opt,name=execute_time,json=executeTime,proto3" json:"execute_time,omitempty"` // 计划执行时间戳
-	RetryCount    int32                  `protobuf:"varint,5,opt,name=retry_count,json=retryCount,proto3" json:"retry_count,omitempty"`    // 已重试次数 (默认0)
-	MaxRetries    int32                  `protobuf:"varint,6,opt,name=max_retries,json=maxRetries,proto3" json:"max_retries,omitempty"`    // 最大允许重试次数
-	CreatedAt     int64                  `protobuf:"varint,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`       // 任务创建时间戳 (用于统计或清理)
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"` // 任务所属业务主题
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`       // 任务ID
+	Lease         string                 `protobuf:"bytes,3,opt,name=lease,proto3" json:"lease,omitempty"` // 投递时下发的租约令牌 (Task.lease)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Task) Reset() {
-	*x = Task{}
+func (x *AckRequest) Reset() {
+	*x = AckRequest{}
 	mi := &file_api_proto_queue_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Task) String() string {
+func (x *AckRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Task) ProtoMessage() {}
+func (*AckRequest) ProtoMessage() {}
 
-func (x *Task) ProtoReflect() protoreflect.Message {
+func (x *AckRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_queue_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -382,156 +534,2825 @@ func (x *Task) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Task.ProtoReflect.Descriptor instead.
-func (*Task) Descriptor() ([]byte, []int) {
+// Deprecated: Use AckRequest.ProtoReflect.Descriptor instead.
+func (*AckRequest) Descriptor() ([]byte, []int) {
 	return file_api_proto_queue_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *Task) GetId() string {
+func (x *AckRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *AckRequest) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-func (x *Task) GetTopic() string {
+func (x *AckRequest) GetLease() string {
+	if x != nil {
+		return x.Lease
+	}
+	return ""
+}
+
+type AckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AckResponse) Reset() {
+	*x = AckResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AckResponse) ProtoMessage() {}
+
+func (x *AckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AckResponse.ProtoReflect.Descriptor instead.
+func (*AckResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AckResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type NackRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Topic             string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`                                                     // 任务所属业务主题
+	Id                string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`                                                           // 任务ID
+	Reason            string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`                                                   // 失败原因，记录到任务的 last_error
+	RetryDelaySeconds int64                  `protobuf:"varint,4,opt,name=retry_delay_seconds,json=retryDelaySeconds,proto3" json:"retry_delay_seconds,omitempty"` // 重试前等待时间 (秒)，0 表示按任务的 retry_policy 退避
+	Lease             string                 `protobuf:"bytes,5,opt,name=lease,proto3" json:"lease,omitempty"`                                                     // 投递时下发的租约令牌 (Task.lease)
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *NackRequest) Reset() {
+	*x = NackRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NackRequest) ProtoMessage() {}
+
+func (x *NackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NackRequest.ProtoReflect.Descriptor instead.
+func (*NackRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *NackRequest) GetTopic() string {
 	if x != nil {
 		return x.Topic
 	}
 	return ""
 }
 
-func (x *Task) GetPayload() string {
+func (x *NackRequest) GetId() string {
 	if x != nil {
-		return x.Payload
+		return x.Id
 	}
 	return ""
 }
 
-func (x *Task) GetExecuteTime() int64 {
+func (x *NackRequest) GetReason() string {
 	if x != nil {
-		return x.ExecuteTime
+		return x.Reason
 	}
-	return 0
+	return ""
 }
 
-func (x *Task) GetRetryCount() int32 {
+func (x *NackRequest) GetRetryDelaySeconds() int64 {
 	if x != nil {
-		return x.RetryCount
+		return x.RetryDelaySeconds
 	}
 	return 0
 }
 
-func (x *Task) GetMaxRetries() int32 {
+func (x *NackRequest) GetLease() string {
 	if x != nil {
-		return x.MaxRetries
+		return x.Lease
 	}
-	return 0
+	return ""
 }
 
-func (x *Task) GetCreatedAt() int64 {
+type NackResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NackResponse) Reset() {
+	*x = NackResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NackResponse) ProtoMessage() {}
+
+func (x *NackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[9]
 	if x != nil {
-		return x.CreatedAt
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-var File_api_proto_queue_proto protoreflect.FileDescriptor
+// Deprecated: Use NackResponse.ProtoReflect.Descriptor instead.
+func (*NackResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{9}
+}
 
-const file_api_proto_queue_proto_rawDesc = "" +
-	"\n" +
-	"\x15api/proto/queue.proto\x12\tapi.queue\"\x96\x01\n" +
-	"\x0eEnqueueRequest\x12\x14\n" +
-	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x18\n" +
-	"\apayload\x18\x02 \x01(\tR\apayload\x12#\n" +
-	"\rdelay_seconds\x18\x03 \x01(\x03R\fdelaySeconds\x12\x0e\n" +
-	"\x02id\x18\x04 \x01(\tR\x02id\x12\x1f\n" +
-	"\vmax_retries\x18\x05 \x01(\x05R\n" +
-	"maxRetries\"`\n" +
-	"\x0fEnqueueResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x0e\n" +
-	"\x02id\x18\x02 \x01(\tR\x02id\x12#\n" +
-	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\"F\n" +
-	"\x0fRetrieveRequest\x12\x14\n" +
-	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x1d\n" +
-	"\n" +
-	"batch_size\x18\x02 \x01(\x05R\tbatchSize\"9\n" +
-	"\x10RetrieveResponse\x12%\n" +
-	"\x05tasks\x18\x01 \x03(\v2\x0f.api.queue.TaskR\x05tasks\"\x1f\n" +
-	"\rDeleteRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"*\n" +
-	"\x0eDeleteResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xca\x01\n" +
-	"\x04Task\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
-	"\x05topic\x18\x02 \x01(\tR\x05topic\x12\x18\n" +
-	"\apayload\x18\x03 \x01(\tR\apayload\x12!\n" +
-	"\fexecute_time\x18\x04 \x01(\x03R\vexecuteTime\x12\x1f\n" +
-	"\vretry_count\x18\x05 \x01(\x05R\n" +
-	"retryCount\x12\x1f\n" +
-	"\vmax_retries\x18\x06 \x01(\x05R\n" +
-	"maxRetries\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\a \x01(\x03R\tcreatedAt2\xd9\x01\n" +
-	"\x11DelayQueueService\x12@\n" +
-	"\aEnqueue\x12\x19.api.queue.EnqueueRequest\x1a\x1a.api.queue.EnqueueResponse\x12C\n" +
-	"\bRetrieve\x12\x1a.api.queue.RetrieveRequest\x1a\x1b.api.queue.RetrieveResponse\x12=\n" +
-	"\x06Delete\x12\x18.api.queue.DeleteRequest\x1a\x19.api.queue.DeleteResponseB8Z6github.com/AkikoAkaki/async-task-platform/api/proto;pbb\x06proto3"
+func (x *NackResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
 
-var (
-	file_api_proto_queue_proto_rawDescOnce sync.Once
-	file_api_proto_queue_proto_rawDescData []byte
-)
+type ExtendLeaseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`                                       // 任务所属业务主题
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`                                             // 任务ID
+	Lease         string                 `protobuf:"bytes,3,opt,name=lease,proto3" json:"lease,omitempty"`                                       // 投递时下发的租约令牌 (Task.lease)
+	ExtendSeconds int64                  `protobuf:"varint,4,opt,name=extend_seconds,json=extendSeconds,proto3" json:"extend_seconds,omitempty"` // 从当前时刻起至少再保留的秒数 (> 0)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-func file_api_proto_queue_proto_rawDescGZIP() []byte {
-	file_api_proto_queue_proto_rawDescOnce.Do(func() {
-		file_api_proto_queue_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_proto_queue_proto_rawDesc), len(file_api_proto_queue_proto_rawDesc)))
-	})
-	return file_api_proto_queue_proto_rawDescData
+func (x *ExtendLeaseRequest) Reset() {
+	*x = ExtendLeaseRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-var file_api_proto_queue_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
-var file_api_proto_queue_proto_goTypes = []any{
-	(*EnqueueRequest)(nil),   // 0: api.queue.EnqueueRequest
-	(*EnqueueResponse)(nil),  // 1: api.queue.EnqueueResponse
-	(*RetrieveRequest)(nil),  // 2: api.queue.RetrieveRequest
-	(*RetrieveResponse)(nil), // 3: api.queue.RetrieveResponse
-	(*DeleteRequest)(nil),    // 4: api.queue.DeleteRequest
-	(*DeleteResponse)(nil),   // 5: api.queue.DeleteResponse
-	(*Task)(nil),             // 6: api.queue.Task
+func (x *ExtendLeaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
-var file_api_proto_queue_proto_depIdxs = []int32{
-	6, // 0: api.queue.RetrieveResponse.tasks:type_name -> api.queue.Task
-	0, // 1: api.queue.DelayQueueService.Enqueue:input_type -> api.queue.EnqueueRequest
-	2, // 2: api.queue.DelayQueueService.Retrieve:input_type -> api.queue.RetrieveRequest
-	4, // 3: api.queue.DelayQueueService.Delete:input_type -> api.queue.DeleteRequest
-	1, // 4: api.queue.DelayQueueService.Enqueue:output_type -> api.queue.EnqueueResponse
-	3, // 5: api.queue.DelayQueueService.Retrieve:output_type -> api.queue.RetrieveResponse
-	5, // 6: api.queue.DelayQueueService.Delete:output_type -> api.queue.DeleteResponse
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+
+func (*ExtendLeaseRequest) ProtoMessage() {}
+
+func (x *ExtendLeaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func init() { file_api_proto_queue_proto_init() }
-func file_api_proto_queue_proto_init() {
+// Deprecated: Use ExtendLeaseRequest.ProtoReflect.Descriptor instead.
+func (*ExtendLeaseRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ExtendLeaseRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *ExtendLeaseRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ExtendLeaseRequest) GetLease() string {
+	if x != nil {
+		return x.Lease
+	}
+	return ""
+}
+
+func (x *ExtendLeaseRequest) GetExtendSeconds() int64 {
+	if x != nil {
+		return x.ExtendSeconds
+	}
+	return 0
+}
+
+type ExtendLeaseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExtendLeaseResponse) Reset() {
+	*x = ExtendLeaseResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExtendLeaseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtendLeaseResponse) ProtoMessage() {}
+
+func (x *ExtendLeaseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtendLeaseResponse.ProtoReflect.Descriptor instead.
+func (*ExtendLeaseResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ExtendLeaseResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type ListDeadLettersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`                           // 业务主题 (必填)
+	StartTime     int64                  `protobuf:"varint,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // 进入死信时间下限 (Unix 秒，含)，0 表示不限
+	EndTime       int64                  `protobuf:"varint,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // 进入死信时间上限 (Unix 秒，含)，0 表示不限
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`    // 每页数量，默认 10，上限 100
+	PageToken     string                 `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`  // 上一页返回的 next_page_token，首页为空
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeadLettersRequest) Reset() {
+	*x = ListDeadLettersRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeadLettersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeadLettersRequest) ProtoMessage() {}
+
+func (x *ListDeadLettersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeadLettersRequest.ProtoReflect.Descriptor instead.
+func (*ListDeadLettersRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListDeadLettersRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *ListDeadLettersRequest) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *ListDeadLettersRequest) GetEndTime() int64 {
+	if x != nil {
+		return x.EndTime
+	}
+	return 0
+}
+
+func (x *ListDeadLettersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListDeadLettersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListDeadLettersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`                                        // 按进入死信时间由新到旧排列
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // 为空表示已无更多数据
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeadLettersResponse) Reset() {
+	*x = ListDeadLettersResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeadLettersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeadLettersResponse) ProtoMessage() {}
+
+func (x *ListDeadLettersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeadLettersResponse.ProtoReflect.Descriptor instead.
+func (*ListDeadLettersResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListDeadLettersResponse) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+func (x *ListDeadLettersResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetDeadLetterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // 任务ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeadLetterRequest) Reset() {
+	*x = GetDeadLetterRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeadLetterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeadLetterRequest) ProtoMessage() {}
+
+func (x *GetDeadLetterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeadLetterRequest.ProtoReflect.Descriptor instead.
+func (*GetDeadLetterRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetDeadLetterRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetDeadLetterResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeadLetterResponse) Reset() {
+	*x = GetDeadLetterResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeadLetterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeadLetterResponse) ProtoMessage() {}
+
+func (x *GetDeadLetterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeadLetterResponse.ProtoReflect.Descriptor instead.
+func (*GetDeadLetterResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetDeadLetterResponse) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+type RedriveDeadLettersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`                                    // 业务主题；all 为 true 时必填，按 ID 操作时用于限定范围
+	Ids           []string               `protobuf:"bytes,2,rep,name=ids,proto3" json:"ids,omitempty"`                                        // 指定重投的任务ID
+	All           bool                   `protobuf:"varint,3,opt,name=all,proto3" json:"all,omitempty"`                                       // 重投该 Topic 下的全部死信，与 ids 互斥
+	DelaySeconds  int64                  `protobuf:"varint,4,opt,name=delay_seconds,json=delaySeconds,proto3" json:"delay_seconds,omitempty"` // 重投后的延迟执行时间 (秒)，0 表示立即
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedriveDeadLettersRequest) Reset() {
+	*x = RedriveDeadLettersRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedriveDeadLettersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedriveDeadLettersRequest) ProtoMessage() {}
+
+func (x *RedriveDeadLettersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedriveDeadLettersRequest.ProtoReflect.Descriptor instead.
+func (*RedriveDeadLettersRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RedriveDeadLettersRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *RedriveDeadLettersRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *RedriveDeadLettersRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+func (x *RedriveDeadLettersRequest) GetDelaySeconds() int64 {
+	if x != nil {
+		return x.DelaySeconds
+	}
+	return 0
+}
+
+type RedriveDeadLettersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"` // 实际重投的任务数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedriveDeadLettersResponse) Reset() {
+	*x = RedriveDeadLettersResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedriveDeadLettersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedriveDeadLettersResponse) ProtoMessage() {}
+
+func (x *RedriveDeadLettersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedriveDeadLettersResponse.ProtoReflect.Descriptor instead.
+func (*RedriveDeadLettersResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RedriveDeadLettersResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type PurgeDeadLettersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"` // 业务主题；all 为 true 时必填，按 ID 操作时用于限定范围
+	Ids           []string               `protobuf:"bytes,2,rep,name=ids,proto3" json:"ids,omitempty"`     // 指定删除的任务ID
+	All           bool                   `protobuf:"varint,3,opt,name=all,proto3" json:"all,omitempty"`    // 删除该 Topic 下的全部死信，与 ids 互斥
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeDeadLettersRequest) Reset() {
+	*x = PurgeDeadLettersRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeDeadLettersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeDeadLettersRequest) ProtoMessage() {}
+
+func (x *PurgeDeadLettersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeDeadLettersRequest.ProtoReflect.Descriptor instead.
+func (*PurgeDeadLettersRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *PurgeDeadLettersRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *PurgeDeadLettersRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *PurgeDeadLettersRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+type PurgeDeadLettersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"` // 实际删除的任务数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeDeadLettersResponse) Reset() {
+	*x = PurgeDeadLettersResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeDeadLettersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeDeadLettersResponse) ProtoMessage() {}
+
+func (x *PurgeDeadLettersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeDeadLettersResponse.ProtoReflect.Descriptor instead.
+func (*PurgeDeadLettersResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PurgeDeadLettersResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// QuarantinedEntry 拉取或超时回收时无法解码、被转入隔离区的任务条目。
+type QuarantinedEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                             // 任务ID；升级前的成员本身无法解析时为原始成员
+	Topic         string                 `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`                                       // 业务主题
+	Raw           []byte                 `protobuf:"bytes,3,opt,name=raw,proto3" json:"raw,omitempty"`                                           // 存储中的原始数据，数据缺失时为空
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`                                       // 解码失败原因
+	Source        string                 `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`                                     // 发现位置：fetch (拉取) / recover (超时回收) / ack / nack / extend (执行中操作) / redrive (死信重投)
+	QuarantinedAt int64                  `protobuf:"varint,6,opt,name=quarantined_at,json=quarantinedAt,proto3" json:"quarantined_at,omitempty"` // 被隔离的时间戳 (秒)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuarantinedEntry) Reset() {
+	*x = QuarantinedEntry{}
+	mi := &file_api_proto_queue_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuarantinedEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuarantinedEntry) ProtoMessage() {}
+
+func (x *QuarantinedEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuarantinedEntry.ProtoReflect.Descriptor instead.
+func (*QuarantinedEntry) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *QuarantinedEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *QuarantinedEntry) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *QuarantinedEntry) GetRaw() []byte {
+	if x != nil {
+		return x.Raw
+	}
+	return nil
+}
+
+func (x *QuarantinedEntry) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *QuarantinedEntry) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *QuarantinedEntry) GetQuarantinedAt() int64 {
+	if x != nil {
+		return x.QuarantinedAt
+	}
+	return 0
+}
+
+type ListQuarantinedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`                          // 业务主题 (必填)
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`   // 每页数量，默认 10，上限 100 (近似值)
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"` // 上一页返回的 next_page_token，首页为空
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListQuarantinedRequest) Reset() {
+	*x = ListQuarantinedRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListQuarantinedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListQuarantinedRequest) ProtoMessage() {}
+
+func (x *ListQuarantinedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListQuarantinedRequest.ProtoReflect.Descriptor instead.
+func (*ListQuarantinedRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListQuarantinedRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *ListQuarantinedRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListQuarantinedRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListQuarantinedResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Entries          []*QuarantinedEntry    `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	NextPageToken    string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`         // 为空表示已无更多数据
+	Count            int64                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`                                               // 该 Topic 当前被隔离的条目数
+	TotalQuarantined int64                  `protobuf:"varint,4,opt,name=total_quarantined,json=totalQuarantined,proto3" json:"total_quarantined,omitempty"` // 该 Topic 累计被隔离的条目数 (删除后不减少)，用于监控告警
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ListQuarantinedResponse) Reset() {
+	*x = ListQuarantinedResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListQuarantinedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListQuarantinedResponse) ProtoMessage() {}
+
+func (x *ListQuarantinedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListQuarantinedResponse.ProtoReflect.Descriptor instead.
+func (*ListQuarantinedResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListQuarantinedResponse) GetEntries() []*QuarantinedEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *ListQuarantinedResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListQuarantinedResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *ListQuarantinedResponse) GetTotalQuarantined() int64 {
+	if x != nil {
+		return x.TotalQuarantined
+	}
+	return 0
+}
+
+type DeleteQuarantinedRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"` // 业务主题 (必填)
+	Ids           []string               `protobuf:"bytes,2,rep,name=ids,proto3" json:"ids,omitempty"`     // 指定删除的条目ID
+	All           bool                   `protobuf:"varint,3,opt,name=all,proto3" json:"all,omitempty"`    // 删除该 Topic 下的全部隔离条目，与 ids 互斥
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteQuarantinedRequest) Reset() {
+	*x = DeleteQuarantinedRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteQuarantinedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteQuarantinedRequest) ProtoMessage() {}
+
+func (x *DeleteQuarantinedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteQuarantinedRequest.ProtoReflect.Descriptor instead.
+func (*DeleteQuarantinedRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *DeleteQuarantinedRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *DeleteQuarantinedRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *DeleteQuarantinedRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+type DeleteQuarantinedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"` // 实际删除的条目数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteQuarantinedResponse) Reset() {
+	*x = DeleteQuarantinedResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteQuarantinedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteQuarantinedResponse) ProtoMessage() {}
+
+func (x *DeleteQuarantinedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteQuarantinedResponse.ProtoReflect.Descriptor instead.
+func (*DeleteQuarantinedResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *DeleteQuarantinedResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type CreateScheduleRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                                                          // 周期任务ID，若为空则由服务端生成
+	Topic             string                 `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`                                                                    // 投递的业务主题
+	Payload           string                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`                                                                // 每次投递的任务载荷
+	CronExpr          string                 `protobuf:"bytes,4,opt,name=cron_expr,json=cronExpr,proto3" json:"cron_expr,omitempty"`                                              // cron 表达式，支持 5 段 (分 时 日 月 周) 或带秒的 6 段，以及 @every 1h 等描述符
+	TimeZone          string                 `protobuf:"bytes,5,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`                                              // IANA 时区 (如 "Asia/Shanghai")，为空表示 UTC
+	StartTime         int64                  `protobuf:"varint,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`                                          // 生效时间 (Unix 秒)，0 表示立即生效
+	EndTime           int64                  `protobuf:"varint,7,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`                                                // 失效时间 (Unix 秒)，0 表示永不失效
+	JitterSeconds     int64                  `protobuf:"varint,8,opt,name=jitter_seconds,json=jitterSeconds,proto3" json:"jitter_seconds,omitempty"`                              // 每次投递在触发时刻后随机推迟 [0, jitter_seconds] 秒，用于打散整点洪峰
+	MisfirePolicy     MisfirePolicy          `protobuf:"varint,9,opt,name=misfire_policy,json=misfirePolicy,proto3,enum=api.queue.MisfirePolicy" json:"misfire_policy,omitempty"` // 错过触发时刻 (如服务停机) 后的补偿策略
+	MaxRetries        int32                  `protobuf:"varint,10,opt,name=max_retries,json=maxRetries,proto3" json:"max_retries,omitempty"`                                      // 投递任务的最大重试次数，不传则使用系统默认
+	VisibilityTimeout int64                  `protobuf:"varint,11,opt,name=visibility_timeout,json=visibilityTimeout,proto3" json:"visibility_timeout,omitempty"`                 // 投递任务的可见性超时 (秒)
+	RetryPolicy       *RetryPolicy           `protobuf:"bytes,12,opt,name=retry_policy,json=retryPolicy,proto3" json:"retry_policy,omitempty"`                                    // 投递任务的退避策略
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *CreateScheduleRequest) Reset() {
+	*x = CreateScheduleRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateScheduleRequest) ProtoMessage() {}
+
+func (x *CreateScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateScheduleRequest.ProtoReflect.Descriptor instead.
+func (*CreateScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *CreateScheduleRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetCronExpr() string {
+	if x != nil {
+		return x.CronExpr
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *CreateScheduleRequest) GetEndTime() int64 {
+	if x != nil {
+		return x.EndTime
+	}
+	return 0
+}
+
+func (x *CreateScheduleRequest) GetJitterSeconds() int64 {
+	if x != nil {
+		return x.JitterSeconds
+	}
+	return 0
+}
+
+func (x *CreateScheduleRequest) GetMisfirePolicy() MisfirePolicy {
+	if x != nil {
+		return x.MisfirePolicy
+	}
+	return MisfirePolicy_MISFIRE_POLICY_UNSPECIFIED
+}
+
+func (x *CreateScheduleRequest) GetMaxRetries() int32 {
+	if x != nil {
+		return x.MaxRetries
+	}
+	return 0
+}
+
+func (x *CreateScheduleRequest) GetVisibilityTimeout() int64 {
+	if x != nil {
+		return x.VisibilityTimeout
+	}
+	return 0
+}
+
+func (x *CreateScheduleRequest) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
+}
+
+type CreateScheduleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedule      *Schedule              `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateScheduleResponse) Reset() {
+	*x = CreateScheduleResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateScheduleResponse) ProtoMessage() {}
+
+func (x *CreateScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateScheduleResponse.ProtoReflect.Descriptor instead.
+func (*CreateScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *CreateScheduleResponse) GetSchedule() *Schedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+type PauseScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseScheduleRequest) Reset() {
+	*x = PauseScheduleRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseScheduleRequest) ProtoMessage() {}
+
+func (x *PauseScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseScheduleRequest.ProtoReflect.Descriptor instead.
+func (*PauseScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *PauseScheduleRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type PauseScheduleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedule      *Schedule              `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseScheduleResponse) Reset() {
+	*x = PauseScheduleResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseScheduleResponse) ProtoMessage() {}
+
+func (x *PauseScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseScheduleResponse.ProtoReflect.Descriptor instead.
+func (*PauseScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *PauseScheduleResponse) GetSchedule() *Schedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+type ResumeScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeScheduleRequest) Reset() {
+	*x = ResumeScheduleRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeScheduleRequest) ProtoMessage() {}
+
+func (x *ResumeScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeScheduleRequest.ProtoReflect.Descriptor instead.
+func (*ResumeScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ResumeScheduleRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ResumeScheduleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedule      *Schedule              `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeScheduleResponse) Reset() {
+	*x = ResumeScheduleResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeScheduleResponse) ProtoMessage() {}
+
+func (x *ResumeScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeScheduleResponse.ProtoReflect.Descriptor instead.
+func (*ResumeScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ResumeScheduleResponse) GetSchedule() *Schedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+type ListSchedulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"` // 为空表示全部 Topic
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSchedulesRequest) Reset() {
+	*x = ListSchedulesRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSchedulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSchedulesRequest) ProtoMessage() {}
+
+func (x *ListSchedulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSchedulesRequest.ProtoReflect.Descriptor instead.
+func (*ListSchedulesRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ListSchedulesRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+type ListSchedulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedules     []*Schedule            `protobuf:"bytes,1,rep,name=schedules,proto3" json:"schedules,omitempty"` // 按 ID 排序
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSchedulesResponse) Reset() {
+	*x = ListSchedulesResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSchedulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSchedulesResponse) ProtoMessage() {}
+
+func (x *ListSchedulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSchedulesResponse.ProtoReflect.Descriptor instead.
+func (*ListSchedulesResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ListSchedulesResponse) GetSchedules() []*Schedule {
+	if x != nil {
+		return x.Schedules
+	}
+	return nil
+}
+
+type DeleteScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteScheduleRequest) Reset() {
+	*x = DeleteScheduleRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteScheduleRequest) ProtoMessage() {}
+
+func (x *DeleteScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteScheduleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *DeleteScheduleRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteScheduleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteScheduleResponse) Reset() {
+	*x = DeleteScheduleResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteScheduleResponse) ProtoMessage() {}
+
+func (x *DeleteScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteScheduleResponse.ProtoReflect.Descriptor instead.
+func (*DeleteScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *DeleteScheduleResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type GetLeaderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLeaderRequest) Reset() {
+	*x = GetLeaderRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLeaderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLeaderRequest) ProtoMessage() {}
+
+func (x *GetLeaderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLeaderRequest.ProtoReflect.Descriptor instead.
+func (*GetLeaderRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{35}
+}
+
+type GetLeaderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`        // 响应请求的副本 ID
+	IsLeader      bool                   `protobuf:"varint,2,opt,name=is_leader,json=isLeader,proto3" json:"is_leader,omitempty"` // 响应请求的副本是否为 Leader
+	LeaderId      string                 `protobuf:"bytes,3,opt,name=leader_id,json=leaderId,proto3" json:"leader_id,omitempty"`  // 当前 Leader 的副本 ID，为空表示暂无 Leader (如选举进行中)
+	Token         int64                  `protobuf:"varint,4,opt,name=token,proto3" json:"token,omitempty"`                       // 当前 Leader 租约的纪元，每次换主单调递增，仅用于观测
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLeaderResponse) Reset() {
+	*x = GetLeaderResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLeaderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLeaderResponse) ProtoMessage() {}
+
+func (x *GetLeaderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLeaderResponse.ProtoReflect.Descriptor instead.
+func (*GetLeaderResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *GetLeaderResponse) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *GetLeaderResponse) GetIsLeader() bool {
+	if x != nil {
+		return x.IsLeader
+	}
+	return false
+}
+
+func (x *GetLeaderResponse) GetLeaderId() string {
+	if x != nil {
+		return x.LeaderId
+	}
+	return ""
+}
+
+func (x *GetLeaderResponse) GetToken() int64 {
+	if x != nil {
+		return x.Token
+	}
+	return 0
+}
+
+// SubscribeRequest Worker 通过订阅流发送的消息。首条消息必须为 open。
+type SubscribeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*SubscribeRequest_Open
+	//	*SubscribeRequest_Credit
+	//	*SubscribeRequest_Ack
+	//	*SubscribeRequest_Nack
+	Payload       isSubscribeRequest_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_api_proto_queue_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SubscribeRequest) GetPayload() isSubscribeRequest_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetOpen() *SubscribeOpen {
+	if x != nil {
+		if x, ok := x.Payload.(*SubscribeRequest_Open); ok {
+			return x.Open
+		}
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetCredit() int32 {
+	if x != nil {
+		if x, ok := x.Payload.(*SubscribeRequest_Credit); ok {
+			return x.Credit
+		}
+	}
+	return 0
+}
+
+func (x *SubscribeRequest) GetAck() *AckRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*SubscribeRequest_Ack); ok {
+			return x.Ack
+		}
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetNack() *NackRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*SubscribeRequest_Nack); ok {
+			return x.Nack
+		}
+	}
+	return nil
+}
+
+type isSubscribeRequest_Payload interface {
+	isSubscribeRequest_Payload()
+}
+
+type SubscribeRequest_Open struct {
+	Open *SubscribeOpen `protobuf:"bytes,1,opt,name=open,proto3,oneof"` // 建立订阅：声明 Topic 与初始 credit
+}
+
+type SubscribeRequest_Credit struct {
+	Credit int32 `protobuf:"varint,2,opt,name=credit,proto3,oneof"` // 追加授予的 credit 数量
+}
+
+type SubscribeRequest_Ack struct {
+	Ack *AckRequest `protobuf:"bytes,3,opt,name=ack,proto3,oneof"` // 确认任务成功
+}
+
+type SubscribeRequest_Nack struct {
+	Nack *NackRequest `protobuf:"bytes,4,opt,name=nack,proto3,oneof"` // 报告任务失败
+}
+
+func (*SubscribeRequest_Open) isSubscribeRequest_Payload() {}
+
+func (*SubscribeRequest_Credit) isSubscribeRequest_Payload() {}
+
+func (*SubscribeRequest_Ack) isSubscribeRequest_Payload() {}
+
+func (*SubscribeRequest_Nack) isSubscribeRequest_Payload() {}
+
+type SubscribeOpen struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topics        []string               `protobuf:"bytes,1,rep,name=topics,proto3" json:"topics,omitempty"`  // 订阅的业务主题列表
+	Credit        int32                  `protobuf:"varint,2,opt,name=credit,proto3" json:"credit,omitempty"` // 初始 credit，即最多可同时推送的未确认任务数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeOpen) Reset() {
+	*x = SubscribeOpen{}
+	mi := &file_api_proto_queue_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeOpen) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeOpen) ProtoMessage() {}
+
+func (x *SubscribeOpen) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeOpen.ProtoReflect.Descriptor instead.
+func (*SubscribeOpen) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *SubscribeOpen) GetTopics() []string {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+func (x *SubscribeOpen) GetCredit() int32 {
+	if x != nil {
+		return x.Credit
+	}
+	return 0
+}
+
+// SubscribeResponse 服务端通过订阅流推送的消息。
+type SubscribeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*SubscribeResponse_Task
+	//	*SubscribeResponse_Result
+	Payload       isSubscribeResponse_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeResponse) Reset() {
+	*x = SubscribeResponse{}
+	mi := &file_api_proto_queue_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeResponse) ProtoMessage() {}
+
+func (x *SubscribeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeResponse.ProtoReflect.Descriptor instead.
+func (*SubscribeResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *SubscribeResponse) GetPayload() isSubscribeResponse_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *SubscribeResponse) GetTask() *Task {
+	if x != nil {
+		if x, ok := x.Payload.(*SubscribeResponse_Task); ok {
+			return x.Task
+		}
+	}
+	return nil
+}
+
+func (x *SubscribeResponse) GetResult() *AckResult {
+	if x != nil {
+		if x, ok := x.Payload.(*SubscribeResponse_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+type isSubscribeResponse_Payload interface {
+	isSubscribeResponse_Payload()
+}
+
+type SubscribeResponse_Task struct {
+	Task *Task `protobuf:"bytes,1,opt,name=task,proto3,oneof"` // 到期任务
+}
+
+type SubscribeResponse_Result struct {
+	Result *AckResult `protobuf:"bytes,2,opt,name=result,proto3,oneof"` // 流内 Ack/Nack 的处理结果
+}
+
+func (*SubscribeResponse_Task) isSubscribeResponse_Payload() {}
+
+func (*SubscribeResponse_Result) isSubscribeResponse_Payload() {}
+
+type AckResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // 对应的任务ID
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Code          int32                  `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`      // gRPC 状态码 (0 表示成功)
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"` // 失败时的错误描述
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AckResult) Reset() {
+	*x = AckResult{}
+	mi := &file_api_proto_queue_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AckResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AckResult) ProtoMessage() {}
+
+func (x *AckResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AckResult.ProtoReflect.Descriptor instead.
+func (*AckResult) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *AckResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AckResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *AckResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *AckResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Task 核心任务模型
+type Task struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Topic             string                 `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	Payload           string                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	ExecuteTime       int64                  `protobuf:"varint,4,opt,name=execute_time,json=executeTime,proto3" json:"execute_time,omitempty"`                    // 计划执行时间戳 (秒)，由 execute_time_ms 向下取整，保留兼容旧客户端
+	RetryCount        int32                  `protobuf:"varint,5,opt,name=retry_count,json=retryCount,proto3" json:"retry_count,omitempty"`                       // 已重试次数 (默认0)
+	MaxRetries        int32                  `protobuf:"varint,6,opt,name=max_retries,json=maxRetries,proto3" json:"max_retries,omitempty"`                       // 最大允许重试次数
+	CreatedAt         int64                  `protobuf:"varint,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`                          // 任务创建时间戳 (用于统计或清理)
+	LastError         string                 `protobuf:"bytes,8,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`                           // 最近一次 Nack 上报的失败原因
+	Lease             string                 `protobuf:"bytes,9,opt,name=lease,proto3" json:"lease,omitempty"`                                                    // 本次投递的租约令牌，仅在 Retrieve/Subscribe 下发时填充，Ack/Nack 须原样回传
+	VisibilityTimeout int64                  `protobuf:"varint,10,opt,name=visibility_timeout,json=visibilityTimeout,proto3" json:"visibility_timeout,omitempty"` // 单次执行的可见性超时 (秒)，0 表示使用 Watchdog 的全局配置
+	RetryPolicy       *RetryPolicy           `protobuf:"bytes,11,opt,name=retry_policy,json=retryPolicy,proto3" json:"retry_policy,omitempty"`                    // 入队时确定的退避策略，Nack 与 Watchdog 回收共用
+	LastBackoff       int64                  `protobuf:"varint,12,opt,name=last_backoff,json=lastBackoff,proto3" json:"last_backoff,omitempty"`                   // 上一次重试的等待时长 (秒)，供 decorrelated jitter 计算
+	DeadReason        string                 `protobuf:"bytes,13,opt,name=dead_reason,json=deadReason,proto3" json:"dead_reason,omitempty"`                       // 进入死信队列的原因：retries_exhausted (Nack) / visibility_timeout (Watchdog 回收)
+	DeadAt            int64                  `protobuf:"varint,14,opt,name=dead_at,json=deadAt,proto3" json:"dead_at,omitempty"`                                  // 进入死信队列的时间戳
+	Priority          int32                  `protobuf:"varint,15,opt,name=priority,proto3" json:"priority,omitempty"`                                            // 优先级，越大越先被拉取；等待越久的任务有效优先级越高 (queue.priority_aging)
+	ExecuteTimeMs     int64                  `protobuf:"varint,16,opt,name=execute_time_ms,json=executeTimeMs,proto3" json:"execute_time_ms,omitempty"`           // 计划执行时间戳 (毫秒)，0 表示升级前写入的任务，以 execute_time 为准
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_api_proto_queue_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *Task) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Task) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Task) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+func (x *Task) GetExecuteTime() int64 {
+	if x != nil {
+		return x.ExecuteTime
+	}
+	return 0
+}
+
+func (x *Task) GetRetryCount() int32 {
+	if x != nil {
+		return x.RetryCount
+	}
+	return 0
+}
+
+func (x *Task) GetMaxRetries() int32 {
+	if x != nil {
+		return x.MaxRetries
+	}
+	return 0
+}
+
+func (x *Task) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Task) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *Task) GetLease() string {
+	if x != nil {
+		return x.Lease
+	}
+	return ""
+}
+
+func (x *Task) GetVisibilityTimeout() int64 {
+	if x != nil {
+		return x.VisibilityTimeout
+	}
+	return 0
+}
+
+func (x *Task) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
+}
+
+func (x *Task) GetLastBackoff() int64 {
+	if x != nil {
+		return x.LastBackoff
+	}
+	return 0
+}
+
+func (x *Task) GetDeadReason() string {
+	if x != nil {
+		return x.DeadReason
+	}
+	return ""
+}
+
+func (x *Task) GetDeadAt() int64 {
+	if x != nil {
+		return x.DeadAt
+	}
+	return 0
+}
+
+func (x *Task) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Task) GetExecuteTimeMs() int64 {
+	if x != nil {
+		return x.ExecuteTimeMs
+	}
+	return 0
+}
+
+// Schedule 周期任务定义。调度器在每个触发时刻以 "<id>@<触发时间戳>" 为 ID 投递一个任务。
+type Schedule struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Topic             string                 `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	Payload           string                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	CronExpr          string                 `protobuf:"bytes,4,opt,name=cron_expr,json=cronExpr,proto3" json:"cron_expr,omitempty"`
+	TimeZone          string                 `protobuf:"bytes,5,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	StartTime         int64                  `protobuf:"varint,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime           int64                  `protobuf:"varint,7,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	JitterSeconds     int64                  `protobuf:"varint,8,opt,name=jitter_seconds,json=jitterSeconds,proto3" json:"jitter_seconds,omitempty"`
+	MisfirePolicy     MisfirePolicy          `protobuf:"varint,9,opt,name=misfire_policy,json=misfirePolicy,proto3,enum=api.queue.MisfirePolicy" json:"misfire_policy,omitempty"`
+	MaxRetries        int32                  `protobuf:"varint,10,opt,name=max_retries,json=maxRetries,proto3" json:"max_retries,omitempty"`
+	VisibilityTimeout int64                  `protobuf:"varint,11,opt,name=visibility_timeout,json=visibilityTimeout,proto3" json:"visibility_timeout,omitempty"`
+	RetryPolicy       *RetryPolicy           `protobuf:"bytes,12,opt,name=retry_policy,json=retryPolicy,proto3" json:"retry_policy,omitempty"`
+	Paused            bool                   `protobuf:"varint,13,opt,name=paused,proto3" json:"paused,omitempty"`
+	NextRunTime       int64                  `protobuf:"varint,14,opt,name=next_run_time,json=nextRunTime,proto3" json:"next_run_time,omitempty"` // 下一次触发时间戳，0 表示已越过 end_time 不再触发
+	LastRunTime       int64                  `protobuf:"varint,15,opt,name=last_run_time,json=lastRunTime,proto3" json:"last_run_time,omitempty"` // 最近一次投递对应的触发时间戳
+	CreatedAt         int64                  `protobuf:"varint,16,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Schedule) Reset() {
+	*x = Schedule{}
+	mi := &file_api_proto_queue_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Schedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule) ProtoMessage() {}
+
+func (x *Schedule) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule.ProtoReflect.Descriptor instead.
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *Schedule) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Schedule) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Schedule) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+func (x *Schedule) GetCronExpr() string {
+	if x != nil {
+		return x.CronExpr
+	}
+	return ""
+}
+
+func (x *Schedule) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+func (x *Schedule) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *Schedule) GetEndTime() int64 {
+	if x != nil {
+		return x.EndTime
+	}
+	return 0
+}
+
+func (x *Schedule) GetJitterSeconds() int64 {
+	if x != nil {
+		return x.JitterSeconds
+	}
+	return 0
+}
+
+func (x *Schedule) GetMisfirePolicy() MisfirePolicy {
+	if x != nil {
+		return x.MisfirePolicy
+	}
+	return MisfirePolicy_MISFIRE_POLICY_UNSPECIFIED
+}
+
+func (x *Schedule) GetMaxRetries() int32 {
+	if x != nil {
+		return x.MaxRetries
+	}
+	return 0
+}
+
+func (x *Schedule) GetVisibilityTimeout() int64 {
+	if x != nil {
+		return x.VisibilityTimeout
+	}
+	return 0
+}
+
+func (x *Schedule) GetRetryPolicy() *RetryPolicy {
+	if x != nil {
+		return x.RetryPolicy
+	}
+	return nil
+}
+
+func (x *Schedule) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *Schedule) GetNextRunTime() int64 {
+	if x != nil {
+		return x.NextRunTime
+	}
+	return 0
+}
+
+func (x *Schedule) GetLastRunTime() int64 {
+	if x != nil {
+		return x.LastRunTime
+	}
+	return 0
+}
+
+func (x *Schedule) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+// RetryPolicy 失败重试的退避策略。所有策略的等待时长都受 max_seconds 封顶。
+type RetryPolicy struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Strategy        RetryStrategy          `protobuf:"varint,1,opt,name=strategy,proto3,enum=api.queue.RetryStrategy" json:"strategy,omitempty"`
+	BaseSeconds     int64                  `protobuf:"varint,2,opt,name=base_seconds,json=baseSeconds,proto3" json:"base_seconds,omitempty"`                    // 基础等待时长 (秒)
+	MaxSeconds      int64                  `protobuf:"varint,3,opt,name=max_seconds,json=maxSeconds,proto3" json:"max_seconds,omitempty"`                       // 等待时长上限 (秒)，0 表示不封顶
+	Multiplier      float64                `protobuf:"fixed64,4,opt,name=multiplier,proto3" json:"multiplier,omitempty"`                                        // 指数退避的倍数，<=1 时按 2 处理
+	ScheduleSeconds []int64                `protobuf:"varint,5,rep,packed,name=schedule_seconds,json=scheduleSeconds,proto3" json:"schedule_seconds,omitempty"` // SCHEDULE 策略的显式等待列表 (秒)，如 [10, 60, 600, 3600]
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RetryPolicy) Reset() {
+	*x = RetryPolicy{}
+	mi := &file_api_proto_queue_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryPolicy) ProtoMessage() {}
+
+func (x *RetryPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_queue_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryPolicy.ProtoReflect.Descriptor instead.
+func (*RetryPolicy) Descriptor() ([]byte, []int) {
+	return file_api_proto_queue_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *RetryPolicy) GetStrategy() RetryStrategy {
+	if x != nil {
+		return x.Strategy
+	}
+	return RetryStrategy_RETRY_STRATEGY_UNSPECIFIED
+}
+
+func (x *RetryPolicy) GetBaseSeconds() int64 {
+	if x != nil {
+		return x.BaseSeconds
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetMaxSeconds() int64 {
+	if x != nil {
+		return x.MaxSeconds
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetMultiplier() float64 {
+	if x != nil {
+		return x.Multiplier
+	}
+	return 0
+}
+
+func (x *RetryPolicy) GetScheduleSeconds() []int64 {
+	if x != nil {
+		return x.ScheduleSeconds
+	}
+	return nil
+}
+
+var File_api_proto_queue_proto protoreflect.FileDescriptor
+
+const file_api_proto_queue_proto_rawDesc = "" +
+	"\n" +
+	"\x15api/proto/queue.proto\x12\tapi.queue\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x88\x03\n" +
+	"\x0eEnqueueRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x18\n" +
+	"\apayload\x18\x02 \x01(\tR\apayload\x12#\n" +
+	"\rdelay_seconds\x18\x03 \x01(\x03R\fdelaySeconds\x12\x0e\n" +
+	"\x02id\x18\x04 \x01(\tR\x02id\x12\x1f\n" +
+	"\vmax_retries\x18\x05 \x01(\x05R\n" +
+	"maxRetries\x12-\n" +
+	"\x12visibility_timeout\x18\x06 \x01(\x03R\x11visibilityTimeout\x129\n" +
+	"\fretry_policy\x18\a \x01(\v2\x16.api.queue.RetryPolicyR\vretryPolicy\x12\x1a\n" +
+	"\bpriority\x18\b \x01(\x05R\bpriority\x129\n" +
+	"\n" +
+	"execute_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\texecuteAt\x12/\n" +
+	"\x05delay\x18\n" +
+	" \x01(\v2\x19.google.protobuf.DurationR\x05delay\"`\n" +
+	"\x0fEnqueueResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12#\n" +
+	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\"F\n" +
+	"\x0fRetrieveRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x02 \x01(\x05R\tbatchSize\"9\n" +
+	"\x10RetrieveResponse\x12%\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x0f.api.queue.TaskR\x05tasks\"\x1f\n" +
+	"\rDeleteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"*\n" +
+	"\x0eDeleteResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"H\n" +
+	"\n" +
+	"AckRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x14\n" +
+	"\x05lease\x18\x03 \x01(\tR\x05lease\"'\n" +
+	"\vAckResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x91\x01\n" +
+	"\vNackRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12.\n" +
+	"\x13retry_delay_seconds\x18\x04 \x01(\x03R\x11retryDelaySeconds\x12\x14\n" +
+	"\x05lease\x18\x05 \x01(\tR\x05lease\"(\n" +
+	"\fNackResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"w\n" +
+	"\x12ExtendLeaseRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x14\n" +
+	"\x05lease\x18\x03 \x01(\tR\x05lease\x12%\n" +
+	"\x0eextend_seconds\x18\x04 \x01(\x03R\rextendSeconds\"/\n" +
+	"\x13ExtendLeaseResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xa4\x01\n" +
+	"\x16ListDeadLettersRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x02 \x01(\x03R\tstartTime\x12\x19\n" +
+	"\bend_time\x18\x03 \x01(\x03R\aendTime\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x05 \x01(\tR\tpageToken\"h\n" +
+	"\x17ListDeadLettersResponse\x12%\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x0f.api.queue.TaskR\x05tasks\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"&\n" +
+	"\x14GetDeadLetterRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"<\n" +
+	"\x15GetDeadLetterResponse\x12#\n" +
+	"\x04task\x18\x01 \x01(\v2\x0f.api.queue.TaskR\x04task\"z\n" +
+	"\x19RedriveDeadLettersRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x10\n" +
+	"\x03ids\x18\x02 \x03(\tR\x03ids\x12\x10\n" +
+	"\x03all\x18\x03 \x01(\bR\x03all\x12#\n" +
+	"\rdelay_seconds\x18\x04 \x01(\x03R\fdelaySeconds\"2\n" +
+	"\x1aRedriveDeadLettersResponse\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\"S\n" +
+	"\x17PurgeDeadLettersRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x10\n" +
+	"\x03ids\x18\x02 \x03(\tR\x03ids\x12\x10\n" +
+	"\x03all\x18\x03 \x01(\bR\x03all\"0\n" +
+	"\x18PurgeDeadLettersResponse\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\"\x9f\x01\n" +
+	"\x10QuarantinedEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05topic\x18\x02 \x01(\tR\x05topic\x12\x10\n" +
+	"\x03raw\x18\x03 \x01(\fR\x03raw\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x16\n" +
+	"\x06source\x18\x05 \x01(\tR\x06source\x12%\n" +
+	"\x0equarantined_at\x18\x06 \x01(\x03R\rquarantinedAt\"j\n" +
+	"\x16ListQuarantinedRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"\xbb\x01\n" +
+	"\x17ListQuarantinedResponse\x125\n" +
+	"\aentries\x18\x01 \x03(\v2\x1b.api.queue.QuarantinedEntryR\aentries\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x03R\x05count\x12+\n" +
+	"\x11total_quarantined\x18\x04 \x01(\x03R\x10totalQuarantined\"T\n" +
+	"\x18DeleteQuarantinedRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x10\n" +
+	"\x03ids\x18\x02 \x03(\tR\x03ids\x12\x10\n" +
+	"\x03all\x18\x03 \x01(\bR\x03all\"1\n" +
+	"\x19DeleteQuarantinedResponse\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\"\xbe\x03\n" +
+	"\x15CreateScheduleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05topic\x18\x02 \x01(\tR\x05topic\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\tR\apayload\x12\x1b\n" +
+	"\tcron_expr\x18\x04 \x01(\tR\bcronExpr\x12\x1b\n" +
+	"\ttime_zone\x18\x05 \x01(\tR\btimeZone\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x06 \x01(\x03R\tstartTime\x12\x19\n" +
+	"\bend_time\x18\a \x01(\x03R\aendTime\x12%\n" +
+	"\x0ejitter_seconds\x18\b \x01(\x03R\rjitterSeconds\x12?\n" +
+	"\x0emisfire_policy\x18\t \x01(\x0e2\x18.api.queue.MisfirePolicyR\rmisfirePolicy\x12\x1f\n" +
+	"\vmax_retries\x18\n" +
+	" \x01(\x05R\n" +
+	"maxRetries\x12-\n" +
+	"\x12visibility_timeout\x18\v \x01(\x03R\x11visibilityTimeout\x129\n" +
+	"\fretry_policy\x18\f \x01(\v2\x16.api.queue.RetryPolicyR\vretryPolicy\"I\n" +
+	"\x16CreateScheduleResponse\x12/\n" +
+	"\bschedule\x18\x01 \x01(\v2\x13.api.queue.ScheduleR\bschedule\"&\n" +
+	"\x14PauseScheduleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"H\n" +
+	"\x15PauseScheduleResponse\x12/\n" +
+	"\bschedule\x18\x01 \x01(\v2\x13.api.queue.ScheduleR\bschedule\"'\n" +
+	"\x15ResumeScheduleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"I\n" +
+	"\x16ResumeScheduleResponse\x12/\n" +
+	"\bschedule\x18\x01 \x01(\v2\x13.api.queue.ScheduleR\bschedule\",\n" +
+	"\x14ListSchedulesRequest\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\"J\n" +
+	"\x15ListSchedulesResponse\x121\n" +
+	"\tschedules\x18\x01 \x03(\v2\x13.api.queue.ScheduleR\tschedules\"'\n" +
+	"\x15DeleteScheduleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"2\n" +
+	"\x16DeleteScheduleResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x12\n" +
+	"\x10GetLeaderRequest\"|\n" +
+	"\x11GetLeaderResponse\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x1b\n" +
+	"\tis_leader\x18\x02 \x01(\bR\bisLeader\x12\x1b\n" +
+	"\tleader_id\x18\x03 \x01(\tR\bleaderId\x12\x14\n" +
+	"\x05token\x18\x04 \x01(\x03R\x05token\"\xc0\x01\n" +
+	"\x10SubscribeRequest\x12.\n" +
+	"\x04open\x18\x01 \x01(\v2\x18.api.queue.SubscribeOpenH\x00R\x04open\x12\x18\n" +
+	"\x06credit\x18\x02 \x01(\x05H\x00R\x06credit\x12)\n" +
+	"\x03ack\x18\x03 \x01(\v2\x15.api.queue.AckRequestH\x00R\x03ack\x12,\n" +
+	"\x04nack\x18\x04 \x01(\v2\x16.api.queue.NackRequestH\x00R\x04nackB\t\n" +
+	"\apayload\"?\n" +
+	"\rSubscribeOpen\x12\x16\n" +
+	"\x06topics\x18\x01 \x03(\tR\x06topics\x12\x16\n" +
+	"\x06credit\x18\x02 \x01(\x05R\x06credit\"u\n" +
+	"\x11SubscribeResponse\x12%\n" +
+	"\x04task\x18\x01 \x01(\v2\x0f.api.queue.TaskH\x00R\x04task\x12.\n" +
+	"\x06result\x18\x02 \x01(\v2\x14.api.queue.AckResultH\x00R\x06resultB\t\n" +
+	"\apayload\"c\n" +
+	"\tAckResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"\x8a\x04\n" +
+	"\x04Task\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05topic\x18\x02 \x01(\tR\x05topic\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\tR\apayload\x12!\n" +
+	"\fexecute_time\x18\x04 \x01(\x03R\vexecuteTime\x12\x1f\n" +
+	"\vretry_count\x18\x05 \x01(\x05R\n" +
+	"retryCount\x12\x1f\n" +
+	"\vmax_retries\x18\x06 \x01(\x05R\n" +
+	"maxRetries\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\b \x01(\tR\tlastError\x12\x14\n" +
+	"\x05lease\x18\t \x01(\tR\x05lease\x12-\n" +
+	"\x12visibility_timeout\x18\n" +
+	" \x01(\x03R\x11visibilityTimeout\x129\n" +
+	"\fretry_policy\x18\v \x01(\v2\x16.api.queue.RetryPolicyR\vretryPolicy\x12!\n" +
+	"\flast_backoff\x18\f \x01(\x03R\vlastBackoff\x12\x1f\n" +
+	"\vdead_reason\x18\r \x01(\tR\n" +
+	"deadReason\x12\x17\n" +
+	"\adead_at\x18\x0e \x01(\x03R\x06deadAt\x12\x1a\n" +
+	"\bpriority\x18\x0f \x01(\x05R\bpriority\x12&\n" +
+	"\x0fexecute_time_ms\x18\x10 \x01(\x03R\rexecuteTimeMs\"\xb0\x04\n" +
+	"\bSchedule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05topic\x18\x02 \x01(\tR\x05topic\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\tR\apayload\x12\x1b\n" +
+	"\tcron_expr\x18\x04 \x01(\tR\bcronExpr\x12\x1b\n" +
+	"\ttime_zone\x18\x05 \x01(\tR\btimeZone\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x06 \x01(\x03R\tstartTime\x12\x19\n" +
+	"\bend_time\x18\a \x01(\x03R\aendTime\x12%\n" +
+	"\x0ejitter_seconds\x18\b \x01(\x03R\rjitterSeconds\x12?\n" +
+	"\x0emisfire_policy\x18\t \x01(\x0e2\x18.api.queue.MisfirePolicyR\rmisfirePolicy\x12\x1f\n" +
+	"\vmax_retries\x18\n" +
+	" \x01(\x05R\n" +
+	"maxRetries\x12-\n" +
+	"\x12visibility_timeout\x18\v \x01(\x03R\x11visibilityTimeout\x129\n" +
+	"\fretry_policy\x18\f \x01(\v2\x16.api.queue.RetryPolicyR\vretryPolicy\x12\x16\n" +
+	"\x06paused\x18\r \x01(\bR\x06paused\x12\"\n" +
+	"\rnext_run_time\x18\x0e \x01(\x03R\vnextRunTime\x12\"\n" +
+	"\rlast_run_time\x18\x0f \x01(\x03R\vlastRunTime\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x10 \x01(\x03R\tcreatedAt\"\xd2\x01\n" +
+	"\vRetryPolicy\x124\n" +
+	"\bstrategy\x18\x01 \x01(\x0e2\x18.api.queue.RetryStrategyR\bstrategy\x12!\n" +
+	"\fbase_seconds\x18\x02 \x01(\x03R\vbaseSeconds\x12\x1f\n" +
+	"\vmax_seconds\x18\x03 \x01(\x03R\n" +
+	"maxSeconds\x12\x1e\n" +
+	"\n" +
+	"multiplier\x18\x04 \x01(\x01R\n" +
+	"multiplier\x12)\n" +
+	"\x10schedule_seconds\x18\x05 \x03(\x03R\x0fscheduleSeconds*\x83\x01\n" +
+	"\rMisfirePolicy\x12\x1e\n" +
+	"\x1aMISFIRE_POLICY_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13MISFIRE_POLICY_SKIP\x10\x01\x12\x1c\n" +
+	"\x18MISFIRE_POLICY_FIRE_ONCE\x10\x02\x12\x1b\n" +
+	"\x17MISFIRE_POLICY_FIRE_ALL\x10\x03*\xe9\x01\n" +
+	"\rRetryStrategy\x12\x1e\n" +
+	"\x1aRETRY_STRATEGY_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14RETRY_STRATEGY_FIXED\x10\x01\x12\x19\n" +
+	"\x15RETRY_STRATEGY_LINEAR\x10\x02\x12\x1e\n" +
+	"\x1aRETRY_STRATEGY_EXPONENTIAL\x10\x03\x12\x1e\n" +
+	"\x1aRETRY_STRATEGY_FULL_JITTER\x10\x04\x12&\n" +
+	"\"RETRY_STRATEGY_DECORRELATED_JITTER\x10\x05\x12\x1b\n" +
+	"\x17RETRY_STRATEGY_SCHEDULE\x10\x062\xff\v\n" +
+	"\x11DelayQueueService\x12@\n" +
+	"\aEnqueue\x12\x19.api.queue.EnqueueRequest\x1a\x1a.api.queue.EnqueueResponse\x12C\n" +
+	"\bRetrieve\x12\x1a.api.queue.RetrieveRequest\x1a\x1b.api.queue.RetrieveResponse\x12=\n" +
+	"\x06Delete\x12\x18.api.queue.DeleteRequest\x1a\x19.api.queue.DeleteResponse\x124\n" +
+	"\x03Ack\x12\x15.api.queue.AckRequest\x1a\x16.api.queue.AckResponse\x127\n" +
+	"\x04Nack\x12\x16.api.queue.NackRequest\x1a\x17.api.queue.NackResponse\x12J\n" +
+	"\tSubscribe\x12\x1b.api.queue.SubscribeRequest\x1a\x1c.api.queue.SubscribeResponse(\x010\x01\x12L\n" +
+	"\vExtendLease\x12\x1d.api.queue.ExtendLeaseRequest\x1a\x1e.api.queue.ExtendLeaseResponse\x12X\n" +
+	"\x0fListDeadLetters\x12!.api.queue.ListDeadLettersRequest\x1a\".api.queue.ListDeadLettersResponse\x12R\n" +
+	"\rGetDeadLetter\x12\x1f.api.queue.GetDeadLetterRequest\x1a .api.queue.GetDeadLetterResponse\x12a\n" +
+	"\x12RedriveDeadLetters\x12$.api.queue.RedriveDeadLettersRequest\x1a%.api.queue.RedriveDeadLettersResponse\x12[\n" +
+	"\x10PurgeDeadLetters\x12\".api.queue.PurgeDeadLettersRequest\x1a#.api.queue.PurgeDeadLettersResponse\x12X\n" +
+	"\x0fListQuarantined\x12!.api.queue.ListQuarantinedRequest\x1a\".api.queue.ListQuarantinedResponse\x12^\n" +
+	"\x11DeleteQuarantined\x12#.api.queue.DeleteQuarantinedRequest\x1a$.api.queue.DeleteQuarantinedResponse\x12U\n" +
+	"\x0eCreateSchedule\x12 .api.queue.CreateScheduleRequest\x1a!.api.queue.CreateScheduleResponse\x12R\n" +
+	"\rPauseSchedule\x12\x1f.api.queue.PauseScheduleRequest\x1a .api.queue.PauseScheduleResponse\x12U\n" +
+	"\x0eResumeSchedule\x12 .api.queue.ResumeScheduleRequest\x1a!.api.queue.ResumeScheduleResponse\x12R\n" +
+	"\rListSchedules\x12\x1f.api.queue.ListSchedulesRequest\x1a .api.queue.ListSchedulesResponse\x12U\n" +
+	"\x0eDeleteSchedule\x12 .api.queue.DeleteScheduleRequest\x1a!.api.queue.DeleteScheduleResponse\x12F\n" +
+	"\tGetLeader\x12\x1b.api.queue.GetLeaderRequest\x1a\x1c.api.queue.GetLeaderResponseB8Z6github.com/AkikoAkaki/async-task-platform/api/proto;pbb\x06proto3"
+
+var (
+	file_api_proto_queue_proto_rawDescOnce sync.Once
+	file_api_proto_queue_proto_rawDescData []byte
+)
+
+func file_api_proto_queue_proto_rawDescGZIP() []byte {
+	file_api_proto_queue_proto_rawDescOnce.Do(func() {
+		file_api_proto_queue_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_proto_queue_proto_rawDesc), len(file_api_proto_queue_proto_rawDesc)))
+	})
+	return file_api_proto_queue_proto_rawDescData
+}
+
+var file_api_proto_queue_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_api_proto_queue_proto_msgTypes = make([]protoimpl.MessageInfo, 44)
+var file_api_proto_queue_proto_goTypes = []any{
+	(MisfirePolicy)(0),                 // 0: api.queue.MisfirePolicy
+	(RetryStrategy)(0),                 // 1: api.queue.RetryStrategy
+	(*EnqueueRequest)(nil),             // 2: api.queue.EnqueueRequest
+	(*EnqueueResponse)(nil),            // 3: api.queue.EnqueueResponse
+	(*RetrieveRequest)(nil),            // 4: api.queue.RetrieveRequest
+	(*RetrieveResponse)(nil),           // 5: api.queue.RetrieveResponse
+	(*DeleteRequest)(nil),              // 6: api.queue.DeleteRequest
+	(*DeleteResponse)(nil),             // 7: api.queue.DeleteResponse
+	(*AckRequest)(nil),                 // 8: api.queue.AckRequest
+	(*AckResponse)(nil),                // 9: api.queue.AckResponse
+	(*NackRequest)(nil),                // 10: api.queue.NackRequest
+	(*NackResponse)(nil),               // 11: api.queue.NackResponse
+	(*ExtendLeaseRequest)(nil),         // 12: api.queue.ExtendLeaseRequest
+	(*ExtendLeaseResponse)(nil),        // 13: api.queue.ExtendLeaseResponse
+	(*ListDeadLettersRequest)(nil),     // 14: api.queue.ListDeadLettersRequest
+	(*ListDeadLettersResponse)(nil),    // 15: api.queue.ListDeadLettersResponse
+	(*GetDeadLetterRequest)(nil),       // 16: api.queue.GetDeadLetterRequest
+	(*GetDeadLetterResponse)(nil),      // 17: api.queue.GetDeadLetterResponse
+	(*RedriveDeadLettersRequest)(nil),  // 18: api.queue.RedriveDeadLettersRequest
+	(*RedriveDeadLettersResponse)(nil), // 19: api.queue.RedriveDeadLettersResponse
+	(*PurgeDeadLettersRequest)(nil),    // 20: api.queue.PurgeDeadLettersRequest
+	(*PurgeDeadLettersResponse)(nil),   // 21: api.queue.PurgeDeadLettersResponse
+	(*QuarantinedEntry)(nil),           // 22: api.queue.QuarantinedEntry
+	(*ListQuarantinedRequest)(nil),     // 23: api.queue.ListQuarantinedRequest
+	(*ListQuarantinedResponse)(nil),    // 24: api.queue.ListQuarantinedResponse
+	(*DeleteQuarantinedRequest)(nil),   // 25: api.queue.DeleteQuarantinedRequest
+	(*DeleteQuarantinedResponse)(nil),  // 26: api.queue.DeleteQuarantinedResponse
+	(*CreateScheduleRequest)(nil),      // 27: api.queue.CreateScheduleRequest
+	(*CreateScheduleResponse)(nil),     // 28: api.queue.CreateScheduleResponse
+	(*PauseScheduleRequest)(nil),       // 29: api.queue.PauseScheduleRequest
+	(*PauseScheduleResponse)(nil),      // 30: api.queue.PauseScheduleResponse
+	(*ResumeScheduleRequest)(nil),      // 31: api.queue.ResumeScheduleRequest
+	(*ResumeScheduleResponse)(nil),     // 32: api.queue.ResumeScheduleResponse
+	(*ListSchedulesRequest)(nil),       // 33: api.queue.ListSchedulesRequest
+	(*ListSchedulesResponse)(nil),      // 34: api.queue.ListSchedulesResponse
+	(*DeleteScheduleRequest)(nil),      // 35: api.queue.DeleteScheduleRequest
+	(*DeleteScheduleResponse)(nil),     // 36: api.queue.DeleteScheduleResponse
+	(*GetLeaderRequest)(nil),           // 37: api.queue.GetLeaderRequest
+	(*GetLeaderResponse)(nil),          // 38: api.queue.GetLeaderResponse
+	(*SubscribeRequest)(nil),           // 39: api.queue.SubscribeRequest
+	(*SubscribeOpen)(nil),              // 40: api.queue.SubscribeOpen
+	(*SubscribeResponse)(nil),          // 41: api.queue.SubscribeResponse
+	(*AckResult)(nil),                  // 42: api.queue.AckResult
+	(*Task)(nil),                       // 43: api.queue.Task
+	(*Schedule)(nil),                   // 44: api.queue.Schedule
+	(*RetryPolicy)(nil),                // 45: api.queue.RetryPolicy
+	(*timestamppb.Timestamp)(nil),      // 46: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),        // 47: google.protobuf.Duration
+}
+var file_api_proto_queue_proto_depIdxs = []int32{
+	45, // 0: api.queue.EnqueueRequest.retry_policy:type_name -> api.queue.RetryPolicy
+	46, // 1: api.queue.EnqueueRequest.execute_at:type_name -> google.protobuf.Timestamp
+	47, // 2: api.queue.EnqueueRequest.delay:type_name -> google.protobuf.Duration
+	43, // 3: api.queue.RetrieveResponse.tasks:type_name -> api.queue.Task
+	43, // 4: api.queue.ListDeadLettersResponse.tasks:type_name -> api.queue.Task
+	43, // 5: api.queue.GetDeadLetterResponse.task:type_name -> api.queue.Task
+	22, // 6: api.queue.ListQuarantinedResponse.entries:type_name -> api.queue.QuarantinedEntry
+	0,  // 7: api.queue.CreateScheduleRequest.misfire_policy:type_name -> api.queue.MisfirePolicy
+	45, // 8: api.queue.CreateScheduleRequest.retry_policy:type_name -> api.queue.RetryPolicy
+	44, // 9: api.queue.CreateScheduleResponse.schedule:type_name -> api.queue.Schedule
+	44, // 10: api.queue.PauseScheduleResponse.schedule:type_name -> api.queue.Schedule
+	44, // 11: api.queue.ResumeScheduleResponse.schedule:type_name -> api.queue.Schedule
+	44, // 12: api.queue.ListSchedulesResponse.schedules:type_name -> api.queue.Schedule
+	40, // 13: api.queue.SubscribeRequest.open:type_name -> api.queue.SubscribeOpen
+	8,  // 14: api.queue.SubscribeRequest.ack:type_name -> api.queue.AckRequest
+	10, // 15: api.queue.SubscribeRequest.nack:type_name -> api.queue.NackRequest
+	43, // 16: api.queue.SubscribeResponse.task:type_name -> api.queue.Task
+	42, // 17: api.queue.SubscribeResponse.result:type_name -> api.queue.AckResult
+	45, // 18: api.queue.Task.retry_policy:type_name -> api.queue.RetryPolicy
+	0,  // 19: api.queue.Schedule.misfire_policy:type_name -> api.queue.MisfirePolicy
+	45, // 20: api.queue.Schedule.retry_policy:type_name -> api.queue.RetryPolicy
+	1,  // 21: api.queue.RetryPolicy.strategy:type_name -> api.queue.RetryStrategy
+	2,  // 22: api.queue.DelayQueueService.Enqueue:input_type -> api.queue.EnqueueRequest
+	4,  // 23: api.queue.DelayQueueService.Retrieve:input_type -> api.queue.RetrieveRequest
+	6,  // 24: api.queue.DelayQueueService.Delete:input_type -> api.queue.DeleteRequest
+	8,  // 25: api.queue.DelayQueueService.Ack:input_type -> api.queue.AckRequest
+	10, // 26: api.queue.DelayQueueService.Nack:input_type -> api.queue.NackRequest
+	39, // 27: api.queue.DelayQueueService.Subscribe:input_type -> api.queue.SubscribeRequest
+	12, // 28: api.queue.DelayQueueService.ExtendLease:input_type -> api.queue.ExtendLeaseRequest
+	14, // 29: api.queue.DelayQueueService.ListDeadLetters:input_type -> api.queue.ListDeadLettersRequest
+	16, // 30: api.queue.DelayQueueService.GetDeadLetter:input_type -> api.queue.GetDeadLetterRequest
+	18, // 31: api.queue.DelayQueueService.RedriveDeadLetters:input_type -> api.queue.RedriveDeadLettersRequest
+	20, // 32: api.queue.DelayQueueService.PurgeDeadLetters:input_type -> api.queue.PurgeDeadLettersRequest
+	23, // 33: api.queue.DelayQueueService.ListQuarantined:input_type -> api.queue.ListQuarantinedRequest
+	25, // 34: api.queue.DelayQueueService.DeleteQuarantined:input_type -> api.queue.DeleteQuarantinedRequest
+	27, // 35: api.queue.DelayQueueService.CreateSchedule:input_type -> api.queue.CreateScheduleRequest
+	29, // 36: api.queue.DelayQueueService.PauseSchedule:input_type -> api.queue.PauseScheduleRequest
+	31, // 37: api.queue.DelayQueueService.ResumeSchedule:input_type -> api.queue.ResumeScheduleRequest
+	33, // 38: api.queue.DelayQueueService.ListSchedules:input_type -> api.queue.ListSchedulesRequest
+	35, // 39: api.queue.DelayQueueService.DeleteSchedule:input_type -> api.queue.DeleteScheduleRequest
+	37, // 40: api.queue.DelayQueueService.GetLeader:input_type -> api.queue.GetLeaderRequest
+	3,  // 41: api.queue.DelayQueueService.Enqueue:output_type -> api.queue.EnqueueResponse
+	5,  // 42: api.queue.DelayQueueService.Retrieve:output_type -> api.queue.RetrieveResponse
+	7,  // 43: api.queue.DelayQueueService.Delete:output_type -> api.queue.DeleteResponse
+	9,  // 44: api.queue.DelayQueueService.Ack:output_type -> api.queue.AckResponse
+	11, // 45: api.queue.DelayQueueService.Nack:output_type -> api.queue.NackResponse
+	41, // 46: api.queue.DelayQueueService.Subscribe:output_type -> api.queue.SubscribeResponse
+	13, // 47: api.queue.DelayQueueService.ExtendLease:output_type -> api.queue.ExtendLeaseResponse
+	15, // 48: api.queue.DelayQueueService.ListDeadLetters:output_type -> api.queue.ListDeadLettersResponse
+	17, // 49: api.queue.DelayQueueService.GetDeadLetter:output_type -> api.queue.GetDeadLetterResponse
+	19, // 50: api.queue.DelayQueueService.RedriveDeadLetters:output_type -> api.queue.RedriveDeadLettersResponse
+	21, // 51: api.queue.DelayQueueService.PurgeDeadLetters:output_type -> api.queue.PurgeDeadLettersResponse
+	24, // 52: api.queue.DelayQueueService.ListQuarantined:output_type -> api.queue.ListQuarantinedResponse
+	26, // 53: api.queue.DelayQueueService.DeleteQuarantined:output_type -> api.queue.DeleteQuarantinedResponse
+	28, // 54: api.queue.DelayQueueService.CreateSchedule:output_type -> api.queue.CreateScheduleResponse
+	30, // 55: api.queue.DelayQueueService.PauseSchedule:output_type -> api.queue.PauseScheduleResponse
+	32, // 56: api.queue.DelayQueueService.ResumeSchedule:output_type -> api.queue.ResumeScheduleResponse
+	34, // 57: api.queue.DelayQueueService.ListSchedules:output_type -> api.queue.ListSchedulesResponse
+	36, // 58: api.queue.DelayQueueService.DeleteSchedule:output_type -> api.queue.DeleteScheduleResponse
+	38, // 59: api.queue.DelayQueueService.GetLeader:output_type -> api.queue.GetLeaderResponse
+	41, // [41:60] is the sub-list for method output_type
+	22, // [22:41] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
+}
+
+func init() { file_api_proto_queue_proto_init() }
+func file_api_proto_queue_proto_init() {
 	if File_api_proto_queue_proto != nil {
 		return
 	}
+	file_api_proto_queue_proto_msgTypes[37].OneofWrappers = []any{
+		(*SubscribeRequest_Open)(nil),
+		(*SubscribeRequest_Credit)(nil),
+		(*SubscribeRequest_Ack)(nil),
+		(*SubscribeRequest_Nack)(nil),
+	}
+	file_api_proto_queue_proto_msgTypes[39].OneofWrappers = []any{
+		(*SubscribeResponse_Task)(nil),
+		(*SubscribeResponse_Result)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_queue_proto_rawDesc), len(file_api_proto_queue_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   7,
+			NumEnums:      2,
+			NumMessages:   44,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_api_proto_queue_proto_goTypes,
 		DependencyIndexes: file_api_proto_queue_proto_depIdxs,
+		EnumInfos:         file_api_proto_queue_proto_enumTypes,
 		MessageInfos:      file_api_proto_queue_proto_msgTypes,
 	}.Build()
 	File_api_proto_queue_proto = out.File
@@ -0,0 +1,35 @@
+// Package main 存量数据迁移工具。
+// 职责：将 MVP 版本全局 Key（ddq:tasks / ddq:running / ddq:dlq）中的任务一次性迁移到按 Topic 分区的新布局。
+// @Usage: 迁移前应停止 Server 与 Worker，迁移完成后再以新版本启动。
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/redis"
+)
+
+func main() {
+	defaultTopic := flag.String("default-topic", "default", "topic assigned to legacy tasks without one")
+	flag.Parse()
+
+	// 1. 加载配置
+	cfg, err := conf.Load("./config")
+	if err != nil {
+		cfg, err = conf.Load("../../config")
+		if err != nil {
+			log.Fatalf("failed to load config: %v", err)
+		}
+	}
+
+	// 2. 执行迁移
+	store := redis.NewStore(cfg.Redis.Addr)
+	moved, err := store.MigrateLegacy(context.Background(), *defaultTopic)
+	if err != nil {
+		log.Fatalf("migration aborted after %d entries: %v", moved, err)
+	}
+	log.Printf("Migration finished, %d legacy entries processed", moved)
+}
@@ -1,20 +1,30 @@
 // Package main 项目启动入口。
-// 职责：负责配置加载、基础设施（Redis）初始化、gRPC 服务注册以及生命周期管理（优雅退出）。
+// 职责：负责配置加载、基础设施（存储层）初始化、gRPC 服务注册以及生命周期管理（优雅退出）。
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
+	"time"
 
 	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
 	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+	"github.com/AkikoAkaki/async-task-platform/internal/election"
 	"github.com/AkikoAkaki/async-task-platform/internal/queue"
 	"github.com/AkikoAkaki/async-task-platform/internal/scheduler"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/local"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/memory"
 	"github.com/AkikoAkaki/async-task-platform/internal/storage/redis"
+	sqlstore "github.com/AkikoAkaki/async-task-platform/internal/storage/sql"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/tiered"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
@@ -33,13 +43,64 @@ func main() {
 	log.Printf("Starting %s [%s]...", cfg.App.Name, cfg.App.Env)
 
 	// 2. 核心存储层初始化。
-	// @Note: 使用 Redis 作为主存储，内部包含 JobStore 接口实现。
-	store := redis.NewStore(cfg.Redis.Addr)
+	// @Note: 由 storage.driver 选择 JobStore 实现，默认使用 Redis。
+	store, err := newStore(cfg)
+	if err != nil {
+		log.Fatalf("failed to init storage: %v", err)
+	}
 
 	// 3. 异步调度组件启动。
 	// @Watchdog: 负责可见性超时任务的自动恢复。
+	// @CronScheduler: 将到期的周期任务物化为待执行任务，多副本之间由存储层 CAS 保证只投递一次。
+	// @Election: 开启选主时两者只在 Leader 副本上运行，失去 Leader 身份时停止，由新 Leader 接管。
+	// @SkewDetector: 每个副本各自检测本地时钟与存储服务端时钟的偏差，与选主无关；存储层无独立时钟时不启动。
+	// @Mover: 开启冷热分层时将冷存储中即将到期的任务迁回热存储，与 Watchdog 一样由 Leader 运行。
+	// 分层存储的时钟与选主锁都来自热存储。
+	base := store
+	var mover *tiered.Mover
+	if ts, ok := store.(*tiered.Store); ok {
+		base = ts.Hot()
+		interval := cfg.Storage.Tiering.MoveInterval
+		if interval <= 0 {
+			interval = 10
+		}
+		mover = tiered.NewMover(ts, time.Duration(interval)*time.Second)
+	}
+	var sd *scheduler.SkewDetector
+	if clock, ok := base.(storage.Clock); ok {
+		sd = scheduler.NewSkewDetector(cfg.Scheduler, clock)
+		sd.Start()
+	}
 	wd := scheduler.NewWatchdog(cfg.Queue, store)
-	wd.Start()
+	var cs *scheduler.CronScheduler
+	if ss, ok := store.(storage.ScheduleStore); ok {
+		cs = scheduler.NewCronScheduler(cfg.Scheduler, ss)
+	}
+	var elector *election.Elector
+	if rs, ok := base.(*redis.Store); ok && cfg.Scheduler.LeaderElection {
+		lock := election.NewRedisLock(rs.GetClient(), "ddq:leader")
+		elector = election.NewElector(lock, nodeID(cfg.Scheduler.NodeID),
+			time.Duration(cfg.Scheduler.LeaseDuration)*time.Second)
+		runs := []func(context.Context){wd.Run}
+		if cs != nil {
+			runs = append(runs, cs.Run)
+		}
+		if mover != nil {
+			runs = append(runs, mover.Run)
+		}
+		elector.Start(runs...)
+	} else {
+		if cfg.Scheduler.LeaderElection {
+			log.Printf("leader election requires the redis storage driver, disabled for %q", cfg.Storage.Driver)
+		}
+		wd.Start()
+		if cs != nil {
+			cs.Start()
+		}
+		if mover != nil {
+			mover.Start()
+		}
+	}
 
 	// 4. 网络层监听。
 	// @Address: 默认从配置中读取 gRPC 端口号。
@@ -52,7 +113,11 @@ func main() {
 	// 5. gRPC 服务注册。
 	// @Services: 注册延迟队列业务服务，并开启反射（Reflection）以便于调试。
 	s := grpc.NewServer()
-	svc := queue.NewService(store)
+	var opts []queue.Option
+	if elector != nil {
+		opts = append(opts, queue.WithElector(elector))
+	}
+	svc := queue.NewService(cfg.Queue, store, opts...)
 	pb.RegisterDelayQueueServiceServer(s, svc)
 	reflection.Register(s)
 
@@ -71,7 +136,127 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down gRPC server...")
-	wd.Stop()
+	// 先让出 Leader 身份并释放租约，其他副本无需等待租约过期即可接管
+	if elector != nil {
+		elector.Stop()
+	} else {
+		if mover != nil {
+			mover.Stop()
+		}
+		if cs != nil {
+			cs.Stop()
+		}
+		wd.Stop()
+	}
+	if sd != nil {
+		sd.Stop()
+	}
 	s.GracefulStop()
+	// 本地磁盘存储（包括作为分层的冷存储时）需要落盘并关闭预写日志
+	if c, ok := store.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			log.Printf("failed to close storage: %v", err)
+		}
+	}
 	log.Println("Server stopped")
 }
+
+// newStore 按 storage.driver 创建 JobStore，开启 storage.tiering 时以其作为热存储并包装为分层存储。
+func newStore(cfg *conf.Config) (storage.JobStore, error) {
+	hot, err := openStore(cfg, cfg.Storage.Driver, false)
+	if err != nil || !cfg.Storage.Tiering.Enabled {
+		return hot, err
+	}
+
+	t := cfg.Storage.Tiering
+	if t.ColdDriver != "sql" && t.ColdDriver != "local" {
+		return nil, fmt.Errorf("storage.tiering.cold_driver must be sql or local, got %q", t.ColdDriver)
+	}
+	cold, err := openStore(cfg, t.ColdDriver, true)
+	if err != nil {
+		return nil, fmt.Errorf("open cold store failed: %w", err)
+	}
+	log.Printf("Tiered storage enabled: tasks due beyond %ds go to the %s store", t.Horizon, t.ColdDriver)
+	return tiered.NewStore(hot, cold,
+		tiered.WithHorizon(time.Duration(t.Horizon)*time.Second),
+		tiered.WithPromoteAhead(time.Duration(t.PromoteAhead)*time.Second)), nil
+}
+
+// openStore 创建 driver 对应的 JobStore。
+// @Param cold: 作为分层的冷存储时不保留去重窗口，并使用独立的表名前缀或数据目录，与同类型的热存储互不干扰。
+func openStore(cfg *conf.Config, driver string, cold bool) (storage.JobStore, error) {
+	dedupWindow := time.Duration(cfg.Queue.DedupWindow) * time.Second
+	visibilityTimeout := time.Duration(cfg.Queue.VisibilityTimeout) * time.Second
+	priorityAging := time.Duration(cfg.Queue.PriorityAging) * time.Second
+	tablePrefix := cfg.Storage.SQL.TablePrefix
+	localDir := cfg.Storage.Local.Dir
+	if localDir == "" {
+		localDir = "./data"
+	}
+	if cold {
+		dedupWindow = 0
+		if tablePrefix == "" {
+			tablePrefix = "ddq"
+		}
+		tablePrefix += "_cold"
+		localDir = filepath.Join(localDir, "cold")
+	}
+
+	switch driver {
+	case "", "redis":
+		return redis.NewStore(cfg.Redis.Addr,
+			redis.WithDedupWindow(dedupWindow),
+			redis.WithVisibilityTimeout(visibilityTimeout),
+			redis.WithPriorityAging(priorityAging)), nil
+	case "sql":
+		dialect, err := sqlstore.ParseDialect(cfg.Storage.SQL.Dialect)
+		if err != nil {
+			return nil, err
+		}
+		// 驱动需以空白导入链接进二进制，如 _ "github.com/jackc/pgx/v5/stdlib"
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return sqlstore.Open(ctx, dialect, cfg.Storage.SQL.DriverName, cfg.Storage.SQL.DSN,
+			sqlstore.WithTablePrefix(tablePrefix),
+			sqlstore.WithDedupWindow(dedupWindow),
+			sqlstore.WithVisibilityTimeout(visibilityTimeout),
+			sqlstore.WithPriorityAging(priorityAging))
+	case "memory":
+		log.Println("Using in-memory storage: data is lost on restart, do not run more than one replica")
+		return memory.NewStore(
+			memory.WithDedupWindow(dedupWindow),
+			memory.WithVisibilityTimeout(visibilityTimeout),
+			memory.WithPriorityAging(priorityAging)), nil
+	case "local":
+		fsync, err := local.ParseFsyncPolicy(cfg.Storage.Local.Fsync)
+		if err != nil {
+			return nil, err
+		}
+		compactSize := int64(64)
+		if cfg.Storage.Local.CompactSizeMB > 0 {
+			compactSize = int64(cfg.Storage.Local.CompactSizeMB)
+		}
+		log.Printf("Using local disk storage at %s: do not run more than one replica on the same directory", localDir)
+		return local.Open(localDir,
+			local.WithFsyncPolicy(fsync),
+			local.WithFsyncInterval(cfg.Storage.Local.FsyncInterval),
+			local.WithCompactSize(compactSize<<20),
+			local.WithDedupWindow(dedupWindow),
+			local.WithVisibilityTimeout(visibilityTimeout),
+			local.WithPriorityAging(priorityAging))
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", driver)
+	}
+}
+
+// nodeID 返回当前副本在选主中使用的 ID，未配置时使用 "<hostname>-<pid>"。
+func nodeID(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
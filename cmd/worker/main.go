@@ -3,14 +3,21 @@ package main
 import (
 	"context"
 	"log"
-	"os"
 	"os/signal"
-	"sync"
 	"syscall"
 	"time"
 
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
 	"github.com/AkikoAkaki/async-task-platform/internal/conf"
-	"github.com/AkikoAkaki/async-task-platform/internal/storage/redis"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/AkikoAkaki/async-task-platform/pkg/client"
+	"github.com/AkikoAkaki/async-task-platform/pkg/worker"
+)
+
+// 未配置 worker.topics 时消费的 Topic 及其并发度。
+const (
+	defaultTopic       = "default"
+	defaultConcurrency = 10
 )
 
 func main() {
@@ -23,66 +30,47 @@ func main() {
 		}
 	}
 
-	// 2. 使用配置连接 Redis
-	store := redis.NewStore(cfg.Redis.Addr)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	log.Println("Worker started, polling for tasks...")
-
-	// 使用 WaitGroup 保证退出时处理完当前 Loop
-	var wg sync.WaitGroup
-	wg.Add(1)
-
-	go func() {
-		defer wg.Done()
-		ticker := time.NewTicker(1 * time.Second) // 轮询间隔 1秒
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				// 收到停止信号，退出循环
-				return
-			case <-ticker.C:
-				// 1. 拉取任务
-				tasks, err := store.FetchAndHold(ctx, "default", 10)
-				if err != nil {
-					log.Printf("Error polling tasks: %v", err)
-					continue
-				}
-
-				// 2. 执行任务 (MVP: 仅打印)
-				if len(tasks) > 0 {
-					log.Printf("--- Processed %d tasks ---", len(tasks))
-					for _, t := range tasks {
-						// 工业级：这里应该扔给一个 Worker Pool 线程池去并发执行，而不是串行阻塞
-						log.Printf("[EXECUTE] TaskID: %s, Payload: %s, Delay: %ds",
-							t.Id, t.Payload, time.Now().Unix()-t.ExecuteTime)
-
-						// 3. 任务执行成功后，调用 Ack 确认完成
-						// @Critical: 如果不调用 Ack，任务会永远停留在 Running 状态，
-						// 最终被 Watchdog 认为超时并重新入队，导致重复执行。
-						if err := store.Ack(ctx, t.Id); err != nil {
-							log.Printf("[ERROR] Ack failed for task %s: %v", t.Id, err)
-							// 注意：Ack 失败意味着任务状态不一致，Watchdog 会恢复它
-						} else {
-							log.Printf("[ACK] Task %s completed successfully", t.Id)
-						}
-					}
-				}
-			}
+	// 2. 连接 Server：Worker 只通过 gRPC 消费任务，不持有 Redis 凭据
+	c, err := client.New(cfg.Worker.ServerAddr)
+	if err != nil {
+		log.Fatalf("failed to connect server: %v", err)
+	}
+	defer func() {
+		if err := c.Close(); err != nil {
+			log.Printf("Failed to close connection: %v", err)
 		}
 	}()
 
-	// 优雅退出监听
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	// 3. 按 Topic 注册 Handler
+	// 任务自带可见性超时时以其为准，否则使用全局 queue.visibility_timeout
+	w := worker.New(c,
+		worker.WithHeartbeat(time.Duration(cfg.Worker.HeartbeatInterval)*time.Second),
+		worker.WithVisibilityTimeout(time.Duration(cfg.Queue.VisibilityTimeout)*time.Second),
+		worker.WithDrainTimeout(time.Duration(cfg.Worker.DrainTimeout)*time.Second),
+	)
+	topics := cfg.Worker.Topics
+	if len(topics) == 0 {
+		topics = map[string]int{defaultTopic: defaultConcurrency}
+	}
+	for topic, concurrency := range topics {
+		w.Handle(topic, execute, concurrency)
+	}
+
+	// 4. 收到退出信号后停止拉取，等待执行中的任务完成
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	log.Println("Worker shutting down...")
-	cancel()  // 通知 Loop 停止
-	wg.Wait() // 等待 Loop 彻底结束
+	log.Printf("Worker started, subscribing to %s for topics %v...", cfg.Worker.ServerAddr, topics)
+	if err := w.Run(ctx); err != nil {
+		log.Printf("Worker stopped: %v", err)
+		return
+	}
 	log.Println("Worker stopped")
 }
+
+// execute 执行任务 (MVP: 仅打印)。返回 nil 时运行时自动 Ack，返回 error 时 Nack 并按重试策略重投。
+func execute(_ context.Context, t *pb.Task) error {
+	log.Printf("[EXECUTE] TaskID: %s, Topic: %s, Payload: %s, Delay: %v",
+		t.Id, t.Topic, t.Payload, time.Since(storage.ExecuteAt(t)).Round(time.Millisecond))
+	return nil
+}
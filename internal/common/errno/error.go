@@ -38,9 +38,19 @@ var (
 	ErrInternalServerError = New(10001, "internal server error")
 	// 10002：输入参数校验失败。
 	ErrInvalidParam = New(10002, "invalid parameter")
+	// 10003：存储实现不支持该操作（如分层存储的热存储缺少对应的可选能力）。
+	ErrNotSupported = New(10003, "storage backend does not support this operation")
 
 	// 20001：请求的任务资源在系统中不存在。
 	ErrTaskNotFound = New(20001, "task not found")
 	// 20002：尝试创建已存在的任务资源。
 	ErrTaskAlreadyExist = New(20002, "task already exists")
+	// 20003：任务已被 Worker 持有执行，当前状态不允许该操作（如撤销）。
+	ErrTaskRunning = New(20003, "task is running")
+	// 20004：租约令牌与当前投递不匹配，任务已超时并被重新投递给其他 Worker。
+	ErrLeaseMismatch = New(20004, "lease token mismatch")
+	// 20005：请求的周期任务在系统中不存在。
+	ErrScheduleNotFound = New(20005, "schedule not found")
+	// 20006：尝试创建已存在的周期任务。
+	ErrScheduleAlreadyExist = New(20006, "schedule already exists")
 )
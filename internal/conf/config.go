@@ -3,15 +3,19 @@ package conf
 import (
 	"log"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	App    AppConfig    `mapstructure:"app"`
-	Server ServerConfig `mapstructure:"server"`
-	Redis  RedisConfig  `mapstructure:"redis"`
-	Queue  QueueConfig  `mapstructure:"queue"`
+	App       AppConfig       `mapstructure:"app"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Storage   StorageConfig   `mapstructure:"storage"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	Queue     QueueConfig     `mapstructure:"queue"`
+	Worker    WorkerConfig    `mapstructure:"worker"`
+	Scheduler SchedulerConfig `mapstructure:"scheduler"`
 }
 
 type AppConfig struct {
@@ -24,6 +28,87 @@ type ServerConfig struct {
 	GrpcPort int `mapstructure:"grpc_port"`
 }
 
+// WorkerConfig Worker 进程配置。Worker 仅通过 gRPC 与 Server 交互，不直接访问 Redis。
+type WorkerConfig struct {
+	// Server 的 gRPC 地址，如 "localhost:9090"
+	ServerAddr string `mapstructure:"server_addr"`
+	// 执行任务期间调用 ExtendLease 续租的间隔 (秒)，<=0 表示不发送心跳
+	HeartbeatInterval int `mapstructure:"heartbeat_interval"`
+	// 消费的 Topic 及其并发度 (同时执行的任务数)，为空时只消费 "default"，并发度 10
+	Topics map[string]int `mapstructure:"topics"`
+	// 优雅退出时等待执行中任务完成的最长时间 (秒)，<=0 时为 30
+	DrainTimeout int `mapstructure:"drain_timeout"`
+}
+
+// SchedulerConfig 周期任务调度器配置。
+type SchedulerConfig struct {
+	// 扫描到期周期任务的间隔 (秒)，<=0 时为 1
+	Interval int `mapstructure:"interval"`
+	// 触发时刻过去超过该时长 (秒) 仍未投递视为错过 (misfire)，按周期任务的 misfire_policy 处理，<=0 时为 60
+	MisfireThreshold int `mapstructure:"misfire_threshold"`
+	// 是否开启选主。开启后 Watchdog 与周期任务调度器只在 Leader 副本上运行
+	LeaderElection bool `mapstructure:"leader_election"`
+	// Leader 租约有效期 (秒)，Leader 宕机后其他副本最迟在该时间后接管，<=0 时为 15
+	LeaseDuration int `mapstructure:"lease_duration"`
+	// 当前副本 ID，为空时使用 "<hostname>-<pid>"
+	NodeID string `mapstructure:"node_id"`
+	// 检测本地时钟与 Redis 服务端时钟偏差的间隔 (秒)，<=0 时为 30
+	ClockSkewInterval int `mapstructure:"clock_skew_interval"`
+	// 本地时钟与 Redis 服务端时钟的偏差超过该值 (毫秒) 时告警，<=0 时为 500
+	MaxClockSkewMs int `mapstructure:"max_clock_skew_ms"`
+}
+
+// StorageConfig 存储后端配置。
+type StorageConfig struct {
+	// 存储后端：redis (默认) / memory / sql / local。memory 为进程内存储，重启后数据丢失，仅适用于单副本嵌入式部署与测试；
+	// local 同样只能单副本运行，但数据持久化到本地磁盘，重启后恢复
+	Driver string `mapstructure:"driver"`
+	// driver 为 sql 时的数据库配置
+	SQL SQLConfig `mapstructure:"sql"`
+	// driver 为 local 时的本地磁盘配置
+	Local LocalConfig `mapstructure:"local"`
+	// 冷热分层配置
+	Tiering TieringConfig `mapstructure:"tiering"`
+}
+
+// TieringConfig 冷热分层配置。
+type TieringConfig struct {
+	// 开启后 driver 选择的存储作为热存储，执行时间晚于 horizon 的任务写入冷存储
+	Enabled bool `mapstructure:"enabled"`
+	// 冷存储：sql / local，连接参数复用 storage.sql / storage.local（表名前缀追加 "_cold"，数据目录为其下的 cold 子目录）
+	ColdDriver string `mapstructure:"cold_driver"`
+	// 分层阈值 (秒)，默认 86400
+	Horizon int `mapstructure:"horizon"`
+	// 冷存储中的任务在执行时间之前多少秒迁回热存储，默认 3600，不超过 horizon
+	PromoteAhead int `mapstructure:"promote_ahead"`
+	// 迁移扫描间隔 (秒)，默认 10
+	MoveInterval int `mapstructure:"move_interval"`
+}
+
+// SQLConfig 关系型数据库存储配置。
+type SQLConfig struct {
+	// SQL 方言：postgres / mysql / sqlite
+	Dialect string `mapstructure:"dialect"`
+	// 已链接进二进制的 database/sql 驱动名，为空时与方言同名
+	DriverName string `mapstructure:"driver_name"`
+	// 数据源名称，格式由驱动决定
+	DSN string `mapstructure:"dsn"`
+	// 表名前缀，为空时为 "ddq"
+	TablePrefix string `mapstructure:"table_prefix"`
+}
+
+// LocalConfig 本地磁盘存储配置。
+type LocalConfig struct {
+	// 数据目录，保存预写日志与快照，为空时为 "./data"
+	Dir string `mapstructure:"dir"`
+	// 预写日志落盘策略：always (每次写入) / interval (默认，周期批量) / never (交给操作系统)
+	Fsync string `mapstructure:"fsync"`
+	// fsync 为 interval 时的落盘周期，默认 1s
+	FsyncInterval time.Duration `mapstructure:"fsync_interval"`
+	// 日志分段超过该大小 (MB) 时写快照并删除旧分段，默认 64
+	CompactSizeMB int `mapstructure:"compact_size_mb"`
+}
+
 type RedisConfig struct {
 	Addr     string `mapstructure:"addr"`
 	Password string `mapstructure:"password"`
@@ -37,6 +122,36 @@ type QueueConfig struct {
 	WatchdogInterval int `mapstructure:"watchdog_interval"`
 	// 默认最大重试次数
 	MaxRetries int `mapstructure:"max_retries"`
+	// 重复 ID 提交的处理策略：return_existing (默认) / reject / replace
+	DedupPolicy string `mapstructure:"dedup_policy"`
+	// 去重窗口 (秒)，任务完成后其 ID 仍在该时间内被视为已存在，<=0 表示仅在任务存续期间去重
+	DedupWindow int `mapstructure:"dedup_window"`
+	// 优先级老化周期 (秒)：任务每多等待该时长，有效优先级提升 1 级，防止低优先级任务饿死；<=0 时为 60
+	PriorityAging int `mapstructure:"priority_aging"`
+	// 全局默认的失败重试退避策略
+	Retry RetryConfig `mapstructure:"retry"`
+	// 按 Topic 覆盖的配置，Key 为 Topic 名称
+	Topics map[string]TopicConfig `mapstructure:"topics"`
+}
+
+// TopicConfig 单个 Topic 的覆盖配置，未设置的项沿用 QueueConfig 的全局值。
+type TopicConfig struct {
+	Retry *RetryConfig `mapstructure:"retry"`
+}
+
+// RetryConfig 失败重试的退避策略配置。
+// 优先级：EnqueueRequest.retry_policy > queue.topics.<topic>.retry > queue.retry
+type RetryConfig struct {
+	// 退避算法：fixed / linear / exponential / full_jitter / decorrelated_jitter / schedule，为空表示立即重试
+	Strategy string `mapstructure:"strategy"`
+	// 基础等待时长，如 "10s"
+	Base time.Duration `mapstructure:"base"`
+	// 等待时长上限，指数与 jitter 策略必填
+	Max time.Duration `mapstructure:"max"`
+	// 指数退避的倍数，<=1 时按 2 处理
+	Multiplier float64 `mapstructure:"multiplier"`
+	// schedule 策略的显式等待列表，如 ["10s", "1m", "10m", "1h"]
+	Schedule []time.Duration `mapstructure:"schedule"`
 }
 
 // Load 加载配置。
@@ -0,0 +1,210 @@
+// Package election 提供多副本之间的选主能力。
+// 核心逻辑：各副本周期性竞争同一把带 TTL 的分布式租约锁，持有者即为 Leader，
+// 仅 Leader 运行 Watchdog 等后台调度循环；租约续约失败或进程退出时主动让出，由其他副本接管。
+package election
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Lock 定义了选主所依赖的分布式租约锁，不同的协调服务（Redis、etcd、数据库）各自实现。
+// @Description 每次成功获取租约都会分配一个单调递增的租约纪元 (token)，续约与释放必须携带该 token，
+// 使租约过期后被其他副本抢占的旧 Leader 无法再续约或误删新 Leader 的租约。
+// @Note: token 只约束租约本身，不会传给存储层的写操作，不能阻止旧 Leader 已发出的写请求；
+// 调度循环不重叠依赖 Elector 在租约到期前让位。
+type Lock interface {
+	// Acquire 尝试以 holder 身份获取租约。
+	// @Return: 租约被其他副本持有时 ok 为 false；成功时返回本次租约的纪元 token。
+	Acquire(ctx context.Context, holder string, ttl time.Duration) (token int64, ok bool, err error)
+
+	// Renew 将租约有效期重置为 ttl。
+	// @Return: 租约已过期或已被其他副本持有时返回 false。
+	Renew(ctx context.Context, holder string, token int64, ttl time.Duration) (bool, error)
+
+	// Release 主动释放租约，租约已不属于 holder/token 时不做任何修改。
+	Release(ctx context.Context, holder string, token int64) error
+
+	// Holder 查询当前租约的持有者。
+	// @Return: 无人持有时 ok 为 false。
+	Holder(ctx context.Context) (holder string, token int64, ok bool, err error)
+}
+
+// Status 描述当前副本视角下的选主状态，用于观测。
+type Status struct {
+	NodeID   string // 当前副本 ID
+	IsLeader bool   // 当前副本是否为 Leader
+	LeaderID string // 当前 Leader 的副本 ID，无 Leader 时为空
+	Token    int64  // 当前 Leader 租约的纪元，每次换主单调递增
+}
+
+// Elector 驱动租约锁完成选主，并在当选期间运行调度循环。
+// @Description 当选后以一个 Leader Context 启动全部调度循环；续约失败、续约超时或 Stop 时取消该 Context，
+// 等待所有循环退出后才释放租约，保证任意时刻最多只有一个副本在运行调度循环。
+// 租约有效期从发出 Acquire/Renew 请求前开始计算，服务端实际的过期时刻只会更晚。
+// @ThreadSafe: 状态查询方法可被任意协程并发调用。
+type Elector struct {
+	lock  Lock
+	id    string
+	ttl   time.Duration // 租约有效期
+	retry time.Duration // 续约与竞选的间隔，为 ttl 的 1/3
+
+	leading atomic.Bool
+
+	cancel context.CancelFunc // 停止竞选循环
+	wg     sync.WaitGroup     // 等待竞选循环退出
+}
+
+// NewElector 创建选主器。
+// @Param lock: 分布式租约锁实现，如 NewRedisLock。
+// @Param id: 当前副本的唯一 ID，建议包含主机名与进程号。
+// @Param ttl: 租约有效期。Leader 崩溃后其他副本最迟在 ttl 之后接管；<=0 时为 15 秒。
+func NewElector(lock Lock, id string, ttl time.Duration) *Elector {
+	if ttl <= 0 {
+		ttl = 15 * time.Second
+	}
+	return &Elector{
+		lock:  lock,
+		id:    id,
+		ttl:   ttl,
+		retry: ttl / 3,
+	}
+}
+
+// Start 异步启动竞选循环，当选期间并发运行 loops。
+// @Param loops: 仅应在 Leader 上运行的调度循环，须在 ctx 取消后尽快返回。
+func (e *Elector) Start(loops ...func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		log.Printf("Elector started. Node: %s, TTL: %v", e.id, e.ttl)
+
+		for {
+			e.campaign(ctx, loops)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(e.retry):
+			}
+		}
+	}()
+}
+
+// Stop 停止竞选。若当前为 Leader，先停止全部调度循环再释放租约，使其他副本无需等待租约过期即可接管。
+func (e *Elector) Stop() {
+	e.cancel()
+	e.wg.Wait()
+	log.Println("Elector stopped")
+}
+
+// IsLeader 返回当前副本是否为 Leader。
+func (e *Elector) IsLeader() bool {
+	return e.leading.Load()
+}
+
+// ID 返回当前副本的 ID。
+func (e *Elector) ID() string {
+	return e.id
+}
+
+// Status 查询当前的选主状态。
+func (e *Elector) Status(ctx context.Context) (Status, error) {
+	st := Status{NodeID: e.id, IsLeader: e.leading.Load()}
+	holder, token, ok, err := e.lock.Holder(ctx)
+	if err != nil {
+		return st, err
+	}
+	if ok {
+		st.LeaderID = holder
+		st.Token = token
+	}
+	return st, nil
+}
+
+// campaign 尝试获取一次租约，成功则在持有期间运行调度循环，直到失去租约或 ctx 取消。
+func (e *Elector) campaign(ctx context.Context, loops []func(ctx context.Context)) {
+	// 服务端在收到请求后才开始计算 TTL，以发出请求前的时刻为起点只会低估剩余有效期
+	renewed := time.Now()
+	token, ok, err := e.lock.Acquire(ctx, e.id, e.ttl)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Elector acquire error: %v", err)
+		}
+		return
+	}
+	if !ok {
+		return
+	}
+
+	// 1. 当选：以 Leader Context 启动全部调度循环。
+	e.leading.Store(true)
+	log.Printf("Elector %s became leader (token %d)", e.id, token)
+
+	leaderCtx, stepDown := context.WithCancel(ctx)
+	var loopsWg sync.WaitGroup
+	for _, loop := range loops {
+		loopsWg.Add(1)
+		go func() {
+			defer loopsWg.Done()
+			loop(leaderCtx)
+		}()
+	}
+
+	// 2. 续约：续约被拒绝，或距上次成功续约的时间逼近 ttl（租约可能已在服务端过期）时主动让位。
+	// @Note: 预留一个 retry 周期的余量，覆盖时钟误差与让位时等待调度循环退出的耗时。
+	ticker := time.NewTicker(e.retry)
+	for held := true; held; {
+		select {
+		case <-ctx.Done():
+			held = false
+		case <-ticker.C:
+			sent := time.Now()
+			ok, err := e.lock.Renew(ctx, e.id, token, e.ttl)
+			switch {
+			case err == nil && ok:
+				renewed = sent
+			case err == nil:
+				log.Printf("Elector %s lost leadership (token %d)", e.id, token)
+				held = false
+			case time.Since(renewed) >= e.ttl-e.retry:
+				log.Printf("Elector %s renew failed, stepping down: %v", e.id, err)
+				held = false
+			default:
+				log.Printf("Elector renew error: %v", err)
+			}
+		}
+	}
+	ticker.Stop()
+
+	// 3. 让位：等待调度循环全部退出后再释放租约，避免新旧 Leader 的循环重叠。
+	// 等待不超过租约的剩余有效期：超过后其他副本可能已经当选，继续持有 leading 状态没有意义，
+	// 记录日志后照常释放（token 已失效时 Release 不做修改），再等待循环退出后才允许重新竞选。
+	e.leading.Store(false)
+	stepDown()
+	done := make(chan struct{})
+	go func() {
+		loopsWg.Wait()
+		close(done)
+	}()
+	wait := time.NewTimer(e.ttl - time.Since(renewed))
+	select {
+	case <-done:
+	case <-wait.C:
+		log.Printf("Elector %s loops did not exit before the lease expired (token %d), leaders may overlap", e.id, token)
+	}
+	wait.Stop()
+
+	releaseCtx, cancel := context.WithTimeout(context.Background(), e.retry)
+	defer cancel()
+	if err := e.lock.Release(releaseCtx, e.id, token); err != nil {
+		log.Printf("Elector release error: %v", err)
+	}
+	log.Printf("Elector %s stepped down (token %d)", e.id, token)
+	<-done
+}
@@ -0,0 +1,168 @@
+package election
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memLock 是 Lock 的内存实现，租约过期由测试通过 expire 手动触发。
+type memLock struct {
+	mu     sync.Mutex
+	holder string
+	token  int64
+	epoch  int64
+}
+
+func (l *memLock) Acquire(_ context.Context, holder string, _ time.Duration) (int64, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holder != "" && l.holder != holder {
+		return 0, false, nil
+	}
+	l.epoch++
+	l.holder, l.token = holder, l.epoch
+	return l.token, true, nil
+}
+
+func (l *memLock) Renew(_ context.Context, holder string, token int64, _ time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.holder == holder && l.token == token, nil
+}
+
+func (l *memLock) Release(_ context.Context, holder string, token int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holder == holder && l.token == token {
+		l.holder, l.token = "", 0
+	}
+	return nil
+}
+
+func (l *memLock) Holder(context.Context) (string, int64, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.holder, l.token, l.holder != "", nil
+}
+
+// expire 模拟租约在服务端过期。
+func (l *memLock) expire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.holder, l.token = "", 0
+}
+
+// waitFor 轮询直到 cond 成立或超时。
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestElectorHandOff(t *testing.T) {
+	lock := &memLock{}
+	var running atomic.Int32 // 正在运行调度循环的副本数
+	loop := func(ctx context.Context) {
+		if running.Add(1) > 1 {
+			t.Error("loops running on two replicas at once")
+		}
+		<-ctx.Done()
+		running.Add(-1)
+	}
+
+	a := NewElector(lock, "a", 60*time.Millisecond)
+	a.Start(loop)
+	waitFor(t, "a to lead", a.IsLeader)
+
+	b := NewElector(lock, "b", 60*time.Millisecond)
+	b.Start(loop)
+	time.Sleep(100 * time.Millisecond)
+	if b.IsLeader() {
+		t.Fatal("b became leader while a holds the lease")
+	}
+
+	// a 停止时主动释放租约，b 在下一次竞选时接管
+	a.Stop()
+	if a.IsLeader() || running.Load() != 0 {
+		t.Fatal("a still leading after Stop")
+	}
+	waitFor(t, "b to lead", b.IsLeader)
+
+	st, err := b.Status(context.Background())
+	if err != nil || st.LeaderID != "b" || !st.IsLeader || st.Token != 2 {
+		t.Errorf("Status() = %+v, %v, want leader b with token 2", st, err)
+	}
+	b.Stop()
+}
+
+func TestElectorStepsDownOnLostLease(t *testing.T) {
+	lock := &memLock{}
+	stopped := make(chan struct{})
+	var started atomic.Int32
+	e := NewElector(lock, "a", 60*time.Millisecond)
+	e.Start(func(ctx context.Context) {
+		if started.Add(1) == 1 {
+			<-ctx.Done()
+			close(stopped)
+			return
+		}
+		<-ctx.Done()
+	})
+	defer e.Stop()
+	waitFor(t, "a to lead", e.IsLeader)
+
+	// 租约被抢占后，续约失败的 Leader 须停止调度循环
+	lock.expire()
+	lock.Acquire(context.Background(), "other", time.Minute)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("loop not stopped after losing the lease")
+	}
+	if e.IsLeader() {
+		t.Error("still leader after losing the lease")
+	}
+}
+
+func TestElectorBoundsStepDownWait(t *testing.T) {
+	lock := &memLock{}
+	release := make(chan struct{})
+	e := NewElector(lock, "a", 60*time.Millisecond)
+	e.Start(func(ctx context.Context) {
+		<-ctx.Done()
+		<-release // 模拟退出缓慢的调度循环
+	})
+	waitFor(t, "a to lead", e.IsLeader)
+
+	stopped := make(chan struct{})
+	go func() {
+		e.Stop()
+		close(stopped)
+	}()
+
+	// 租约到期前循环仍未退出，Leader 不再等待，照常释放租约
+	waitFor(t, "lease released", func() bool {
+		_, _, ok, _ := lock.Holder(context.Background())
+		return !ok
+	})
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned while a loop is still running")
+	default:
+	}
+
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return after the loop exited")
+	}
+}
@@ -0,0 +1,141 @@
+package election
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// luaAcquire 在租约空闲时写入新的持有者。
+// @Logic
+// 1. 租约被其他副本持有时直接返回 0；同一 holder 重复获取（如进程快速重启）视为重新当选。
+// 2. INCR 纪元计数器生成新的租约纪元 (token)，与持有者一起写入租约并设置 TTL。
+//
+// KEYS[1]: 租约 (ddq:leader)
+// KEYS[2]: 纪元计数器 (ddq:leader:epoch)，不过期，保证 token 单调递增
+// ARGV[1]: holder
+// ARGV[2]: TTL (毫秒)
+//
+// @Returns number: 新的租约纪元；租约被占用时返回 0
+const luaAcquire = `
+local raw = redis.call('GET', KEYS[1])
+if raw then
+    local lease = cjson.decode(raw)
+    if lease.holder ~= ARGV[1] then
+        return 0
+    end
+end
+local token = redis.call('INCR', KEYS[2])
+redis.call('SET', KEYS[1], cjson.encode({holder = ARGV[1], token = token}), 'PX', ARGV[2])
+return token
+`
+
+// luaRenew 在租约仍属于 holder/token 时重置 TTL。
+// KEYS[1]: 租约 (ddq:leader)
+// ARGV[1]: holder
+// ARGV[2]: 租约纪元 (token)
+// ARGV[3]: TTL (毫秒)
+//
+// @Returns number: 1=续约成功, 0=租约已过期或被抢占
+const luaRenew = `
+local raw = redis.call('GET', KEYS[1])
+if not raw then
+    return 0
+end
+local lease = cjson.decode(raw)
+if lease.holder ~= ARGV[1] or tonumber(lease.token) ~= tonumber(ARGV[2]) then
+    return 0
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return 1
+`
+
+// luaRelease 在租约仍属于 holder/token 时删除租约。
+// KEYS[1]: 租约 (ddq:leader)
+// ARGV[1]: holder
+// ARGV[2]: 租约纪元 (token)
+//
+// @Returns number: 1=已释放, 0=租约不属于调用方
+const luaRelease = `
+local raw = redis.call('GET', KEYS[1])
+if not raw then
+    return 0
+end
+local lease = cjson.decode(raw)
+if lease.holder ~= ARGV[1] or tonumber(lease.token) ~= tonumber(ARGV[2]) then
+    return 0
+end
+redis.call('DEL', KEYS[1])
+return 1
+`
+
+// RedisLock 基于 Redis 单 Key + TTL 实现的租约锁。
+// @Description 租约 Key 的 Value 为 {"holder": "...", "token": N}，token 来自不过期的纪元计数器 <key>:epoch。
+// 所有读改写均在 Lua 脚本内完成，保证校验与修改之间不被其他副本插入。
+type RedisLock struct {
+	client *redis.Client
+	key    string
+}
+
+// 编译期校验：RedisLock 实现 Lock。
+var _ Lock = (*RedisLock)(nil)
+
+// NewRedisLock 创建 Redis 租约锁。
+// @Param client: Redis 客户端，可与 JobStore 共用连接池。
+// @Param key: 租约 Key，如 "ddq:leader"；纪元计数器使用 "<key>:epoch"。
+func NewRedisLock(client *redis.Client, key string) *RedisLock {
+	return &RedisLock{client: client, key: key}
+}
+
+// epochKey 返回纪元计数器的键名。
+func (l *RedisLock) epochKey() string {
+	return l.key + ":epoch"
+}
+
+// Acquire 尝试获取租约。
+func (l *RedisLock) Acquire(ctx context.Context, holder string, ttl time.Duration) (int64, bool, error) {
+	token, err := l.client.Eval(ctx, luaAcquire, []string{l.key, l.epochKey()}, holder, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, false, fmt.Errorf("redis acquire lease failed: %w", err)
+	}
+	return token, token > 0, nil
+}
+
+// Renew 续约。
+func (l *RedisLock) Renew(ctx context.Context, holder string, token int64, ttl time.Duration) (bool, error) {
+	res, err := l.client.Eval(ctx, luaRenew, []string{l.key}, holder, token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("redis renew lease failed: %w", err)
+	}
+	return res == 1, nil
+}
+
+// Release 释放租约。
+func (l *RedisLock) Release(ctx context.Context, holder string, token int64) error {
+	if err := l.client.Eval(ctx, luaRelease, []string{l.key}, holder, token).Err(); err != nil {
+		return fmt.Errorf("redis release lease failed: %w", err)
+	}
+	return nil
+}
+
+// Holder 查询当前租约的持有者。
+func (l *RedisLock) Holder(ctx context.Context) (string, int64, bool, error) {
+	raw, err := l.client.Get(ctx, l.key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", 0, false, nil
+		}
+		return "", 0, false, fmt.Errorf("redis get lease failed: %w", err)
+	}
+	var lease struct {
+		Holder string `json:"holder"`
+		Token  int64  `json:"token"`
+	}
+	if err := json.Unmarshal([]byte(raw), &lease); err != nil {
+		return "", 0, false, fmt.Errorf("unmarshal lease failed: %w", err)
+	}
+	return lease.Holder, lease.Token, true, nil
+}
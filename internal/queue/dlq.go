@@ -0,0 +1,128 @@
+package queue
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListDeadLetters 分页查询指定 Topic 的死信任务（运维接口）。
+// @Description page_token 为不透明的翻页游标，客户端应原样回传上一页的 next_page_token。
+func (s *Service) ListDeadLetters(ctx context.Context, req *pb.ListDeadLettersRequest) (*pb.ListDeadLettersResponse, error) {
+	if req.Topic == "" {
+		return nil, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+	}
+	if req.StartTime < 0 || req.EndTime < 0 || (req.EndTime > 0 && req.EndTime < req.StartTime) {
+		return nil, status.Error(codes.InvalidArgument, "invalid time range")
+	}
+
+	var offset int64
+	if req.PageToken != "" {
+		v, err := strconv.ParseInt(req.PageToken, 10, 64)
+		if err != nil || v < 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		offset = v
+	}
+
+	pageSize := int64(req.PageSize)
+	if pageSize <= 0 {
+		pageSize = defaultBatchSize
+	}
+	if pageSize > maxBatchSize {
+		pageSize = maxBatchSize
+	}
+
+	q := storage.DeadLetterQuery{
+		Topic:  req.Topic,
+		Offset: offset,
+		Limit:  pageSize,
+	}
+	if req.StartTime > 0 {
+		q.Since = time.Unix(req.StartTime, 0)
+	}
+	if req.EndTime > 0 {
+		q.Until = time.Unix(req.EndTime, 0)
+	}
+
+	tasks, next, err := s.store.ListDead(ctx, q)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	resp := &pb.ListDeadLettersResponse{Tasks: tasks}
+	if next > 0 {
+		resp.NextPageToken = strconv.FormatInt(next, 10)
+	}
+	return resp, nil
+}
+
+// GetDeadLetter 按 ID 查询死信任务详情，包含死信原因、最后一次错误与进入死信的时间。
+// @Return: ID 不存在或任务不在死信状态时返回 NotFound。
+func (s *Service) GetDeadLetter(ctx context.Context, req *pb.GetDeadLetterRequest) (*pb.GetDeadLetterResponse, error) {
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+	}
+
+	task, err := s.store.GetDead(ctx, req.Id)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.GetDeadLetterResponse{Task: task}, nil
+}
+
+// RedriveDeadLetters 将死信任务重新投递到等待队列，重置重试次数。
+// @Return: 实际重投的数量；不在死信状态的 ID 被忽略，不视为错误。
+func (s *Service) RedriveDeadLetters(ctx context.Context, req *pb.RedriveDeadLettersRequest) (*pb.RedriveDeadLettersResponse, error) {
+	sel, err := deadLetterSelector(req.Topic, req.Ids, req.All)
+	if err != nil {
+		return nil, err
+	}
+	if req.DelaySeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "delay_seconds must be >= 0")
+	}
+
+	n, err := s.store.Redrive(ctx, sel, time.Duration(req.DelaySeconds)*time.Second)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.RedriveDeadLettersResponse{Count: n}, nil
+}
+
+// PurgeDeadLetters 永久删除死信任务，释放其 ID。
+// @Return: 实际删除的数量；不在死信状态的 ID 被忽略，不视为错误。
+func (s *Service) PurgeDeadLetters(ctx context.Context, req *pb.PurgeDeadLettersRequest) (*pb.PurgeDeadLettersResponse, error) {
+	sel, err := deadLetterSelector(req.Topic, req.Ids, req.All)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := s.store.Purge(ctx, sel)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.PurgeDeadLettersResponse{Count: n}, nil
+}
+
+// deadLetterSelector 校验并构造死信选取条件。
+// @Validation: all 与 ids 互斥且必须二选一；all 模式必须指定 Topic，防止误操作清空所有死信。
+func deadLetterSelector(topic string, ids []string, all bool) (storage.DeadLetterSelector, error) {
+	if all == (len(ids) > 0) {
+		return storage.DeadLetterSelector{}, status.Error(codes.InvalidArgument, "exactly one of ids or all must be set")
+	}
+	if all && topic == "" {
+		return storage.DeadLetterSelector{}, status.Error(codes.InvalidArgument, "topic is required when all is set")
+	}
+	for _, id := range ids {
+		if id == "" {
+			return storage.DeadLetterSelector{}, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+		}
+	}
+	return storage.DeadLetterSelector{Topic: topic, IDs: ids, All: all}, nil
+}
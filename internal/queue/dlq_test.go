@@ -0,0 +1,158 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/mocks"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListDeadLetters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mocks.NewMockJobStore(ctrl)
+	svc := NewService(conf.QueueConfig{}, mockStore)
+
+	tests := []struct {
+		name      string
+		req       *pb.ListDeadLettersRequest
+		mock      func()
+		wantCode  codes.Code
+		wantToken string
+	}{
+		{
+			name: "First Page",
+			req:  &pb.ListDeadLettersRequest{Topic: "test", StartTime: 100, PageSize: 2},
+			mock: func() {
+				mockStore.EXPECT().
+					ListDead(gomock.Any(), storage.DeadLetterQuery{Topic: "test", Since: time.Unix(100, 0), Limit: 2}).
+					Return([]*pb.Task{{Id: "a"}, {Id: "b"}}, int64(2), nil)
+			},
+			wantCode:  codes.OK,
+			wantToken: "2",
+		},
+		{
+			name: "Last Page",
+			req:  &pb.ListDeadLettersRequest{Topic: "test", PageToken: "2"},
+			mock: func() {
+				mockStore.EXPECT().
+					ListDead(gomock.Any(), storage.DeadLetterQuery{Topic: "test", Offset: 2, Limit: defaultBatchSize}).
+					Return([]*pb.Task{{Id: "c"}}, int64(0), nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "Bad Page Token",
+			req:      &pb.ListDeadLettersRequest{Topic: "test", PageToken: "abc"},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Inverted Time Range",
+			req:      &pb.ListDeadLettersRequest{Topic: "test", StartTime: 200, EndTime: 100},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			resp, err := svc.ListDeadLetters(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("ListDeadLetters() code = %v, want %v", got, tt.wantCode)
+			}
+			if err == nil && resp.NextPageToken != tt.wantToken {
+				t.Errorf("ListDeadLetters() next_page_token = %q, want %q", resp.NextPageToken, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestGetDeadLetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mocks.NewMockJobStore(ctrl)
+	svc := NewService(conf.QueueConfig{}, mockStore)
+
+	mockStore.EXPECT().GetDead(gomock.Any(), "pending-task").Return(nil, errno.ErrTaskNotFound)
+
+	_, err := svc.GetDeadLetter(context.Background(), &pb.GetDeadLetterRequest{Id: "pending-task"})
+	if got := status.Code(err); got != codes.NotFound {
+		t.Errorf("GetDeadLetter() code = %v, want %v", got, codes.NotFound)
+	}
+}
+
+func TestRedriveDeadLetters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mocks.NewMockJobStore(ctrl)
+	svc := NewService(conf.QueueConfig{}, mockStore)
+
+	tests := []struct {
+		name     string
+		req      *pb.RedriveDeadLettersRequest
+		mock     func()
+		wantCode codes.Code
+	}{
+		{
+			name: "By IDs With Delay",
+			req:  &pb.RedriveDeadLettersRequest{Ids: []string{"a", "b"}, DelaySeconds: 60},
+			mock: func() {
+				mockStore.EXPECT().
+					Redrive(gomock.Any(), storage.DeadLetterSelector{IDs: []string{"a", "b"}}, time.Minute).
+					Return(int64(2), nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "All",
+			req:  &pb.RedriveDeadLettersRequest{Topic: "test", All: true},
+			mock: func() {
+				mockStore.EXPECT().
+					Redrive(gomock.Any(), storage.DeadLetterSelector{Topic: "test", All: true}, time.Duration(0)).
+					Return(int64(10), nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "All Without Topic",
+			req:      &pb.RedriveDeadLettersRequest{All: true},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Both IDs And All",
+			req:      &pb.RedriveDeadLettersRequest{Topic: "test", Ids: []string{"a"}, All: true},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Nothing Selected",
+			req:      &pb.RedriveDeadLettersRequest{Topic: "test"},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			_, err := svc.RedriveDeadLetters(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("RedriveDeadLetters() code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package queue
+
+import (
+	"context"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetLeader 查询选主状态（运维接口）。
+// @Description 可向任意副本发起，返回当前 Leader 及响应副本自身是否为 Leader。
+// @Return: 未开启选主时返回 FailedPrecondition。
+func (s *Service) GetLeader(ctx context.Context, _ *pb.GetLeaderRequest) (*pb.GetLeaderResponse, error) {
+	if s.elector == nil {
+		return nil, status.Error(codes.FailedPrecondition, "leader election is disabled")
+	}
+
+	st, err := s.elector.Status(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.GetLeaderResponse{
+		NodeId:   st.NodeID,
+		IsLeader: st.IsLeader,
+		LeaderId: st.LeaderID,
+		Token:    st.Token,
+	}, nil
+}
@@ -0,0 +1,56 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+	"github.com/AkikoAkaki/async-task-platform/internal/election"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/mocks"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// heldLock 是始终由 holder 持有的租约锁，用于模拟其他副本为 Leader 的场景。
+type heldLock struct {
+	holder string
+	token  int64
+}
+
+func (l heldLock) Acquire(context.Context, string, time.Duration) (int64, bool, error) {
+	return 0, false, nil
+}
+
+func (l heldLock) Renew(context.Context, string, int64, time.Duration) (bool, error) {
+	return false, nil
+}
+
+func (l heldLock) Release(context.Context, string, int64) error { return nil }
+
+func (l heldLock) Holder(context.Context) (string, int64, bool, error) {
+	return l.holder, l.token, true, nil
+}
+
+func TestGetLeader(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	disabled := NewService(conf.QueueConfig{}, mocks.NewMockJobStore(ctrl))
+	_, err := disabled.GetLeader(context.Background(), &pb.GetLeaderRequest{})
+	if got := status.Code(err); got != codes.FailedPrecondition {
+		t.Errorf("GetLeader() code = %v, want %v", got, codes.FailedPrecondition)
+	}
+
+	e := election.NewElector(heldLock{holder: "node-2", token: 7}, "node-1", time.Minute)
+	svc := NewService(conf.QueueConfig{}, mocks.NewMockJobStore(ctrl), WithElector(e))
+	resp, err := svc.GetLeader(context.Background(), &pb.GetLeaderRequest{})
+	if err != nil {
+		t.Fatalf("GetLeader() error = %v", err)
+	}
+	if resp.NodeId != "node-1" || resp.IsLeader || resp.LeaderId != "node-2" || resp.Token != 7 {
+		t.Errorf("GetLeader() = %+v, want follower node-1 of leader node-2 (token 7)", resp)
+	}
+}
@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"strconv"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListQuarantined 分页查询指定 Topic 下被隔离的损坏任务条目（运维接口）。
+// @Description page_token 为不透明的翻页游标；响应同时携带当前隔离数与累计隔离数，可直接用于监控告警。
+func (s *Service) ListQuarantined(ctx context.Context, req *pb.ListQuarantinedRequest) (*pb.ListQuarantinedResponse, error) {
+	store, err := s.quarantineStore()
+	if err != nil {
+		return nil, err
+	}
+	if req.Topic == "" {
+		return nil, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+	}
+
+	var cursor uint64
+	if req.PageToken != "" {
+		v, err := strconv.ParseUint(req.PageToken, 10, 64)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		cursor = v
+	}
+
+	pageSize := int64(req.PageSize)
+	if pageSize <= 0 {
+		pageSize = defaultBatchSize
+	}
+	if pageSize > maxBatchSize {
+		pageSize = maxBatchSize
+	}
+
+	entries, next, err := store.ListQuarantined(ctx, req.Topic, cursor, pageSize)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	current, total, err := store.QuarantineStats(ctx, req.Topic)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	resp := &pb.ListQuarantinedResponse{Entries: entries, Count: current, TotalQuarantined: total}
+	if next > 0 {
+		resp.NextPageToken = strconv.FormatUint(next, 10)
+	}
+	return resp, nil
+}
+
+// DeleteQuarantined 永久删除被隔离的条目。
+// @Validation: 必须指定 Topic；all 与 ids 互斥且必须二选一。
+// @Return: 实际删除的数量；不存在的 ID 被忽略，不视为错误。
+func (s *Service) DeleteQuarantined(ctx context.Context, req *pb.DeleteQuarantinedRequest) (*pb.DeleteQuarantinedResponse, error) {
+	store, err := s.quarantineStore()
+	if err != nil {
+		return nil, err
+	}
+	if req.Topic == "" {
+		return nil, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+	}
+	if req.All == (len(req.Ids) > 0) {
+		return nil, status.Error(codes.InvalidArgument, "exactly one of ids or all must be set")
+	}
+
+	n, err := store.DeleteQuarantined(ctx, req.Topic, req.Ids, req.All)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.DeleteQuarantinedResponse{Count: n}, nil
+}
+
+// quarantineStore 探测存储实现是否支持隔离区管理。
+// @Return: 不支持时返回 Unimplemented。
+func (s *Service) quarantineStore() (storage.QuarantineStore, error) {
+	store, ok := s.store.(storage.QuarantineStore)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage backend does not support quarantine")
+	}
+	return store, nil
+}
@@ -0,0 +1,193 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/mocks"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// quarantineMockStore 组合 JobStore 与 QuarantineStore 的 Mock，模拟支持隔离区的存储实现。
+type quarantineMockStore struct {
+	*mocks.MockJobStore
+	*mocks.MockQuarantineStore
+}
+
+func TestListQuarantined(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQuarantine := mocks.NewMockQuarantineStore(ctrl)
+	svc := NewService(conf.QueueConfig{}, quarantineMockStore{mocks.NewMockJobStore(ctrl), mockQuarantine})
+
+	entry := &pb.QuarantinedEntry{Id: "bad", Topic: "test", Raw: []byte{2, 'x'}, Error: "task header separator not found", Source: "fetch"}
+
+	tests := []struct {
+		name      string
+		req       *pb.ListQuarantinedRequest
+		mock      func()
+		wantCode  codes.Code
+		wantToken string
+	}{
+		{
+			name: "First Page",
+			req:  &pb.ListQuarantinedRequest{Topic: "test", PageSize: 2},
+			mock: func() {
+				mockQuarantine.EXPECT().ListQuarantined(gomock.Any(), "test", uint64(0), int64(2)).
+					Return([]*pb.QuarantinedEntry{entry}, uint64(17), nil)
+				mockQuarantine.EXPECT().QuarantineStats(gomock.Any(), "test").Return(int64(3), int64(5), nil)
+			},
+			wantCode:  codes.OK,
+			wantToken: "17",
+		},
+		{
+			name: "Last Page Clamps Size",
+			req:  &pb.ListQuarantinedRequest{Topic: "test", PageSize: 1000, PageToken: "17"},
+			mock: func() {
+				mockQuarantine.EXPECT().ListQuarantined(gomock.Any(), "test", uint64(17), int64(maxBatchSize)).
+					Return(nil, uint64(0), nil)
+				mockQuarantine.EXPECT().QuarantineStats(gomock.Any(), "test").Return(int64(3), int64(5), nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "Missing Topic",
+			req:      &pb.ListQuarantinedRequest{},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Invalid Token",
+			req:      &pb.ListQuarantinedRequest{Topic: "test", PageToken: "abc"},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "Storage Error",
+			req:  &pb.ListQuarantinedRequest{Topic: "test"},
+			mock: func() {
+				mockQuarantine.EXPECT().ListQuarantined(gomock.Any(), "test", uint64(0), int64(defaultBatchSize)).
+					Return(nil, uint64(0), errors.New("redis down"))
+			},
+			wantCode: codes.Internal,
+		},
+		{
+			name: "Backend Not Supported",
+			req:  &pb.ListQuarantinedRequest{Topic: "test"},
+			mock: func() {
+				mockQuarantine.EXPECT().ListQuarantined(gomock.Any(), "test", uint64(0), int64(defaultBatchSize)).
+					Return(nil, uint64(0), fmt.Errorf("hot store: %w", errno.ErrNotSupported))
+			},
+			wantCode: codes.Unimplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			resp, err := svc.ListQuarantined(context.Background(), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("ListQuarantined() code = %v, want %v (err: %v)", status.Code(err), tt.wantCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if resp.NextPageToken != tt.wantToken {
+				t.Errorf("NextPageToken = %q, want %q", resp.NextPageToken, tt.wantToken)
+			}
+			if resp.Count != 3 || resp.TotalQuarantined != 5 {
+				t.Errorf("Count/TotalQuarantined = %d/%d, want 3/5", resp.Count, resp.TotalQuarantined)
+			}
+		})
+	}
+}
+
+func TestDeleteQuarantined(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockQuarantine := mocks.NewMockQuarantineStore(ctrl)
+	svc := NewService(conf.QueueConfig{}, quarantineMockStore{mocks.NewMockJobStore(ctrl), mockQuarantine})
+
+	tests := []struct {
+		name      string
+		req       *pb.DeleteQuarantinedRequest
+		mock      func()
+		wantCode  codes.Code
+		wantCount int64
+	}{
+		{
+			name: "By IDs",
+			req:  &pb.DeleteQuarantinedRequest{Topic: "test", Ids: []string{"a", "b"}},
+			mock: func() {
+				mockQuarantine.EXPECT().DeleteQuarantined(gomock.Any(), "test", []string{"a", "b"}, false).Return(int64(1), nil)
+			},
+			wantCode:  codes.OK,
+			wantCount: 1,
+		},
+		{
+			name: "All",
+			req:  &pb.DeleteQuarantinedRequest{Topic: "test", All: true},
+			mock: func() {
+				mockQuarantine.EXPECT().DeleteQuarantined(gomock.Any(), "test", nil, true).Return(int64(4), nil)
+			},
+			wantCode:  codes.OK,
+			wantCount: 4,
+		},
+		{
+			name:     "Neither IDs Nor All",
+			req:      &pb.DeleteQuarantinedRequest{Topic: "test"},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Both IDs And All",
+			req:      &pb.DeleteQuarantinedRequest{Topic: "test", Ids: []string{"a"}, All: true},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Missing Topic",
+			req:      &pb.DeleteQuarantinedRequest{Ids: []string{"a"}},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			resp, err := svc.DeleteQuarantined(context.Background(), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("DeleteQuarantined() code = %v, want %v (err: %v)", status.Code(err), tt.wantCode, err)
+			}
+			if err == nil && resp.Count != tt.wantCount {
+				t.Errorf("Count = %d, want %d", resp.Count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestQuarantineUnsupported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := NewService(conf.QueueConfig{}, mocks.NewMockJobStore(ctrl))
+
+	_, err := svc.ListQuarantined(context.Background(), &pb.ListQuarantinedRequest{Topic: "test"})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("ListQuarantined() code = %v, want Unimplemented", status.Code(err))
+	}
+	_, err = svc.DeleteQuarantined(context.Background(), &pb.DeleteQuarantinedRequest{Topic: "test", All: true})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("DeleteQuarantined() code = %v, want Unimplemented", status.Code(err))
+	}
+}
@@ -0,0 +1,91 @@
+package queue
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+)
+
+// retryStrategies 配置项 queue.retry.strategy 与 pb.RetryStrategy 的对应关系。
+var retryStrategies = map[string]pb.RetryStrategy{
+	"":                    pb.RetryStrategy_RETRY_STRATEGY_UNSPECIFIED,
+	"fixed":               pb.RetryStrategy_RETRY_STRATEGY_FIXED,
+	"linear":              pb.RetryStrategy_RETRY_STRATEGY_LINEAR,
+	"exponential":         pb.RetryStrategy_RETRY_STRATEGY_EXPONENTIAL,
+	"full_jitter":         pb.RetryStrategy_RETRY_STRATEGY_FULL_JITTER,
+	"decorrelated_jitter": pb.RetryStrategy_RETRY_STRATEGY_DECORRELATED_JITTER,
+	"schedule":            pb.RetryStrategy_RETRY_STRATEGY_SCHEDULE,
+}
+
+// retryPolicyFromConfig 将配置文件中的退避策略转换为 pb.RetryPolicy。
+// @Return: 未配置策略时返回 nil；策略名非法或参数不合法时返回 error。
+func retryPolicyFromConfig(c *conf.RetryConfig) (*pb.RetryPolicy, error) {
+	if c == nil {
+		return nil, nil
+	}
+	strategy, ok := retryStrategies[c.Strategy]
+	if !ok {
+		return nil, fmt.Errorf("unknown retry strategy %q", c.Strategy)
+	}
+	if strategy == pb.RetryStrategy_RETRY_STRATEGY_UNSPECIFIED {
+		return nil, nil
+	}
+
+	policy := &pb.RetryPolicy{
+		Strategy:    strategy,
+		BaseSeconds: int64(c.Base / time.Second),
+		MaxSeconds:  int64(c.Max / time.Second),
+		Multiplier:  c.Multiplier,
+	}
+	for _, d := range c.Schedule {
+		policy.ScheduleSeconds = append(policy.ScheduleSeconds, int64(d/time.Second))
+	}
+	if err := validateRetryPolicy(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// validateRetryPolicy 校验退避策略参数。
+// @Validation: 时长不能为负；指数与 jitter 策略的等待时长随重试次数无界增长，必须设置上限；schedule 策略必须给出等待列表。
+func validateRetryPolicy(p *pb.RetryPolicy) error {
+	if p.BaseSeconds < 0 || p.MaxSeconds < 0 {
+		return fmt.Errorf("retry base and max must be >= 0")
+	}
+	switch p.Strategy {
+	case pb.RetryStrategy_RETRY_STRATEGY_UNSPECIFIED,
+		pb.RetryStrategy_RETRY_STRATEGY_FIXED,
+		pb.RetryStrategy_RETRY_STRATEGY_LINEAR:
+	case pb.RetryStrategy_RETRY_STRATEGY_EXPONENTIAL,
+		pb.RetryStrategy_RETRY_STRATEGY_FULL_JITTER,
+		pb.RetryStrategy_RETRY_STRATEGY_DECORRELATED_JITTER:
+		if p.MaxSeconds == 0 {
+			return fmt.Errorf("retry strategy %s requires max", p.Strategy)
+		}
+	case pb.RetryStrategy_RETRY_STRATEGY_SCHEDULE:
+		if len(p.ScheduleSeconds) == 0 {
+			return fmt.Errorf("retry strategy %s requires a schedule", p.Strategy)
+		}
+		for _, d := range p.ScheduleSeconds {
+			if d < 0 {
+				return fmt.Errorf("retry schedule entries must be >= 0")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown retry strategy %d", p.Strategy)
+	}
+	return nil
+}
+
+// resolveRetryPolicy 按优先级确定任务的退避策略：请求指定 > Topic 配置 > 全局配置。
+func (s *Service) resolveRetryPolicy(topic string, requested *pb.RetryPolicy) *pb.RetryPolicy {
+	if requested != nil && requested.Strategy != pb.RetryStrategy_RETRY_STRATEGY_UNSPECIFIED {
+		return requested
+	}
+	if p, ok := s.topicRetry[topic]; ok {
+		return p
+	}
+	return s.defaultRetry
+}
@@ -0,0 +1,106 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/mocks"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRetryPolicyFromConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *conf.RetryConfig
+		want    *pb.RetryPolicy
+		wantErr bool
+	}{
+		{
+			name: "Unset",
+			cfg:  &conf.RetryConfig{},
+			want: nil,
+		},
+		{
+			name: "Exponential With Cap",
+			cfg:  &conf.RetryConfig{Strategy: "exponential", Base: 10 * time.Second, Max: time.Hour, Multiplier: 3},
+			want: &pb.RetryPolicy{
+				Strategy:    pb.RetryStrategy_RETRY_STRATEGY_EXPONENTIAL,
+				BaseSeconds: 10,
+				MaxSeconds:  3600,
+				Multiplier:  3,
+			},
+		},
+		{
+			name: "Schedule",
+			cfg:  &conf.RetryConfig{Strategy: "schedule", Schedule: []time.Duration{10 * time.Second, time.Minute, 10 * time.Minute, time.Hour}},
+			want: &pb.RetryPolicy{
+				Strategy:        pb.RetryStrategy_RETRY_STRATEGY_SCHEDULE,
+				ScheduleSeconds: []int64{10, 60, 600, 3600},
+			},
+		},
+		{
+			name:    "Exponential Without Cap",
+			cfg:     &conf.RetryConfig{Strategy: "exponential", Base: time.Second},
+			wantErr: true,
+		},
+		{
+			name:    "Empty Schedule",
+			cfg:     &conf.RetryConfig{Strategy: "schedule"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown Strategy",
+			cfg:     &conf.RetryConfig{Strategy: "random"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := retryPolicyFromConfig(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("retryPolicyFromConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("retryPolicyFromConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRetryPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := NewService(conf.QueueConfig{
+		Retry: conf.RetryConfig{Strategy: "fixed", Base: 30 * time.Second},
+		Topics: map[string]conf.TopicConfig{
+			"payment": {Retry: &conf.RetryConfig{Strategy: "linear", Base: time.Minute}},
+		},
+	}, mocks.NewMockJobStore(ctrl))
+
+	requested := &pb.RetryPolicy{Strategy: pb.RetryStrategy_RETRY_STRATEGY_FIXED, BaseSeconds: 5}
+
+	tests := []struct {
+		name      string
+		topic     string
+		requested *pb.RetryPolicy
+		want      pb.RetryStrategy
+	}{
+		{name: "Global Default", topic: "email", want: pb.RetryStrategy_RETRY_STRATEGY_FIXED},
+		{name: "Topic Override", topic: "payment", want: pb.RetryStrategy_RETRY_STRATEGY_LINEAR},
+		{name: "Per-task Override", topic: "payment", requested: requested, want: pb.RetryStrategy_RETRY_STRATEGY_FIXED},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := svc.resolveRetryPolicy(tt.topic, tt.requested)
+			if got.GetStrategy() != tt.want {
+				t.Errorf("resolveRetryPolicy() strategy = %v, want %v", got.GetStrategy(), tt.want)
+			}
+		})
+	}
+}
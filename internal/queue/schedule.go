@@ -0,0 +1,187 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/scheduler"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateSchedule 创建周期任务。
+// @Description 校验 cron 表达式与时区并计算首次触发时间；投递任务的重试次数与退避策略在创建时确定，规则同 Enqueue。
+// @Return: 表达式非法或在 end_time 之前不会触发时返回 InvalidArgument；ID 已存在时返回 AlreadyExists。
+func (s *Service) CreateSchedule(ctx context.Context, req *pb.CreateScheduleRequest) (*pb.CreateScheduleResponse, error) {
+	store, err := s.scheduleStore()
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 参数校验。
+	if req.Topic == "" || req.Payload == "" || req.CronExpr == "" {
+		return nil, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+	}
+	if err := reservedTopic(req.Topic); err != nil {
+		return nil, err
+	}
+	if req.StartTime < 0 || req.EndTime < 0 || (req.EndTime > 0 && req.EndTime < req.StartTime) {
+		return nil, status.Error(codes.InvalidArgument, "invalid time range")
+	}
+	if req.JitterSeconds < 0 || req.MaxRetries < 0 || req.VisibilityTimeout < 0 {
+		return nil, status.Error(codes.InvalidArgument, "jitter_seconds, max_retries and visibility_timeout must be >= 0")
+	}
+	if _, ok := pb.MisfirePolicy_name[int32(req.MisfirePolicy)]; !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown misfire_policy")
+	}
+	if req.RetryPolicy != nil {
+		if err := validateRetryPolicy(req.RetryPolicy); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	id := req.Id
+	if id == "" {
+		id = uuid.New().String()
+	}
+	maxRetries := req.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = s.maxRetries
+	}
+
+	now := s.currentTime(ctx)
+	sched := &pb.Schedule{
+		Id:                id,
+		Topic:             req.Topic,
+		Payload:           req.Payload,
+		CronExpr:          req.CronExpr,
+		TimeZone:          req.TimeZone,
+		StartTime:         req.StartTime,
+		EndTime:           req.EndTime,
+		JitterSeconds:     req.JitterSeconds,
+		MisfirePolicy:     req.MisfirePolicy,
+		MaxRetries:        maxRetries,
+		VisibilityTimeout: req.VisibilityTimeout,
+		RetryPolicy:       s.resolveRetryPolicy(req.Topic, req.RetryPolicy),
+		CreatedAt:         now.Unix(),
+	}
+
+	// 2. 计算首次触发时间。
+	next, err := scheduler.NextRun(sched, now)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if next.IsZero() {
+		return nil, status.Error(codes.InvalidArgument, "schedule never fires before end_time")
+	}
+	sched.NextRunTime = next.Unix()
+
+	if err := store.CreateSchedule(ctx, sched); err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.CreateScheduleResponse{Schedule: sched}, nil
+}
+
+// PauseSchedule 暂停周期任务。重复暂停不视为错误。
+// @Return: ID 不存在时返回 NotFound。
+func (s *Service) PauseSchedule(ctx context.Context, req *pb.PauseScheduleRequest) (*pb.PauseScheduleResponse, error) {
+	store, err := s.scheduleStore()
+	if err != nil {
+		return nil, err
+	}
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+	}
+
+	sched, err := store.SetSchedulePaused(ctx, req.Id, true, time.Time{})
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.PauseScheduleResponse{Schedule: sched}, nil
+}
+
+// ResumeSchedule 恢复周期任务，从当前时刻起重新计算下一次触发时间，暂停期间错过的触发不补发。
+// @Return: ID 不存在时返回 NotFound。
+func (s *Service) ResumeSchedule(ctx context.Context, req *pb.ResumeScheduleRequest) (*pb.ResumeScheduleResponse, error) {
+	store, err := s.scheduleStore()
+	if err != nil {
+		return nil, err
+	}
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+	}
+
+	sched, err := store.GetSchedule(ctx, req.Id)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	next, err := scheduler.NextRun(sched, s.currentTime(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	sched, err = store.SetSchedulePaused(ctx, req.Id, false, next)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.ResumeScheduleResponse{Schedule: sched}, nil
+}
+
+// ListSchedules 查询周期任务，topic 为空时返回全部。
+func (s *Service) ListSchedules(ctx context.Context, req *pb.ListSchedulesRequest) (*pb.ListSchedulesResponse, error) {
+	store, err := s.scheduleStore()
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := store.ListSchedules(ctx, req.Topic)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	return &pb.ListSchedulesResponse{Schedules: schedules}, nil
+}
+
+// DeleteSchedule 删除周期任务，已投递的任务不受影响。
+// @Return: ID 不存在时返回 NotFound。
+func (s *Service) DeleteSchedule(ctx context.Context, req *pb.DeleteScheduleRequest) (*pb.DeleteScheduleResponse, error) {
+	store, err := s.scheduleStore()
+	if err != nil {
+		return nil, err
+	}
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+	}
+
+	if err := store.DeleteSchedule(ctx, req.Id); err != nil {
+		return &pb.DeleteScheduleResponse{Success: false}, storeError(err)
+	}
+	return &pb.DeleteScheduleResponse{Success: true}, nil
+}
+
+// currentTime 返回计算周期任务触发时间使用的当前时间，规则同 CronScheduler：
+// 存储实现 storage.Clock 时以服务端时间为准，使首次触发时间与 CronScheduler 的到期判定基于同一时钟；读取失败或不支持时退化为本地时钟。
+func (s *Service) currentTime(ctx context.Context) time.Time {
+	if clock, ok := s.store.(storage.Clock); ok {
+		now, err := clock.ServerTime(ctx)
+		if err == nil {
+			return now
+		}
+		log.Printf("Schedule read server time error: %v", err)
+	}
+	return time.Now()
+}
+
+// scheduleStore 探测存储实现是否支持周期任务。
+// @Return: 不支持时返回 Unimplemented。
+func (s *Service) scheduleStore() (storage.ScheduleStore, error) {
+	store, ok := s.store.(storage.ScheduleStore)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "storage backend does not support schedules")
+	}
+	return store, nil
+}
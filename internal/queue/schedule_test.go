@@ -0,0 +1,171 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/mocks"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// scheduleMockStore 组合 JobStore 与 ScheduleStore 的 Mock，模拟支持周期任务的存储实现。
+type scheduleMockStore struct {
+	*mocks.MockJobStore
+	*mocks.MockScheduleStore
+}
+
+func TestCreateSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSchedules := mocks.NewMockScheduleStore(ctrl)
+	svc := NewService(conf.QueueConfig{MaxRetries: 5}, scheduleMockStore{mocks.NewMockJobStore(ctrl), mockSchedules})
+
+	tests := []struct {
+		name     string
+		req      *pb.CreateScheduleRequest
+		mock     func()
+		wantCode codes.Code
+	}{
+		{
+			name: "Success",
+			req:  &pb.CreateScheduleRequest{Id: "daily", Topic: "report", Payload: "{}", CronExpr: "0 0 9 * * *", TimeZone: "Asia/Shanghai"},
+			mock: func() {
+				mockSchedules.EXPECT().CreateSchedule(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, s *pb.Schedule) error {
+					next := time.Unix(s.NextRunTime, 0).In(time.FixedZone("CST", 8*3600))
+					if next.Hour() != 9 || next.Minute() != 0 || !next.After(time.Now()) {
+						t.Errorf("NextRunTime = %v, want next 09:00 CST", next)
+					}
+					if s.MaxRetries != 5 {
+						t.Errorf("MaxRetries = %d, want default 5", s.MaxRetries)
+					}
+					return nil
+				})
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "Duplicate ID",
+			req:  &pb.CreateScheduleRequest{Id: "daily", Topic: "report", Payload: "{}", CronExpr: "@hourly"},
+			mock: func() {
+				mockSchedules.EXPECT().CreateSchedule(gomock.Any(), gomock.Any()).Return(errno.ErrScheduleAlreadyExist)
+			},
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name:     "Invalid Cron",
+			req:      &pb.CreateScheduleRequest{Topic: "report", Payload: "{}", CronExpr: "every day"},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Invalid Time Zone",
+			req:      &pb.CreateScheduleRequest{Topic: "report", Payload: "{}", CronExpr: "@daily", TimeZone: "Mars/Olympus"},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Ended",
+			req:      &pb.CreateScheduleRequest{Topic: "report", Payload: "{}", CronExpr: "@daily", EndTime: 1000},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Unknown Misfire Policy",
+			req:      &pb.CreateScheduleRequest{Topic: "report", Payload: "{}", CronExpr: "@daily", MisfirePolicy: 42},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			_, err := svc.CreateSchedule(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("CreateSchedule() code = %v, want %v (%v)", got, tt.wantCode, err)
+			}
+		})
+	}
+}
+
+// clockMockStore 在 scheduleMockStore 之上实现 storage.Clock，返回固定的服务端时间。
+type clockMockStore struct {
+	scheduleMockStore
+	now time.Time
+}
+
+func (c clockMockStore) ServerTime(context.Context) (time.Time, error) {
+	return c.now, nil
+}
+
+func TestCreateScheduleServerTime(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// 服务端时间与本地时钟相差数年，首次触发时间须按服务端时间计算
+	server := time.Date(2030, 1, 1, 8, 0, 0, 0, time.UTC)
+	mockSchedules := mocks.NewMockScheduleStore(ctrl)
+	svc := NewService(conf.QueueConfig{}, clockMockStore{scheduleMockStore{mocks.NewMockJobStore(ctrl), mockSchedules}, server})
+
+	mockSchedules.EXPECT().CreateSchedule(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, s *pb.Schedule) error {
+		if want := server.Add(time.Hour).Unix(); s.NextRunTime != want {
+			t.Errorf("NextRunTime = %v, want %v", time.Unix(s.NextRunTime, 0).UTC(), time.Unix(want, 0).UTC())
+		}
+		if s.CreatedAt != server.Unix() {
+			t.Errorf("CreatedAt = %d, want server time %d", s.CreatedAt, server.Unix())
+		}
+		return nil
+	})
+	req := &pb.CreateScheduleRequest{Id: "daily", Topic: "report", Payload: "{}", CronExpr: "0 0 9 * * *", TimeZone: "UTC"}
+	if _, err := svc.CreateSchedule(context.Background(), req); err != nil {
+		t.Fatalf("CreateSchedule() error = %v", err)
+	}
+}
+
+func TestResumeSchedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSchedules := mocks.NewMockScheduleStore(ctrl)
+	svc := NewService(conf.QueueConfig{}, scheduleMockStore{mocks.NewMockJobStore(ctrl), mockSchedules})
+
+	paused := &pb.Schedule{Id: "tick", Topic: "t", CronExpr: "@every 1m", Paused: true, NextRunTime: 1000}
+	mockSchedules.EXPECT().GetSchedule(gomock.Any(), "tick").Return(paused, nil)
+	mockSchedules.EXPECT().
+		SetSchedulePaused(gomock.Any(), "tick", false, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, _ bool, next time.Time) (*pb.Schedule, error) {
+			// 暂停期间错过的触发不补发，从当前时刻重新计算
+			if !next.After(time.Now()) {
+				t.Errorf("next = %v, want a future time", next)
+			}
+			return &pb.Schedule{Id: "tick", NextRunTime: next.Unix()}, nil
+		})
+
+	if _, err := svc.ResumeSchedule(context.Background(), &pb.ResumeScheduleRequest{Id: "tick"}); err != nil {
+		t.Fatalf("ResumeSchedule() error = %v", err)
+	}
+
+	mockSchedules.EXPECT().GetSchedule(gomock.Any(), "missing").Return(nil, errno.ErrScheduleNotFound)
+	_, err := svc.ResumeSchedule(context.Background(), &pb.ResumeScheduleRequest{Id: "missing"})
+	if got := status.Code(err); got != codes.NotFound {
+		t.Errorf("ResumeSchedule() code = %v, want %v", got, codes.NotFound)
+	}
+}
+
+func TestScheduleUnsupportedStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := NewService(conf.QueueConfig{}, mocks.NewMockJobStore(ctrl))
+	_, err := svc.ListSchedules(context.Background(), &pb.ListSchedulesRequest{})
+	if got := status.Code(err); got != codes.Unimplemented {
+		t.Errorf("ListSchedules() code = %v, want %v", got, codes.Unimplemented)
+	}
+}
@@ -4,29 +4,109 @@ package queue
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
 	"time"
 
 	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
 	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+	"github.com/AkikoAkaki/async-task-platform/internal/election"
 	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/tiered"
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// Retrieve 单次拉取数量的默认值与上限。
+const (
+	defaultBatchSize = 10
+	maxBatchSize     = 100
+)
+
+// maxPriority 任务优先级上限，优先级取值范围为 [0, maxPriority]。
+const maxPriority = 1000
+
+// 重复 ID 提交的处理策略，对应配置项 queue.dedup_policy。
+const (
+	DedupReturnExisting = "return_existing" // 视为成功并返回已存在的 ID，不重复入队
+	DedupReject         = "reject"          // 返回 AlreadyExists 错误
+	DedupReplace        = "replace"         // 覆盖等待中/已死信的旧任务
+)
+
 // Service 延迟队列 gRPC 服务实现。
 // @Description 充当业务网关（Gateway），负责输入校验、ID 生成、任务规整，最后通过 JobStore 接口实现持久化。
 type Service struct {
 	pb.UnimplementedDelayQueueServiceServer
-	store storage.JobStore // 任务持久化后端实现
+	store       storage.JobStore // 任务持久化后端实现
+	dedupPolicy string           // 重复 ID 提交的处理策略
+	maxRetries  int32            // 请求未指定时的默认最大重试次数
+
+	defaultRetry *pb.RetryPolicy            // 全局默认退避策略，nil 表示立即重试
+	topicRetry   map[string]*pb.RetryPolicy // 按 Topic 覆盖的退避策略
+
+	elector *election.Elector // 选主器，nil 表示未开启选主
+}
+
+// Option 定义 Service 的可选配置项。
+type Option func(*Service)
+
+// WithElector 注入选主器，使 GetLeader 可以报告选主状态。
+func WithElector(e *election.Elector) Option {
+	return func(s *Service) {
+		s.elector = e
+	}
 }
 
 // NewService 创建延迟队列服务实例。
+// @Param cfg: 队列全局配置，包含去重策略、默认重试次数、退避策略等。
 // @Param store: 任务存取引擎的实现，通常为 Redis 实现。
-func NewService(store storage.JobStore) *Service {
-	return &Service{
-		store: store,
+// @Param opts: 可选配置项，如 WithElector。
+func NewService(cfg conf.QueueConfig, store storage.JobStore, opts ...Option) *Service {
+	policy := cfg.DedupPolicy
+	switch policy {
+	case DedupReturnExisting, DedupReject, DedupReplace:
+	default:
+		// 未配置或非法取值时回退到最安全的默认策略
+		policy = DedupReturnExisting
+	}
+
+	maxRetries := int32(min(cfg.MaxRetries, math.MaxInt32))
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+
+	// 非法的退避配置不阻止启动，记录日志后回退为立即重试
+	defaultRetry, err := retryPolicyFromConfig(&cfg.Retry)
+	if err != nil {
+		log.Printf("Invalid queue.retry config, fallback to immediate retry: %v", err)
+	}
+	topicRetry := make(map[string]*pb.RetryPolicy, len(cfg.Topics))
+	for topic, tc := range cfg.Topics {
+		if tc.Retry == nil {
+			continue
+		}
+		p, err := retryPolicyFromConfig(tc.Retry)
+		if err != nil {
+			log.Printf("Invalid queue.topics.%s.retry config, fallback to immediate retry: %v", topic, err)
+		}
+		topicRetry[topic] = p
+	}
+
+	s := &Service{
+		store:        store,
+		dedupPolicy:  policy,
+		maxRetries:   maxRetries,
+		defaultRetry: defaultRetry,
+		topicRetry:   topicRetry,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // Enqueue 处理任务提交请求（入队）。
@@ -34,46 +114,79 @@ func NewService(store storage.JobStore) *Service {
 // @Return: 成功则返回任务分配的唯一 ID；失败则返回 gRPC 错误码。
 func (s *Service) Enqueue(ctx context.Context, req *pb.EnqueueRequest) (*pb.EnqueueResponse, error) {
 	// 1. 参数校验。
-	// @Validation: 检查 Topic、Payload 是否为空，延时时间是否合法。
+	// @Validation: 检查 Topic、Payload 是否为空，执行时间是否合法。
 	if req.Topic == "" || req.Payload == "" {
 		return nil, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
 	}
-	if req.DelaySeconds < 0 {
-		return nil, status.Error(codes.InvalidArgument, "delay_seconds must be >= 0")
+	if err := reservedTopic(req.Topic); err != nil {
+		return nil, err
+	}
+	executeAt, err := executeTime(req, time.Now())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.MaxRetries < 0 || req.VisibilityTimeout < 0 {
+		return nil, status.Error(codes.InvalidArgument, "max_retries and visibility_timeout must be >= 0")
+	}
+	if req.Priority < 0 || req.Priority > maxPriority {
+		return nil, status.Errorf(codes.InvalidArgument, "priority must be between 0 and %d", maxPriority)
+	}
+	if req.RetryPolicy != nil {
+		if err := validateRetryPolicy(req.RetryPolicy); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 	}
 
 	// 2. 身份标识分配。
-	// @Note: 优先使用客户端传入的 ID 以支持幂等提交，否则由系统自动生成 UUID。
+	// @Note: 优先使用客户端传入的 ID 以支持幂等提交（重复 ID 按 dedupPolicy 处理），否则由系统自动生成 UUID。
 	taskID := req.Id
 	if taskID == "" {
 		taskID = uuid.New().String()
 	}
 
 	// 3. 策略初始化。
-	// @Default: 若未指定最大重试次数，则赋予系统预设默认值（3次）。
+	// @Default: 若未指定最大重试次数，则赋予 queue.max_retries（未配置时为 3 次）。
+	// 可见性超时未指定时保持 0，由 Watchdog 按 queue.visibility_timeout 判定。
+	// 退避策略在入队时确定并随任务保存，使 Nack 与 Watchdog 回收采用同一策略。
 	maxRetries := req.MaxRetries
 	if maxRetries == 0 {
-		maxRetries = 3
+		maxRetries = s.maxRetries
 	}
 
 	// 4. 构造任务实体快照。
 	task := &pb.Task{
-		Id:          taskID,
-		Topic:       req.Topic,
-		Payload:     req.Payload,
-		ExecuteTime: time.Now().Add(time.Duration(req.DelaySeconds) * time.Second).Unix(),
-		RetryCount:  0,
-		MaxRetries:  maxRetries,
-		CreatedAt:   time.Now().Unix(),
+		Id:                taskID,
+		Topic:             req.Topic,
+		Payload:           req.Payload,
+		ExecuteTime:       executeAt.Unix(),
+		ExecuteTimeMs:     executeAt.UnixMilli(),
+		RetryCount:        0,
+		MaxRetries:        maxRetries,
+		CreatedAt:         time.Now().Unix(),
+		VisibilityTimeout: req.VisibilityTimeout,
+		RetryPolicy:       s.resolveRetryPolicy(req.Topic, req.RetryPolicy),
+		Priority:          req.Priority,
 	}
 
 	// 5. 调用持久化层。
+	// @Idempotency: 存储层原子地执行"ID 不存在才写入"，重复提交按策略返回已有 ID、拒绝或覆盖。
 	// @ErrorHandling: 若存储层故障（如 Redis 连接断开），返回 Internal 错误给客户端以便重试。
-	if err := s.store.Add(ctx, task); err != nil {
+	if s.dedupPolicy == DedupReplace {
+		err = s.store.Replace(ctx, task)
+	} else {
+		err = s.store.Add(ctx, task)
+	}
+	if err != nil {
+		if errors.Is(err, errno.ErrTaskAlreadyExist) && s.dedupPolicy == DedupReturnExisting {
+			return &pb.EnqueueResponse{
+				Success: true,
+				Id:      taskID,
+			}, nil
+		}
 		return &pb.EnqueueResponse{
 			Success:      false,
 			ErrorMessage: "failed to store task",
-		}, status.Error(codes.Internal, err.Error())
+		}, storeError(err)
 	}
 
 	return &pb.EnqueueResponse{
@@ -82,14 +195,181 @@ func (s *Service) Enqueue(ctx context.Context, req *pb.EnqueueRequest) (*pb.Enqu
 	}, nil
 }
 
-// Retrieve 拉取任务（Admin/Monitoring 扩展接口）。
-// @Status: Unimplemented
+// executeTime 根据请求计算任务的执行时刻，精度为毫秒。
+// @Validation: execute_at、delay、delay_seconds 至多指定一个，都未指定时立即执行；延迟不能为负。
+// execute_at 早于当前时间视为立即执行。
+func executeTime(req *pb.EnqueueRequest, now time.Time) (time.Time, error) {
+	set := 0
+	for _, ok := range []bool{req.ExecuteAt != nil, req.Delay != nil, req.DelaySeconds != 0} {
+		if ok {
+			set++
+		}
+	}
+	if set > 1 {
+		return time.Time{}, errors.New("only one of execute_at, delay and delay_seconds may be set")
+	}
+
+	switch {
+	case req.ExecuteAt != nil:
+		if err := req.ExecuteAt.CheckValid(); err != nil {
+			return time.Time{}, fmt.Errorf("invalid execute_at: %w", err)
+		}
+		return req.ExecuteAt.AsTime().Truncate(time.Millisecond), nil
+	case req.Delay != nil:
+		if err := req.Delay.CheckValid(); err != nil {
+			return time.Time{}, fmt.Errorf("invalid delay: %w", err)
+		}
+		if req.Delay.AsDuration() < 0 {
+			return time.Time{}, errors.New("delay must be >= 0")
+		}
+		return now.Add(req.Delay.AsDuration()).Truncate(time.Millisecond), nil
+	case req.DelaySeconds < 0:
+		return time.Time{}, errors.New("delay_seconds must be >= 0")
+	default:
+		return now.Add(time.Duration(req.DelaySeconds) * time.Second).Truncate(time.Millisecond), nil
+	}
+}
+
+// Retrieve 拉取并锁定指定 Topic 下已到期的任务（Worker 消费入口）。
+// @Description 基于 JobStore.FetchAndHold 实现，返回的任务进入执行中状态，
+// Worker 需在可见性超时前调用 Ack/Nack，否则任务会被 Watchdog 回收重投。
+// @Return: 无到期任务时返回空列表。
 func (s *Service) Retrieve(ctx context.Context, req *pb.RetrieveRequest) (*pb.RetrieveResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method Retrieve not implemented yet")
+	if req.Topic == "" {
+		return nil, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+	}
+	if err := reservedTopic(req.Topic); err != nil {
+		return nil, err
+	}
+
+	// @Limit: 限制单次拉取数量，防止单个 Lua 脚本批量弹出过多任务阻塞 Redis。
+	batchSize := int64(req.BatchSize)
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	if batchSize > maxBatchSize {
+		batchSize = maxBatchSize
+	}
+
+	tasks, err := s.store.FetchAndHold(ctx, req.Topic, batchSize)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.RetrieveResponse{Tasks: tasks}, nil
+}
+
+// Ack 确认任务处理成功（Worker 调用）。
+// @Fencing: 须回传投递时下发的 lease；任务已超时并被重新投递时令牌失效，返回 Aborted。
+// @Return: 任务已不在执行中（如超时被回收）时返回 NotFound。
+func (s *Service) Ack(ctx context.Context, req *pb.AckRequest) (*pb.AckResponse, error) {
+	if req.Topic == "" || req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+	}
+	if err := reservedTopic(req.Topic); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Ack(ctx, req.Topic, req.Id, req.Lease); err != nil {
+		return &pb.AckResponse{Success: false}, storeError(err)
+	}
+
+	return &pb.AckResponse{Success: true}, nil
+}
+
+// Nack 报告任务处理失败（Worker 调用）。
+// @Description 任务在 retry_delay_seconds 之后重新可见；重试次数耗尽则进入死信队列。
+// lease 校验规则同 Ack。
+// @Return: 任务已不在执行中（如超时被回收）时返回 NotFound。
+func (s *Service) Nack(ctx context.Context, req *pb.NackRequest) (*pb.NackResponse, error) {
+	if req.Topic == "" || req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+	}
+	if err := reservedTopic(req.Topic); err != nil {
+		return nil, err
+	}
+	if req.RetryDelaySeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "retry_delay_seconds must be >= 0")
+	}
+
+	opts := storage.NackOptions{
+		Reason:     req.Reason,
+		RetryDelay: time.Duration(req.RetryDelaySeconds) * time.Second,
+		MaxRetries: s.maxRetries,
+	}
+	if err := s.store.Nack(ctx, req.Topic, req.Id, req.Lease, opts); err != nil {
+		return &pb.NackResponse{Success: false}, storeError(err)
+	}
+
+	return &pb.NackResponse{Success: true}, nil
+}
+
+// ExtendLease 延长执行中任务的可见性超时（Worker 心跳调用）。
+// @Description 任务至少在 extend_seconds 之后才会被 Watchdog 判定超时；lease 校验规则同 Ack。
+// @Return: 任务已不在执行中时返回 NotFound；任务已被重新投递时返回 Aborted。
+func (s *Service) ExtendLease(ctx context.Context, req *pb.ExtendLeaseRequest) (*pb.ExtendLeaseResponse, error) {
+	if req.Topic == "" || req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+	}
+	if err := reservedTopic(req.Topic); err != nil {
+		return nil, err
+	}
+	if req.ExtendSeconds <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "extend_seconds must be > 0")
+	}
+
+	extra := time.Duration(req.ExtendSeconds) * time.Second
+	if err := s.store.Extend(ctx, req.Topic, req.Id, req.Lease, extra); err != nil {
+		return &pb.ExtendLeaseResponse{Success: false}, storeError(err)
+	}
+
+	return &pb.ExtendLeaseResponse{Success: true}, nil
 }
 
 // Delete 撤销任务。
-// @Status: Unimplemented
+// @Description 等待中或已进入死信队列的任务可被撤销；执行中的任务已被 Worker 持有，返回 FailedPrecondition。
+// @Return: ID 不存在时返回 NotFound。
 func (s *Service) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "method Delete not implemented yet")
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+	}
+
+	if err := s.store.Remove(ctx, req.Id); err != nil {
+		return &pb.DeleteResponse{Success: false}, storeError(err)
+	}
+
+	return &pb.DeleteResponse{Success: true}, nil
+}
+
+// reservedTopic 拒绝使用存储层内部 Topic 前缀的请求，避免业务任务与分层存储的占位条目、信封混在一起。
+// @Note: 只约束投递与消费类接口；死信、隔离区等运维接口不做限制，以便排查内部 Topic 中被隔离的占位条目。
+// @Return: topic 以 tiered.ReservedTopicPrefix 开头时返回 InvalidArgument，否则返回 nil。
+func reservedTopic(topic string) error {
+	if tiered.IsReservedTopic(topic) {
+		return status.Errorf(codes.InvalidArgument, "topic prefix %q is reserved", tiered.ReservedTopicPrefix)
+	}
+	return nil
+}
+
+// storeError 将存储层返回的错误转换为 gRPC 状态码。
+// @Mapping: 已知的 errno 业务错误映射为对应的语义状态码，其余错误一律视为 Internal。
+func storeError(err error) error {
+	switch {
+	case errors.Is(err, errno.ErrTaskNotFound):
+		return status.Error(codes.NotFound, errno.ErrTaskNotFound.Message)
+	case errors.Is(err, errno.ErrTaskRunning):
+		return status.Error(codes.FailedPrecondition, errno.ErrTaskRunning.Message)
+	case errors.Is(err, errno.ErrTaskAlreadyExist):
+		return status.Error(codes.AlreadyExists, errno.ErrTaskAlreadyExist.Message)
+	case errors.Is(err, errno.ErrLeaseMismatch):
+		return status.Error(codes.Aborted, errno.ErrLeaseMismatch.Message)
+	case errors.Is(err, errno.ErrScheduleNotFound):
+		return status.Error(codes.NotFound, errno.ErrScheduleNotFound.Message)
+	case errors.Is(err, errno.ErrScheduleAlreadyExist):
+		return status.Error(codes.AlreadyExists, errno.ErrScheduleAlreadyExist.Message)
+	case errors.Is(err, errno.ErrNotSupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
 }
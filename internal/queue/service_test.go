@@ -3,10 +3,18 @@ package queue
 import (
 	"context"
 	"testing"
+	"time"
 
 	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
 	"github.com/AkikoAkaki/async-task-platform/internal/storage/mocks"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestEnqueue(t *testing.T) {
@@ -16,7 +24,7 @@ func TestEnqueue(t *testing.T) {
 
 	// 2. 创建 Mock 对象
 	mockStore := mocks.NewMockJobStore(ctrl)
-	svc := NewService(mockStore)
+	svc := NewService(conf.QueueConfig{}, mockStore)
 
 	// 3. 定义测试用例
 	tests := []struct {
@@ -63,3 +71,434 @@ func TestEnqueue(t *testing.T) {
 		})
 	}
 }
+
+func TestReservedTopic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// 存储层的任何方法都不应被调用
+	svc := NewService(conf.QueueConfig{}, scheduleMockStore{mocks.NewMockJobStore(ctrl), mocks.NewMockScheduleStore(ctrl)})
+	ctx := context.Background()
+	const topic = "_tiered_cold"
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"Enqueue", func() error {
+			_, err := svc.Enqueue(ctx, &pb.EnqueueRequest{Topic: topic, Payload: "{}"})
+			return err
+		}},
+		{"Retrieve", func() error {
+			_, err := svc.Retrieve(ctx, &pb.RetrieveRequest{Topic: topic})
+			return err
+		}},
+		{"Ack", func() error {
+			_, err := svc.Ack(ctx, &pb.AckRequest{Topic: topic, Id: "t-1"})
+			return err
+		}},
+		{"Nack", func() error {
+			_, err := svc.Nack(ctx, &pb.NackRequest{Topic: topic, Id: "t-1"})
+			return err
+		}},
+		{"ExtendLease", func() error {
+			_, err := svc.ExtendLease(ctx, &pb.ExtendLeaseRequest{Topic: topic, Id: "t-1", ExtendSeconds: 10})
+			return err
+		}},
+		{"CreateSchedule", func() error {
+			_, err := svc.CreateSchedule(ctx, &pb.CreateScheduleRequest{Topic: topic, Payload: "{}", CronExpr: "* * * * *"})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.call()); got != codes.InvalidArgument {
+				t.Errorf("%s() code = %v, want %v", tt.name, got, codes.InvalidArgument)
+			}
+		})
+	}
+}
+
+func TestEnqueueDuplicateID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mocks.NewMockJobStore(ctrl)
+	req := &pb.EnqueueRequest{Topic: "test", Payload: "{}", Id: "order-1024"}
+
+	tests := []struct {
+		name     string
+		policy   string
+		mock     func()
+		wantCode codes.Code
+	}{
+		{
+			name:   "Return Existing",
+			policy: DedupReturnExisting,
+			mock: func() {
+				mockStore.EXPECT().Add(gomock.Any(), gomock.Any()).Return(errno.ErrTaskAlreadyExist)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:   "Reject",
+			policy: DedupReject,
+			mock: func() {
+				mockStore.EXPECT().Add(gomock.Any(), gomock.Any()).Return(errno.ErrTaskAlreadyExist)
+			},
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name:   "Replace",
+			policy: DedupReplace,
+			mock: func() {
+				mockStore.EXPECT().Replace(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:   "Replace Running",
+			policy: DedupReplace,
+			mock: func() {
+				mockStore.EXPECT().Replace(gomock.Any(), gomock.Any()).Return(errno.ErrTaskRunning)
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			svc := NewService(conf.QueueConfig{DedupPolicy: tt.policy}, mockStore)
+			resp, err := svc.Enqueue(context.Background(), req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("Enqueue() code = %v, want %v", got, tt.wantCode)
+			}
+			if err == nil && resp.Id != req.Id {
+				t.Errorf("Enqueue() id = %s, want %s", resp.Id, req.Id)
+			}
+		})
+	}
+}
+
+func TestEnqueueRetryPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mocks.NewMockJobStore(ctrl)
+	svc := NewService(conf.QueueConfig{MaxRetries: 5}, mockStore)
+
+	tests := []struct {
+		name     string
+		req      *pb.EnqueueRequest
+		mock     func()
+		wantCode codes.Code
+	}{
+		{
+			name: "Config Default Retries",
+			req:  &pb.EnqueueRequest{Topic: "test", Payload: "{}"},
+			mock: func() {
+				mockStore.EXPECT().Add(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *pb.Task) error {
+					if task.MaxRetries != 5 || task.VisibilityTimeout != 0 {
+						t.Errorf("task = %v, want max_retries 5 and no visibility_timeout", task)
+					}
+					return nil
+				})
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "Per-task Policy",
+			req:  &pb.EnqueueRequest{Topic: "test", Payload: "{}", MaxRetries: 1, VisibilityTimeout: 600},
+			mock: func() {
+				mockStore.EXPECT().Add(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *pb.Task) error {
+					if task.MaxRetries != 1 || task.VisibilityTimeout != 600 {
+						t.Errorf("task = %v, want max_retries 1 and visibility_timeout 600", task)
+					}
+					return nil
+				})
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "Negative Visibility Timeout",
+			req:      &pb.EnqueueRequest{Topic: "test", Payload: "{}", VisibilityTimeout: -1},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "Priority",
+			req:  &pb.EnqueueRequest{Topic: "test", Payload: "{}", Priority: 9},
+			mock: func() {
+				mockStore.EXPECT().Add(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, task *pb.Task) error {
+					if task.Priority != 9 {
+						t.Errorf("task.Priority = %d, want 9", task.Priority)
+					}
+					return nil
+				})
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "Priority Out Of Range",
+			req:      &pb.EnqueueRequest{Topic: "test", Payload: "{}", Priority: maxPriority + 1},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			_, err := svc.Enqueue(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("Enqueue() code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestExecuteTime(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := now.Add(90 * time.Minute)
+
+	tests := []struct {
+		name    string
+		req     *pb.EnqueueRequest
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "Immediate", req: &pb.EnqueueRequest{}, want: now},
+		{name: "Delay Seconds", req: &pb.EnqueueRequest{DelaySeconds: 10}, want: now.Add(10 * time.Second)},
+		{name: "Millisecond Delay", req: &pb.EnqueueRequest{Delay: durationpb.New(1500 * time.Millisecond)}, want: now.Add(1500 * time.Millisecond)},
+		{name: "Sub-millisecond Truncated", req: &pb.EnqueueRequest{Delay: durationpb.New(2*time.Millisecond + 999*time.Microsecond)}, want: now.Add(2 * time.Millisecond)},
+		{name: "Absolute", req: &pb.EnqueueRequest{ExecuteAt: timestamppb.New(at)}, want: at},
+		{name: "Negative Delay", req: &pb.EnqueueRequest{Delay: durationpb.New(-time.Second)}, wantErr: true},
+		{name: "Negative Delay Seconds", req: &pb.EnqueueRequest{DelaySeconds: -1}, wantErr: true},
+		{name: "Conflicting Fields", req: &pb.EnqueueRequest{DelaySeconds: 1, ExecuteAt: timestamppb.New(at)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := executeTime(tt.req, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("executeTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("executeTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mocks.NewMockJobStore(ctrl)
+	svc := NewService(conf.QueueConfig{}, mockStore)
+
+	tests := []struct {
+		name     string
+		req      *pb.DeleteRequest
+		mock     func()
+		wantCode codes.Code
+	}{
+		{
+			name: "Success",
+			req:  &pb.DeleteRequest{Id: "task-1"},
+			mock: func() {
+				mockStore.EXPECT().Remove(gomock.Any(), "task-1").Return(nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "Not Found",
+			req:  &pb.DeleteRequest{Id: "missing"},
+			mock: func() {
+				mockStore.EXPECT().Remove(gomock.Any(), "missing").Return(errno.ErrTaskNotFound)
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "Running",
+			req:  &pb.DeleteRequest{Id: "busy"},
+			mock: func() {
+				mockStore.EXPECT().Remove(gomock.Any(), "busy").Return(errno.ErrTaskRunning)
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "Empty ID",
+			req:      &pb.DeleteRequest{},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			_, err := svc.Delete(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("Delete() code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRetrieve(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mocks.NewMockJobStore(ctrl)
+	svc := NewService(conf.QueueConfig{}, mockStore)
+
+	tests := []struct {
+		name     string
+		req      *pb.RetrieveRequest
+		mock     func()
+		wantCode codes.Code
+	}{
+		{
+			name: "Default Batch Size",
+			req:  &pb.RetrieveRequest{Topic: "test"},
+			mock: func() {
+				mockStore.EXPECT().FetchAndHold(gomock.Any(), "test", int64(defaultBatchSize)).
+					Return([]*pb.Task{{Id: "task-1", Topic: "test"}}, nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "Batch Size Capped",
+			req:  &pb.RetrieveRequest{Topic: "test", BatchSize: 1000},
+			mock: func() {
+				mockStore.EXPECT().FetchAndHold(gomock.Any(), "test", int64(maxBatchSize)).Return(nil, nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "Empty Topic",
+			req:      &pb.RetrieveRequest{},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			_, err := svc.Retrieve(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("Retrieve() code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestNack(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mocks.NewMockJobStore(ctrl)
+	svc := NewService(conf.QueueConfig{MaxRetries: 5}, mockStore)
+
+	tests := []struct {
+		name     string
+		req      *pb.NackRequest
+		mock     func()
+		wantCode codes.Code
+	}{
+		{
+			name: "Success With Delay",
+			req:  &pb.NackRequest{Topic: "test", Id: "task-1", Lease: "lease-1", Reason: "timeout", RetryDelaySeconds: 30},
+			mock: func() {
+				mockStore.EXPECT().
+					Nack(gomock.Any(), "test", "task-1", "lease-1", storage.NackOptions{Reason: "timeout", RetryDelay: 30 * time.Second, MaxRetries: 5}).
+					Return(nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "Not Running",
+			req:  &pb.NackRequest{Topic: "test", Id: "task-2"},
+			mock: func() {
+				mockStore.EXPECT().Nack(gomock.Any(), "test", "task-2", gomock.Any(), gomock.Any()).Return(errno.ErrTaskNotFound)
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name: "Stale Lease",
+			req:  &pb.NackRequest{Topic: "test", Id: "task-4", Lease: "stale"},
+			mock: func() {
+				mockStore.EXPECT().Nack(gomock.Any(), "test", "task-4", "stale", gomock.Any()).Return(errno.ErrLeaseMismatch)
+			},
+			wantCode: codes.Aborted,
+		},
+		{
+			name:     "Negative Delay",
+			req:      &pb.NackRequest{Topic: "test", Id: "task-3", RetryDelaySeconds: -1},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			_, err := svc.Nack(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("Nack() code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestExtendLease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mocks.NewMockJobStore(ctrl)
+	svc := NewService(conf.QueueConfig{}, mockStore)
+
+	tests := []struct {
+		name     string
+		req      *pb.ExtendLeaseRequest
+		mock     func()
+		wantCode codes.Code
+	}{
+		{
+			name: "Success",
+			req:  &pb.ExtendLeaseRequest{Topic: "test", Id: "task-1", Lease: "lease-1", ExtendSeconds: 60},
+			mock: func() {
+				mockStore.EXPECT().Extend(gomock.Any(), "test", "task-1", "lease-1", 60*time.Second).Return(nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "Redelivered",
+			req:  &pb.ExtendLeaseRequest{Topic: "test", Id: "task-2", Lease: "stale", ExtendSeconds: 60},
+			mock: func() {
+				mockStore.EXPECT().Extend(gomock.Any(), "test", "task-2", "stale", gomock.Any()).Return(errno.ErrLeaseMismatch)
+			},
+			wantCode: codes.Aborted,
+		},
+		{
+			name:     "Non-positive Extension",
+			req:      &pb.ExtendLeaseRequest{Topic: "test", Id: "task-3"},
+			mock:     func() {},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			_, err := svc.ExtendLease(context.Background(), tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("ExtendLease() code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// subscribePollInterval 订阅流的兜底轮询间隔。
+// @Description 存储层不支持 Notifier 或通知丢失时，最迟在该间隔后重新检查到期任务。
+const subscribePollInterval = 5 * time.Second
+
+// subscribeMinWait 两次检查之间的最小间隔，避免时钟误差导致推送循环空转。
+const subscribeMinWait = 10 * time.Millisecond
+
+// Subscribe 处理 Worker 的双向订阅流。
+// @Description 首条消息必须为 open，声明订阅的 Topic 与初始 credit。此后服务端在任务到期时主动推送，
+// 每推送一个任务消耗一个 credit；Worker 通过 credit 消息追加额度，通过 ack/nack 消息确认任务，
+// 处理结果以 AckResult 回传。流断开后未确认的任务由 Watchdog 在可见性超时后回收重投。
+func (s *Service) Subscribe(stream pb.DelayQueueService_SubscribeServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	open := first.GetOpen()
+	if open == nil {
+		return status.Error(codes.InvalidArgument, "first message must be open")
+	}
+	if len(open.Topics) == 0 || open.Credit < 0 {
+		return status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+	}
+	for _, topic := range open.Topics {
+		if topic == "" {
+			return status.Error(codes.InvalidArgument, errno.ErrInvalidParam.Message)
+		}
+		if err := reservedTopic(topic); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	sub := &subscription{
+		svc:    s,
+		stream: stream,
+		topics: open.Topics,
+		wake:   make(chan struct{}, 1),
+		errCh:  make(chan error, 1),
+	}
+	sub.credit.Store(int64(open.Credit))
+
+	go sub.recvLoop(ctx)
+
+	// @Notify: 优先使用存储层的就绪通知；不支持或订阅失败时退化为定时轮询。
+	var notify <-chan string
+	if n, ok := s.store.(storage.Notifier); ok {
+		ch, err := n.Notifications(ctx, open.Topics)
+		if err != nil {
+			log.Printf("Subscribe notifications unavailable, fallback to polling: %v", err)
+		} else {
+			notify = ch
+		}
+	}
+
+	return sub.pushLoop(ctx, notify)
+}
+
+// subscription 保存单条订阅流的运行状态。
+type subscription struct {
+	svc    *Service
+	stream pb.DelayQueueService_SubscribeServer
+	topics []string
+	next   int // 轮询起点，保证多个 Topic 之间公平推送
+
+	credit atomic.Int64  // 剩余可推送的任务数
+	wake   chan struct{} // credit 增加时唤醒推送循环
+	errCh  chan error    // 接收循环结束原因
+
+	sendMu sync.Mutex // gRPC 流不允许并发 Send
+}
+
+// send 串行化地向流写入一条消息。
+func (sub *subscription) send(resp *pb.SubscribeResponse) error {
+	sub.sendMu.Lock()
+	defer sub.sendMu.Unlock()
+	return sub.stream.Send(resp)
+}
+
+// recvLoop 持续读取 Worker 发来的 credit/ack/nack 消息，直到流关闭。
+func (sub *subscription) recvLoop(ctx context.Context) {
+	for {
+		req, err := sub.stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			sub.errCh <- err
+			return
+		}
+
+		switch p := req.Payload.(type) {
+		case *pb.SubscribeRequest_Credit:
+			if p.Credit <= 0 {
+				sub.errCh <- status.Error(codes.InvalidArgument, "credit must be > 0")
+				return
+			}
+			sub.credit.Add(int64(p.Credit))
+			select {
+			case sub.wake <- struct{}{}:
+			default:
+			}
+		case *pb.SubscribeRequest_Ack:
+			_, err := sub.svc.Ack(ctx, p.Ack)
+			if err := sub.send(ackResult(p.Ack.GetId(), err)); err != nil {
+				sub.errCh <- err
+				return
+			}
+		case *pb.SubscribeRequest_Nack:
+			_, err := sub.svc.Nack(ctx, p.Nack)
+			if err := sub.send(ackResult(p.Nack.GetId(), err)); err != nil {
+				sub.errCh <- err
+				return
+			}
+		default:
+			sub.errCh <- status.Error(codes.InvalidArgument, "open can only be sent once")
+			return
+		}
+	}
+}
+
+// pushLoop 在有 credit 时推送到期任务，否则等待通知、credit 或兜底定时器唤醒。
+func (sub *subscription) pushLoop(ctx context.Context, notify <-chan string) error {
+	for {
+		if err := sub.deliver(ctx); err != nil {
+			return err
+		}
+
+		timer := time.NewTimer(sub.nextWait(ctx))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case err := <-sub.errCh:
+			timer.Stop()
+			return err
+		case _, ok := <-notify:
+			if !ok {
+				notify = nil // 通知通道关闭后仅依赖轮询
+			}
+		case <-sub.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// deliver 按 Topic 轮转拉取到期任务并推送，直到 credit 耗尽或所有 Topic 均无到期任务。
+func (sub *subscription) deliver(ctx context.Context) error {
+	idle := 0
+	for idle < len(sub.topics) {
+		credit := sub.credit.Load()
+		if credit <= 0 {
+			return nil
+		}
+		limit := min(credit, maxBatchSize)
+
+		topic := sub.topics[sub.next]
+		sub.next = (sub.next + 1) % len(sub.topics)
+
+		tasks, err := sub.svc.store.FetchAndHold(ctx, topic, limit)
+		if err != nil {
+			return storeError(err)
+		}
+		if len(tasks) == 0 {
+			idle++
+			continue
+		}
+		idle = 0
+
+		sub.credit.Add(-int64(len(tasks)))
+		for _, task := range tasks {
+			resp := &pb.SubscribeResponse{Payload: &pb.SubscribeResponse_Task{Task: task}}
+			if err := sub.send(resp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// nextWait 计算下一次兜底检查前的等待时长：不超过轮询间隔，且不晚于最早任务的到期时间。
+func (sub *subscription) nextWait(ctx context.Context) time.Duration {
+	wait := subscribePollInterval
+	if sub.credit.Load() <= 0 {
+		return wait
+	}
+	n, ok := sub.svc.store.(storage.Notifier)
+	if !ok {
+		return wait
+	}
+	for _, topic := range sub.topics {
+		due, ok, err := n.NextDueTime(ctx, topic)
+		if err != nil || !ok {
+			continue
+		}
+		wait = min(wait, max(time.Until(due), subscribeMinWait))
+	}
+	return wait
+}
+
+// ackResult 将流内 Ack/Nack 的处理结果转换为响应消息。
+func ackResult(id string, err error) *pb.SubscribeResponse {
+	st := status.Convert(err)
+	return &pb.SubscribeResponse{Payload: &pb.SubscribeResponse_Result{Result: &pb.AckResult{
+		Id:      id,
+		Success: err == nil,
+		Code:    int32(st.Code()),
+		Message: st.Message(),
+	}}}
+}
@@ -0,0 +1,129 @@
+package queue
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/mocks"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeSubscribeStream 模拟订阅双向流：按顺序返回预置的请求，读完后返回 io.EOF。
+type fakeSubscribeStream struct {
+	grpc.ServerStream
+	reqs chan *pb.SubscribeRequest
+
+	mu   sync.Mutex
+	sent []*pb.SubscribeResponse
+}
+
+func newFakeSubscribeStream(reqs ...*pb.SubscribeRequest) *fakeSubscribeStream {
+	ch := make(chan *pb.SubscribeRequest, len(reqs))
+	for _, r := range reqs {
+		ch <- r
+	}
+	close(ch)
+	return &fakeSubscribeStream{reqs: ch}
+}
+
+func (f *fakeSubscribeStream) Context() context.Context { return context.Background() }
+
+func (f *fakeSubscribeStream) Recv() (*pb.SubscribeRequest, error) {
+	req, ok := <-f.reqs
+	if !ok {
+		return nil, io.EOF
+	}
+	return req, nil
+}
+
+func (f *fakeSubscribeStream) Send(resp *pb.SubscribeResponse) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, resp)
+	return nil
+}
+
+func openRequest(credit int32, topics ...string) *pb.SubscribeRequest {
+	return &pb.SubscribeRequest{Payload: &pb.SubscribeRequest_Open{
+		Open: &pb.SubscribeOpen{Topics: topics, Credit: credit},
+	}}
+}
+
+func TestSubscribeValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := NewService(conf.QueueConfig{}, mocks.NewMockJobStore(ctrl))
+
+	tests := []struct {
+		name string
+		req  *pb.SubscribeRequest
+	}{
+		{
+			name: "First Message Not Open",
+			req:  &pb.SubscribeRequest{Payload: &pb.SubscribeRequest_Credit{Credit: 1}},
+		},
+		{
+			name: "No Topics",
+			req:  openRequest(1),
+		},
+		{
+			name: "Negative Credit",
+			req:  openRequest(-1, "test"),
+		},
+		{
+			name: "Reserved Topic",
+			req:  openRequest(1, "test", "_tiered_reserved"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.Subscribe(newFakeSubscribeStream(tt.req))
+			if got := status.Code(err); got != codes.InvalidArgument {
+				t.Errorf("Subscribe() code = %v, want %v", got, codes.InvalidArgument)
+			}
+		})
+	}
+}
+
+func TestSubscribeDeliverAndAck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mocks.NewMockJobStore(ctrl)
+	svc := NewService(conf.QueueConfig{}, mockStore)
+
+	// credit 为 1：只允许拉取一次且上限为 1，推送后 credit 耗尽不再拉取
+	mockStore.EXPECT().FetchAndHold(gomock.Any(), "test", int64(1)).
+		Return([]*pb.Task{{Id: "task-1", Topic: "test", Lease: "lease-1"}}, nil)
+	mockStore.EXPECT().Ack(gomock.Any(), "test", "task-1", "lease-1").Return(nil)
+
+	stream := newFakeSubscribeStream(
+		openRequest(1, "test"),
+		&pb.SubscribeRequest{Payload: &pb.SubscribeRequest_Ack{Ack: &pb.AckRequest{Topic: "test", Id: "task-1", Lease: "lease-1"}}},
+	)
+	if err := svc.Subscribe(stream); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	var gotTask, gotResult bool
+	for _, resp := range stream.sent {
+		if task := resp.GetTask(); task != nil && task.Id == "task-1" {
+			gotTask = true
+		}
+		if res := resp.GetResult(); res != nil && res.Id == "task-1" && res.Success {
+			gotResult = true
+		}
+	}
+	if !gotTask || !gotResult {
+		t.Errorf("sent = %v, want task and successful ack result", stream.sent)
+	}
+}
@@ -0,0 +1,246 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/robfig/cron/v3"
+)
+
+// cronParser 支持 5 段标准表达式、带秒的 6 段表达式以及 @every/@daily 等描述符。
+var cronParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+const (
+	// cronBatchSize 单次扫描处理的最大周期任务数量。
+	cronBatchSize = 100
+	// maxCatchUp FIRE_ALL 策略单次推进最多补发的触发次数，剩余部分在后续扫描中继续补发。
+	maxCatchUp = 100
+)
+
+// NextRun 计算周期任务在 after 之后（不含）的下一次触发时刻，并应用 start_time/end_time 约束。
+// @Return: 已越过 end_time 不再触发时返回零值；表达式或时区非法时返回 error。
+func NextRun(sched *pb.Schedule, after time.Time) (time.Time, error) {
+	spec, err := parseCron(sched)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return nextRun(spec, sched, after), nil
+}
+
+// parseCron 按周期任务的时区解析 cron 表达式。
+// @Validation: 时区须通过 time_zone 字段指定，不接受表达式内的 TZ=/CRON_TZ= 前缀，避免两处配置冲突。
+func parseCron(sched *pb.Schedule) (cron.Schedule, error) {
+	if strings.HasPrefix(sched.CronExpr, "TZ=") || strings.HasPrefix(sched.CronExpr, "CRON_TZ=") {
+		return nil, fmt.Errorf("use time_zone instead of a TZ prefix in cron_expr")
+	}
+	loc := time.UTC
+	if sched.TimeZone != "" {
+		l, err := time.LoadLocation(sched.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time_zone %q: %w", sched.TimeZone, err)
+		}
+		loc = l
+	}
+	spec, err := cronParser.Parse(sched.CronExpr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron_expr %q: %w", sched.CronExpr, err)
+	}
+	if s, ok := spec.(*cron.SpecSchedule); ok {
+		s.Location = loc
+	}
+	return spec, nil
+}
+
+// nextRun 是 NextRun 的内部实现，复用已解析的表达式。
+func nextRun(spec cron.Schedule, sched *pb.Schedule, after time.Time) time.Time {
+	// cron.Schedule.Next 返回严格晚于参数的时刻，从 start_time 前一秒开始计算使 start_time 本身可以触发
+	if start := time.Unix(sched.StartTime-1, 0); sched.StartTime > 0 && after.Before(start) {
+		after = start
+	}
+	t := spec.Next(after)
+	if t.IsZero() || (sched.EndTime > 0 && t.Unix() > sched.EndTime) {
+		return time.Time{}
+	}
+	return t
+}
+
+// CronScheduler 周期任务调度器，负责将到期的周期任务物化为待执行任务。
+// @Description 与 Watchdog 一样通常只在 Leader 上运行。选主关闭或新旧 Leader 短暂重叠时多个副本可能并发扫描同一周期任务，
+// 由 ScheduleStore.FireSchedule 的 CAS 与任务 ID "<周期任务ID>@<触发时间戳>" 保证每个触发时刻只投递一次。
+// @ThreadSafe: 内部状态受协程生命周期管理，支持跨协程安全启动/停止。
+type CronScheduler struct {
+	store            storage.ScheduleStore // 周期任务存储
+	interval         time.Duration         // 扫描频率
+	misfireThreshold time.Duration         // 触发时刻过去超过该时长视为错过
+	now              func() time.Time      // 当前时间，测试时可替换
+
+	cancel context.CancelFunc // 停止由 Start 启动的循环
+	wg     sync.WaitGroup     // 等待协程关闭
+}
+
+// NewCronScheduler 根据配置初始化周期任务调度器。
+// @Param cfg: 调度器配置，包含扫描间隔与 misfire 阈值。
+// @Param store: 支持周期任务的存储实现。
+func NewCronScheduler(cfg conf.SchedulerConfig, store storage.ScheduleStore) *CronScheduler {
+	interval := time.Duration(cfg.Interval) * time.Second
+	if interval <= 0 {
+		interval = time.Second
+	}
+	threshold := time.Duration(cfg.MisfireThreshold) * time.Second
+	if threshold <= 0 {
+		threshold = time.Minute
+	}
+
+	return &CronScheduler{
+		store:            store,
+		interval:         interval,
+		misfireThreshold: threshold,
+		now:              time.Now,
+	}
+}
+
+// Start 异步启动调度循环。
+func (c *CronScheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.Run(ctx)
+	}()
+}
+
+// Stop 停止调度循环并等待协程安全退出。
+func (c *CronScheduler) Stop() {
+	c.cancel()
+	c.wg.Wait()
+	log.Println("CronScheduler stopped")
+}
+
+// Run 在当前协程内运行调度循环，直到 ctx 取消。
+func (c *CronScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	log.Printf("CronScheduler started. Interval: %v, MisfireThreshold: %v", c.interval, c.misfireThreshold)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.tick(ctx)
+		}
+	}
+}
+
+// tick 扫描并推进所有已到期的周期任务。
+func (c *CronScheduler) tick(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	now := c.currentTime(ctx)
+	schedules, err := c.store.DueSchedules(ctx, now, cronBatchSize)
+	if err != nil {
+		log.Printf("CronScheduler scan error: %v", err)
+		return
+	}
+	for _, sched := range schedules {
+		if err := c.fire(ctx, sched, now); err != nil {
+			log.Printf("CronScheduler fire schedule %s error: %v", sched.Id, err)
+		}
+	}
+}
+
+// currentTime 返回判定周期任务是否到期使用的当前时间。
+// @Description 存储实现 storage.Clock 时以服务端时间为准，使各副本的本地时钟偏差不影响触发时刻；
+// 读取失败或不支持时退化为本地时钟。
+func (c *CronScheduler) currentTime(ctx context.Context) time.Time {
+	if clock, ok := c.store.(storage.Clock); ok {
+		now, err := clock.ServerTime(ctx)
+		if err == nil {
+			return now
+		}
+		log.Printf("CronScheduler read server time error: %v", err)
+	}
+	return c.now()
+}
+
+// fire 计算周期任务本轮应投递的任务并提交给存储层。
+// @Algorithm
+//  1. 首个待触发时刻距今未超过 misfire 阈值：投递 [next_run_time, now] 内的全部触发时刻（通常只有一个）。
+//  2. 超过阈值视为错过，按 misfire_policy 处理：
+//     SKIP 不投递，直接跳到 now 之后的下一个时刻；
+//     FIRE_ONCE（默认）以 next_run_time 为 ID 立即投递一次，再跳到 now 之后的下一个时刻；
+//     FIRE_ALL 逐一补发，单次最多 maxCatchUp 个，余下的在后续扫描中继续补发。
+//
+// @Note: 存储层 CAS 失败说明其他副本已推进该周期任务，直接忽略。
+func (c *CronScheduler) fire(ctx context.Context, sched *pb.Schedule, now time.Time) error {
+	spec, err := parseCron(sched)
+	if err != nil {
+		return err
+	}
+
+	expected := time.Unix(sched.NextRunTime, 0)
+	f := storage.ScheduleFiring{
+		ScheduleID: sched.Id,
+		Topic:      sched.Topic,
+		Expected:   expected,
+	}
+
+	policy := sched.MisfirePolicy
+	if now.Sub(expected) <= c.misfireThreshold {
+		policy = pb.MisfirePolicy_MISFIRE_POLICY_FIRE_ALL
+	}
+
+	switch policy {
+	case pb.MisfirePolicy_MISFIRE_POLICY_SKIP:
+		f.Next = nextRun(spec, sched, now)
+	case pb.MisfirePolicy_MISFIRE_POLICY_FIRE_ALL:
+		t := expected
+		for !t.IsZero() && !t.After(now) && len(f.Tasks) < maxCatchUp {
+			f.Tasks = append(f.Tasks, c.occurrence(sched, t, t))
+			f.LastRun = t
+			t = nextRun(spec, sched, t)
+		}
+		f.Next = t
+	default:
+		f.Tasks = []*pb.Task{c.occurrence(sched, expected, now)}
+		f.LastRun = expected
+		f.Next = nextRun(spec, sched, now)
+	}
+
+	_, err = c.store.FireSchedule(ctx, f)
+	return err
+}
+
+// occurrence 为周期任务的一个触发时刻构造待投递的任务。
+// @Param at: 触发时刻，决定任务 ID。
+// @Param executeAt: 执行时刻，在此基础上叠加 [0, jitter_seconds] 的随机推迟（毫秒粒度）。
+func (c *CronScheduler) occurrence(sched *pb.Schedule, at, executeAt time.Time) *pb.Task {
+	executeTime := executeAt.UnixMilli()
+	if sched.JitterSeconds > 0 {
+		executeTime += rand.Int64N(sched.JitterSeconds*1000 + 1)
+	}
+	return &pb.Task{
+		Id:                fmt.Sprintf("%s@%d", sched.Id, at.Unix()),
+		Topic:             sched.Topic,
+		Payload:           sched.Payload,
+		ExecuteTime:       executeTime / 1000,
+		ExecuteTimeMs:     executeTime,
+		MaxRetries:        sched.MaxRetries,
+		CreatedAt:         c.now().Unix(),
+		VisibilityTimeout: sched.VisibilityTimeout,
+		RetryPolicy:       sched.RetryPolicy,
+	}
+}
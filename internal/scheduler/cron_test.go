@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNextRun(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		sched *pb.Schedule
+		after time.Time
+		want  time.Time
+	}{
+		{
+			name:  "Seconds Field",
+			sched: &pb.Schedule{CronExpr: "*/15 * * * * *"},
+			after: base.Add(20 * time.Second),
+			want:  base.Add(30 * time.Second),
+		},
+		{
+			name:  "Time Zone",
+			sched: &pb.Schedule{CronExpr: "0 9 * * *", TimeZone: "Asia/Shanghai"},
+			after: base,
+			want:  time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "Start Time Inclusive",
+			sched: &pb.Schedule{CronExpr: "@hourly", StartTime: base.Add(3 * time.Hour).Unix()},
+			after: base,
+			want:  base.Add(3 * time.Hour),
+		},
+		{
+			name:  "After End Time",
+			sched: &pb.Schedule{CronExpr: "@hourly", EndTime: base.Add(90 * time.Minute).Unix()},
+			after: base.Add(time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NextRun(tt.sched, tt.after)
+			if err != nil {
+				t.Fatalf("NextRun() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("NextRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NextRun(&pb.Schedule{CronExpr: "CRON_TZ=UTC @daily"}, base); err == nil {
+		t.Error("NextRun() with TZ prefix should fail")
+	}
+}
+
+func TestCronSchedulerFire(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mocks.NewMockScheduleStore(ctrl)
+	c := NewCronScheduler(conf.SchedulerConfig{MisfireThreshold: 60}, mockStore)
+
+	// 每分钟触发一次，调度器在 10:05:30 才扫描到 10:00 的触发
+	first := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	late := first.Add(5*time.Minute + 30*time.Second)
+
+	tests := []struct {
+		name      string
+		policy    pb.MisfirePolicy
+		now       time.Time
+		wantIDs   []string
+		wantNext  time.Time
+		wantLast  time.Time
+		wantAfter time.Time // 任务执行时间下限
+	}{
+		{
+			name:      "On Time",
+			policy:    pb.MisfirePolicy_MISFIRE_POLICY_SKIP,
+			now:       first.Add(2 * time.Second),
+			wantIDs:   []string{"s@1735725600"},
+			wantNext:  first.Add(time.Minute),
+			wantLast:  first,
+			wantAfter: first,
+		},
+		{
+			name:     "Skip",
+			policy:   pb.MisfirePolicy_MISFIRE_POLICY_SKIP,
+			now:      late,
+			wantNext: first.Add(6 * time.Minute),
+		},
+		{
+			name:      "Fire Once",
+			policy:    pb.MisfirePolicy_MISFIRE_POLICY_UNSPECIFIED,
+			now:       late,
+			wantIDs:   []string{"s@1735725600"},
+			wantNext:  first.Add(6 * time.Minute),
+			wantLast:  first,
+			wantAfter: late,
+		},
+		{
+			name:   "Fire All",
+			policy: pb.MisfirePolicy_MISFIRE_POLICY_FIRE_ALL,
+			now:    late,
+			wantIDs: []string{
+				"s@1735725600", "s@1735725660", "s@1735725720",
+				"s@1735725780", "s@1735725840", "s@1735725900",
+			},
+			wantNext:  first.Add(6 * time.Minute),
+			wantLast:  first.Add(5 * time.Minute),
+			wantAfter: first,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched := &pb.Schedule{
+				Id:            "s",
+				Topic:         "t",
+				CronExpr:      "0 * * * * *",
+				MisfirePolicy: tt.policy,
+				NextRunTime:   first.Unix(),
+			}
+			mockStore.EXPECT().FireSchedule(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, f storage.ScheduleFiring) (bool, error) {
+					if !f.Expected.Equal(first) || !f.Next.Equal(tt.wantNext) || !f.LastRun.Equal(tt.wantLast) {
+						t.Errorf("firing = {expected %v, next %v, last %v}, want {%v, %v, %v}",
+							f.Expected, f.Next, f.LastRun, first, tt.wantNext, tt.wantLast)
+					}
+					if len(f.Tasks) != len(tt.wantIDs) {
+						t.Fatalf("got %d tasks, want %d", len(f.Tasks), len(tt.wantIDs))
+					}
+					for i, task := range f.Tasks {
+						if task.Id != tt.wantIDs[i] || task.Topic != "t" {
+							t.Errorf("task[%d] = %s/%s, want %s/t", i, task.Topic, task.Id, tt.wantIDs[i])
+						}
+						if task.ExecuteTime < tt.wantAfter.Unix() {
+							t.Errorf("task[%d] ExecuteTime = %d, want >= %d", i, task.ExecuteTime, tt.wantAfter.Unix())
+						}
+					}
+					return true, nil
+				})
+
+			if err := c.fire(context.Background(), sched, tt.now); err != nil {
+				t.Fatalf("fire() error = %v", err)
+			}
+		})
+	}
+}
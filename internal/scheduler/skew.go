@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+)
+
+// SkewDetector 定期比较本进程的本地时钟与存储服务端时钟，偏差超过阈值时输出告警。
+// @Description 任务的到期与超时判定已统一使用存储服务端时间，本地时钟偏差不会影响调度正确性；
+// 但本地时钟仍用于日志、Enqueue 计算相对延迟等场景，偏差过大通常意味着 NTP 异常，需要运维介入。
+// 与 Watchdog 不同，每个副本都应运行自己的 SkewDetector。
+// @ThreadSafe: Skew 可在任意协程中读取。
+type SkewDetector struct {
+	clock     storage.Clock    // 存储服务端时钟
+	interval  time.Duration    // 检测频率
+	threshold time.Duration    // 告警阈值
+	now       func() time.Time // 本地时钟，测试时可替换
+
+	skew atomic.Int64 // 最近一次测得的偏差 (纳秒)，本地时钟快于服务端时为正
+
+	cancel context.CancelFunc // 停止由 Start 启动的循环
+	wg     sync.WaitGroup     // 等待协程关闭
+}
+
+// NewSkewDetector 根据配置初始化时钟偏差检测器。
+// @Param cfg: 调度器配置，包含检测间隔与告警阈值。
+// @Param clock: 提供服务端时间的存储实现。
+func NewSkewDetector(cfg conf.SchedulerConfig, clock storage.Clock) *SkewDetector {
+	interval := time.Duration(cfg.ClockSkewInterval) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	threshold := time.Duration(cfg.MaxClockSkewMs) * time.Millisecond
+	if threshold <= 0 {
+		threshold = 500 * time.Millisecond
+	}
+
+	return &SkewDetector{
+		clock:     clock,
+		interval:  interval,
+		threshold: threshold,
+		now:       time.Now,
+	}
+}
+
+// Skew 返回最近一次测得的本地时钟相对服务端时钟的偏差，本地时钟偏快时为正，可用于指标上报。
+func (d *SkewDetector) Skew() time.Duration {
+	return time.Duration(d.skew.Load())
+}
+
+// Start 异步启动检测循环。
+func (d *SkewDetector) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.Run(ctx)
+	}()
+}
+
+// Stop 停止检测循环并等待协程安全退出。
+func (d *SkewDetector) Stop() {
+	d.cancel()
+	d.wg.Wait()
+	log.Println("SkewDetector stopped")
+}
+
+// Run 在当前协程内运行检测循环，直到 ctx 取消。启动时立即检测一次。
+func (d *SkewDetector) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	log.Printf("SkewDetector started. Interval: %v, Threshold: %v", d.interval, d.threshold)
+
+	for {
+		d.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check 测量一次时钟偏差。
+// @Algorithm: 以请求发出与收到响应的本地时刻的中点作为服务端读取 TIME 时的本地时刻，
+// 偏差 = 中点 - 服务端时间；测量误差不超过往返耗时的一半，只有超出阈值与该误差之和才告警。
+// @Return: 偏差是否超过阈值；测量失败时返回 false。
+func (d *SkewDetector) check(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	sent := d.now()
+	server, err := d.clock.ServerTime(ctx)
+	if err != nil {
+		log.Printf("SkewDetector read server time error: %v", err)
+		return false
+	}
+	rtt := d.now().Sub(sent)
+	skew := sent.Add(rtt / 2).Sub(server)
+	d.skew.Store(int64(skew))
+
+	if skew.Abs() > d.threshold+rtt/2 {
+		log.Printf("SkewDetector local clock skew %v exceeds threshold %v (rtt %v), check NTP on this host",
+			skew, d.threshold, rtt)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AkikoAkaki/async-task-platform/internal/conf"
+)
+
+// fakeClock 以固定偏移返回服务端时间。
+type fakeClock struct {
+	offset time.Duration
+	err    error
+}
+
+func (f *fakeClock) ServerTime(context.Context) (time.Time, error) {
+	if f.err != nil {
+		return time.Time{}, f.err
+	}
+	return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Add(f.offset), nil
+}
+
+func TestSkewDetectorCheck(t *testing.T) {
+	local := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		clock    *fakeClock
+		wantSkew time.Duration
+		wantWarn bool
+	}{
+		{name: "In Sync", clock: &fakeClock{offset: -100 * time.Millisecond}, wantSkew: 100 * time.Millisecond},
+		{name: "Local Ahead", clock: &fakeClock{offset: -2 * time.Second}, wantSkew: 2 * time.Second, wantWarn: true},
+		{name: "Local Behind", clock: &fakeClock{offset: time.Second}, wantSkew: -time.Second, wantWarn: true},
+		{name: "Server Time Error", clock: &fakeClock{err: errors.New("connection refused")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewSkewDetector(conf.SchedulerConfig{MaxClockSkewMs: 500}, tt.clock)
+			d.now = func() time.Time { return local }
+
+			if got := d.check(context.Background()); got != tt.wantWarn {
+				t.Errorf("check() = %v, want %v", got, tt.wantWarn)
+			}
+			if got := d.Skew(); got != tt.wantSkew {
+				t.Errorf("Skew() = %v, want %v", got, tt.wantSkew)
+			}
+		})
+	}
+}
@@ -17,11 +17,11 @@ import (
 type Watchdog struct {
 	store    storage.JobStore // 任务持久化存储接口
 	interval time.Duration    // 扫描频率
-	timeout  int64            // 可见性超时阈值（秒）
-	maxRetry int32            // 任务最大重试次数
+	timeout  int64            // 默认可见性超时阈值（秒），任务未单独设置时使用
+	maxRetry int32            // 默认最大重试次数，任务未单独设置时使用
 
-	quit chan struct{}  // 退出信号通道
-	wg   sync.WaitGroup // 等待协程关闭
+	cancel context.CancelFunc // 停止由 Start 启动的循环
+	wg     sync.WaitGroup     // 等待协程关闭
 }
 
 // NewWatchdog 根据配置初始化 Watchdog 实例。
@@ -45,48 +45,61 @@ func NewWatchdog(cfg conf.QueueConfig, store storage.JobStore) *Watchdog {
 		interval: time.Duration(cfg.WatchdogInterval) * time.Second,
 		timeout:  int64(visibilityTimeout),
 		maxRetry: int32(maxRetries),
-		quit:     make(chan struct{}),
 	}
 }
 
 // Start 异步启动看门狗循环。
+// @Note: 多副本部署时应改为在选主器的 Leader 回调中调用 Run，避免每个副本都全量扫描执行中任务。
 func (w *Watchdog) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
 	w.wg.Add(1)
 	go func() {
 		defer w.wg.Done()
-		ticker := time.NewTicker(w.interval)
-		defer ticker.Stop()
-
-		log.Printf("Watchdog started. Interval: %v, Timeout: %ds, MaxRetries: %d", w.interval, w.timeout, w.maxRetry)
-
-		for {
-			select {
-			case <-w.quit:
-				return
-			case <-ticker.C:
-				// 定期触发异常任务恢复
-				w.recover()
-			}
-		}
+		w.Run(ctx)
 	}()
 }
 
 // Stop 停止看门狗循环并等待协程安全退出。
 func (w *Watchdog) Stop() {
-	close(w.quit)
+	w.cancel()
 	w.wg.Wait()
 	log.Println("Watchdog stopped")
 }
 
+// Run 在当前协程内运行看门狗循环，直到 ctx 取消。
+func (w *Watchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Printf("Watchdog started. Interval: %v, Timeout: %ds, MaxRetries: %d", w.interval, w.timeout, w.maxRetry)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// 定期触发异常任务恢复
+			w.recover(ctx)
+		}
+	}
+}
+
 // recover 执行任务恢复逻辑。
 // @Algorithm: 调用存储层的 CheckAndMoveExpired，利用 Lua 脚本保证“检测超时+重入队”的原子性。
 // @Note: 恢复过程带有重试次数限制，超过限制的任务将进入死信队列（DLQ）。
-func (w *Watchdog) recover() {
+// 存储层每轮只处理有限数量的超时任务，积压部分在后续轮次中继续回收。
+func (w *Watchdog) recover(ctx context.Context) {
 	// 设置单次恢复任务的 Context 超时，防止因存储层压力过大导致协程堆积。
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	if err := w.store.CheckAndMoveExpired(ctx, w.timeout, w.maxRetry); err != nil {
+	stats, err := w.store.CheckAndMoveExpired(ctx, w.timeout, w.maxRetry)
+	if err != nil {
 		log.Printf("Watchdog recover error: %v", err)
 	}
+	if stats.Requeued > 0 || stats.Dead > 0 || stats.Quarantined > 0 {
+		log.Printf("Watchdog recovered expired tasks: requeued=%d, dead=%d, quarantined=%d",
+			stats.Requeued, stats.Dead, stats.Quarantined)
+	}
 }
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"math"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+)
+
+// Backoff 按任务的 RetryPolicy 计算第 task.RetryCount 次重试前的等待时长，供在 Go 侧处理重试的存储实现使用。
+// @Description 算法与 Redis 实现的 luaBackoff 一致：结果向下取整到秒，超过 max_seconds 时封顶，未设置策略时返回 0（立即重试）。
+// @Param rand: 返回 [0, 1) 之间的随机数，仅 jitter 策略使用。
+func Backoff(task *pb.Task, rand func() float64) time.Duration {
+	p := task.GetRetryPolicy()
+	if p == nil {
+		return 0
+	}
+	base := float64(p.BaseSeconds)
+	limit := float64(p.MaxSeconds)
+	n := float64(task.RetryCount)
+	if n <= 0 {
+		n = 1
+	}
+
+	var delay float64
+	switch p.Strategy {
+	case pb.RetryStrategy_RETRY_STRATEGY_FIXED:
+		delay = base
+	case pb.RetryStrategy_RETRY_STRATEGY_LINEAR:
+		delay = base * n
+	case pb.RetryStrategy_RETRY_STRATEGY_EXPONENTIAL:
+		mult := p.Multiplier
+		if mult <= 1 {
+			mult = 2
+		}
+		delay = base * math.Pow(mult, n-1)
+	case pb.RetryStrategy_RETRY_STRATEGY_FULL_JITTER:
+		ceil := base * math.Pow(2, n-1)
+		if limit > 0 && ceil > limit {
+			ceil = limit
+		}
+		delay = rand() * ceil
+	case pb.RetryStrategy_RETRY_STRATEGY_DECORRELATED_JITTER:
+		prev := math.Max(float64(task.LastBackoff), base)
+		delay = base + rand()*(prev*3-base)
+	case pb.RetryStrategy_RETRY_STRATEGY_SCHEDULE:
+		if len(p.ScheduleSeconds) > 0 {
+			delay = float64(p.ScheduleSeconds[min(int(n), len(p.ScheduleSeconds))-1])
+		}
+	}
+
+	if limit > 0 && delay > limit {
+		delay = limit
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return time.Duration(math.Floor(delay)) * time.Second
+}
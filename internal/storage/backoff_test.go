@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+)
+
+func TestBackoff(t *testing.T) {
+	half := func() float64 { return 0.5 }
+
+	tests := []struct {
+		name   string
+		policy *pb.RetryPolicy
+		retry  int32
+		last   int64
+		want   time.Duration
+	}{
+		{name: "No Policy", retry: 3, want: 0},
+		{
+			name:   "Fixed",
+			policy: &pb.RetryPolicy{Strategy: pb.RetryStrategy_RETRY_STRATEGY_FIXED, BaseSeconds: 10},
+			retry:  3,
+			want:   10 * time.Second,
+		},
+		{
+			name:   "Linear",
+			policy: &pb.RetryPolicy{Strategy: pb.RetryStrategy_RETRY_STRATEGY_LINEAR, BaseSeconds: 10},
+			retry:  3,
+			want:   30 * time.Second,
+		},
+		{
+			name:   "Exponential Capped",
+			policy: &pb.RetryPolicy{Strategy: pb.RetryStrategy_RETRY_STRATEGY_EXPONENTIAL, BaseSeconds: 10, MaxSeconds: 60},
+			retry:  4,
+			want:   60 * time.Second,
+		},
+		{
+			name:   "Full Jitter",
+			policy: &pb.RetryPolicy{Strategy: pb.RetryStrategy_RETRY_STRATEGY_FULL_JITTER, BaseSeconds: 10, MaxSeconds: 600},
+			retry:  3,
+			want:   20 * time.Second,
+		},
+		{
+			name:   "Decorrelated Jitter",
+			policy: &pb.RetryPolicy{Strategy: pb.RetryStrategy_RETRY_STRATEGY_DECORRELATED_JITTER, BaseSeconds: 10, MaxSeconds: 600},
+			retry:  2,
+			last:   20,
+			want:   35 * time.Second,
+		},
+		{
+			name:   "Schedule Repeats Last",
+			policy: &pb.RetryPolicy{Strategy: pb.RetryStrategy_RETRY_STRATEGY_SCHEDULE, ScheduleSeconds: []int64{10, 60}},
+			retry:  5,
+			want:   60 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &pb.Task{RetryPolicy: tt.policy, RetryCount: tt.retry, LastBackoff: tt.last}
+			if got := Backoff(task, half); got != tt.want {
+				t.Errorf("Backoff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
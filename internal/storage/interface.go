@@ -7,36 +7,201 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
 )
 
+// NackOptions 描述一次失败确认的附加信息。
+type NackOptions struct {
+	Reason     string        // 失败原因,记录到任务的 last_error
+	RetryDelay time.Duration // 重试前的等待时长,0 表示按任务的 RetryPolicy 计算(未设置策略则立即重试)
+	MaxRetries int32         // 默认最大重试次数,仅在任务未设置 Task.MaxRetries(<=0)时使用,规则同 CheckAndMoveExpired 的 maxRetries
+}
+
+// ExecuteAt 返回任务的计划执行时刻。
+// @Description 优先使用毫秒精度的 Task.ExecuteTimeMs；升级前写入的任务只有秒级的 Task.ExecuteTime。
+func ExecuteAt(task *pb.Task) time.Time {
+	if task.ExecuteTimeMs > 0 {
+		return time.UnixMilli(task.ExecuteTimeMs)
+	}
+	return time.Unix(task.ExecuteTime, 0)
+}
+
+// RecoverStats 描述一次超时回收的结果。
+type RecoverStats struct {
+	Requeued    int64 // 未超过重试上限、按退避策略重新入队的任务数
+	Dead        int64 // 超过重试上限进入死信队列的任务数
+	Quarantined int64 // 任务数据无法解码、转入隔离区的任务数
+}
+
+// DeadLetterQuery 描述一次死信分页查询。
+type DeadLetterQuery struct {
+	Topic  string    // 业务主题,必填
+	Since  time.Time // 进入死信时间下限(含),零值表示不限
+	Until  time.Time // 进入死信时间上限(含),零值表示不限
+	Offset int64     // 从死信队列第几条开始扫描(按进入时间由新到旧),即上一页返回的 next
+	Limit  int64     // 本页最多返回的条数
+}
+
+// DeadLetterSelector 选取待重投/删除的死信任务。
+// @Description All 为 true 时作用于 Topic 下的全部死信;否则仅作用于 IDs 中处于死信状态的任务,
+// 此时 Topic 非空则只匹配该 Topic。
+type DeadLetterSelector struct {
+	Topic string
+	IDs   []string
+	All   bool
+}
+
+// ScheduleFiring 描述周期任务的一次推进:投递本轮到期的任务,并把下一次触发时间前移。
+type ScheduleFiring struct {
+	ScheduleID string
+	Topic      string     // 周期任务的投递 Topic
+	Expected   time.Time  // 调度器读取到的 next_run_time,与存储中的当前值不一致时放弃本次推进
+	Next       time.Time  // 推进后的 next_run_time,零值表示已越过 end_time 不再触发
+	LastRun    time.Time  // 本次投递对应的触发时刻,零值表示不更新(如 SKIP 策略未投递任何任务)
+	Tasks      []*pb.Task // 本次投递的任务,ID 由周期任务 ID 与触发时刻确定
+}
+
 // JobStore 定义了任务存储层的行为契约。
 // @Description 实现类必须保证操作的原子性(尤其是 GetReady 中的"拉取并隐藏/移除"逻辑),
 // 并负责处理底层驱动的连接池管理及重试机制。
 type JobStore interface {
 	// Add 将任务持久化至存储引擎。
+	// @Description 必须以原子的"ID 不存在才写入"语义执行:任务仍存在,或已完成但仍处于去重窗口内时,不做任何修改。
 	// @Param ctx: 传递上下文链路信息,支持超时取消。
 	// @Param task: 待存储的任务原始数据,调用方需确保 task 字段合法。
-	// @Return: 存储失败时返回包含具体原因的 errno,如存储连接异常或数据校验失败。
+	// @Return: ID 重复时返回 errno.ErrTaskAlreadyExist;存储失败时返回包含具体原因的 error。
 	Add(ctx context.Context, task *pb.Task) error
 
+	// Replace 以新任务覆盖同 ID 的旧任务(等待中或已死信),旧任务不存在时等同于 Add。
+	// @Return: 旧任务正在执行时返回 errno.ErrTaskRunning。
+	Replace(ctx context.Context, task *pb.Task) error
+
 	// FetchAndHold 批量获取并锁定已到执行时间的任务列表。
 	// @Description 该方法通常包含"读取-修改"的复合操作,实现者需确保在并发环境下不重复下发同一任务。
 	// @Param topic: 任务所属的业务主题分类。
 	// @Param limit: 本次拉取任务的最大数量上限,用于防止内存溢出。
-	// @Return: 返回待处理的任务切片;若当前无到期任务,返回空切片及 nil error。
+	// @Return: 返回待处理的任务切片,每个任务的 Lease 字段为本次投递唯一的租约令牌;若当前无到期任务,返回空切片及 nil error。
+	// 无法解码的条目不会返回给调用方;支持 QuarantineStore 的实现会将其转入隔离区,而不是使整批拉取失败。
 	FetchAndHold(ctx context.Context, topic string, limit int64) ([]*pb.Task, error)
 
 	// Remove 根据任务唯一标识从存储中彻底删除任务。
-	// @Description 常用于任务撤回或任务成功处理后的终结操作。
+	// @Description 常用于任务撤回(如订单已支付,取消超时关单任务)。
+	// 等待中与已死信的任务可被删除;执行中的任务已被 Worker 持有,不允许删除。
 	// @Param id: 任务全局唯一 ID。
-	// @Return: 若 ID 不存在,实现者应根据业务需求决定是否返回特定错误。
+	// @Return: ID 不存在时返回 errno.ErrTaskNotFound;任务执行中返回 errno.ErrTaskRunning。
 	Remove(ctx context.Context, id string) error
 
-	Ack(ctx context.Context, id string) error
+	// Ack 确认任务处理成功,将其从所属 Topic 的执行中集合移除。
+	// @Param topic: 任务所属的业务主题,用于定位分区。
+	// @Param id: 任务全局唯一 ID。
+	// @Param lease: FetchAndHold 下发的租约令牌(Task.Lease),用于拒绝已被重新投递的过期确认。
+	// @Return: 任务不在执行中时返回 errno.ErrTaskNotFound;令牌不匹配时返回 errno.ErrLeaseMismatch。
+	Ack(ctx context.Context, topic, id, lease string) error
+
+	// Nack 报告任务处理失败,由存储层基于执行中的任务快照递增重试计数。
+	// @Description 未超过 max_retries 时在 opts.RetryDelay(未指定则按 Task.RetryPolicy 退避)后重新可见,否则进入死信队列。
+	// @Return: 任务不在执行中时返回 errno.ErrTaskNotFound;令牌不匹配时返回 errno.ErrLeaseMismatch。
+	Nack(ctx context.Context, topic, id, lease string, opts NackOptions) error
+
+	// Extend 延长执行中任务的可见性超时(心跳续租),使其至少在 extra 之后才会被判定超时。
+	// @Description 只会推迟、不会提前任务的超时时刻,长耗时任务应在超时前周期性调用。
+	// @Return: 任务不在执行中时返回 errno.ErrTaskNotFound;令牌不匹配时返回 errno.ErrLeaseMismatch。
+	Extend(ctx context.Context, topic, id, lease string, extra time.Duration) error
+
+	// CheckAndMoveExpired 回收可见性超时的执行中任务:未超过重试上限的按 Task.RetryPolicy 退避后重新入队,否则进入死信队列。
+	// @Description 优先使用任务自身的 Task.VisibilityTimeout 与 Task.MaxRetries,
+	// 两者未设置(<=0)时才使用参数传入的全局默认值(来自 QueueConfig)。
+	// 实现应只触及已超时的任务并限制单次调用的处理量,剩余部分留给下一次调用。
+	// @Param visibilityTimeout: 默认可见性超时(秒)。
+	// @Param maxRetries: 默认最大重试次数。
+	// @Return: 本次重新入队与进入死信队列的任务数;部分 Topic 失败时仍返回已完成部分的统计。
+	CheckAndMoveExpired(ctx context.Context, visibilityTimeout int64, maxRetries int32) (RecoverStats, error)
+
+	// ListDead 分页查询死信任务,结果按进入死信时间由新到旧排列。
+	// @Return: next 为下一页的 Offset,0 表示已扫描到队尾。
+	ListDead(ctx context.Context, q DeadLetterQuery) (tasks []*pb.Task, next int64, err error)
+
+	// GetDead 按 ID 查询死信任务。
+	// @Return: ID 不存在或任务不在死信状态时返回 errno.ErrTaskNotFound。
+	GetDead(ctx context.Context, id string) (*pb.Task, error)
+
+	// Redrive 将选中的死信任务重新放回等待队列,在 delay 之后执行。
+	// @Description 重置 retry_count 并清除死信信息,保留 last_error 便于排查。
+	// @Return: 实际重投的任务数;不在死信状态的 ID 被忽略。
+	Redrive(ctx context.Context, sel DeadLetterSelector, delay time.Duration) (int64, error)
+
+	// Purge 永久删除选中的死信任务,并释放其 ID(允许以相同 ID 重新提交)。
+	// @Return: 实际删除的任务数;不在死信状态的 ID 被忽略。
+	Purge(ctx context.Context, sel DeadLetterSelector) (int64, error)
+}
+
+// Notifier 是 JobStore 的可选扩展能力:在任务可能变为可消费时主动通知,替代消费端的盲目轮询。
+// @Description 调用方通过类型断言探测存储实现是否支持该能力,不支持时应退化为定时轮询。
+type Notifier interface {
+	// Notifications 订阅指定 Topic 的"任务写入/重新入队"事件,通道中传递发生变化的 Topic。
+	// @Description 通知仅作为唤醒信号,可能合并或丢失,消费端仍需保留兜底轮询。ctx 取消后通道关闭。
+	Notifications(ctx context.Context, topics []string) (<-chan string, error)
+
+	// NextDueTime 返回 Topic 中最早一个待执行任务的计划执行时间。
+	// @Return: 队列为空时 ok 为 false。
+	NextDueTime(ctx context.Context, topic string) (due time.Time, ok bool, err error)
+}
+
+// Clock 是 JobStore 的可选扩展能力:提供存储服务端的当前时间。
+// @Description 实现应以存储服务端的时钟判定任务是否到期、是否超时,使各进程的本地时钟偏差不影响调度;
+// 调用方可据此检测本地时钟相对服务端的偏差。不支持时调用方只能使用本地时钟。
+type Clock interface {
+	// ServerTime 返回存储服务端的当前时间。
+	ServerTime(ctx context.Context) (time.Time, error)
+}
+
+// QuarantineStore 是 JobStore 的可选扩展能力:查看与清理因数据损坏(Poison Pill)而被隔离的任务条目。
+// @Description 实现应在拉取或回收时原子地将无法解码的条目连同原始字节与解码错误转入隔离区,不阻塞其他任务;
+// 调用方通过类型断言探测存储实现是否支持该能力。
+type QuarantineStore interface {
+	// ListQuarantined 分页查询 Topic 下被隔离的条目,顺序不保证。
+	// @Param cursor: 上一页返回的 next,首页为 0。
+	// @Return: next 为下一页游标,0 表示已扫描完毕;单页条数可能略多于或少于 limit。
+	ListQuarantined(ctx context.Context, topic string, cursor uint64, limit int64) (entries []*pb.QuarantinedEntry, next uint64, err error)
+
+	// QuarantineStats 返回 Topic 当前被隔离的条目数,以及累计被隔离的条目数(删除后不减少,用于监控)。
+	QuarantineStats(ctx context.Context, topic string) (current, total int64, err error)
+
+	// DeleteQuarantined 永久删除 Topic 下被隔离的条目,all 为 true 时删除全部,否则只删除 ids 中的条目。
+	// @Return: 实际删除的条目数。
+	DeleteQuarantined(ctx context.Context, topic string, ids []string, all bool) (int64, error)
+}
+
+// ScheduleStore 是 JobStore 的可选扩展能力:持久化周期任务,并与任务写入在同一存储内原子地推进。
+// @Description 调用方通过类型断言探测存储实现是否支持该能力,不支持时周期任务相关接口不可用。
+type ScheduleStore interface {
+	// CreateSchedule 保存新的周期任务,NextRunTime 须由调用方预先计算。
+	// @Return: ID 已存在时返回 errno.ErrScheduleAlreadyExist。
+	CreateSchedule(ctx context.Context, s *pb.Schedule) error
+
+	// GetSchedule 按 ID 查询周期任务。
+	// @Return: ID 不存在时返回 errno.ErrScheduleNotFound。
+	GetSchedule(ctx context.Context, id string) (*pb.Schedule, error)
+
+	// ListSchedules 查询周期任务,topic 为空表示全部,结果按 ID 排序。
+	ListSchedules(ctx context.Context, topic string) ([]*pb.Schedule, error)
+
+	// DeleteSchedule 删除周期任务,已投递的任务不受影响。
+	// @Return: ID 不存在时返回 errno.ErrScheduleNotFound。
+	DeleteSchedule(ctx context.Context, id string) error
+
+	// SetSchedulePaused 暂停或恢复周期任务,恢复时以 next 作为下一次触发时间(零值表示不再触发)。
+	// @Return: 更新后的周期任务;ID 不存在时返回 errno.ErrScheduleNotFound。
+	SetSchedulePaused(ctx context.Context, id string, paused bool, next time.Time) (*pb.Schedule, error)
 
-	Nack(ctx context.Context, task *pb.Task) error
+	// DueSchedules 返回 next_run_time 不晚于 now 且未暂停的周期任务,最多 limit 个。
+	DueSchedules(ctx context.Context, now time.Time, limit int64) ([]*pb.Schedule, error)
 
-	CheckAndMoveExpired(ctx context.Context, visibilityTimeout int64, maxRetries int32) error
+	// FireSchedule 原子地投递 f.Tasks 并推进周期任务的触发时间。
+	// @Description 多个调度器副本并发推进同一周期任务时,仅 next_run_time 仍等于 f.Expected 的一方生效;
+	// 任务写入遵循 Add 的"ID 不存在才写入"语义,因此每个触发时刻最多投递一次。
+	// @Return: 周期任务已被其他副本推进、暂停或删除时返回 false 且不做任何修改。
+	FireSchedule(ctx context.Context, f ScheduleFiring) (bool, error)
 }
@@ -0,0 +1,209 @@
+package local
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/memory"
+	"google.golang.org/protobuf/proto"
+)
+
+// 帧格式：| length uint32 LE | crc32c(payload) uint32 LE | payload |
+// payload 为一批变更：| count uvarint | change ... |，每条变更以 1 字节的 memory.ChangeKind 开头：
+//   - PutTask:        state byte, due varint, deadline varint, dead_seq varint, lease string, task bytes
+//   - Delete*:        id string
+//   - PutDedup:       id string, expiry varint
+//   - PutSchedule:    schedule bytes
+//
+// string/bytes 均以 uvarint 长度前缀编码，task/schedule 为 protobuf 编码。
+const (
+	frameHeaderSize = 8
+	// maxFrameSize 单帧的长度上限，超出视为损坏，避免按损坏的长度字段分配内存
+	maxFrameSize = 256 << 20
+)
+
+var (
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+	// errTornFrame 帧不完整或校验失败，通常是写入过程中进程崩溃留下的残缺尾部
+	errTornFrame = errors.New("torn or corrupt frame")
+)
+
+// appendFrame 将一批变更编码为一帧追加到 buf。
+func appendFrame(buf []byte, changes []memory.Change) ([]byte, error) {
+	start := len(buf)
+	buf = append(buf, make([]byte, frameHeaderSize)...)
+	buf = binary.AppendUvarint(buf, uint64(len(changes)))
+	for _, c := range changes {
+		var err error
+		if buf, err = appendChange(buf, c); err != nil {
+			return nil, err
+		}
+	}
+
+	payload := buf[start+frameHeaderSize:]
+	if len(payload) > maxFrameSize {
+		return nil, fmt.Errorf("frame of %d bytes exceeds limit", len(payload))
+	}
+	binary.LittleEndian.PutUint32(buf[start:], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(buf[start+4:], crc32.Checksum(payload, crcTable))
+	return buf, nil
+}
+
+func appendChange(buf []byte, c memory.Change) ([]byte, error) {
+	buf = append(buf, byte(c.Kind))
+	switch c.Kind {
+	case memory.ChangePutTask:
+		body, err := proto.Marshal(c.Task)
+		if err != nil {
+			return nil, fmt.Errorf("marshal task failed: %w", err)
+		}
+		buf = append(buf, byte(c.State))
+		buf = binary.AppendVarint(buf, c.Due)
+		buf = binary.AppendVarint(buf, c.Deadline)
+		buf = binary.AppendVarint(buf, c.DeadSeq)
+		buf = appendBytes(buf, []byte(c.Lease))
+		buf = appendBytes(buf, body)
+	case memory.ChangeDeleteTask, memory.ChangeDeleteDedup, memory.ChangeDeleteSchedule:
+		buf = appendBytes(buf, []byte(c.ID))
+	case memory.ChangePutDedup:
+		buf = appendBytes(buf, []byte(c.ID))
+		buf = binary.AppendVarint(buf, c.DedupExpiry)
+	case memory.ChangePutSchedule:
+		body, err := proto.Marshal(c.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("marshal schedule failed: %w", err)
+		}
+		buf = appendBytes(buf, body)
+	default:
+		return nil, fmt.Errorf("unknown change kind %d", c.Kind)
+	}
+	return buf, nil
+}
+
+func appendBytes(buf, b []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// readFrame 从 data 开头读取一帧并解码。
+// @Return: 帧及其后续数据的长度；数据不足一帧或校验失败时返回 errTornFrame。
+func readFrame(data []byte) ([]memory.Change, int, error) {
+	if len(data) < frameHeaderSize {
+		return nil, 0, errTornFrame
+	}
+	size := binary.LittleEndian.Uint32(data)
+	if size > maxFrameSize || int(size) > len(data)-frameHeaderSize {
+		return nil, 0, errTornFrame
+	}
+	payload := data[frameHeaderSize : frameHeaderSize+int(size)]
+	if crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(data[4:]) {
+		return nil, 0, errTornFrame
+	}
+
+	changes, err := decodePayload(payload)
+	if err != nil {
+		// 校验和正确但无法解码，不是残缺写入而是格式错误
+		return nil, 0, err
+	}
+	return changes, frameHeaderSize + int(size), nil
+}
+
+// decoder 按顺序读取 payload 中的字段，遇到第一个错误后的读取均返回零值。
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = errors.New("malformed change record")
+	}
+	d.buf = nil
+}
+
+func (d *decoder) byte() byte {
+	if len(d.buf) < 1 {
+		d.fail()
+		return 0
+	}
+	b := d.buf[0]
+	d.buf = d.buf[1:]
+	return b
+}
+
+func (d *decoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.uvarint()
+	if n > uint64(len(d.buf)) {
+		d.fail()
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func decodePayload(payload []byte) ([]memory.Change, error) {
+	d := &decoder{buf: payload}
+	count := d.uvarint()
+	// 每条变更至少 2 字节，据此限制预分配的容量
+	changes := make([]memory.Change, 0, min(count, uint64(len(payload)/2)))
+	for i := uint64(0); i < count && d.err == nil; i++ {
+		c := memory.Change{Kind: memory.ChangeKind(d.byte())}
+		switch c.Kind {
+		case memory.ChangePutTask:
+			c.State = memory.TaskState(d.byte())
+			c.Due = d.varint()
+			c.Deadline = d.varint()
+			c.DeadSeq = d.varint()
+			c.Lease = string(d.bytes())
+			c.Task = &pb.Task{}
+			if err := proto.Unmarshal(d.bytes(), c.Task); err != nil {
+				return nil, fmt.Errorf("unmarshal task failed: %w", err)
+			}
+		case memory.ChangeDeleteTask, memory.ChangeDeleteDedup, memory.ChangeDeleteSchedule:
+			c.ID = string(d.bytes())
+		case memory.ChangePutDedup:
+			c.ID = string(d.bytes())
+			c.DedupExpiry = d.varint()
+		case memory.ChangePutSchedule:
+			c.Schedule = &pb.Schedule{}
+			if err := proto.Unmarshal(d.bytes(), c.Schedule); err != nil {
+				return nil, fmt.Errorf("unmarshal schedule failed: %w", err)
+			}
+		default:
+			d.fail()
+		}
+		changes = append(changes, c)
+	}
+	if d.err == nil && len(d.buf) != 0 {
+		d.fail()
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return changes, nil
+}
@@ -0,0 +1,18 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.RunConformance(t, func(t *testing.T) storetest.Harness {
+		s, err := Open(t.TempDir())
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		t.Cleanup(func() { _ = s.Close() })
+		return storetest.Harness{Store: s}
+	})
+}
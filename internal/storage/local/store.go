@@ -0,0 +1,270 @@
+// Package local 提供了基于本地磁盘的持久化 JobStore 实现，适用于无法部署 Redis 或数据库的单机/边缘场景。
+//
+// 数据在内存中由 memory.Store 维护（时间索引与全部查询都在内存中完成），每个修改状态的操作
+// 通过 memory.Journal 将受影响对象的完整新状态作为一帧追加到预写日志 (WAL)；启动时加载最新快照并按顺序重放其后的日志分段，
+// 因此等待中、执行中与死信任务在重启后都会恢复（执行中的任务保留原租约与超时时刻，超时后由 Watchdog 照常回收）。
+//
+// 日志分段超过 WithCompactSize 后，后台将当前状态写成快照并删除更早的分段，使日志大小与启动耗时保持有界。
+// 数据目录只能由一个进程打开。
+package local
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/memory"
+)
+
+// snapshotBatch 快照中每帧包含的变更数。
+const snapshotBatch = 1024
+
+// FsyncPolicy 预写日志的落盘策略。
+type FsyncPolicy string
+
+const (
+	// FsyncAlways 每次写入后 fsync，掉电也不丢失已确认的操作，写入吞吐受磁盘 fsync 延迟限制
+	FsyncAlways FsyncPolicy = "always"
+	// FsyncInterval 每隔 WithFsyncInterval 批量 fsync，掉电最多丢失一个周期内的写入（默认）
+	FsyncInterval FsyncPolicy = "interval"
+	// FsyncNever 从不主动 fsync，由操作系统决定落盘时机；进程崩溃不丢失数据，掉电可能丢失
+	FsyncNever FsyncPolicy = "never"
+)
+
+// ParseFsyncPolicy 解析配置中的落盘策略，空串表示默认的 FsyncInterval。
+// @Return: 未知策略返回 error。
+func ParseFsyncPolicy(name string) (FsyncPolicy, error) {
+	switch p := FsyncPolicy(strings.ToLower(name)); p {
+	case "":
+		return FsyncInterval, nil
+	case FsyncAlways, FsyncInterval, FsyncNever:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown fsync policy %q", name)
+	}
+}
+
+// backend 是 memory.Store 对外提供的全部存储能力，Store 通过嵌入它转发调用。
+type backend interface {
+	storage.JobStore
+	storage.Notifier
+	storage.ScheduleStore
+}
+
+// Store 实现了 storage.JobStore 接口，状态保存在内存中并通过预写日志持久化到本地目录。
+// @ThreadSafe: 读写由 memory.Store 的锁串行化，日志按操作发生的顺序写入。
+type Store struct {
+	backend
+	mem *memory.Store
+	wal *wal
+	dir string
+
+	fsync         FsyncPolicy
+	fsyncInterval time.Duration
+	compactSize   int64
+	memOpts       []memory.Option
+
+	compactMu sync.Mutex    // 串行化快照的生成
+	compactCh chan struct{} // 日志分段超过 compactSize 时的压缩信号
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// Option 定义 Store 的可选配置项。
+type Option func(*Store)
+
+// WithFsyncPolicy 设置预写日志的落盘策略，默认 FsyncInterval。
+func WithFsyncPolicy(p FsyncPolicy) Option {
+	return func(s *Store) {
+		s.fsync = p
+	}
+}
+
+// WithFsyncInterval 设置 FsyncInterval 策略下的 fsync 周期。d <= 0 时保持默认值 1 秒。
+func WithFsyncInterval(d time.Duration) Option {
+	return func(s *Store) {
+		if d > 0 {
+			s.fsyncInterval = d
+		}
+	}
+}
+
+// WithCompactSize 设置触发压缩的日志分段大小 (字节)，默认 64 MiB；<=0 表示只在调用 Compact 时压缩。
+func WithCompactSize(n int64) Option {
+	return func(s *Store) {
+		s.compactSize = n
+	}
+}
+
+// WithDedupWindow 设置幂等去重窗口，含义同 memory.WithDedupWindow。
+func WithDedupWindow(d time.Duration) Option {
+	return func(s *Store) {
+		s.memOpts = append(s.memOpts, memory.WithDedupWindow(d))
+	}
+}
+
+// WithVisibilityTimeout 设置默认可见性超时，含义同 memory.WithVisibilityTimeout。
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(s *Store) {
+		s.memOpts = append(s.memOpts, memory.WithVisibilityTimeout(d))
+	}
+}
+
+// WithPriorityAging 设置优先级老化周期，含义同 memory.WithPriorityAging。
+func WithPriorityAging(d time.Duration) Option {
+	return func(s *Store) {
+		s.memOpts = append(s.memOpts, memory.WithPriorityAging(d))
+	}
+}
+
+// 编译期校验：确保 Store 完整实现了 JobStore 定义的所有契约，并支持就绪通知与周期任务存储。
+var (
+	_ storage.JobStore      = (*Store)(nil)
+	_ storage.Notifier      = (*Store)(nil)
+	_ storage.ScheduleStore = (*Store)(nil)
+)
+
+// Open 打开 dir 下的数据（目录不存在时创建），加载最新快照并重放其后的日志分段。
+// @Description 最新分段末尾因崩溃而写了一半的记录会被截断丢弃；其余位置的损坏返回 error，不会以不完整的状态启动。
+// @Return: 使用完毕后须调用 Close 落盘并释放文件。
+func Open(dir string, opts ...Option) (*Store, error) {
+	s := &Store{
+		dir:           dir,
+		fsync:         FsyncInterval,
+		fsyncInterval: time.Second,
+		compactSize:   64 << 20,
+		compactCh:     make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir failed: %w", err)
+	}
+
+	s.wal = &wal{dir: dir, fsync: s.fsync, compactSize: s.compactSize, compact: s.compactCh}
+	s.mem = memory.NewStore(append(s.memOpts, memory.WithJournal(s.wal))...)
+	s.backend = s.mem
+
+	seq, base, err := s.replay()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.wal.open(seq); err != nil {
+		return nil, err
+	}
+	if err := removeBefore(dir, base); err != nil {
+		_ = s.wal.close()
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// replay 加载最新快照并按顺序重放其后的日志分段。
+// @Return: seq 为继续追加写入的分段序号；base 为快照序号，更早的文件已不再需要。
+func (s *Store) replay() (seq, base uint64, err error) {
+	snaps, err := listSeqs(s.dir, snapExt)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(snaps) > 0 {
+		base = snaps[len(snaps)-1]
+		if _, err := replaySegment(filepath.Join(s.dir, segmentName(base, snapExt)), false, s.mem.Apply); err != nil {
+			return 0, 0, fmt.Errorf("load snapshot failed: %w", err)
+		}
+	}
+
+	wals, err := listSeqs(s.dir, walExt)
+	if err != nil {
+		return 0, 0, err
+	}
+	seq = max(base, 1)
+	for _, w := range wals {
+		if w < base {
+			continue
+		}
+		if w != seq {
+			return 0, 0, fmt.Errorf("wal segment %s is missing", segmentName(seq, walExt))
+		}
+		last := w == wals[len(wals)-1]
+		n, err := replaySegment(filepath.Join(s.dir, segmentName(w, walExt)), last, s.mem.Apply)
+		if err != nil {
+			return 0, 0, fmt.Errorf("replay wal failed: %w", err)
+		}
+		if n > 0 {
+			log.Printf("Local store: discarded %d bytes of incomplete wal record in %s", n, segmentName(w, walExt))
+		}
+		if !last {
+			seq++
+		}
+	}
+	return seq, base, nil
+}
+
+// run 在后台按策略周期 fsync，并在日志分段过大时压缩。
+func (s *Store) run() {
+	defer s.wg.Done()
+
+	var tick <-chan time.Time
+	if s.fsync == FsyncInterval {
+		ticker := time.NewTicker(s.fsyncInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-tick:
+			if err := s.wal.sync(); err != nil {
+				log.Printf("Local store fsync failed: %v", err)
+			}
+		case <-s.compactCh:
+			if err := s.Compact(); err != nil {
+				log.Printf("Local store compaction failed: %v", err)
+			}
+		}
+	}
+}
+
+// Compact 将当前状态写成快照并删除更早的日志分段与快照。
+// @Description 只在切换分段的瞬间持有存储锁，快照在锁外写入；写入失败时旧的快照与分段保持不变，重启仍可完整恢复。
+func (s *Store) Compact() error {
+	s.compactMu.Lock()
+	defer s.compactMu.Unlock()
+
+	var seq uint64
+	changes, err := s.mem.Checkpoint(func() error {
+		var err error
+		seq, err = s.wal.rotate()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := writeSnapshot(s.dir, seq, changes); err != nil {
+		return err
+	}
+	return removeBefore(s.dir, seq)
+}
+
+// Close 停止后台任务，落盘并关闭日志，之后的修改操作返回 error。
+func (s *Store) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+		err = s.wal.close()
+	})
+	return err
+}
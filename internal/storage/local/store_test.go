@@ -0,0 +1,253 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+)
+
+// openStore 打开 dir 下的存储，测试结束时关闭。
+func openStore(t *testing.T, dir string, opts ...Option) *Store {
+	t.Helper()
+	s, err := Open(dir, opts...)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func dueTask(id string) *pb.Task {
+	now := time.Now()
+	return &pb.Task{Id: id, Topic: "test", ExecuteTime: now.Unix(), ExecuteTimeMs: now.UnixMilli(), MaxRetries: 1}
+}
+
+// populate 写入等待中 (pending)、执行中 (running)、死信 (dead) 任务与一个周期任务。
+// @Return: running 的租约令牌。
+func populate(t *testing.T, s *Store) string {
+	t.Helper()
+	ctx := context.Background()
+	for _, id := range []string{"running", "dead"} {
+		if err := s.Add(ctx, dueTask(id)); err != nil {
+			t.Fatalf("Add %s failed: %v", id, err)
+		}
+	}
+	held, err := s.FetchAndHold(ctx, "test", 2)
+	if err != nil || len(held) != 2 {
+		t.Fatalf("FetchAndHold = %d tasks, %v; want 2", len(held), err)
+	}
+	var lease string
+	for _, task := range held {
+		if task.Id == "running" {
+			lease = task.Lease
+			continue
+		}
+		if err := s.Nack(ctx, "test", task.Id, task.Lease, storage.NackOptions{Reason: "boom"}); err != nil {
+			t.Fatalf("Nack failed: %v", err)
+		}
+	}
+	if err := s.Add(ctx, dueTask("pending")); err != nil {
+		t.Fatalf("Add pending failed: %v", err)
+	}
+	if err := s.CreateSchedule(ctx, &pb.Schedule{Id: "sched", Topic: "test", NextRunTime: time.Now().Unix()}); err != nil {
+		t.Fatalf("CreateSchedule failed: %v", err)
+	}
+	return lease
+}
+
+// verify 校验 populate 写入的状态在 s 中完整存在。
+func verify(t *testing.T, s *Store, lease string) {
+	t.Helper()
+	ctx := context.Background()
+
+	dead, err := s.GetDead(ctx, "dead")
+	if err != nil {
+		t.Fatalf("GetDead failed: %v", err)
+	}
+	if dead.LastError != "boom" || dead.DeadReason != "retries_exhausted" {
+		t.Errorf("dead task = %+v, want last_error boom and reason retries_exhausted", dead)
+	}
+	if _, err := s.GetSchedule(ctx, "sched"); err != nil {
+		t.Errorf("GetSchedule failed: %v", err)
+	}
+	// 执行中的任务不会被再次拉取，且可用原租约确认
+	held, err := s.FetchAndHold(ctx, "test", 10)
+	if err != nil || len(held) != 1 || held[0].Id != "pending" {
+		t.Fatalf("FetchAndHold = %v, %v; want only pending", held, err)
+	}
+	if err := s.Ack(ctx, "test", "running", lease); err != nil {
+		t.Errorf("Ack running with original lease failed: %v", err)
+	}
+}
+
+func TestStore_Restart(t *testing.T) {
+	policies := []FsyncPolicy{FsyncAlways, FsyncInterval, FsyncNever}
+	for _, policy := range policies {
+		t.Run(string(policy), func(t *testing.T) {
+			dir := t.TempDir()
+			s := openStore(t, dir, WithFsyncPolicy(policy))
+			lease := populate(t, s)
+			if err := s.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if err := s.Add(context.Background(), dueTask("late")); err == nil {
+				t.Error("Add after Close should fail")
+			}
+
+			verify(t, openStore(t, dir, WithFsyncPolicy(policy)), lease)
+		})
+	}
+}
+
+func TestStore_Compact(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := openStore(t, dir, WithCompactSize(0))
+	lease := populate(t, s)
+
+	if err := s.Compact(); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	// 压缩后的写入进入新分段，重放时叠加在快照之上
+	if err := s.Add(ctx, dueTask("after")); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := s.Remove(ctx, "after"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	wals, _ := listSeqs(dir, walExt)
+	snaps, _ := listSeqs(dir, snapExt)
+	if len(wals) != 1 || len(snaps) != 1 || wals[0] != snaps[0] {
+		t.Fatalf("files after compaction: wal %v, snap %v; want one segment starting at the snapshot", wals, snaps)
+	}
+
+	reopened := openStore(t, dir)
+	if _, err := reopened.GetDead(ctx, "after"); !errors.Is(err, errno.ErrTaskNotFound) {
+		t.Errorf("removed task should stay removed, GetDead error = %v", err)
+	}
+	verify(t, reopened, lease)
+}
+
+func TestStore_CompactOnSize(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := openStore(t, dir, WithCompactSize(1))
+
+	if err := s.Add(ctx, dueTask("a")); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if snaps, _ := listSeqs(dir, snapExt); len(snaps) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("background compaction did not write a snapshot")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStore_TornTail(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := openStore(t, dir)
+	if err := s.Add(ctx, dueTask("a")); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// 模拟写入一半时崩溃：在分段末尾追加一个不完整的帧
+	path := filepath.Join(dir, segmentName(1, walExt))
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat wal failed: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("open wal failed: %v", err)
+	}
+	if _, err := f.Write([]byte{0xff, 0x00, 0x00, 0x00, 0x01}); err != nil {
+		t.Fatalf("write wal failed: %v", err)
+	}
+	_ = f.Close()
+
+	reopened := openStore(t, dir)
+	if after, _ := os.Stat(path); after.Size() != info.Size() {
+		t.Errorf("wal size after recovery = %d, want truncated to %d", after.Size(), info.Size())
+	}
+	// 截断后继续追加，再次重启两条记录都在
+	if err := reopened.Add(ctx, dueTask("b")); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := reopened.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	held, err := openStore(t, dir).FetchAndHold(ctx, "test", 10)
+	if err != nil || len(held) != 2 {
+		t.Errorf("FetchAndHold = %d tasks, %v; want 2", len(held), err)
+	}
+}
+
+func TestStore_CorruptSegment(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s := openStore(t, dir, WithCompactSize(0))
+	if err := s.Add(ctx, dueTask("a")); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := s.Compact(); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// 快照不是最新分段，其中的损坏不能按残缺尾部处理
+	snaps, _ := listSeqs(dir, snapExt)
+	path := filepath.Join(dir, segmentName(snaps[0], snapExt))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read snapshot failed: %v", err)
+	}
+	data[len(data)-1] ^= 0xff
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write snapshot failed: %v", err)
+	}
+	if _, err := Open(dir); err == nil {
+		t.Error("Open() with a corrupt snapshot should fail")
+	}
+}
+
+func TestParseFsyncPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    FsyncPolicy
+		wantErr bool
+	}{
+		{"", FsyncInterval, false},
+		{"always", FsyncAlways, false},
+		{"Interval", FsyncInterval, false},
+		{"never", FsyncNever, false},
+		{"sometimes", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFsyncPolicy(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFsyncPolicy(%q) = %q, %v; want %q, wantErr %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/memory"
+)
+
+// 数据目录中的文件：
+//   - <seq>.wal : 预写日志分段，按 seq 递增；只有最新的分段被追加写入
+//   - <seq>.snap: 快照，内容为分段 seq 开始之前的完整状态，重放时从它开始并跳过更早的分段
+//   - *.tmp     : 写入中的快照，完成后原子重命名，启动时删除残留
+const (
+	walExt  = ".wal"
+	snapExt = ".snap"
+	tmpExt  = ".tmp"
+)
+
+var errClosed = errors.New("local store is closed")
+
+// segmentName 返回序号 seq 对应的文件名，固定宽度保证按名称排序即按序号排序。
+func segmentName(seq uint64, ext string) string {
+	return fmt.Sprintf("%020d%s", seq, ext)
+}
+
+// listSeqs 返回目录中扩展名为 ext 的文件序号，升序排列。
+func listSeqs(dir, ext string) ([]uint64, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+	if err != nil {
+		return nil, err
+	}
+	seqs := make([]uint64, 0, len(names))
+	for _, name := range names {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(name), ext), 10, 64)
+		if err != nil {
+			continue // 忽略不是由本包创建的文件
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+// wal 是当前被追加写入的预写日志分段，实现 memory.Journal。
+// @ThreadSafe: Record 与 rotate 由 memory.Store 的锁串行化，mu 额外保护与后台 sync 的并发。
+type wal struct {
+	mu    sync.Mutex
+	dir   string
+	file  *os.File
+	seq   uint64
+	size  int64
+	dirty bool  // 有尚未 fsync 的写入
+	err   error // 首个写入错误，之后的写入一律失败，避免日志出现空洞
+	buf   []byte
+
+	fsync       FsyncPolicy
+	compactSize int64
+	compact     chan<- struct{} // 分段超过 compactSize 时通知后台压缩
+}
+
+// open 以追加方式打开分段 seq 作为当前分段，文件不存在时创建。
+func (w *wal) open(seq uint64) error {
+	f, err := os.OpenFile(filepath.Join(w.dir, segmentName(seq, walExt)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open wal segment failed: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("stat wal segment failed: %w", err)
+	}
+	if err := syncDir(w.dir); err != nil {
+		_ = f.Close()
+		return err
+	}
+	w.file, w.seq, w.size = f, seq, info.Size()
+	return nil
+}
+
+// Record 实现 memory.Journal，将一次操作的全部变更编码为一帧写入当前分段。
+// @Description FsyncAlways 时返回前 fsync；否则数据写入操作系统缓存即返回，进程崩溃不丢失，掉电可能丢失最近的写入。
+func (w *wal) Record(changes []memory.Change) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return w.err
+	}
+	frame, err := appendFrame(w.buf[:0], changes)
+	if err != nil {
+		w.err = err
+		return err
+	}
+	w.buf = frame[:0]
+	if _, err := w.file.Write(frame); err != nil {
+		w.err = fmt.Errorf("write wal failed: %w", err)
+		return w.err
+	}
+	w.size += int64(len(frame))
+	w.dirty = true
+	if w.fsync == FsyncAlways {
+		if err := w.syncLocked(); err != nil {
+			return err
+		}
+	}
+
+	if w.compactSize > 0 && w.size >= w.compactSize {
+		select {
+		case w.compact <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// sync 将尚未落盘的写入 fsync 到磁盘。
+func (w *wal) sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	return w.syncLocked()
+}
+
+func (w *wal) syncLocked() error {
+	if !w.dirty {
+		return nil
+	}
+	if err := w.file.Sync(); err != nil {
+		w.err = fmt.Errorf("fsync wal failed: %w", err)
+		return w.err
+	}
+	w.dirty = false
+	return nil
+}
+
+// rotate 封存当前分段并切换到下一个分段。
+// @Return: 新分段的序号。调用方须持有 memory.Store 的锁（在 Checkpoint 中调用）。
+func (w *wal) rotate() (uint64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return 0, w.err
+	}
+	if err := w.syncLocked(); err != nil {
+		return 0, err
+	}
+	old := w.file
+	if err := w.open(w.seq + 1); err != nil {
+		return 0, err
+	}
+	if err := old.Close(); err != nil {
+		return 0, fmt.Errorf("close wal segment failed: %w", err)
+	}
+	return w.seq, nil
+}
+
+// close 落盘并关闭当前分段，之后的写入返回 errClosed。
+func (w *wal) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var err error
+	if w.err == nil && w.dirty {
+		err = w.file.Sync()
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	w.err = errClosed
+	return err
+}
+
+// replaySegment 读取分段文件中的全部帧并依次交给 apply。
+// @Param tolerateTail: 为 true 时（最新的分段）将末尾的残缺帧视为崩溃时未写完的数据，截断文件后正常返回；
+// 否则任何残缺帧都视为数据损坏。
+// @Return: 截断掉的字节数。
+func replaySegment(path string, tolerateTail bool, apply func([]memory.Change)) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("read %s failed: %w", filepath.Base(path), err)
+	}
+
+	offset := 0
+	for offset < len(data) {
+		changes, n, err := readFrame(data[offset:])
+		if errors.Is(err, errTornFrame) && tolerateTail {
+			if err := os.Truncate(path, int64(offset)); err != nil {
+				return 0, fmt.Errorf("truncate %s failed: %w", filepath.Base(path), err)
+			}
+			return int64(len(data) - offset), nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%s at offset %d: %w", filepath.Base(path), offset, err)
+		}
+		apply(changes)
+		offset += n
+	}
+	return 0, nil
+}
+
+// writeSnapshot 将完整状态写入序号 seq 的快照：先写临时文件并 fsync，再原子重命名。
+func writeSnapshot(dir string, seq uint64, changes []memory.Change) error {
+	path := filepath.Join(dir, segmentName(seq, snapExt))
+	f, err := os.Create(path + tmpExt)
+	if err != nil {
+		return fmt.Errorf("create snapshot failed: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(path + tmpExt)
+	}()
+
+	var buf []byte
+	for len(changes) > 0 {
+		n := min(len(changes), snapshotBatch)
+		if buf, err = appendFrame(buf[:0], changes[:n]); err != nil {
+			return err
+		}
+		if _, err := f.Write(buf); err != nil {
+			return fmt.Errorf("write snapshot failed: %w", err)
+		}
+		changes = changes[n:]
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("fsync snapshot failed: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close snapshot failed: %w", err)
+	}
+	if err := os.Rename(path+tmpExt, path); err != nil {
+		return fmt.Errorf("rename snapshot failed: %w", err)
+	}
+	return syncDir(dir)
+}
+
+// removeBefore 删除序号小于 seq 的分段与快照，以及残留的临时文件。
+func removeBefore(dir string, seq uint64) error {
+	for _, ext := range []string{walExt, snapExt} {
+		seqs, err := listSeqs(dir, ext)
+		if err != nil {
+			return err
+		}
+		for _, s := range seqs {
+			if s >= seq {
+				break
+			}
+			if err := os.Remove(filepath.Join(dir, segmentName(s, ext))); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("remove %s failed: %w", segmentName(s, ext), err)
+			}
+		}
+	}
+	tmps, err := filepath.Glob(filepath.Join(dir, "*"+tmpExt))
+	if err != nil {
+		return err
+	}
+	for _, name := range tmps {
+		_ = os.Remove(name)
+	}
+	return nil
+}
+
+// syncDir fsync 目录，使文件的创建、重命名与删除持久化。
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("open data dir failed: %w", err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("fsync data dir failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.RunConformance(t, func(t *testing.T) storetest.Harness {
+		return storetest.Harness{Store: NewStore()}
+	})
+}
@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"google.golang.org/protobuf/proto"
+)
+
+// ListDead 分页扫描 Topic 的死信队列，按进入死信时间由新到旧排列。
+// @Description Offset 为由新到旧的下标，语义与 Redis 实现的 LRANGE 下标一致；按 dead_at 过滤直到凑满 q.Limit 条。
+func (s *Store) ListDead(ctx context.Context, q storage.DeadLetterQuery) ([]*pb.Task, int64, error) {
+	if q.Topic == "" {
+		return nil, 0, fmt.Errorf("topic is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tq, ok := s.topics[q.Topic]
+	if !ok {
+		return []*pb.Task{}, 0, nil
+	}
+	n := int64(len(tq.dead))
+	tasks := make([]*pb.Task, 0, q.Limit)
+	offset := max(q.Offset, 0)
+	for ; offset < n && int64(len(tasks)) < q.Limit; offset++ {
+		task := tq.dead[n-1-offset].task
+		if !q.Since.IsZero() && task.DeadAt < q.Since.Unix() {
+			continue
+		}
+		if !q.Until.IsZero() && task.DeadAt > q.Until.Unix() {
+			continue
+		}
+		tasks = append(tasks, proto.Clone(task).(*pb.Task))
+	}
+
+	if offset >= n {
+		return tasks, 0, nil
+	}
+	return tasks, offset, nil
+}
+
+// GetDead 按 ID 查询死信任务。
+// @Return: ID 不存在或不在死信状态时返回 errno.ErrTaskNotFound。
+func (s *Store) GetDead(ctx context.Context, id string) (*pb.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[id]
+	if !ok || e.state != stateDead {
+		return nil, errno.ErrTaskNotFound
+	}
+	return proto.Clone(e.task).(*pb.Task), nil
+}
+
+// Redrive 将选中的死信任务重新放回等待队列，在 delay 之后执行，逻辑同 luaRedrive。
+// @Description 重置 retry_count/last_backoff 并清除死信信息，保留 last_error。
+func (s *Store) Redrive(ctx context.Context, sel storage.DeadLetterSelector, delay time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	selected, err := s.selectDead(sel)
+	if err != nil {
+		return 0, err
+	}
+
+	executeAt := s.now().Add(delay)
+	topics := make(map[string]struct{})
+	for _, e := range selected {
+		s.queue(e.task.Topic).removeDead(e)
+		e.task.RetryCount = 0
+		e.task.LastBackoff = 0
+		e.task.DeadReason = ""
+		e.task.DeadAt = 0
+		e.task.ExecuteTime = executeAt.Unix()
+		e.task.ExecuteTimeMs = executeAt.UnixMilli()
+		s.enqueue(e, executeAt.UnixMilli())
+		s.logTask(e)
+		topics[e.task.Topic] = struct{}{}
+	}
+	for topic := range topics {
+		s.notify(topic)
+	}
+	return int64(len(selected)), s.commit()
+}
+
+// Purge 永久删除选中的死信任务，并清除其去重标记（允许以相同 ID 重新提交）。选取规则同 Redrive。
+func (s *Store) Purge(ctx context.Context, sel storage.DeadLetterSelector) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	selected, err := s.selectDead(sel)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range selected {
+		s.detach(e)
+		delete(s.dedup, e.task.Id)
+		s.logDelete(ChangeDeleteTask, e.task.Id)
+		s.logDelete(ChangeDeleteDedup, e.task.Id)
+	}
+	return int64(len(selected)), s.commit()
+}
+
+// selectDead 按 DeadLetterSelector 选取死信任务，不在死信状态或不属于指定 Topic 的 ID 被忽略。调用方须持有锁。
+func (s *Store) selectDead(sel storage.DeadLetterSelector) ([]*entry, error) {
+	if sel.All {
+		if sel.Topic == "" {
+			return nil, fmt.Errorf("topic is required")
+		}
+		q, ok := s.topics[sel.Topic]
+		if !ok {
+			return nil, nil
+		}
+		return append([]*entry(nil), q.dead...), nil
+	}
+
+	var selected []*entry
+	for _, id := range sel.IDs {
+		e, ok := s.entries[id]
+		if !ok || e.state != stateDead || (sel.Topic != "" && e.task.Topic != sel.Topic) {
+			continue
+		}
+		selected = append(selected, e)
+	}
+	return selected, nil
+}
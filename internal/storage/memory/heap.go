@@ -0,0 +1,45 @@
+package memory
+
+// taskHeap 是按 entry.key 升序排列的最小堆，key 相同时按任务 ID 排序（与 Redis ZSet 相同分数按成员排序一致）。
+// @Description 与 container/heap 配合使用。每个任务同一时刻最多位于一个堆中（pending、ready 或 deadlines），
+// 因此 entry.index 可以直接记录其在所在堆中的下标，支持 O(log n) 的任意位置删除与调整。
+type taskHeap []*entry
+
+func (h taskHeap) Len() int { return len(h) }
+
+func (h taskHeap) Less(i, j int) bool {
+	if h[i].key != h[j].key {
+		return h[i].key < h[j].key
+	}
+	return h[i].task.Id < h[j].task.Id
+}
+
+func (h taskHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *taskHeap) Push(x any) {
+	e := x.(*entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *taskHeap) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*h = old[:n-1]
+	return e
+}
+
+// peek 返回堆顶元素，堆为空时返回 nil。
+func (h taskHeap) peek() *entry {
+	if len(h) == 0 {
+		return nil
+	}
+	return h[0]
+}
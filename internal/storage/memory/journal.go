@@ -0,0 +1,213 @@
+package memory
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// TaskState 任务状态，用于 Change 记录。
+type TaskState uint8
+
+const (
+	TaskPending TaskState = TaskState(statePending) // 等待执行
+	TaskRunning TaskState = TaskState(stateRunning) // 执行中
+	TaskDead    TaskState = TaskState(stateDead)    // 已进入死信队列
+)
+
+// ChangeKind 状态变更的类型。
+type ChangeKind uint8
+
+const (
+	ChangePutTask        ChangeKind = iota + 1 // 写入或覆盖任务的完整状态
+	ChangeDeleteTask                           // 删除任务
+	ChangePutDedup                             // 写入或刷新去重标记
+	ChangeDeleteDedup                          // 删除去重标记
+	ChangePutSchedule                          // 写入或覆盖周期任务
+	ChangeDeleteSchedule                       // 删除周期任务
+)
+
+// Change 描述一条状态变更。每条变更都携带受影响对象的完整新状态，按顺序重放即可重建存储。
+type Change struct {
+	Kind ChangeKind
+	// ID 删除类变更与 ChangePutDedup 的对象 ID
+	ID string
+	// Task ChangePutTask 的任务数据
+	Task *pb.Task
+	// State ChangePutTask 的任务状态
+	State TaskState
+	// Due 等待中任务重新可见的毫秒时间戳
+	Due int64
+	// Deadline 执行中任务的超时时刻 (毫秒)
+	Deadline int64
+	// Lease 执行中任务的租约令牌
+	Lease string
+	// DeadSeq 死信任务进入死信队列的序号，越大越新
+	DeadSeq int64
+	// DedupExpiry ChangePutDedup 的过期时刻 (毫秒)
+	DedupExpiry int64
+	// Schedule ChangePutSchedule 的周期任务数据
+	Schedule *pb.Schedule
+}
+
+// Journal 接收 Store 的状态变更，用于持久化（如 local 存储的预写日志）。
+type Journal interface {
+	// Record 在每个修改状态的操作结束时调用一次，changes 为该操作产生的全部变更。
+	// @Description 调用时持有 Store 的锁，各次调用的顺序即变更发生的顺序。changes 中的数据在返回后会被继续修改，实现不得持有引用。
+	// 返回错误时操作的结果已在内存中生效，错误原样返回给操作的调用方。
+	Record(changes []Change) error
+}
+
+// WithJournal 设置状态变更的接收方。
+func WithJournal(j Journal) Option {
+	return func(s *Store) {
+		s.journal = j
+	}
+}
+
+// logTask 记录任务的当前完整状态。调用方须持有锁。
+func (s *Store) logTask(e *entry) {
+	if s.journal == nil {
+		return
+	}
+	c := Change{Kind: ChangePutTask, Task: e.task, State: TaskState(e.state), DeadSeq: e.deadSeq}
+	switch e.state {
+	case statePending:
+		c.Due = e.due
+	case stateRunning:
+		c.Deadline = e.key
+		c.Lease = e.lease
+	}
+	s.changes = append(s.changes, c)
+}
+
+// logDelete 记录删除类变更。调用方须持有锁。
+func (s *Store) logDelete(kind ChangeKind, id string) {
+	if s.journal == nil {
+		return
+	}
+	s.changes = append(s.changes, Change{Kind: kind, ID: id})
+}
+
+// logDedup 记录去重标记的写入。调用方须持有锁。
+func (s *Store) logDedup(id string, expiryMs int64) {
+	if s.journal == nil {
+		return
+	}
+	s.changes = append(s.changes, Change{Kind: ChangePutDedup, ID: id, DedupExpiry: expiryMs})
+}
+
+// logSchedule 记录周期任务的写入。调用方须持有锁。
+func (s *Store) logSchedule(sched *pb.Schedule) {
+	if s.journal == nil {
+		return
+	}
+	s.changes = append(s.changes, Change{Kind: ChangePutSchedule, Schedule: sched})
+}
+
+// commit 将本次操作累积的变更交给 Journal。调用方须持有锁。
+func (s *Store) commit() error {
+	if s.journal == nil || len(s.changes) == 0 {
+		return nil
+	}
+	err := s.journal.Record(s.changes)
+	clear(s.changes)
+	s.changes = s.changes[:0]
+	if err != nil {
+		return fmt.Errorf("journal failed: %w", err)
+	}
+	return nil
+}
+
+// Apply 按顺序重放状态变更，用于启动时从持久化数据恢复。变更不会再交给 Journal，也不会发送就绪通知。
+// @Warning: 只应在存储投入使用之前调用；变更中的任务与周期任务由 Store 接管，调用方不得再修改。
+func (s *Store) Apply(changes []Change) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range changes {
+		switch c.Kind {
+		case ChangePutTask:
+			s.restoreTask(c)
+		case ChangeDeleteTask:
+			if e, ok := s.entries[c.ID]; ok {
+				s.detach(e)
+			}
+		case ChangePutDedup:
+			s.dedup[c.ID] = time.UnixMilli(c.DedupExpiry)
+		case ChangeDeleteDedup:
+			delete(s.dedup, c.ID)
+		case ChangePutSchedule:
+			s.schedules[c.Schedule.Id] = c.Schedule
+		case ChangeDeleteSchedule:
+			delete(s.schedules, c.ID)
+		}
+	}
+}
+
+// restoreTask 按记录的状态放回任务，覆盖同 ID 的旧任务。调用方须持有锁。
+func (s *Store) restoreTask(c Change) {
+	if old, ok := s.entries[c.Task.Id]; ok {
+		s.detach(old)
+	}
+	e := &entry{task: c.Task, index: -1}
+	s.entries[c.Task.Id] = e
+	q := s.queue(c.Task.Topic)
+
+	switch c.State {
+	case TaskRunning:
+		e.state = stateRunning
+		e.lease = c.Lease
+		e.key = c.Deadline
+		heap.Push(&q.deadlines, e)
+	case TaskDead:
+		e.state = stateDead
+		e.deadSeq = c.DeadSeq
+		s.deadSeq = max(s.deadSeq, c.DeadSeq)
+		// 死信队列按序号有序，重放时序号通常递增，直接追加
+		i := sort.Search(len(q.dead), func(i int) bool { return q.dead[i].deadSeq > e.deadSeq })
+		q.dead = append(q.dead, nil)
+		copy(q.dead[i+1:], q.dead[i:])
+		q.dead[i] = e
+	default:
+		s.enqueue(e, c.Due)
+	}
+}
+
+// Checkpoint 返回当前全部状态的副本（以 Put 类变更表示），重放这些变更即可得到相同的存储。
+// @Description fn 在持有锁时调用，此时没有任何并发修改，调用方可借此切换预写日志的分段，
+// 使返回的状态与新分段的起点精确对齐。fn 返回错误时 Checkpoint 返回该错误。
+func (s *Store) Checkpoint(fn func() error) ([]Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fn != nil {
+		if err := fn(); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := make([]Change, 0, len(s.entries)+len(s.dedup)+len(s.schedules))
+	for _, e := range s.entries {
+		c := Change{Kind: ChangePutTask, Task: proto.Clone(e.task).(*pb.Task), State: TaskState(e.state), DeadSeq: e.deadSeq}
+		switch e.state {
+		case statePending:
+			c.Due = e.due
+		case stateRunning:
+			c.Deadline = e.key
+			c.Lease = e.lease
+		}
+		changes = append(changes, c)
+	}
+	for id, expiry := range s.dedup {
+		changes = append(changes, Change{Kind: ChangePutDedup, ID: id, DedupExpiry: expiry.UnixMilli()})
+	}
+	for _, sched := range s.schedules {
+		changes = append(changes, Change{Kind: ChangePutSchedule, Schedule: proto.Clone(sched).(*pb.Schedule)})
+	}
+	return changes, nil
+}
@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+// recordJournal 保存收到的变更副本，err 非 nil 时拒绝写入。
+type recordJournal struct {
+	changes []Change
+	err     error
+}
+
+func (j *recordJournal) Record(changes []Change) error {
+	if j.err != nil {
+		return j.err
+	}
+	for _, c := range changes {
+		if c.Task != nil {
+			c.Task = proto.Clone(c.Task).(*pb.Task)
+		}
+		if c.Schedule != nil {
+			c.Schedule = proto.Clone(c.Schedule).(*pb.Schedule)
+		}
+		j.changes = append(j.changes, c)
+	}
+	return nil
+}
+
+// dump 以规范化的文本描述存储的全部状态，用于比较两个 Store 是否一致。
+func dump(t *testing.T, s *Store) []string {
+	t.Helper()
+	changes, err := s.Checkpoint(nil)
+	if err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	out := make([]string, 0, len(changes))
+	for _, c := range changes {
+		switch c.Kind {
+		case ChangePutTask:
+			out = append(out, fmt.Sprintf("task %d %d %d %s %d %s", c.State, c.Due, c.Deadline, c.Lease, c.DeadSeq, prototext.Format(c.Task)))
+		case ChangePutDedup:
+			out = append(out, fmt.Sprintf("dedup %s %d", c.ID, c.DedupExpiry))
+		case ChangePutSchedule:
+			out = append(out, fmt.Sprintf("schedule %s", prototext.Format(c.Schedule)))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestStore_JournalReplay(t *testing.T) {
+	ctx := context.Background()
+	j := &recordJournal{}
+	s, clock := newTestStore(WithJournal(j), WithDedupWindow(time.Hour))
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if err := s.Add(ctx, newTask(clock, id, 0)); err != nil {
+			t.Fatalf("Add %s failed: %v", id, err)
+		}
+	}
+	dying := newTask(clock, "e", 0)
+	dying.MaxRetries = 1
+	if err := s.Add(ctx, dying); err != nil {
+		t.Fatalf("Add e failed: %v", err)
+	}
+	if err := s.Remove(ctx, "d"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	clock.Advance(time.Second)
+
+	held, err := s.FetchAndHold(ctx, "test", 10)
+	if err != nil || len(held) != 4 {
+		t.Fatalf("FetchAndHold = %d tasks, %v; want 4", len(held), err)
+	}
+	leases := make(map[string]string)
+	for _, task := range held {
+		leases[task.Id] = task.Lease
+	}
+	if err := s.Ack(ctx, "test", "a", leases["a"]); err != nil {
+		t.Fatalf("Ack failed: %v", err)
+	}
+	if err := s.Nack(ctx, "test", "b", leases["b"], storage.NackOptions{Reason: "boom", RetryDelay: time.Minute}); err != nil {
+		t.Fatalf("Nack failed: %v", err)
+	}
+	if err := s.Nack(ctx, "test", "e", leases["e"], storage.NackOptions{Reason: "fatal"}); err != nil {
+		t.Fatalf("Nack failed: %v", err)
+	}
+	if err := s.Extend(ctx, "test", "c", leases["c"], 5*time.Minute); err != nil {
+		t.Fatalf("Extend failed: %v", err)
+	}
+	if err := s.CreateSchedule(ctx, &pb.Schedule{Id: "s1", Topic: "test", CronExpr: "* * * * *", NextRunTime: clock.Now().Unix()}); err != nil {
+		t.Fatalf("CreateSchedule failed: %v", err)
+	}
+	if _, err := s.SetSchedulePaused(ctx, "s1", true, time.Time{}); err != nil {
+		t.Fatalf("SetSchedulePaused failed: %v", err)
+	}
+
+	want := dump(t, s)
+
+	// 重放逐条变更与重放快照都应得到相同的状态
+	replayed := NewStore()
+	replayed.Apply(j.changes)
+	if got := dump(t, replayed); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("replayed journal state:\n%v\nwant:\n%v", got, want)
+	}
+	snapshot, err := s.Checkpoint(nil)
+	if err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	restored := NewStore()
+	restored.Apply(snapshot)
+	if got := dump(t, restored); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("restored snapshot state:\n%v\nwant:\n%v", got, want)
+	}
+
+	// 恢复后执行中的任务仍可用原租约确认，死信仍可查询
+	restored.now = clock.Now
+	if err := restored.Ack(ctx, "test", "c", leases["c"]); err != nil {
+		t.Errorf("Ack after restore failed: %v", err)
+	}
+	if _, err := restored.GetDead(ctx, "e"); err != nil {
+		t.Errorf("GetDead after restore failed: %v", err)
+	}
+}
+
+func TestStore_JournalError(t *testing.T) {
+	ctx := context.Background()
+	j := &recordJournal{err: errors.New("disk full")}
+	s, clock := newTestStore(WithJournal(j))
+
+	if err := s.Add(ctx, newTask(clock, "a", 0)); err == nil || !errors.Is(err, j.err) {
+		t.Errorf("Add error = %v, want wrapped %v", err, j.err)
+	}
+	// 只读操作不经过 Journal
+	if _, err := s.ListSchedules(ctx, ""); err != nil {
+		t.Errorf("ListSchedules failed: %v", err)
+	}
+}
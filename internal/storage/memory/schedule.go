@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"google.golang.org/protobuf/proto"
+)
+
+// CreateSchedule 保存新的周期任务。
+// @Return: ID 已存在时返回 errno.ErrScheduleAlreadyExist。
+func (s *Store) CreateSchedule(ctx context.Context, sched *pb.Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.schedules[sched.Id]; ok {
+		return errno.ErrScheduleAlreadyExist
+	}
+	s.schedules[sched.Id] = proto.Clone(sched).(*pb.Schedule)
+	s.logSchedule(s.schedules[sched.Id])
+	return s.commit()
+}
+
+// GetSchedule 按 ID 查询周期任务。
+// @Return: ID 不存在时返回 errno.ErrScheduleNotFound。
+func (s *Store) GetSchedule(ctx context.Context, id string) (*pb.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sched, ok := s.schedules[id]
+	if !ok {
+		return nil, errno.ErrScheduleNotFound
+	}
+	return proto.Clone(sched).(*pb.Schedule), nil
+}
+
+// ListSchedules 查询周期任务，topic 为空表示全部，结果按 ID 排序。
+func (s *Store) ListSchedules(ctx context.Context, topic string) ([]*pb.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules := make([]*pb.Schedule, 0, len(s.schedules))
+	for _, sched := range s.schedules {
+		if topic == "" || sched.Topic == topic {
+			schedules = append(schedules, proto.Clone(sched).(*pb.Schedule))
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].Id < schedules[j].Id })
+	return schedules, nil
+}
+
+// DeleteSchedule 删除周期任务，已投递的任务不受影响。
+// @Return: ID 不存在时返回 errno.ErrScheduleNotFound。
+func (s *Store) DeleteSchedule(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.schedules[id]; !ok {
+		return errno.ErrScheduleNotFound
+	}
+	delete(s.schedules, id)
+	s.logDelete(ChangeDeleteSchedule, id)
+	return s.commit()
+}
+
+// SetSchedulePaused 暂停或恢复周期任务，逻辑同 luaPauseSchedule。
+// @Return: 更新后的周期任务；ID 不存在时返回 errno.ErrScheduleNotFound。
+func (s *Store) SetSchedulePaused(ctx context.Context, id string, paused bool, next time.Time) (*pb.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sched, ok := s.schedules[id]
+	if !ok {
+		return nil, errno.ErrScheduleNotFound
+	}
+	sched.Paused = paused
+	if !paused {
+		sched.NextRunTime = 0
+		if !next.IsZero() {
+			sched.NextRunTime = next.Unix()
+		}
+	}
+	s.logSchedule(sched)
+	if err := s.commit(); err != nil {
+		return nil, err
+	}
+	return proto.Clone(sched).(*pb.Schedule), nil
+}
+
+// DueSchedules 返回 next_run_time 不晚于 now 且未暂停的周期任务，按触发时间先后排列，最多 limit 个。
+func (s *Store) DueSchedules(ctx context.Context, now time.Time, limit int64) ([]*pb.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*pb.Schedule
+	for _, sched := range s.schedules {
+		if !sched.Paused && sched.NextRunTime > 0 && sched.NextRunTime <= now.Unix() {
+			due = append(due, sched)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].NextRunTime != due[j].NextRunTime {
+			return due[i].NextRunTime < due[j].NextRunTime
+		}
+		return due[i].Id < due[j].Id
+	})
+	if int64(len(due)) > limit {
+		due = due[:limit]
+	}
+	for i, sched := range due {
+		due[i] = proto.Clone(sched).(*pb.Schedule)
+	}
+	return due, nil
+}
+
+// FireSchedule 原子地投递 f.Tasks 并推进周期任务的触发时间，逻辑同 luaFireSchedule。
+// @Return: 周期任务已被推进、暂停或删除时返回 false 且不做任何修改。
+func (s *Store) FireSchedule(ctx context.Context, f storage.ScheduleFiring) (bool, error) {
+	for _, task := range f.Tasks {
+		if task.Topic != f.Topic {
+			return false, fmt.Errorf("task %s topic %q does not match schedule topic %q", task.Id, task.Topic, f.Topic)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sched, ok := s.schedules[f.ScheduleID]
+	if !ok || sched.Paused || sched.NextRunTime != f.Expected.Unix() {
+		return false, nil
+	}
+
+	now := s.now()
+	added := false
+	for _, task := range f.Tasks {
+		if _, exists := s.entries[task.Id]; exists || s.deduped(task.Id, now) {
+			continue
+		}
+		e := &entry{task: proto.Clone(task).(*pb.Task), index: -1}
+		executeAt := storage.ExecuteAt(task)
+		s.entries[task.Id] = e
+		s.enqueue(e, executeAt.UnixMilli())
+		s.logTask(e)
+		s.markDedup(task.Id, maxTime(executeAt, now))
+		added = true
+	}
+	if added {
+		s.notify(f.Topic)
+	}
+
+	if !f.LastRun.IsZero() {
+		sched.LastRunTime = f.LastRun.Unix()
+	}
+	sched.NextRunTime = 0
+	if !f.Next.IsZero() {
+		sched.NextRunTime = f.Next.Unix()
+	}
+	s.logSchedule(sched)
+	if err := s.commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// maxTime 返回两个时刻中较晚的一个。
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,532 @@
+// Package memory 提供了基于进程内数据结构的 JobStore 接口实现。
+// 适用场景：单进程嵌入式部署（无需外部依赖的轻量队列），以及不依赖 Redis 即可验证真实队列语义的单元测试。
+//
+// 数据结构（按 Topic 分区，与 Redis 实现的 Key 布局一一对应）：
+//   - pending   (最小堆): 待执行任务，按重新可见的毫秒时间戳排序，对应 ddq:<topic>:pending
+//   - ready     (最小堆): 已到期、等待拉取的任务，按 execute_time - priority * aging 排序，对应 ddq:<topic>:ready
+//   - deadlines (最小堆): 执行中任务，按超时时刻排序，对应 ddq:<topic>:running 与 ddq:<topic>:deadlines
+//   - dead      (切片)  : 死信队列，末尾为最新进入的任务，对应 ddq:<topic>:dlq
+//
+// 全局的 entries (任务 ID → 任务及其状态) 对应 ddq:index 与 Tasks Hash，dedup 对应 ddq:dedup:<id>。
+// 所有数据保存在内存中，进程退出即丢失；任务以 *pb.Task 副本保存，不存在无法解码的条目，因此不实现 QuarantineStore。
+// 需要持久化时可通过 WithJournal 接收每次操作产生的状态变更，并在启动时以 Apply 重放（见 local 包）。
+package memory
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+// recoverLimit 单次 CheckAndMoveExpired 对每个 Topic 最多回收的超时任务数，剩余部分留给下一轮扫描，
+// 与 Redis 实现的 recoverBatchSize * recoverMaxBatches 一致，避免长时间持有锁。
+const recoverLimit = 2000
+
+// state 任务的当前状态，对应 ddq:index 中的 state 字段。
+type state int
+
+const (
+	statePending state = iota + 1 // 等待执行（位于 pending 或 ready 堆）
+	stateRunning                  // 执行中（位于 deadlines 堆）
+	stateDead                     // 已进入死信队列
+)
+
+// entry 保存单个任务及其在队列中的位置。
+type entry struct {
+	task  *pb.Task
+	state state
+	ready bool   // 等待中的任务是否已提升到 ready 堆
+	due   int64  // 等待中的任务重新可见的毫秒时间戳；Nack/回收重试时更新，Task.ExecuteTimeMs 保持不变（与 Redis 实现一致）
+	key   int64  // 所在堆的排序键 (毫秒)：pending 为 due，ready 为有效优先级分数，running 为超时时刻
+	index int    // 在所在堆中的下标，不在堆中时为 -1
+	lease string // 执行中任务本次投递的租约令牌
+
+	deadSeq int64 // 进入死信队列的序号，用于持久化后恢复死信顺序
+}
+
+// topicQueue 单个 Topic 的分区数据。
+type topicQueue struct {
+	pending   taskHeap
+	ready     taskHeap
+	deadlines taskHeap
+	dead      []*entry
+}
+
+// Store 实现了 storage.JobStore 接口，所有数据保存在进程内存中。
+// @ThreadSafe: 所有操作由同一把互斥锁串行化，复合操作（如 FetchAndHold 的“拉取并锁定”）天然原子。
+type Store struct {
+	mu        sync.Mutex
+	entries   map[string]*entry
+	topics    map[string]*topicQueue
+	dedup     map[string]time.Time // 任务 ID → 去重标记过期时刻
+	schedules map[string]*pb.Schedule
+	subs      map[string]map[chan string]struct{} // Topic → 就绪通知订阅者
+	deadSeq   int64                               // 最近一次进入死信队列的序号
+
+	journal Journal  // 状态变更的接收方，nil 表示不持久化
+	changes []Change // 当前操作累积的状态变更
+
+	dedupWindow       time.Duration // 任务完成后去重标记的保留时长，<=0 表示仅在任务存续期间去重
+	visibilityTimeout time.Duration // 任务未设置 visibility_timeout 时使用的默认值
+	priorityAging     time.Duration // 到期任务每等待该时长，有效优先级提升 1 级
+
+	now func() time.Time // 当前时间，测试中可替换
+}
+
+// Option 定义 Store 的可选配置项。
+type Option func(*Store)
+
+// WithDedupWindow 设置幂等去重窗口，含义同 Redis 实现的 WithDedupWindow。
+func WithDedupWindow(d time.Duration) Option {
+	return func(s *Store) {
+		s.dedupWindow = d
+	}
+}
+
+// WithVisibilityTimeout 设置默认可见性超时。
+// @Description 任务被拉取时按 now + visibility_timeout 计算超时时刻，任务未单独设置时使用该值；
+// 应与 Watchdog 使用的默认值保持一致。
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(s *Store) {
+		s.visibilityTimeout = d
+	}
+}
+
+// WithPriorityAging 设置优先级老化周期，含义同 Redis 实现的 WithPriorityAging。d <= 0 时保持默认值 60 秒。
+func WithPriorityAging(d time.Duration) Option {
+	return func(s *Store) {
+		if d > 0 {
+			s.priorityAging = d
+		}
+	}
+}
+
+// 编译期校验：确保 Store 完整实现了 JobStore 定义的所有契约，并支持就绪通知与周期任务存储。
+var (
+	_ storage.JobStore      = (*Store)(nil)
+	_ storage.Notifier      = (*Store)(nil)
+	_ storage.ScheduleStore = (*Store)(nil)
+)
+
+// NewStore 创建空的内存存储实例。
+// @Param opts: 可选配置项，如 WithDedupWindow。
+func NewStore(opts ...Option) *Store {
+	s := &Store{
+		entries:           make(map[string]*entry),
+		topics:            make(map[string]*topicQueue),
+		dedup:             make(map[string]time.Time),
+		schedules:         make(map[string]*pb.Schedule),
+		subs:              make(map[string]map[chan string]struct{}),
+		visibilityTimeout: 60 * time.Second,
+		priorityAging:     time.Minute,
+		now:               time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// queue 返回 Topic 的分区数据，不存在时创建。调用方须持有锁。
+func (s *Store) queue(topic string) *topicQueue {
+	q, ok := s.topics[topic]
+	if !ok {
+		q = &topicQueue{}
+		s.topics[topic] = q
+	}
+	return q
+}
+
+// deduped 判断 ID 是否仍处于去重窗口内，顺带清理已过期的标记。调用方须持有锁。
+func (s *Store) deduped(id string, now time.Time) bool {
+	expiry, ok := s.dedup[id]
+	if !ok {
+		return false
+	}
+	if !now.Before(expiry) {
+		delete(s.dedup, id)
+		return false
+	}
+	return true
+}
+
+// markDedup 写入去重标记，有效期覆盖到 from 之后的去重窗口。调用方须持有锁。
+func (s *Store) markDedup(id string, from time.Time) {
+	if s.dedupWindow > 0 {
+		s.dedup[id] = from.Add(s.dedupWindow)
+		s.logDedup(id, s.dedup[id].UnixMilli())
+	}
+}
+
+// enqueue 将任务放入 Topic 的 pending 堆，dueMs 之后可被拉取。调用方须持有锁。
+func (s *Store) enqueue(e *entry, dueMs int64) {
+	e.state = statePending
+	e.ready = false
+	e.due = dueMs
+	e.key = dueMs
+	heap.Push(&s.queue(e.task.Topic).pending, e)
+}
+
+// detach 将任务从所在的堆或死信队列中移除，并删除其 ID 索引。调用方须持有锁。
+func (s *Store) detach(e *entry) {
+	q := s.queue(e.task.Topic)
+	switch {
+	case e.state == statePending && e.ready:
+		heap.Remove(&q.ready, e.index)
+	case e.state == statePending:
+		heap.Remove(&q.pending, e.index)
+	case e.state == stateRunning:
+		heap.Remove(&q.deadlines, e.index)
+	case e.state == stateDead:
+		q.removeDead(e)
+	}
+	delete(s.entries, e.task.Id)
+}
+
+// removeDead 从死信队列中移除指定任务。
+func (q *topicQueue) removeDead(e *entry) {
+	for i, d := range q.dead {
+		if d == e {
+			q.dead = append(q.dead[:i], q.dead[i+1:]...)
+			return
+		}
+	}
+}
+
+// Add 写入待执行任务（ID 不存在才写入）。
+// @Return: ID 仍存在或处于去重窗口内时返回 errno.ErrTaskAlreadyExist，不做任何修改。
+func (s *Store) Add(ctx context.Context, task *pb.Task) error {
+	return s.add(task, false)
+}
+
+// Replace 以新任务覆盖同 ID 的旧任务；旧任务不存在时等同于 Add。
+// @Return: 旧任务正在执行时返回 errno.ErrTaskRunning。
+func (s *Store) Replace(ctx context.Context, task *pb.Task) error {
+	return s.add(task, true)
+}
+
+// add 是 Add 与 Replace 的共同实现，逻辑同 luaAdd。
+func (s *Store) add(task *pb.Task, replace bool) error {
+	if task.Topic == "" {
+		return fmt.Errorf("task topic is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	old, exists := s.entries[task.Id]
+	if exists || s.deduped(task.Id, now) {
+		if !replace {
+			return errno.ErrTaskAlreadyExist
+		}
+		if exists {
+			if old.state == stateRunning {
+				return errno.ErrTaskRunning
+			}
+			s.detach(old)
+		}
+	}
+
+	e := &entry{task: proto.Clone(task).(*pb.Task), index: -1}
+	executeAt := storage.ExecuteAt(task)
+	s.entries[task.Id] = e
+	s.enqueue(e, executeAt.UnixMilli())
+	// 去重标记覆盖到执行时间之后再保留一个去重窗口
+	s.logTask(e)
+	s.markDedup(task.Id, maxTime(executeAt, now))
+	s.notify(task.Topic)
+	return s.commit()
+}
+
+// FetchAndHold 批量取出 Topic 中已到期的任务并标记为执行中，逻辑同 luaFetchAndHold。
+// @Description 先将到期任务从 pending 提升到 ready，再按有效优先级取出至多 limit 个；
+// 每个任务获得唯一的租约令牌，超时时刻为 now + visibility_timeout。
+func (s *Store) FetchAndHold(ctx context.Context, topic string, limit int64) ([]*pb.Task, error) {
+	if topic == "" {
+		return nil, fmt.Errorf("topic is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.topics[topic]
+	if !ok {
+		return []*pb.Task{}, nil
+	}
+	now := s.now()
+	nowMs := now.UnixMilli()
+	aging := s.priorityAging.Milliseconds()
+
+	// 1. 提升到期任务
+	for e := q.pending.peek(); e != nil && e.key <= nowMs; e = q.pending.peek() {
+		heap.Pop(&q.pending)
+		e.ready = true
+		e.key = e.due - int64(e.task.Priority)*aging
+		heap.Push(&q.ready, e)
+	}
+
+	// 2. 按有效优先级取出并标记为执行中
+	nonce := uuid.New().String()
+	tasks := make([]*pb.Task, 0, min(limit, int64(q.ready.Len())))
+	for i := int64(1); i <= limit && q.ready.Len() > 0; i++ {
+		e := heap.Pop(&q.ready).(*entry)
+		e.state = stateRunning
+		e.ready = false
+		e.lease = nonce + "-" + strconv.FormatInt(i, 10)
+		e.key = now.Add(s.visibilityOf(e.task)).UnixMilli()
+		heap.Push(&q.deadlines, e)
+		s.logTask(e)
+
+		task := proto.Clone(e.task).(*pb.Task)
+		task.Lease = e.lease
+		tasks = append(tasks, task)
+	}
+	if err := s.commit(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// visibilityOf 返回任务的可见性超时，任务自带值优先。
+func (s *Store) visibilityOf(task *pb.Task) time.Duration {
+	if task.VisibilityTimeout > 0 {
+		return time.Duration(task.VisibilityTimeout) * time.Second
+	}
+	return s.visibilityTimeout
+}
+
+// Remove 按 ID 撤销等待中或已死信的任务，并清除去重标记，允许以相同 ID 重新提交。
+// @Return: ID 不存在时返回 errno.ErrTaskNotFound；任务执行中返回 errno.ErrTaskRunning。
+func (s *Store) Remove(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[id]
+	if !ok {
+		return errno.ErrTaskNotFound
+	}
+	if e.state == stateRunning {
+		return errno.ErrTaskRunning
+	}
+	s.detach(e)
+	delete(s.dedup, id)
+	s.logDelete(ChangeDeleteTask, id)
+	s.logDelete(ChangeDeleteDedup, id)
+	return s.commit()
+}
+
+// running 返回 Topic 下持有指定租约的执行中任务。调用方须持有锁。
+// @Return: 任务不在执行中时返回 errno.ErrTaskNotFound；租约令牌不匹配时返回 errno.ErrLeaseMismatch。
+func (s *Store) running(topic, id, lease string) (*entry, error) {
+	e, ok := s.entries[id]
+	if !ok || e.state != stateRunning || e.task.Topic != topic {
+		return nil, errno.ErrTaskNotFound
+	}
+	if e.lease != lease {
+		return nil, errno.ErrLeaseMismatch
+	}
+	return e, nil
+}
+
+// Ack 确认任务完成并删除任务，去重标记保留 dedupWindow。
+// @Return: 任务不在执行中时返回 errno.ErrTaskNotFound；租约令牌不匹配时返回 errno.ErrLeaseMismatch。
+func (s *Store) Ack(ctx context.Context, topic, id, lease string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.running(topic, id, lease)
+	if err != nil {
+		return err
+	}
+	s.detach(e)
+	s.logDelete(ChangeDeleteTask, id)
+	s.markDedup(id, s.now())
+	return s.commit()
+}
+
+// Nack 报告任务处理失败，逻辑同 luaNack。
+// @Description 重试计数递增后达到 max_retries 进入死信队列，否则在 opts.RetryDelay（未指定则按 retry_policy 退避）之后重新可见。
+// max_retries 优先使用任务自身的取值，未设置 (<=0) 时使用 opts.MaxRetries。
+// @Return: 任务不在执行中时返回 errno.ErrTaskNotFound；租约令牌不匹配时返回 errno.ErrLeaseMismatch。
+func (s *Store) Nack(ctx context.Context, topic, id, lease string, opts storage.NackOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.running(topic, id, lease)
+	if err != nil {
+		return err
+	}
+	q := s.queue(topic)
+	heap.Remove(&q.deadlines, e.index)
+
+	now := s.now()
+	e.task.RetryCount++
+	if opts.Reason != "" {
+		e.task.LastError = opts.Reason
+	}
+	limit := e.task.MaxRetries
+	if limit <= 0 {
+		limit = opts.MaxRetries
+	}
+	if e.task.RetryCount >= limit {
+		s.bury(e, "retries_exhausted", now)
+		s.logTask(e)
+		return s.commit()
+	}
+
+	delay := opts.RetryDelay
+	if delay <= 0 {
+		delay = storage.Backoff(e.task, rand.Float64)
+	}
+	e.task.LastBackoff = int64(delay / time.Second)
+	s.enqueue(e, now.Add(delay).UnixMilli())
+	s.logTask(e)
+	s.notify(topic)
+	return s.commit()
+}
+
+// bury 将已移出执行中状态的任务放入死信队列，记录死信原因与时间。调用方须持有锁。
+func (s *Store) bury(e *entry, reason string, now time.Time) {
+	e.state = stateDead
+	e.lease = ""
+	e.task.DeadReason = reason
+	e.task.DeadAt = now.Unix()
+	s.deadSeq++
+	e.deadSeq = s.deadSeq
+	q := s.queue(e.task.Topic)
+	q.dead = append(q.dead, e)
+}
+
+// Extend 将执行中任务的超时时刻推迟到 now + extra，只会延长不会缩短。
+// @Return: 任务不在执行中时返回 errno.ErrTaskNotFound；租约令牌不匹配时返回 errno.ErrLeaseMismatch。
+func (s *Store) Extend(ctx context.Context, topic, id, lease string, extra time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.running(topic, id, lease)
+	if err != nil {
+		return err
+	}
+	if deadline := s.now().Add(extra).UnixMilli(); deadline > e.key {
+		e.key = deadline
+		heap.Fix(&s.queue(topic).deadlines, e.index)
+		s.logTask(e)
+	}
+	return s.commit()
+}
+
+// CheckAndMoveExpired 回收超时的执行中任务，逻辑同 luaRecover。
+// @Description 只遍历各 Topic deadlines 堆顶已超时的任务，每个 Topic 最多回收 recoverLimit 个；顺带清理已过期的去重标记。
+// 超时时刻在 FetchAndHold 时按任务自身或 WithVisibilityTimeout 的可见性超时计算，visibilityTimeout 参数不再参与判定。
+// max_retries 优先使用任务自身的取值，未设置 (<=0) 时使用 maxRetries。
+func (s *Store) CheckAndMoveExpired(ctx context.Context, visibilityTimeout int64, maxRetries int32) (storage.RecoverStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stats storage.RecoverStats
+	now := s.now()
+	nowMs := now.UnixMilli()
+	for topic, q := range s.topics {
+		var requeued int64
+		for n := 0; n < recoverLimit; n++ {
+			e := q.deadlines.peek()
+			if e == nil || e.key >= nowMs {
+				break
+			}
+			heap.Pop(&q.deadlines)
+
+			limit := e.task.MaxRetries
+			if limit <= 0 {
+				limit = maxRetries
+			}
+			e.task.RetryCount++
+			if e.task.RetryCount >= limit {
+				s.bury(e, "visibility_timeout", now)
+				s.logTask(e)
+				stats.Dead++
+				continue
+			}
+			delay := storage.Backoff(e.task, rand.Float64)
+			e.task.LastBackoff = int64(delay / time.Second)
+			s.enqueue(e, now.Add(delay).UnixMilli())
+			s.logTask(e)
+			requeued++
+		}
+		if requeued > 0 {
+			stats.Requeued += requeued
+			s.notify(topic)
+		}
+	}
+
+	// 过期的去重标记重放后同样按过期处理，清理无需记录
+	for id := range s.dedup {
+		s.deduped(id, now)
+	}
+	return stats, s.commit()
+}
+
+// Notifications 实现 storage.Notifier，任务写入或重新入队时向订阅了该 Topic 的通道发送信号。
+// @Description 输出通道容量为 1，连续通知会被合并；ctx 取消后通道关闭。
+func (s *Store) Notifications(ctx context.Context, topics []string) (<-chan string, error) {
+	out := make(chan string, 1)
+
+	s.mu.Lock()
+	for _, topic := range topics {
+		if s.subs[topic] == nil {
+			s.subs[topic] = make(map[chan string]struct{})
+		}
+		s.subs[topic][out] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, topic := range topics {
+			delete(s.subs[topic], out)
+		}
+		close(out)
+	}()
+	return out, nil
+}
+
+// notify 向 Topic 的订阅者发送就绪信号，已有未消费的信号时合并。调用方须持有锁。
+func (s *Store) notify(topic string) {
+	for ch := range s.subs[topic] {
+		select {
+		case ch <- topic:
+		default:
+		}
+	}
+}
+
+// NextDueTime 实现 storage.Notifier，返回 Topic 中最早一个待执行任务的执行时间。
+// @Note: ready 堆中仍有已提升但未被拉取的任务时，直接返回当前时间。
+func (s *Store) NextDueTime(ctx context.Context, topic string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.topics[topic]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	if q.ready.Len() > 0 {
+		return s.now(), true, nil
+	}
+	if e := q.pending.peek(); e != nil {
+		return time.UnixMilli(e.key), true, nil
+	}
+	return time.Time{}, false, nil
+}
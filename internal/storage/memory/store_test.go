@@ -0,0 +1,390 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+)
+
+// testClock 可手动推进的时钟。
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestStore 返回使用可控时钟的 Store。
+func newTestStore(opts ...Option) (*Store, *testClock) {
+	clock := &testClock{now: time.UnixMilli(1700000000000)}
+	s := NewStore(opts...)
+	s.now = clock.Now
+	return s, clock
+}
+
+// newTask 构造在 clock 当前时刻之后 delay 执行的任务。
+func newTask(clock *testClock, id string, delay time.Duration) *pb.Task {
+	at := clock.Now().Add(delay)
+	return &pb.Task{Id: id, Topic: "test", ExecuteTime: at.Unix(), ExecuteTimeMs: at.UnixMilli(), MaxRetries: 3}
+}
+
+// ids 返回任务 ID 列表。
+func ids(tasks []*pb.Task) []string {
+	out := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, t.Id)
+	}
+	return out
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStore_FetchOrder(t *testing.T) {
+	ctx := context.Background()
+	s, clock := newTestStore(WithPriorityAging(time.Minute))
+
+	// late 未到期；urgent 优先级最高；old 等待了 3 分钟，老化后有效优先级高于 normal
+	tasks := []*pb.Task{
+		newTask(clock, "late", time.Hour),
+		newTask(clock, "normal", -time.Minute),
+		newTask(clock, "old", -3*time.Minute),
+		newTask(clock, "urgent", 0),
+	}
+	tasks[1].Priority = 1
+	tasks[3].Priority = 10
+	for _, task := range tasks {
+		if err := s.Add(ctx, task); err != nil {
+			t.Fatalf("Add(%s) error = %v", task.Id, err)
+		}
+	}
+
+	got, err := s.FetchAndHold(ctx, "test", 10)
+	if err != nil {
+		t.Fatalf("FetchAndHold() error = %v", err)
+	}
+	if want := []string{"urgent", "old", "normal"}; !equalIDs(ids(got), want) {
+		t.Errorf("FetchAndHold() = %v, want %v", ids(got), want)
+	}
+	leases := make(map[string]bool)
+	for _, task := range got {
+		if task.Lease == "" || leases[task.Lease] {
+			t.Errorf("task %s lease %q is empty or duplicated", task.Id, task.Lease)
+		}
+		leases[task.Lease] = true
+	}
+
+	if due, ok, _ := s.NextDueTime(ctx, "test"); !ok || !due.Equal(clock.Now().Add(time.Hour)) {
+		t.Errorf("NextDueTime() = %v, %v", due, ok)
+	}
+	clock.Advance(time.Hour)
+	if got, _ := s.FetchAndHold(ctx, "test", 10); !equalIDs(ids(got), []string{"late"}) {
+		t.Errorf("FetchAndHold() after advance = %v", ids(got))
+	}
+}
+
+func TestStore_Dedup(t *testing.T) {
+	ctx := context.Background()
+	s, clock := newTestStore(WithDedupWindow(time.Hour))
+
+	if err := s.Add(ctx, newTask(clock, "a", 0)); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := s.Add(ctx, newTask(clock, "a", 0)); !errors.Is(err, errno.ErrTaskAlreadyExist) {
+		t.Errorf("duplicate Add() error = %v, want ErrTaskAlreadyExist", err)
+	}
+
+	replaced := newTask(clock, "a", time.Minute)
+	replaced.Payload = "v2"
+	if err := s.Replace(ctx, replaced); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	clock.Advance(time.Minute)
+	got, _ := s.FetchAndHold(ctx, "test", 10)
+	if len(got) != 1 || got[0].Payload != "v2" {
+		t.Fatalf("FetchAndHold() = %v, want replaced task", got)
+	}
+	if err := s.Replace(ctx, newTask(clock, "a", 0)); !errors.Is(err, errno.ErrTaskRunning) {
+		t.Errorf("Replace() of running task error = %v, want ErrTaskRunning", err)
+	}
+
+	// Ack 后仍在去重窗口内，窗口过后可以重新提交
+	if err := s.Ack(ctx, "test", "a", got[0].Lease); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	if err := s.Add(ctx, newTask(clock, "a", 0)); !errors.Is(err, errno.ErrTaskAlreadyExist) {
+		t.Errorf("Add() within dedup window error = %v, want ErrTaskAlreadyExist", err)
+	}
+	clock.Advance(time.Hour)
+	if err := s.Add(ctx, newTask(clock, "a", 0)); err != nil {
+		t.Errorf("Add() after dedup window error = %v", err)
+	}
+}
+
+func TestStore_AckNackLease(t *testing.T) {
+	ctx := context.Background()
+	s, clock := newTestStore()
+	task := newTask(clock, "a", 0)
+	task.MaxRetries = 2
+	_ = s.Add(ctx, task)
+
+	got, _ := s.FetchAndHold(ctx, "test", 1)
+	lease := got[0].Lease
+	if err := s.Ack(ctx, "test", "a", "stale"); !errors.Is(err, errno.ErrLeaseMismatch) {
+		t.Errorf("Ack() with stale lease error = %v", err)
+	}
+	if err := s.Ack(ctx, "other", "a", lease); !errors.Is(err, errno.ErrTaskNotFound) {
+		t.Errorf("Ack() with wrong topic error = %v", err)
+	}
+	if err := s.Remove(ctx, "a"); !errors.Is(err, errno.ErrTaskRunning) {
+		t.Errorf("Remove() of running task error = %v", err)
+	}
+
+	// 第一次失败：按显式等待时长重试
+	if err := s.Nack(ctx, "test", "a", lease, storage.NackOptions{Reason: "boom", RetryDelay: 1500 * time.Millisecond}); err != nil {
+		t.Fatalf("Nack() error = %v", err)
+	}
+	if err := s.Ack(ctx, "test", "a", lease); !errors.Is(err, errno.ErrTaskNotFound) {
+		t.Errorf("Ack() after Nack error = %v", err)
+	}
+	if got, _ := s.FetchAndHold(ctx, "test", 1); len(got) != 0 {
+		t.Fatalf("task visible before retry delay: %v", ids(got))
+	}
+	clock.Advance(1500 * time.Millisecond)
+	got, _ = s.FetchAndHold(ctx, "test", 1)
+	if len(got) != 1 || got[0].RetryCount != 1 || got[0].LastError != "boom" {
+		t.Fatalf("retried task = %v", got)
+	}
+
+	// 第二次失败：达到 max_retries 进入死信队列
+	if err := s.Nack(ctx, "test", "a", got[0].Lease, storage.NackOptions{Reason: "again"}); err != nil {
+		t.Fatalf("Nack() error = %v", err)
+	}
+	dead, err := s.GetDead(ctx, "a")
+	if err != nil {
+		t.Fatalf("GetDead() error = %v", err)
+	}
+	if dead.DeadReason != "retries_exhausted" || dead.RetryCount != 2 || dead.DeadAt != clock.Now().Unix() {
+		t.Errorf("dead task = %v", dead)
+	}
+}
+
+func TestStore_Recover(t *testing.T) {
+	ctx := context.Background()
+	s, clock := newTestStore(WithVisibilityTimeout(30 * time.Second))
+
+	short := newTask(clock, "short", 0)
+	short.VisibilityTimeout = 5
+	extended := newTask(clock, "extended", 0)
+	last := newTask(clock, "last", 0)
+	last.RetryCount = 2
+	last.RetryPolicy = &pb.RetryPolicy{Strategy: pb.RetryStrategy_RETRY_STRATEGY_FIXED, BaseSeconds: 10}
+	for _, task := range []*pb.Task{short, extended, last} {
+		_ = s.Add(ctx, task)
+	}
+	held, _ := s.FetchAndHold(ctx, "test", 10)
+	leases := make(map[string]string)
+	for _, task := range held {
+		leases[task.Id] = task.Lease
+	}
+
+	clock.Advance(10 * time.Second)
+	stats, _ := s.CheckAndMoveExpired(ctx, 30, 3)
+	if stats.Requeued != 1 || stats.Dead != 0 {
+		t.Fatalf("stats after 10s = %+v, want only short requeued", stats)
+	}
+
+	if err := s.Extend(ctx, "test", "extended", leases["extended"], time.Minute); err != nil {
+		t.Fatalf("Extend() error = %v", err)
+	}
+	clock.Advance(25 * time.Second)
+	stats, _ = s.CheckAndMoveExpired(ctx, 30, 3)
+	if stats.Requeued != 0 || stats.Dead != 1 {
+		t.Fatalf("stats after 35s = %+v, want last dead", stats)
+	}
+	if dead, err := s.GetDead(ctx, "last"); err != nil || dead.DeadReason != "visibility_timeout" {
+		t.Errorf("GetDead(last) = %v, %v", dead, err)
+	}
+	if err := s.Ack(ctx, "test", "extended", leases["extended"]); err != nil {
+		t.Errorf("Ack() of extended task error = %v", err)
+	}
+	if err := s.Ack(ctx, "test", "short", leases["short"]); !errors.Is(err, errno.ErrTaskNotFound) {
+		t.Errorf("Ack() of recovered task error = %v", err)
+	}
+}
+
+func TestStore_DeadLetters(t *testing.T) {
+	ctx := context.Background()
+	s, clock := newTestStore()
+
+	// 依次产生 d0..d3 四个死信，每个间隔 1 分钟
+	for _, id := range []string{"d0", "d1", "d2", "d3"} {
+		task := newTask(clock, id, 0)
+		task.MaxRetries = 1
+		_ = s.Add(ctx, task)
+		held, _ := s.FetchAndHold(ctx, "test", 1)
+		_ = s.Nack(ctx, "test", id, held[0].Lease, storage.NackOptions{Reason: "boom"})
+		clock.Advance(time.Minute)
+	}
+
+	page, next, _ := s.ListDead(ctx, storage.DeadLetterQuery{Topic: "test", Limit: 3})
+	if !equalIDs(ids(page), []string{"d3", "d2", "d1"}) || next != 3 {
+		t.Errorf("first page = %v, next %d", ids(page), next)
+	}
+	page, next, _ = s.ListDead(ctx, storage.DeadLetterQuery{Topic: "test", Offset: next, Limit: 3})
+	if !equalIDs(ids(page), []string{"d0"}) || next != 0 {
+		t.Errorf("second page = %v, next %d", ids(page), next)
+	}
+	since := time.Unix(1700000000, 0).Add(90 * time.Second)
+	page, _, _ = s.ListDead(ctx, storage.DeadLetterQuery{Topic: "test", Since: since, Limit: 10})
+	if !equalIDs(ids(page), []string{"d3", "d2"}) {
+		t.Errorf("since page = %v", ids(page))
+	}
+
+	if n, _ := s.Redrive(ctx, storage.DeadLetterSelector{IDs: []string{"d0", "missing"}}, time.Minute); n != 1 {
+		t.Errorf("Redrive() = %d, want 1", n)
+	}
+	clock.Advance(time.Minute)
+	held, _ := s.FetchAndHold(ctx, "test", 10)
+	if len(held) != 1 || held[0].Id != "d0" || held[0].RetryCount != 0 || held[0].DeadReason != "" || held[0].LastError != "boom" {
+		t.Errorf("redriven task = %v", held)
+	}
+
+	if _, err := s.Purge(ctx, storage.DeadLetterSelector{All: true}); err == nil {
+		t.Error("Purge(all) without topic should fail")
+	}
+	if n, _ := s.Purge(ctx, storage.DeadLetterSelector{Topic: "test", All: true}); n != 3 {
+		t.Errorf("Purge(all) = %d, want 3", n)
+	}
+	if _, err := s.GetDead(ctx, "d1"); !errors.Is(err, errno.ErrTaskNotFound) {
+		t.Errorf("GetDead() after purge error = %v", err)
+	}
+	if err := s.Add(ctx, newTask(clock, "d1", 0)); err != nil {
+		t.Errorf("Add() after purge error = %v", err)
+	}
+}
+
+func TestStore_FireSchedule(t *testing.T) {
+	ctx := context.Background()
+	s, clock := newTestStore()
+	next := clock.Now().Truncate(time.Second)
+	_ = s.CreateSchedule(ctx, &pb.Schedule{Id: "s1", Topic: "test", NextRunTime: next.Unix()})
+
+	due, _ := s.DueSchedules(ctx, clock.Now(), 10)
+	if len(due) != 1 {
+		t.Fatalf("DueSchedules() = %v", due)
+	}
+
+	task := newTask(clock, "s1@1", 0)
+	fire := storage.ScheduleFiring{ScheduleID: "s1", Topic: "test", Expected: next, Next: next.Add(time.Minute), LastRun: next, Tasks: []*pb.Task{task}}
+	if ok, err := s.FireSchedule(ctx, fire); !ok || err != nil {
+		t.Fatalf("FireSchedule() = %v, %v", ok, err)
+	}
+	// 其他副本以过期的 Expected 推进时不生效
+	if ok, _ := s.FireSchedule(ctx, fire); ok {
+		t.Error("FireSchedule() with stale expected should fail")
+	}
+	if due, _ := s.DueSchedules(ctx, clock.Now(), 10); len(due) != 0 {
+		t.Errorf("DueSchedules() after fire = %v", due)
+	}
+	if held, _ := s.FetchAndHold(ctx, "test", 10); !equalIDs(ids(held), []string{"s1@1"}) {
+		t.Errorf("fired tasks = %v", ids(held))
+	}
+
+	paused, _ := s.SetSchedulePaused(ctx, "s1", true, time.Time{})
+	if !paused.Paused {
+		t.Error("schedule should be paused")
+	}
+	if due, _ := s.DueSchedules(ctx, clock.Now().Add(time.Hour), 10); len(due) != 0 {
+		t.Errorf("paused schedule is due: %v", due)
+	}
+}
+
+func TestStore_Notifications(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s, clock := newTestStore()
+
+	ch, _ := s.Notifications(ctx, []string{"test"})
+	_ = s.Add(ctx, newTask(clock, "a", 0))
+	_ = s.Add(ctx, newTask(clock, "b", 0))
+	select {
+	case topic := <-ch:
+		if topic != "test" {
+			t.Errorf("notification topic = %q", topic)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no notification")
+	}
+
+	cancel()
+	if _, ok := <-ch; ok {
+		// 合并后最多残留一个信号
+		if _, ok := <-ch; ok {
+			t.Error("channel should be closed after ctx is cancelled")
+		}
+	}
+}
+
+func TestStore_ConcurrentFetch(t *testing.T) {
+	ctx := context.Background()
+	s, clock := newTestStore()
+	const total = 500
+	for i := range total {
+		_ = s.Add(ctx, newTask(clock, fmt.Sprintf("t%d", i), 0))
+	}
+
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				held, err := s.FetchAndHold(ctx, "test", 7)
+				if err != nil || len(held) == 0 {
+					return
+				}
+				for _, task := range held {
+					mu.Lock()
+					if seen[task.Id] {
+						t.Errorf("task %s delivered twice", task.Id)
+					}
+					seen[task.Id] = true
+					mu.Unlock()
+					_ = s.Ack(ctx, "test", task.Id, task.Lease)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != total {
+		t.Errorf("delivered %d tasks, want %d", len(seen), total)
+	}
+}
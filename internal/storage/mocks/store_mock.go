@@ -12,8 +12,10 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	storage "github.com/AkikoAkaki/async-task-platform/internal/storage"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -42,17 +44,17 @@ func (m *MockJobStore) EXPECT() *MockJobStoreMockRecorder {
 }
 
 // Ack mocks base method.
-func (m *MockJobStore) Ack(ctx context.Context, id string) error {
+func (m *MockJobStore) Ack(ctx context.Context, topic, id, lease string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Ack", ctx, id)
+	ret := m.ctrl.Call(m, "Ack", ctx, topic, id, lease)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Ack indicates an expected call of Ack.
-func (mr *MockJobStoreMockRecorder) Ack(ctx, id any) *gomock.Call {
+func (mr *MockJobStoreMockRecorder) Ack(ctx, topic, id, lease any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ack", reflect.TypeOf((*MockJobStore)(nil).Ack), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ack", reflect.TypeOf((*MockJobStore)(nil).Ack), ctx, topic, id, lease)
 }
 
 // Add mocks base method.
@@ -70,11 +72,12 @@ func (mr *MockJobStoreMockRecorder) Add(ctx, task any) *gomock.Call {
 }
 
 // CheckAndMoveExpired mocks base method.
-func (m *MockJobStore) CheckAndMoveExpired(ctx context.Context, visibilityTimeout int64, maxRetries int32) error {
+func (m *MockJobStore) CheckAndMoveExpired(ctx context.Context, visibilityTimeout int64, maxRetries int32) (storage.RecoverStats, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CheckAndMoveExpired", ctx, visibilityTimeout, maxRetries)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(storage.RecoverStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CheckAndMoveExpired indicates an expected call of CheckAndMoveExpired.
@@ -83,6 +86,20 @@ func (mr *MockJobStoreMockRecorder) CheckAndMoveExpired(ctx, visibilityTimeout,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckAndMoveExpired", reflect.TypeOf((*MockJobStore)(nil).CheckAndMoveExpired), ctx, visibilityTimeout, maxRetries)
 }
 
+// Extend mocks base method.
+func (m *MockJobStore) Extend(ctx context.Context, topic, id, lease string, extra time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Extend", ctx, topic, id, lease, extra)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Extend indicates an expected call of Extend.
+func (mr *MockJobStoreMockRecorder) Extend(ctx, topic, id, lease, extra any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Extend", reflect.TypeOf((*MockJobStore)(nil).Extend), ctx, topic, id, lease, extra)
+}
+
 // FetchAndHold mocks base method.
 func (m *MockJobStore) FetchAndHold(ctx context.Context, topic string, limit int64) ([]*pb.Task, error) {
 	m.ctrl.T.Helper()
@@ -98,18 +115,79 @@ func (mr *MockJobStoreMockRecorder) FetchAndHold(ctx, topic, limit any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAndHold", reflect.TypeOf((*MockJobStore)(nil).FetchAndHold), ctx, topic, limit)
 }
 
+// GetDead mocks base method.
+func (m *MockJobStore) GetDead(ctx context.Context, id string) (*pb.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDead", ctx, id)
+	ret0, _ := ret[0].(*pb.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDead indicates an expected call of GetDead.
+func (mr *MockJobStoreMockRecorder) GetDead(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDead", reflect.TypeOf((*MockJobStore)(nil).GetDead), ctx, id)
+}
+
+// ListDead mocks base method.
+func (m *MockJobStore) ListDead(ctx context.Context, q storage.DeadLetterQuery) ([]*pb.Task, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDead", ctx, q)
+	ret0, _ := ret[0].([]*pb.Task)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListDead indicates an expected call of ListDead.
+func (mr *MockJobStoreMockRecorder) ListDead(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDead", reflect.TypeOf((*MockJobStore)(nil).ListDead), ctx, q)
+}
+
 // Nack mocks base method.
-func (m *MockJobStore) Nack(ctx context.Context, task *pb.Task) error {
+func (m *MockJobStore) Nack(ctx context.Context, topic, id, lease string, opts storage.NackOptions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Nack", ctx, task)
+	ret := m.ctrl.Call(m, "Nack", ctx, topic, id, lease, opts)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Nack indicates an expected call of Nack.
-func (mr *MockJobStoreMockRecorder) Nack(ctx, task any) *gomock.Call {
+func (mr *MockJobStoreMockRecorder) Nack(ctx, topic, id, lease, opts any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Nack", reflect.TypeOf((*MockJobStore)(nil).Nack), ctx, task)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Nack", reflect.TypeOf((*MockJobStore)(nil).Nack), ctx, topic, id, lease, opts)
+}
+
+// Purge mocks base method.
+func (m *MockJobStore) Purge(ctx context.Context, sel storage.DeadLetterSelector) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, sel)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockJobStoreMockRecorder) Purge(ctx, sel any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockJobStore)(nil).Purge), ctx, sel)
+}
+
+// Redrive mocks base method.
+func (m *MockJobStore) Redrive(ctx context.Context, sel storage.DeadLetterSelector, delay time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Redrive", ctx, sel, delay)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Redrive indicates an expected call of Redrive.
+func (mr *MockJobStoreMockRecorder) Redrive(ctx, sel, delay any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Redrive", reflect.TypeOf((*MockJobStore)(nil).Redrive), ctx, sel, delay)
 }
 
 // Remove mocks base method.
@@ -125,3 +203,309 @@ func (mr *MockJobStoreMockRecorder) Remove(ctx, id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockJobStore)(nil).Remove), ctx, id)
 }
+
+// Replace mocks base method.
+func (m *MockJobStore) Replace(ctx context.Context, task *pb.Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replace", ctx, task)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Replace indicates an expected call of Replace.
+func (mr *MockJobStoreMockRecorder) Replace(ctx, task any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replace", reflect.TypeOf((*MockJobStore)(nil).Replace), ctx, task)
+}
+
+// MockNotifier is a mock of Notifier interface.
+type MockNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotifierMockRecorder
+	isgomock struct{}
+}
+
+// MockNotifierMockRecorder is the mock recorder for MockNotifier.
+type MockNotifierMockRecorder struct {
+	mock *MockNotifier
+}
+
+// NewMockNotifier creates a new mock instance.
+func NewMockNotifier(ctrl *gomock.Controller) *MockNotifier {
+	mock := &MockNotifier{ctrl: ctrl}
+	mock.recorder = &MockNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
+	return m.recorder
+}
+
+// NextDueTime mocks base method.
+func (m *MockNotifier) NextDueTime(ctx context.Context, topic string) (time.Time, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextDueTime", ctx, topic)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// NextDueTime indicates an expected call of NextDueTime.
+func (mr *MockNotifierMockRecorder) NextDueTime(ctx, topic any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextDueTime", reflect.TypeOf((*MockNotifier)(nil).NextDueTime), ctx, topic)
+}
+
+// Notifications mocks base method.
+func (m *MockNotifier) Notifications(ctx context.Context, topics []string) (<-chan string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notifications", ctx, topics)
+	ret0, _ := ret[0].(<-chan string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Notifications indicates an expected call of Notifications.
+func (mr *MockNotifierMockRecorder) Notifications(ctx, topics any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notifications", reflect.TypeOf((*MockNotifier)(nil).Notifications), ctx, topics)
+}
+
+// MockClock is a mock of Clock interface.
+type MockClock struct {
+	ctrl     *gomock.Controller
+	recorder *MockClockMockRecorder
+	isgomock struct{}
+}
+
+// MockClockMockRecorder is the mock recorder for MockClock.
+type MockClockMockRecorder struct {
+	mock *MockClock
+}
+
+// NewMockClock creates a new mock instance.
+func NewMockClock(ctrl *gomock.Controller) *MockClock {
+	mock := &MockClock{ctrl: ctrl}
+	mock.recorder = &MockClockMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClock) EXPECT() *MockClockMockRecorder {
+	return m.recorder
+}
+
+// ServerTime mocks base method.
+func (m *MockClock) ServerTime(ctx context.Context) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ServerTime", ctx)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ServerTime indicates an expected call of ServerTime.
+func (mr *MockClockMockRecorder) ServerTime(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServerTime", reflect.TypeOf((*MockClock)(nil).ServerTime), ctx)
+}
+
+// MockQuarantineStore is a mock of QuarantineStore interface.
+type MockQuarantineStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockQuarantineStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockQuarantineStoreMockRecorder is the mock recorder for MockQuarantineStore.
+type MockQuarantineStoreMockRecorder struct {
+	mock *MockQuarantineStore
+}
+
+// NewMockQuarantineStore creates a new mock instance.
+func NewMockQuarantineStore(ctrl *gomock.Controller) *MockQuarantineStore {
+	mock := &MockQuarantineStore{ctrl: ctrl}
+	mock.recorder = &MockQuarantineStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockQuarantineStore) EXPECT() *MockQuarantineStoreMockRecorder {
+	return m.recorder
+}
+
+// DeleteQuarantined mocks base method.
+func (m *MockQuarantineStore) DeleteQuarantined(ctx context.Context, topic string, ids []string, all bool) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteQuarantined", ctx, topic, ids, all)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteQuarantined indicates an expected call of DeleteQuarantined.
+func (mr *MockQuarantineStoreMockRecorder) DeleteQuarantined(ctx, topic, ids, all any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteQuarantined", reflect.TypeOf((*MockQuarantineStore)(nil).DeleteQuarantined), ctx, topic, ids, all)
+}
+
+// ListQuarantined mocks base method.
+func (m *MockQuarantineStore) ListQuarantined(ctx context.Context, topic string, cursor uint64, limit int64) ([]*pb.QuarantinedEntry, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListQuarantined", ctx, topic, cursor, limit)
+	ret0, _ := ret[0].([]*pb.QuarantinedEntry)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListQuarantined indicates an expected call of ListQuarantined.
+func (mr *MockQuarantineStoreMockRecorder) ListQuarantined(ctx, topic, cursor, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListQuarantined", reflect.TypeOf((*MockQuarantineStore)(nil).ListQuarantined), ctx, topic, cursor, limit)
+}
+
+// QuarantineStats mocks base method.
+func (m *MockQuarantineStore) QuarantineStats(ctx context.Context, topic string) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuarantineStats", ctx, topic)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// QuarantineStats indicates an expected call of QuarantineStats.
+func (mr *MockQuarantineStoreMockRecorder) QuarantineStats(ctx, topic any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuarantineStats", reflect.TypeOf((*MockQuarantineStore)(nil).QuarantineStats), ctx, topic)
+}
+
+// MockScheduleStore is a mock of ScheduleStore interface.
+type MockScheduleStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockScheduleStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockScheduleStoreMockRecorder is the mock recorder for MockScheduleStore.
+type MockScheduleStoreMockRecorder struct {
+	mock *MockScheduleStore
+}
+
+// NewMockScheduleStore creates a new mock instance.
+func NewMockScheduleStore(ctrl *gomock.Controller) *MockScheduleStore {
+	mock := &MockScheduleStore{ctrl: ctrl}
+	mock.recorder = &MockScheduleStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduleStore) EXPECT() *MockScheduleStoreMockRecorder {
+	return m.recorder
+}
+
+// CreateSchedule mocks base method.
+func (m *MockScheduleStore) CreateSchedule(ctx context.Context, s *pb.Schedule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSchedule", ctx, s)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSchedule indicates an expected call of CreateSchedule.
+func (mr *MockScheduleStoreMockRecorder) CreateSchedule(ctx, s any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchedule", reflect.TypeOf((*MockScheduleStore)(nil).CreateSchedule), ctx, s)
+}
+
+// DeleteSchedule mocks base method.
+func (m *MockScheduleStore) DeleteSchedule(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSchedule", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSchedule indicates an expected call of DeleteSchedule.
+func (mr *MockScheduleStoreMockRecorder) DeleteSchedule(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSchedule", reflect.TypeOf((*MockScheduleStore)(nil).DeleteSchedule), ctx, id)
+}
+
+// DueSchedules mocks base method.
+func (m *MockScheduleStore) DueSchedules(ctx context.Context, now time.Time, limit int64) ([]*pb.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DueSchedules", ctx, now, limit)
+	ret0, _ := ret[0].([]*pb.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DueSchedules indicates an expected call of DueSchedules.
+func (mr *MockScheduleStoreMockRecorder) DueSchedules(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DueSchedules", reflect.TypeOf((*MockScheduleStore)(nil).DueSchedules), ctx, now, limit)
+}
+
+// FireSchedule mocks base method.
+func (m *MockScheduleStore) FireSchedule(ctx context.Context, f storage.ScheduleFiring) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FireSchedule", ctx, f)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FireSchedule indicates an expected call of FireSchedule.
+func (mr *MockScheduleStoreMockRecorder) FireSchedule(ctx, f any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FireSchedule", reflect.TypeOf((*MockScheduleStore)(nil).FireSchedule), ctx, f)
+}
+
+// GetSchedule mocks base method.
+func (m *MockScheduleStore) GetSchedule(ctx context.Context, id string) (*pb.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedule", ctx, id)
+	ret0, _ := ret[0].(*pb.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedule indicates an expected call of GetSchedule.
+func (mr *MockScheduleStoreMockRecorder) GetSchedule(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedule", reflect.TypeOf((*MockScheduleStore)(nil).GetSchedule), ctx, id)
+}
+
+// ListSchedules mocks base method.
+func (m *MockScheduleStore) ListSchedules(ctx context.Context, topic string) ([]*pb.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSchedules", ctx, topic)
+	ret0, _ := ret[0].([]*pb.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSchedules indicates an expected call of ListSchedules.
+func (mr *MockScheduleStoreMockRecorder) ListSchedules(ctx, topic any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSchedules", reflect.TypeOf((*MockScheduleStore)(nil).ListSchedules), ctx, topic)
+}
+
+// SetSchedulePaused mocks base method.
+func (m *MockScheduleStore) SetSchedulePaused(ctx context.Context, id string, paused bool, next time.Time) (*pb.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSchedulePaused", ctx, id, paused, next)
+	ret0, _ := ret[0].(*pb.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSchedulePaused indicates an expected call of SetSchedulePaused.
+func (mr *MockScheduleStoreMockRecorder) SetSchedulePaused(ctx, id, paused, next any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSchedulePaused", reflect.TypeOf((*MockScheduleStore)(nil).SetSchedulePaused), ctx, id, paused, next)
+}
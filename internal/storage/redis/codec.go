@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// 任务存储格式（ddq:<topic>:tasks 中的 Value）：
+//   - v1 (升级前): 整个 pb.Task 的 JSON，首字节为 '{'；同一段 JSON 同时充当 ZSet/List 成员。
+//   - v2: codecProto + 头部 JSON + '\n' + protobuf 正文，ZSet/List 成员只保存任务 ID。
+//
+// 头部只包含 Lua 脚本需要读写的调度字段（重试计数、退避策略、优先级等），字段名与 pb.Task 的 JSON 标签一致，
+// 脚本无需解析 protobuf 即可更新调度状态，也可以用同一套代码处理 v1 任务；payload 等大字段只出现在正文中。
+// cjson 会转义字符串中的换行，头部 JSON 不会包含 '\n'。
+const codecProto byte = 2
+
+// headerSeparator 分隔头部 JSON 与 protobuf 正文。
+const headerSeparator = '\n'
+
+// taskHeader 是 v2 格式的头部，对应 luaTaskCodec 中 decode_task 返回的 Table。
+type taskHeader struct {
+	ExecuteTime       int64           `json:"execute_time,omitempty"`
+	ExecuteTimeMs     int64           `json:"execute_time_ms,omitempty"`
+	RetryCount        int32           `json:"retry_count,omitempty"`
+	MaxRetries        int32           `json:"max_retries,omitempty"`
+	LastError         string          `json:"last_error,omitempty"`
+	VisibilityTimeout int64           `json:"visibility_timeout,omitempty"`
+	RetryPolicy       *pb.RetryPolicy `json:"retry_policy,omitempty"`
+	LastBackoff       int64           `json:"last_backoff,omitempty"`
+	DeadReason        string          `json:"dead_reason,omitempty"`
+	DeadAt            int64           `json:"dead_at,omitempty"`
+	Priority          int32           `json:"priority,omitempty"`
+}
+
+// encodeTask 以 v2 格式编码任务。
+// @Description 头部字段不会重复写入正文；Lease 只在单次投递中有效，不落盘。
+func encodeTask(task *pb.Task) ([]byte, error) {
+	header, err := json.Marshal(taskHeader{
+		ExecuteTime:       task.ExecuteTime,
+		ExecuteTimeMs:     task.ExecuteTimeMs,
+		RetryCount:        task.RetryCount,
+		MaxRetries:        task.MaxRetries,
+		LastError:         task.LastError,
+		VisibilityTimeout: task.VisibilityTimeout,
+		RetryPolicy:       task.RetryPolicy,
+		LastBackoff:       task.LastBackoff,
+		DeadReason:        task.DeadReason,
+		DeadAt:            task.DeadAt,
+		Priority:          task.Priority,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal task header: %w", err)
+	}
+
+	body := proto.Clone(task).(*pb.Task)
+	body.ExecuteTime, body.ExecuteTimeMs = 0, 0
+	body.RetryCount, body.MaxRetries = 0, 0
+	body.LastError, body.Lease = "", ""
+	body.VisibilityTimeout, body.RetryPolicy, body.LastBackoff = 0, nil, 0
+	body.DeadReason, body.DeadAt, body.Priority = "", 0, 0
+	encoded, err := proto.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal task body: %w", err)
+	}
+
+	out := make([]byte, 0, 2+len(header)+len(encoded))
+	out = append(out, codecProto)
+	out = append(out, header...)
+	out = append(out, headerSeparator)
+	return append(out, encoded...), nil
+}
+
+// isLegacyMember 判断 ZSet/List 成员是否为升级前的整段任务 JSON，规则与 luaTaskCodec 中的 parse_member 一致。
+func isLegacyMember(member string) bool {
+	if !strings.HasPrefix(member, "{") {
+		return false
+	}
+	var probe struct {
+		ID *string `json:"id"`
+	}
+	return json.Unmarshal([]byte(member), &probe) == nil && probe.ID != nil
+}
+
+// decodeTask 解码任务，同时支持 v1 (JSON) 与 v2 格式。
+func decodeTask(raw []byte) (*pb.Task, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("empty task data")
+	}
+
+	var task pb.Task
+	switch raw[0] {
+	case '{':
+		if err := json.Unmarshal(raw, &task); err != nil {
+			return nil, fmt.Errorf("unmarshal legacy task: %w", err)
+		}
+	case codecProto:
+		sep := bytes.IndexByte(raw, headerSeparator)
+		if sep < 0 {
+			return nil, errors.New("task header separator not found")
+		}
+		if err := proto.Unmarshal(raw[sep+1:], &task); err != nil {
+			return nil, fmt.Errorf("unmarshal task body: %w", err)
+		}
+		// 头部字段名与 pb.Task 的 JSON 标签一致，直接覆盖到正文解出的任务上
+		if err := json.Unmarshal(raw[1:sep], &task); err != nil {
+			return nil, fmt.Errorf("unmarshal task header: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown task codec version %d", raw[0])
+	}
+	return &task, nil
+}
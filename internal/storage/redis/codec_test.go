@@ -0,0 +1,273 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/protobuf/proto"
+)
+
+// sampleTask 构造一个字段齐全的任务，payload 大小可调。
+func sampleTask(id string, payloadSize int) *pb.Task {
+	return &pb.Task{
+		Id:                id,
+		Topic:             "bench",
+		Payload:           strings.Repeat("x", payloadSize),
+		ExecuteTime:       1700000000,
+		ExecuteTimeMs:     1700000000123,
+		RetryCount:        1,
+		MaxRetries:        3,
+		CreatedAt:         1699999999,
+		LastError:         "timeout\nretry later",
+		VisibilityTimeout: 30,
+		RetryPolicy:       &pb.RetryPolicy{Strategy: pb.RetryStrategy_RETRY_STRATEGY_EXPONENTIAL, BaseSeconds: 1, MaxSeconds: 60, Multiplier: 2},
+		LastBackoff:       2,
+		Priority:          5,
+	}
+}
+
+func TestTaskCodecRoundTrip(t *testing.T) {
+	task := sampleTask("t-1", 64)
+	task.Lease = "lease-1"
+
+	raw, err := encodeTask(task)
+	if err != nil {
+		t.Fatalf("encodeTask() error = %v", err)
+	}
+	if raw[0] != codecProto {
+		t.Fatalf("encodeTask() version = %d, want %d", raw[0], codecProto)
+	}
+
+	got, err := decodeTask(raw)
+	if err != nil {
+		t.Fatalf("decodeTask() error = %v", err)
+	}
+	want := proto.Clone(task).(*pb.Task)
+	want.Lease = "" // 租约不落盘
+	if !proto.Equal(got, want) {
+		t.Errorf("decodeTask() = %v, want %v", got, want)
+	}
+}
+
+func TestDecodeLegacyTask(t *testing.T) {
+	task := sampleTask("t-legacy", 16)
+	raw, err := json.Marshal(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := decodeTask(raw)
+	if err != nil {
+		t.Fatalf("decodeTask() error = %v", err)
+	}
+	if !proto.Equal(got, task) {
+		t.Errorf("decodeTask() = %v, want %v", got, task)
+	}
+	if !isLegacyMember(string(raw)) {
+		t.Errorf("isLegacyMember(%q) = false, want true", raw)
+	}
+}
+
+func TestDecodeTaskInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []byte
+	}{
+		{name: "Empty", raw: nil},
+		{name: "Unknown Version", raw: []byte{9, '{', '}'}},
+		{name: "Missing Separator", raw: []byte{codecProto, '{', '}'}},
+		{name: "Broken JSON", raw: []byte(`{"id":`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeTask(tt.raw); err == nil {
+				t.Errorf("decodeTask(%q) error = nil, want error", tt.raw)
+			}
+		})
+	}
+}
+
+func TestIsLegacyMember(t *testing.T) {
+	tests := []struct {
+		member string
+		want   bool
+	}{
+		{member: "task-1", want: false},
+		{member: `{"id":"task-1"}`, want: true},
+		{member: `{"topic":"a"}`, want: false}, // 缺少 id 的 JSON 视为普通 ID
+		{member: `{not json`, want: false},
+	}
+	for _, tt := range tests {
+		if got := isLegacyMember(tt.member); got != tt.want {
+			t.Errorf("isLegacyMember(%q) = %v, want %v", tt.member, got, tt.want)
+		}
+	}
+}
+
+// benchPayloadSizes 覆盖小消息与典型业务消息两种场景。
+var benchPayloadSizes = []int{64, 1024}
+
+// BenchmarkTaskEncoding 对比两种格式的编码体积与编解码耗时，无需 Redis。
+func BenchmarkTaskEncoding(b *testing.B) {
+	for _, size := range benchPayloadSizes {
+		task := sampleTask("bench-task-0001", size)
+
+		b.Run(fmt.Sprintf("json/payload=%d", size), func(b *testing.B) {
+			var raw []byte
+			for i := 0; i < b.N; i++ {
+				raw, _ = json.Marshal(task)
+				var out pb.Task
+				if err := json.Unmarshal(raw, &out); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(raw)), "B/task")
+		})
+		b.Run(fmt.Sprintf("proto/payload=%d", size), func(b *testing.B) {
+			var raw []byte
+			for i := 0; i < b.N; i++ {
+				raw, _ = encodeTask(task)
+				if _, err := decodeTask(raw); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(len(raw)), "B/task")
+		})
+	}
+}
+
+// benchStore 连接 DDQ_REDIS_ADDR（默认 localhost:6379）上的 Redis，不可用时跳过基准测试。
+// 每个基准使用独立前缀，结束后清理自己写入的 Key。
+func benchStore(b *testing.B) *Store {
+	b.Helper()
+	addr := os.Getenv("DDQ_REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	s := NewStore(addr)
+	ctx := context.Background()
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		b.Skipf("redis %s unavailable: %v", addr, err)
+	}
+	s.prefix = "ddqbench" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	b.Cleanup(func() {
+		iter := s.client.Scan(ctx, 0, s.prefix+":*", 1000).Iterator()
+		for iter.Next(ctx) {
+			s.client.Del(ctx, iter.Val())
+		}
+		_ = s.client.Close()
+	})
+	return s
+}
+
+// seedLegacy 按升级前的布局写入任务：整段 JSON 同时作为 Pending 成员与索引数据。
+func seedLegacy(ctx context.Context, b *testing.B, s *Store, n, payloadSize int) {
+	b.Helper()
+	due := time.Now().Add(-time.Second).UnixMilli()
+	pipe := s.client.Pipeline()
+	for i := 0; i < n; i++ {
+		task := sampleTask(fmt.Sprintf("legacy-%d", i), payloadSize)
+		raw, _ := json.Marshal(task)
+		entry, _ := json.Marshal(indexEntry{Topic: task.Topic, State: "pending", Data: string(raw)})
+		pipe.ZAdd(ctx, s.pendingKey(task.Topic), redis.Z{Score: float64(due), Member: string(raw)})
+		pipe.HSet(ctx, s.indexKey(), task.Id, entry)
+		if pipe.Len() >= 1000 {
+			if _, err := pipe.Exec(ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		b.Fatal(err)
+	}
+}
+
+// seedCurrent 通过 Add 按当前布局写入任务。
+func seedCurrent(ctx context.Context, b *testing.B, s *Store, n, payloadSize int) {
+	b.Helper()
+	for i := 0; i < n; i++ {
+		task := sampleTask(fmt.Sprintf("task-%d", i), payloadSize)
+		task.ExecuteTimeMs = time.Now().Add(-time.Second).UnixMilli()
+		if err := s.Add(ctx, task); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// usedMemory 读取 INFO memory 中的 used_memory。
+func usedMemory(ctx context.Context, b *testing.B, s *Store) int64 {
+	b.Helper()
+	info, err := s.client.Info(ctx, "memory").Result()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, line := range strings.Split(info, "\r\n") {
+		if v, ok := strings.CutPrefix(line, "used_memory:"); ok {
+			n, _ := strconv.ParseInt(v, 10, 64)
+			return n
+		}
+	}
+	b.Fatal("used_memory not found in INFO")
+	return 0
+}
+
+type seedFunc func(ctx context.Context, b *testing.B, s *Store, n, payloadSize int)
+
+var benchLayouts = []struct {
+	name string
+	seed seedFunc
+}{
+	{name: "json", seed: seedLegacy},
+	{name: "proto", seed: seedCurrent},
+}
+
+// BenchmarkStoreMemory 对比两种布局下每个待处理任务占用的 Redis 内存（used_memory 增量）。
+// 运行方式：DDQ_REDIS_ADDR=host:port go test -run ^$ -bench StoreMemory ./internal/storage/redis
+func BenchmarkStoreMemory(b *testing.B) {
+	const n = 10000
+	for _, layout := range benchLayouts {
+		for _, size := range benchPayloadSizes {
+			b.Run(fmt.Sprintf("%s/payload=%d", layout.name, size), func(b *testing.B) {
+				ctx := context.Background()
+				var total int64
+				for i := 0; i < b.N; i++ {
+					s := benchStore(b)
+					before := usedMemory(ctx, b, s)
+					layout.seed(ctx, b, s, n, size)
+					total += usedMemory(ctx, b, s) - before
+				}
+				b.ReportMetric(float64(total)/float64(b.N*n), "B/task")
+			})
+		}
+	}
+}
+
+// BenchmarkStoreFetch 对比两种布局下 FetchAndHold 的拉取吞吐，每次迭代拉取 fetchBatch 个任务。
+// 旧布局的任务会在首次拉取时转存到 Tasks Hash，结果包含这部分兼容开销。
+func BenchmarkStoreFetch(b *testing.B) {
+	const fetchBatch = 100
+	for _, layout := range benchLayouts {
+		for _, size := range benchPayloadSizes {
+			b.Run(fmt.Sprintf("%s/payload=%d", layout.name, size), func(b *testing.B) {
+				ctx := context.Background()
+				s := benchStore(b)
+				layout.seed(ctx, b, s, b.N*fetchBatch, size)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := s.FetchAndHold(ctx, "bench", fetchBatch); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(b.N*fetchBatch)/b.Elapsed().Seconds(), "tasks/s")
+			})
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/storetest"
+	"github.com/alicebob/miniredis/v2"
+)
+
+// TestConformance_Miniredis 在进程内的 miniredis 上运行一致性测试，无需外部 Redis，所有 Lua 脚本在每次 go test 中都会执行。
+// 每个用例使用独立的 miniredis 实例。
+func TestConformance_Miniredis(t *testing.T) {
+	storetest.RunConformance(t, func(t *testing.T) storetest.Harness {
+		return harness(t, miniredis.RunT(t).Addr())
+	})
+}
+
+// TestConformance 对 DDQ_REDIS_ADDR（默认 localhost:6379）上的 Redis 运行一致性测试，不可用时跳过。
+// 每个用例使用独立前缀，结束后清理自己写入的 Key。
+func TestConformance(t *testing.T) {
+	addr := os.Getenv("DDQ_REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	probe := NewStore(addr)
+	err := probe.client.Ping(context.Background()).Err()
+	_ = probe.client.Close()
+	if err != nil {
+		t.Skipf("redis %s unavailable: %v", addr, err)
+	}
+
+	storetest.RunConformance(t, func(t *testing.T) storetest.Harness {
+		return harness(t, addr)
+	})
+}
+
+// harness 连接 addr 上的 Redis 创建被测存储，Corrupt 直接改写任务的序列化数据。
+func harness(t *testing.T, addr string) storetest.Harness {
+	ctx := context.Background()
+	s := NewStore(addr)
+	s.prefix = "ddqtest" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	t.Cleanup(func() {
+		iter := s.client.Scan(ctx, 0, s.prefix+":*", 1000).Iterator()
+		for iter.Next(ctx) {
+			s.client.Del(ctx, iter.Val())
+		}
+		_ = s.client.Close()
+	})
+	return storetest.Harness{
+		Store: s,
+		Corrupt: func(t *testing.T, topic, id string) {
+			if err := s.client.HSet(ctx, s.tasksKey(topic), id, "not a task").Err(); err != nil {
+				t.Fatalf("corrupt %s: %v", id, err)
+			}
+		},
+	}
+}
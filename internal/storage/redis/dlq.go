@@ -0,0 +1,236 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/redis/go-redis/v9"
+)
+
+// dlqBatchSize 死信批量操作（扫描、全量重投/删除）单批处理的最大条目数，避免长时间阻塞 Redis。
+const dlqBatchSize = 500
+
+// ListDead 分页扫描 Topic 的死信队列。
+// @Description 死信队列为 LPUSH 写入的任务 ID List，下标越小越新。从 q.Offset 开始按批 LRANGE 并 HMGET 任务数据，
+// 按 dead_at 过滤直到凑满 q.Limit 条；单次调用最多扫描 dlqBatchSize 条，未凑满时也会返回 next 供继续翻页。
+// @Note: 翻页期间若有死信被重投或删除，下标会发生偏移，可能出现少量重复或遗漏，仅适用于运维查询。
+func (s *Store) ListDead(ctx context.Context, q storage.DeadLetterQuery) ([]*pb.Task, int64, error) {
+	if q.Topic == "" {
+		return nil, 0, fmt.Errorf("topic is required")
+	}
+
+	tasks := make([]*pb.Task, 0, q.Limit)
+	offset := q.Offset
+	for scanned := int64(0); scanned < dlqBatchSize && int64(len(tasks)) < q.Limit; {
+		items, err := s.client.LRange(ctx, s.dlqKey(q.Topic), offset, offset+q.Limit-1).Result()
+		if err != nil {
+			return nil, 0, fmt.Errorf("redis lrange failed: %w", err)
+		}
+		if len(items) == 0 {
+			return tasks, 0, nil // 已到队尾
+		}
+
+		data, err := s.deadData(ctx, q.Topic, items)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, raw := range data {
+			offset++
+			scanned++
+
+			task, err := decodeTask([]byte(raw))
+			if err != nil {
+				continue // 跳过无法解析的死信条目
+			}
+			if !q.Since.IsZero() && task.DeadAt < q.Since.Unix() {
+				continue
+			}
+			if !q.Until.IsZero() && task.DeadAt > q.Until.Unix() {
+				continue
+			}
+			tasks = append(tasks, task)
+			if int64(len(tasks)) == q.Limit {
+				break
+			}
+		}
+	}
+
+	// 判断是否还有后续数据，避免返回一个必然为空的下一页
+	n, err := s.client.LLen(ctx, s.dlqKey(q.Topic)).Result()
+	if err != nil {
+		return nil, 0, fmt.Errorf("redis llen failed: %w", err)
+	}
+	if offset >= n {
+		return tasks, 0, nil
+	}
+	return tasks, offset, nil
+}
+
+// deadData 返回死信队列成员对应的任务数据，与 members 一一对应，缺失的任务数据为空串。
+// @Description 成员通常是任务 ID，通过 HMGET 批量读取 Tasks Hash；升级前写入的成员本身就是任务 JSON，直接返回。
+func (s *Store) deadData(ctx context.Context, topic string, members []string) ([]string, error) {
+	data := make([]string, len(members))
+	var ids []string
+	var pos []int
+	for i, member := range members {
+		if isLegacyMember(member) {
+			data[i] = member
+			continue
+		}
+		ids = append(ids, member)
+		pos = append(pos, i)
+	}
+	if len(ids) == 0 {
+		return data, nil
+	}
+
+	vals, err := s.client.HMGet(ctx, s.tasksKey(topic), ids...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis hmget tasks failed: %w", err)
+	}
+	for i, val := range vals {
+		if str, ok := val.(string); ok {
+			data[pos[i]] = str
+		}
+	}
+	return data, nil
+}
+
+// GetDead 通过 ID 索引读取死信任务。
+// @Return: ID 不存在或不在死信状态时返回 errno.ErrTaskNotFound。
+func (s *Store) GetDead(ctx context.Context, id string) (*pb.Task, error) {
+	raw, err := s.client.HGet(ctx, s.indexKey(), id).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, errno.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("redis hget index failed: %w", err)
+	}
+	var entry indexEntry
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+		return nil, fmt.Errorf("unmarshal index entry failed: %w", err)
+	}
+	if entry.State != "dead" {
+		return nil, errno.ErrTaskNotFound
+	}
+
+	// 升级前的索引条目直接携带任务 JSON
+	data := entry.Data
+	if data == "" {
+		data, err = s.client.HGet(ctx, s.tasksKey(entry.Topic), id).Result()
+		if err != nil {
+			if err == redis.Nil {
+				return nil, errno.ErrTaskNotFound
+			}
+			return nil, fmt.Errorf("redis hget task failed: %w", err)
+		}
+	}
+	return decodeTask([]byte(data))
+}
+
+// Redrive 将死信任务重新投递到等待队列，在 delay 之后（以 Redis 服务端时间为准）执行。
+// @Description 按 ID 重投时先通过索引将 ID 按 Topic 分组，再逐个 Topic 执行 luaRedrive；
+// 全量重投时以调用时的队列长度为上限分批 RPOP，期间新产生的死信不受影响。
+// 任务数据无法解码的死信转入隔离区，不计入重投数量。
+func (s *Store) Redrive(ctx context.Context, sel storage.DeadLetterSelector, delay time.Duration) (int64, error) {
+	return s.applyDead(ctx, sel, func(topic, mode string, args []interface{}) ([]interface{}, error) {
+		argv := append([]interface{}{delay.Milliseconds(), topic, s.notifyChannel(topic), mode}, args...)
+		return s.client.Eval(ctx, luaRedrive,
+			[]string{s.dlqKey(topic), s.pendingKey(topic), s.indexKey(), s.tasksKey(topic),
+				s.quarantineKey(topic), s.quarantineStatsKey()}, argv...).Slice()
+	})
+}
+
+// Purge 永久删除死信任务，并清理其 ID 索引与去重标记。选取规则同 Redrive。
+func (s *Store) Purge(ctx context.Context, sel storage.DeadLetterSelector) (int64, error) {
+	return s.applyDead(ctx, sel, func(topic, mode string, args []interface{}) ([]interface{}, error) {
+		argv := append([]interface{}{s.prefix, topic, mode}, args...)
+		return s.client.Eval(ctx, luaPurge,
+			[]string{s.dlqKey(topic), s.indexKey(), s.tasksKey(topic)}, argv...).Slice()
+	})
+}
+
+// deadScript 在单个 Topic 上执行一次死信批量脚本，返回脚本结果 {处理数量, 扫描数量}。
+type deadScript func(topic, mode string, args []interface{}) ([]interface{}, error)
+
+// applyDead 按 DeadLetterSelector 选取死信并分批执行脚本，返回处理总数。
+func (s *Store) applyDead(ctx context.Context, sel storage.DeadLetterSelector, run deadScript) (int64, error) {
+	if sel.All {
+		if sel.Topic == "" {
+			return 0, fmt.Errorf("topic is required")
+		}
+		remaining, err := s.client.LLen(ctx, s.dlqKey(sel.Topic)).Result()
+		if err != nil {
+			return 0, fmt.Errorf("redis llen failed: %w", err)
+		}
+
+		var total int64
+		for remaining > 0 {
+			done, scanned, err := deadResult(run(sel.Topic, "all", []interface{}{min(remaining, dlqBatchSize)}))
+			if err != nil {
+				return total, err
+			}
+			total += done
+			if scanned == 0 {
+				break
+			}
+			remaining -= scanned
+		}
+		return total, nil
+	}
+
+	// 按 Topic 分组：未指定 Topic 时通过索引查询每个 ID 的归属
+	groups := make(map[string][]interface{})
+	if sel.Topic != "" {
+		for _, id := range sel.IDs {
+			groups[sel.Topic] = append(groups[sel.Topic], id)
+		}
+	} else if len(sel.IDs) > 0 {
+		raws, err := s.client.HMGet(ctx, s.indexKey(), sel.IDs...).Result()
+		if err != nil {
+			return 0, fmt.Errorf("redis hmget index failed: %w", err)
+		}
+		for i, raw := range raws {
+			str, ok := raw.(string)
+			if !ok {
+				continue // ID 不存在
+			}
+			var entry indexEntry
+			if err := json.Unmarshal([]byte(str), &entry); err != nil || entry.State != "dead" {
+				continue
+			}
+			groups[entry.Topic] = append(groups[entry.Topic], sel.IDs[i])
+		}
+	}
+
+	var total int64
+	for topic, ids := range groups {
+		for start := 0; start < len(ids); start += dlqBatchSize {
+			batch := ids[start:min(start+dlqBatchSize, len(ids))]
+			done, _, err := deadResult(run(topic, "ids", batch))
+			if err != nil {
+				return total, err
+			}
+			total += done
+		}
+	}
+	return total, nil
+}
+
+// deadResult 解析死信批量脚本返回的 {处理数量, 扫描数量}。
+func deadResult(res []interface{}, err error) (done, scanned int64, _ error) {
+	if err != nil {
+		return 0, 0, fmt.Errorf("dead letter script failed: %w", err)
+	}
+	if len(res) != 2 {
+		return 0, 0, fmt.Errorf("unexpected dead letter script result: %v", res)
+	}
+	done, _ = res[0].(int64)
+	scanned, _ = res[1].(int64)
+	return done, scanned, nil
+}
@@ -0,0 +1,116 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/redis/go-redis/v9"
+)
+
+// 隔离 Hash (ddq:<topic>:quarantine) 的 Value 为 元信息 JSON + '\n' + 原始字节，由 luaQuarantine 写入。
+// 元信息由 cjson 编码，字符串中的换行会被转义，因此第一个 '\n' 即为分隔符；原始字节可能是二进制数据，不做任何转义。
+
+// quarantineMeta 对应隔离条目的元信息部分。
+type quarantineMeta struct {
+	ID            string `json:"id"`
+	Error         string `json:"error"`
+	Source        string `json:"source"`         // fetch / recover
+	QuarantinedAt int64  `json:"quarantined_at"` // Unix 秒
+}
+
+// decodeQuarantined 解析隔离 Hash 中的一个条目。
+// @Description 元信息损坏时仍返回原始字节，保证任何条目都可以被查看与删除。
+func decodeQuarantined(topic, field string, value []byte) *pb.QuarantinedEntry {
+	entry := &pb.QuarantinedEntry{Id: field, Topic: topic, Raw: value}
+	sep := bytes.IndexByte(value, headerSeparator)
+	if sep < 0 {
+		entry.Error = "malformed quarantine entry"
+		return entry
+	}
+
+	var meta quarantineMeta
+	if err := json.Unmarshal(value[:sep], &meta); err != nil {
+		entry.Error = "malformed quarantine entry: " + err.Error()
+		return entry
+	}
+	entry.Raw = value[sep+1:]
+	entry.Error = meta.Error
+	entry.Source = meta.Source
+	entry.QuarantinedAt = meta.QuarantinedAt
+	return entry
+}
+
+// quarantineRunning 将 FetchAndHold 已取出但无法解码的任务转入隔离区。
+// @Description 任务此时已处于执行中状态，由 luaQuarantineRunning 校验租约后原子地撤销并隔离。
+func (s *Store) quarantineRunning(ctx context.Context, topic, id, lease string, decodeErr error) error {
+	return s.client.Eval(ctx, luaQuarantineRunning,
+		[]string{s.runningKey(topic), s.deadlineKey(topic), s.tasksKey(topic), s.indexKey(),
+			s.quarantineKey(topic), s.quarantineStatsKey()},
+		id, lease, topic, decodeErr.Error()).Err()
+}
+
+// ListQuarantined 实现 storage.QuarantineStore，以 HSCAN 分页遍历 Topic 的隔离 Hash。
+// @Note: HSCAN 的 COUNT 只是提示值，单页条数可能与 limit 不同；遍历期间新增的条目可能不会出现。
+func (s *Store) ListQuarantined(ctx context.Context, topic string, cursor uint64, limit int64) ([]*pb.QuarantinedEntry, uint64, error) {
+	if topic == "" {
+		return nil, 0, fmt.Errorf("topic is required")
+	}
+
+	kvs, next, err := s.client.HScan(ctx, s.quarantineKey(topic), cursor, "", limit).Result()
+	if err != nil {
+		return nil, 0, fmt.Errorf("redis hscan failed: %w", err)
+	}
+
+	entries := make([]*pb.QuarantinedEntry, 0, len(kvs)/2)
+	for i := 0; i+1 < len(kvs); i += 2 {
+		entries = append(entries, decodeQuarantined(topic, kvs[i], []byte(kvs[i+1])))
+	}
+	return entries, next, nil
+}
+
+// QuarantineStats 实现 storage.QuarantineStore。
+// @Return: current 为隔离 Hash 的当前长度；total 为 ddq:quarantine:stats 中该 Topic 的累计计数。
+func (s *Store) QuarantineStats(ctx context.Context, topic string) (int64, int64, error) {
+	pipe := s.client.Pipeline()
+	current := pipe.HLen(ctx, s.quarantineKey(topic))
+	total := pipe.HGet(ctx, s.quarantineStatsKey(), topic)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, 0, fmt.Errorf("redis quarantine stats failed: %w", err)
+	}
+
+	n, err := total.Int64()
+	if err != nil && err != redis.Nil {
+		return 0, 0, fmt.Errorf("parse quarantine total: %w", err)
+	}
+	return current.Val(), n, nil
+}
+
+// DeleteQuarantined 实现 storage.QuarantineStore。
+// @Description all 模式在同一个 MULTI 事务中读取条目数并删除整个隔离 Hash；累计计数不受影响。
+func (s *Store) DeleteQuarantined(ctx context.Context, topic string, ids []string, all bool) (int64, error) {
+	if topic == "" {
+		return 0, fmt.Errorf("topic is required")
+	}
+
+	if all {
+		pipe := s.client.TxPipeline()
+		n := pipe.HLen(ctx, s.quarantineKey(topic))
+		pipe.Unlink(ctx, s.quarantineKey(topic))
+		if _, err := pipe.Exec(ctx); err != nil {
+			return 0, fmt.Errorf("redis delete quarantine failed: %w", err)
+		}
+		return n.Val(), nil
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	n, err := s.client.HDel(ctx, s.quarantineKey(topic), ids...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis hdel failed: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,30 @@
+package redis
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecodeQuarantined(t *testing.T) {
+	raw := []byte{codecProto, '{', '}', 0xff, '\n', 0x00}
+	value := append([]byte(`{"id":"t-1","error":"bad \n header","source":"fetch","quarantined_at":1700000000}`+"\n"), raw...)
+
+	got := decodeQuarantined("orders", "t-1", value)
+	if !bytes.Equal(got.Raw, raw) {
+		t.Errorf("Raw = %q, want %q", got.Raw, raw)
+	}
+	if got.Id != "t-1" || got.Topic != "orders" || got.Source != "fetch" || got.QuarantinedAt != 1700000000 {
+		t.Errorf("decodeQuarantined() = %v", got)
+	}
+	if got.Error != "bad \n header" {
+		t.Errorf("Error = %q, want %q", got.Error, "bad \n header")
+	}
+}
+
+func TestDecodeQuarantinedMalformed(t *testing.T) {
+	value := []byte("no separator")
+	got := decodeQuarantined("orders", "t-1", value)
+	if !bytes.Equal(got.Raw, value) || got.Error == "" {
+		t.Errorf("decodeQuarantined() = %v, want raw value kept with an error", got)
+	}
+}
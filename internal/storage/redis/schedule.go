@@ -0,0 +1,207 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/redis/go-redis/v9"
+)
+
+// 编译期校验：Store 支持周期任务存储。
+var _ storage.ScheduleStore = (*Store)(nil)
+
+// schedulesKey 返回周期任务 Hash 的键名，Field 为周期任务 ID，Value 为 JSON。
+func (s *Store) schedulesKey() string {
+	return s.prefix + ":schedules"
+}
+
+// scheduleDueKey 返回周期任务触发时间 ZSet 的键名，Score 为 next_run_time，仅包含未暂停且仍会触发的周期任务。
+func (s *Store) scheduleDueKey() string {
+	return s.prefix + ":schedules:due"
+}
+
+// CreateSchedule 保存新的周期任务，并按 NextRunTime 登记到触发时间 ZSet。
+// @Return: ID 已存在时返回 errno.ErrScheduleAlreadyExist。
+func (s *Store) CreateSchedule(ctx context.Context, sched *pb.Schedule) error {
+	bytes, err := json.Marshal(sched)
+	if err != nil {
+		return fmt.Errorf("marshal schedule: %w", err)
+	}
+
+	var next int64
+	if !sched.Paused {
+		next = sched.NextRunTime
+	}
+	res, err := s.client.Eval(ctx, luaCreateSchedule,
+		[]string{s.schedulesKey(), s.scheduleDueKey()}, sched.Id, bytes, next).Int64()
+	if err != nil {
+		return fmt.Errorf("redis create schedule failed: %w", err)
+	}
+	if res == 0 {
+		return errno.ErrScheduleAlreadyExist
+	}
+	return nil
+}
+
+// GetSchedule 按 ID 查询周期任务。
+// @Return: ID 不存在时返回 errno.ErrScheduleNotFound。
+func (s *Store) GetSchedule(ctx context.Context, id string) (*pb.Schedule, error) {
+	raw, err := s.client.HGet(ctx, s.schedulesKey(), id).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, errno.ErrScheduleNotFound
+		}
+		return nil, fmt.Errorf("redis hget schedule failed: %w", err)
+	}
+	return unmarshalSchedule(raw)
+}
+
+// ListSchedules 读取全部周期任务并按 Topic 过滤。
+// @Note: 周期任务数量通常远小于任务数量，直接 HGETALL 读取。
+func (s *Store) ListSchedules(ctx context.Context, topic string) ([]*pb.Schedule, error) {
+	raws, err := s.client.HGetAll(ctx, s.schedulesKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis hgetall schedules failed: %w", err)
+	}
+
+	schedules := make([]*pb.Schedule, 0, len(raws))
+	for _, raw := range raws {
+		sched, err := unmarshalSchedule(raw)
+		if err != nil {
+			return nil, err
+		}
+		if topic == "" || sched.Topic == topic {
+			schedules = append(schedules, sched)
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].Id < schedules[j].Id })
+	return schedules, nil
+}
+
+// DeleteSchedule 在同一事务内删除周期任务及其触发时间登记。
+// @Return: ID 不存在时返回 errno.ErrScheduleNotFound。
+func (s *Store) DeleteSchedule(ctx context.Context, id string) error {
+	var del *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		del = pipe.HDel(ctx, s.schedulesKey(), id)
+		pipe.ZRem(ctx, s.scheduleDueKey(), id)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("redis delete schedule failed: %w", err)
+	}
+	if del.Val() == 0 {
+		return errno.ErrScheduleNotFound
+	}
+	return nil
+}
+
+// SetSchedulePaused 暂停或恢复周期任务。
+// @Return: 更新后的周期任务；ID 不存在时返回 errno.ErrScheduleNotFound。
+func (s *Store) SetSchedulePaused(ctx context.Context, id string, paused bool, next time.Time) (*pb.Schedule, error) {
+	mode := 0
+	if paused {
+		mode = 1
+	}
+	var nextRun int64
+	if !next.IsZero() {
+		nextRun = next.Unix()
+	}
+
+	raw, err := s.client.Eval(ctx, luaPauseSchedule,
+		[]string{s.schedulesKey(), s.scheduleDueKey()}, id, mode, nextRun).Text()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, errno.ErrScheduleNotFound
+		}
+		return nil, fmt.Errorf("redis pause schedule failed: %w", err)
+	}
+	return unmarshalSchedule(raw)
+}
+
+// DueSchedules 返回触发时间已到的周期任务。
+// @Description 先从触发时间 ZSet 取出到期 ID，再批量读取定义；读取期间被删除的周期任务直接跳过。
+// @Note: now 由调用方传入而不在 Redis 内读取，因为 CronScheduler 计算补发与下一次触发时间须使用同一时刻；
+// CronScheduler 通过 ServerTime 取得该时刻，同样以 Redis 服务端时间为准。
+func (s *Store) DueSchedules(ctx context.Context, now time.Time, limit int64) ([]*pb.Schedule, error) {
+	ids, err := s.client.ZRangeByScore(ctx, s.scheduleDueKey(), &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   fmt.Sprintf("%d", now.Unix()),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis zrangebyscore schedules failed: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	raws, err := s.client.HMGet(ctx, s.schedulesKey(), ids...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis hmget schedules failed: %w", err)
+	}
+	schedules := make([]*pb.Schedule, 0, len(raws))
+	for _, raw := range raws {
+		str, ok := raw.(string)
+		if !ok {
+			continue
+		}
+		sched, err := unmarshalSchedule(str)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, sched)
+	}
+	return schedules, nil
+}
+
+// FireSchedule 通过 luaFireSchedule 原子地投递任务并推进周期任务。
+// @Return: CAS 失败（已被其他副本推进、暂停或删除）时返回 false。
+func (s *Store) FireSchedule(ctx context.Context, f storage.ScheduleFiring) (bool, error) {
+	var next, lastRun int64
+	if !f.Next.IsZero() {
+		next = f.Next.Unix()
+	}
+	if !f.LastRun.IsZero() {
+		lastRun = f.LastRun.Unix()
+	}
+
+	args := []interface{}{
+		f.ScheduleID, f.Expected.Unix(), next, lastRun,
+		f.Topic, s.notifyChannel(f.Topic), s.prefix,
+		int64(s.dedupWindow / time.Second),
+	}
+	for _, task := range f.Tasks {
+		if task.Topic != f.Topic {
+			return false, fmt.Errorf("task %s topic %q does not match schedule topic %q", task.Id, task.Topic, f.Topic)
+		}
+		data, err := encodeTask(task)
+		if err != nil {
+			return false, err
+		}
+		args = append(args, task.Id, data, storage.ExecuteAt(task).UnixMilli())
+	}
+
+	res, err := s.client.Eval(ctx, luaFireSchedule,
+		[]string{s.schedulesKey(), s.scheduleDueKey(), s.pendingKey(f.Topic), s.topicsKey(), s.indexKey(), s.tasksKey(f.Topic)},
+		args...).Int64()
+	if err != nil {
+		return false, fmt.Errorf("redis fire schedule failed: %w", err)
+	}
+	return res >= 0, nil
+}
+
+// unmarshalSchedule 解析周期任务 JSON。
+func unmarshalSchedule(raw string) (*pb.Schedule, error) {
+	var sched pb.Schedule
+	if err := json.Unmarshal([]byte(raw), &sched); err != nil {
+		return nil, fmt.Errorf("unmarshal schedule failed: %w", err)
+	}
+	return &sched, nil
+}
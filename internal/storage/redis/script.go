@@ -1,155 +1,1229 @@
 package redis
 
-// luaPeekAndRem 实现了分布式延时队列的“消费并删除”原子操作。
+// ID 索引说明：
+// 所有脚本共同维护全局 Hash ddq:index，Field 为任务 ID，Value 为 JSON 索引条目：
+//   {"topic": "<topic>", "state": "pending|running|dead"}
+// 任务数据保存在 ddq:<topic>:tasks，ZSet/List 成员即任务 ID，Remove 可以直接按 ID 执行 ZREM/LREM。
+// 升级前写入的条目还带有 data 字段，保存任务在 ZSet/List 中的原始 JSON 成员，脚本优先使用该字段定位成员。
+
+// luaBackoff 根据任务的 retry_policy 计算第 retry_count 次重试前的等待秒数，供 luaNack 与 luaRecover 共用。
+// @Description 以源码前缀的形式拼接到脚本开头，定义 backoff(task, rand) 函数；策略取值与 pb.RetryStrategy 一致。
+// 未设置策略时返回 0（立即重试），保持旧任务的行为不变。
+// @Note: 脚本内不使用 math.random，随机数由调用方通过 ARGV 传入种子、在脚本内用线性同余生成，
+// 保证脚本对相同参数的执行结果确定，满足主从复制的要求。
+const luaBackoff = `
+local function new_rand(seed)
+    local state = tonumber(seed) or 0
+    return function()
+        state = (state * 1103515245 + 12345) % 2147483648
+        return state / 2147483648
+    end
+end
+
+local function backoff(task, rand)
+    local p = task.retry_policy
+    if type(p) ~= 'table' then
+        return 0
+    end
+    local strategy = tonumber(p.strategy) or 0
+    local base = tonumber(p.base_seconds) or 0
+    local cap = tonumber(p.max_seconds) or 0
+    local n = tonumber(task.retry_count) or 1
+    local delay = 0
+
+    if strategy == 1 then
+        delay = base
+    elseif strategy == 2 then
+        delay = base * n
+    elseif strategy == 3 then
+        local mult = tonumber(p.multiplier) or 0
+        if mult <= 1 then
+            mult = 2
+        end
+        delay = base * mult ^ (n - 1)
+    elseif strategy == 4 then
+        local ceil = base * 2 ^ (n - 1)
+        if cap > 0 and ceil > cap then
+            ceil = cap
+        end
+        delay = rand() * ceil
+    elseif strategy == 5 then
+        local prev = tonumber(task.last_backoff) or 0
+        if prev < base then
+            prev = base
+        end
+        delay = base + rand() * (prev * 3 - base)
+    elseif strategy == 6 then
+        local schedule = p.schedule_seconds
+        if type(schedule) == 'table' and #schedule > 0 then
+            delay = tonumber(schedule[math.min(n, #schedule)]) or 0
+        end
+    end
+
+    if cap > 0 and delay > cap then
+        delay = cap
+    end
+    if delay < 0 then
+        delay = 0
+    end
+    return math.floor(delay)
+end
+`
+
+// luaServerTime 以 Redis 服务端时间作为脚本内的当前时间，供判定到期与超时的脚本共用。
+// @Description 以源码前缀的形式拼接到脚本最开头，定义 now_sec（秒）与 now_ms（毫秒）两个变量，
+// 使到期判定不受调用方进程本地时钟偏差的影响。
+// @Note: TIME 是非确定性命令，须在任何写命令之前调用 redis.replicate_commands() 切换为效果复制（只复制脚本产生的写命令），
+// 否则 Redis 5 之前的版本会拒绝其后的写入；Redis 5+ 默认即为效果复制，该调用无副作用。
+const luaServerTime = `
+if redis.replicate_commands then
+    redis.replicate_commands()
+end
+local server_time = redis.call('TIME')
+local now_sec = tonumber(server_time[1])
+local now_ms = now_sec * 1000 + math.floor(tonumber(server_time[2]) / 1000)
+`
+
+// luaTaskCodec 读写 ddq:<topic>:tasks 中的任务数据（编码格式见 codec.go），供涉及任务内容的脚本共用。
+// @Description 以源码前缀的形式拼接到脚本开头，定义以下函数：
+//   - decode_task(raw): 返回调度字段 Table 与 protobuf 正文；v1 (JSON) 任务的正文为 nil，Table 即完整任务。
+//   - encode_task(task, body): decode_task 的逆操作，保持任务原有的格式。
+//   - parse_member(member): 解析 ZSet/List 成员，返回任务 ID；升级前的成员是整段任务 JSON，此时同时返回该 JSON。
+//   - load_task(tasks_key, id, legacy): 读取任务数据；Hash 中不存在时回退到升级前成员或 Running 记录中携带的任务。
+//
+// @Note: 脚本只解析头部的 JSON，不触碰 protobuf 正文，payload 再大也不会增加脚本的解码开销。
+const luaTaskCodec = `
+local function decode_task(raw)
+    if string.byte(raw, 1) == 2 then
+        local sep = string.find(raw, '\n', 2, true)
+        return cjson.decode(string.sub(raw, 2, sep - 1)), string.sub(raw, sep + 1)
+    end
+    return cjson.decode(raw), nil
+end
+
+local function encode_task(task, body)
+    if body then
+        return '\2' .. cjson.encode(task) .. '\n' .. body
+    end
+    return cjson.encode(task)
+end
+
+local function parse_member(member)
+    if string.byte(member, 1) == 123 then
+        local ok, task = pcall(cjson.decode, member)
+        if ok and type(task) == 'table' and type(task.id) == 'string' then
+            return task.id, member
+        end
+    end
+    return member, nil
+end
+
+local function load_task(tasks_key, id, legacy)
+    local raw = redis.call('HGET', tasks_key, id)
+    if raw then
+        return raw
+    end
+    if type(legacy) == 'table' then
+        return cjson.encode(legacy)
+    end
+    return legacy
+end
+`
+
+// luaQuarantine 将无法解码的任务数据原子地转入隔离区，避免单个损坏条目 (Poison Pill) 使整个脚本报错、阻塞同 Topic 的其他任务。
+// @Description 以源码前缀的形式拼接在 luaServerTime 与 luaTaskCodec 之后，定义以下函数：
+//   - try_load(tasks_key, id, legacy): 同 load_task，但以 pcall 解码；成功返回 (task, body, raw)，失败返回 (nil, nil, raw, err)。
+//   - try_decode(raw): 以 pcall 解码 Running 记录、ID 索引等 JSON 对象；成功返回 Table，失败返回 (nil, err)。
+//   - quarantine(quarantine_key, stats_key, topic, id, raw, err, source): 写入隔离 Hash 并累加该 Topic 的隔离计数。
+//   - quarantine_running(k, topic, id, raw, err, source): 撤销执行中任务的 Running 记录、Deadline 条目、任务数据与 ID 索引后转入隔离区；
+//     k 为 {running, deadline, tasks, index, quarantine, stats} 六个 Key 组成的 Table，raw 为 nil 时保存 Tasks Hash 中的数据。
+//
+// 隔离 Hash 的 Field 为任务 ID，Value 为 元信息 JSON + '\n' + 原始字节，格式见 quarantine.go。
+// 除 quarantine_running 外，调用方负责先从 Pending/Ready/Running 等结构中移除该条目，并删除其任务数据与 ID 索引。
+const luaQuarantine = `
+local function try_load(tasks_key, id, legacy)
+    local raw = load_task(tasks_key, id, legacy)
+    if not raw then
+        return nil, nil, '', 'task data not found'
+    end
+    local ok, task, body = pcall(decode_task, raw)
+    if not ok then
+        return nil, nil, raw, tostring(task)
+    end
+    if type(task) ~= 'table' then
+        return nil, nil, raw, 'task data is not an object'
+    end
+    return task, body, raw
+end
+
+local function try_decode(raw)
+    local ok, value = pcall(cjson.decode, raw)
+    if not ok then
+        return nil, tostring(value)
+    end
+    if type(value) ~= 'table' then
+        return nil, 'entry is not an object'
+    end
+    return value
+end
+
+local function quarantine(quarantine_key, stats_key, topic, id, raw, err, source)
+    local meta = cjson.encode({id = id, error = err, source = source, quarantined_at = now_sec})
+    redis.call('HSET', quarantine_key, id, meta .. '\n' .. raw)
+    redis.call('HINCRBY', stats_key, topic, 1)
+end
+
+local function quarantine_running(k, topic, id, raw, err, source)
+    if not raw then
+        raw = redis.call('HGET', k.tasks, id) or ''
+    end
+    redis.call('HDEL', k.running, id)
+    redis.call('ZREM', k.deadline, id)
+    redis.call('HDEL', k.tasks, id)
+    redis.call('HDEL', k.index, id)
+    quarantine(k.quarantine, k.stats, topic, id, raw, err, source)
+end
+`
+
+// luaLeaseDeadline 计算执行中记录的超时时刻，供 luaFetchAndHold、luaExtend 与 luaIndexRunning 共用。
+// @Description 以源码前缀的形式拼接到脚本开头，定义 lease_deadline(entry, task, default_timeout) 函数：
+// 超时时刻 = start + visibility_timeout（任务未设置时使用默认值）。
+// 升级前 Extend 将续租结果写在 Running 记录的 deadline 字段，取两者中较晚的一个。
+const luaLeaseDeadline = `
+local function lease_deadline(entry, task, default_timeout)
+    local timeout = tonumber(task.visibility_timeout) or 0
+    if timeout <= 0 then
+        timeout = tonumber(default_timeout) or 0
+    end
+    local deadline = (tonumber(entry.start) or 0) + timeout
+    local extended = tonumber(entry.deadline) or 0
+    if extended > deadline then
+        deadline = extended
+    end
+    return deadline
+end
+`
+
+// luaAdd 写入待执行任务并注册其所属 Topic，支持“ID 不存在才写入”与“覆盖写入”两种模式。
 // @Logic
-// 1. ZRANGEBYSCORE: 基于当前系统时间戳，在有序集合(ZSet)中检索所有已到期的任务 ID。
-// 2. ZREM: 同步从 ZSet 中剔除上述命中的任务，防止任务被并发节点重复拉取。
-// 3. Return: 将命中的任务 ID 列表返回给调用方进行后续的业务处理。
+// 1. 去重检查: ID 索引或去重标记 (ddq:dedup:<id>) 任一存在即视为重复提交。
+//   - 普通模式: 直接返回 0，不做任何修改。
+//   - 覆盖模式: 旧任务若在 pending/dead 状态则先移除（pending 任务可能已被提升到 Ready ZSet）；若正在执行则返回 -1 拒绝覆盖。
+//     KEYS[5..7, 9] 由调用方按事先读到的旧 Topic (ARGV[8]) 拼接，索引中的 Topic 与之不符（期间被并发改写）时返回 -2，
+//     不做任何修改，由调用方重新读取索引后重试；索引条目无法解码时返回 -3。
+//
+// 2. SADD: 将 Topic 记入注册表，供 Watchdog 遍历各分区。
+// 3. HSET + ZADD: 任务数据写入 Tasks Hash，任务 ID 以毫秒执行时间戳为 Score 写入该 Topic 的 Pending ZSet。
+// 4. HSET: 写入 ID 索引，状态为 pending。
+// 5. SET EX: 写入去重标记，有效期覆盖到执行时间之后的去重窗口，距执行时间的等待时长以 Redis 服务端时间计算。
+// 6. PUBLISH: 通知订阅该 Topic 的消费端重新计算下一次到期时间。
+//
+// @Parameters
+// KEYS[1] - string: Pending ZSet (ddq:<topic>:pending)
+// KEYS[2] - string: Topic 注册表 Set (ddq:topics)
+// KEYS[3] - string: ID 索引 Hash (ddq:index)
+// KEYS[4] - string: 去重标记 (ddq:dedup:<id>)
+// KEYS[5] - string: 旧任务所在 Topic 的 Pending ZSet（仅覆盖模式使用）
+// KEYS[6] - string: 旧任务所在 Topic 的 Dead Letter Queue（仅覆盖模式使用）
+// KEYS[7] - string: 旧任务所在 Topic 的 Ready ZSet（仅覆盖模式使用）
+// KEYS[8] - string: Tasks Hash (ddq:<topic>:tasks)
+// KEYS[9] - string: 旧任务所在 Topic 的 Tasks Hash（仅覆盖模式使用）
+// ARGV[1] - int64 : 执行时间戳 (毫秒，Score)
+// ARGV[2] - string: 编码后的任务数据 (encodeTask)
+// ARGV[3] - string: Topic 名称
+// ARGV[4] - string: TaskID
+// ARGV[5] - int   : 覆盖模式 (1=覆盖, 0=ID 不存在才写入)
+// ARGV[6] - int64 : 去重窗口 (秒)，<=0 表示不写入标记
+// ARGV[7] - string: 就绪通知频道 (ddq:<topic>:notify)
+// ARGV[8] - string: 调用方读到的旧任务所在 Topic（仅覆盖模式使用，索引中不存在时为新任务的 Topic）
+//
+// @Returns
+// number: 1=写入成功, 0=ID 重复未写入, -1=旧任务正在执行无法覆盖, -2=旧任务所在 Topic 已变化, -3=索引条目无法解码
+const luaAdd = luaServerTime + `
+local pending_key = KEYS[1]
+local topics_key = KEYS[2]
+local index_key = KEYS[3]
+local dedup_key = KEYS[4]
+local old_pending_key = KEYS[5]
+local old_dlq_key = KEYS[6]
+local tasks_key = KEYS[8]
+local score = ARGV[1]
+local data = ARGV[2]
+local topic = ARGV[3]
+local id = ARGV[4]
+local replace = tonumber(ARGV[5])
+local window = tonumber(ARGV[6])
+
+-- 1. 去重检查
+local existing = redis.call('HGET', index_key, id)
+if existing or redis.call('EXISTS', dedup_key) == 1 then
+    if replace ~= 1 then
+        return 0
+    end
+    if existing then
+        local ok, entry = pcall(cjson.decode, existing)
+        if not ok or type(entry) ~= 'table' then
+            return -3
+        end
+        if entry.topic ~= ARGV[8] then
+            return -2
+        end
+        if entry.state == 'running' then
+            return -1
+        elseif entry.state == 'pending' then
+            redis.call('ZREM', old_pending_key, entry.data or id)
+            redis.call('ZREM', KEYS[7], entry.data or id)
+        elseif entry.state == 'dead' then
+            redis.call('LREM', old_dlq_key, 1, entry.data or id)
+        end
+        redis.call('HDEL', KEYS[9], id)
+    end
+end
+
+-- 2. 写入
+redis.call('SADD', topics_key, topic)
+redis.call('HSET', index_key, id, cjson.encode({topic = topic, state = 'pending'}))
+redis.call('HSET', tasks_key, id, data)
+redis.call('ZADD', pending_key, score, id)
+if window > 0 then
+    redis.call('SET', dedup_key, topic, 'EX', window + math.max(math.floor(tonumber(score) / 1000) - now_sec, 0))
+end
+redis.call('PUBLISH', ARGV[7], topic)
+return 1
+`
+
+// luaFetchAndHold 实现了分布式延时队列的“消费并删除”原子操作。
+// @Logic
+// 1. Promote: ZRANGEBYSCORE 检索 Pending ZSet 中已到期的任务 ID，移入 Ready ZSet，
+// Score = execute_time - priority * aging（毫秒），即每多等待 aging 相当于优先级 +1，低优先级任务不会被无限期饿死。
+// 优先级只需解析任务数据的头部，不解码 payload。
+// 升级前写入的秒级 Score (小于 legacy_score_limit) 先换算为毫秒：已到期的直接提升，未到期的按毫秒 Score 写回 Pending。
+// 升级前以整段 JSON 为成员的任务在提升时把 JSON 转存到 Tasks Hash，成员换成任务 ID。
+// 2. ZRANGE + ZREM: 按有效优先级从 Ready ZSet 取出前 limit 个任务并剔除，防止任务被并发节点重复拉取。
+// 未到期的任务始终留在 Pending ZSet，优先级再高也不会提前下发。
+// 3. Lease: 为每次投递生成唯一的租约令牌并写入 Running 记录，Ack/Nack 须携带该令牌。
+// 4. Deadline: 将任务 ID 按超时时刻写入 Deadline ZSet，供 Watchdog 只扫描已超时的条目。
+// 5. Return: 将命中的任务 ID、租约令牌与任务数据返回给调用方进行后续的业务处理。
+// 6. Quarantine: 提升或拉取时无法读取/解码的条目转入隔离区 (luaQuarantine)，不会中断本次拉取。
 //
 // @Constraints
 // - 原子性保障：通过 Lua 脚本执行，确保读取与删除之间不被其他命令插入。
-// - 性能限制：调用方需合理控制 ARGV[2] (limit)，避免大批量删除导致 Redis 阻塞。
+// - 性能限制：调用方需合理控制 ARGV[1] (limit)，避免大批量删除导致 Redis 阻塞；
+// 单次提升的到期任务数受 ARGV[6] 限制，积压时按到期先后分多次提升。
+// - 时钟：是否到期与任务开始执行时间均以 Redis 服务端时间 (luaServerTime) 为准。
 //
 // @Parameters
-// KEYS[1] - string: 延时队列的 ZSet 键名 (e.g., "dq_tasks_zset")
-// ARGV[1] - int64 : 当前 Unix 时间戳 (Score)，用于判定任务是否到期
-// ARGV[2] - int   : 单词拉取的最大任务数量 (Limit)，用于流量削峰
+// KEYS[1] - string: 该 Topic 的 Pending ZSet (e.g., "ddq:order_cancel:pending")
+// KEYS[2] - string: 该 Topic 的 Running Hash (e.g., "ddq:order_cancel:running")
+// KEYS[3] - string: ID 索引 Hash (ddq:index)
+// KEYS[4] - string: 该 Topic 的 Deadline ZSet (e.g., "ddq:order_cancel:deadlines")
+// KEYS[5] - string: 该 Topic 的 Ready ZSet (e.g., "ddq:order_cancel:ready")
+// KEYS[6] - string: 该 Topic 的 Tasks Hash (e.g., "ddq:order_cancel:tasks")
+// KEYS[7] - string: 该 Topic 的隔离 Hash (e.g., "ddq:order_cancel:quarantine")
+// KEYS[8] - string: 隔离计数 Hash (ddq:quarantine:stats)
+// ARGV[1] - int   : 单词拉取的最大任务数量 (Limit)，用于流量削峰
+// ARGV[2] - string: Topic 名称
+// ARGV[3] - string: 本次调用的随机前缀，与序号拼接为每个任务的租约令牌
+// ARGV[4] - int64 : 默认可见性超时 (秒)，任务未设置 visibility_timeout 时使用
+// ARGV[5] - int64 : 优先级老化周期 (毫秒)
+// ARGV[6] - int   : 单次提升到 Ready ZSet 的最大任务数
 //
 // @Returns
-// table: 返回包含任务 Payload (ID) 的数组；若无到期任务则返回空 Table。
-const luaFetchAndHold = `
+// table: 按 {id, lease, task_data, id, lease, task_data, ...} 平铺的数组；若无到期任务则返回空 Table。
+const luaFetchAndHold = luaServerTime + luaTaskCodec + luaQuarantine + luaLeaseDeadline + `
 local pending_key = KEYS[1]
 local running_key = KEYS[2]
-local max_score = ARGV[1]
-local limit = ARGV[2]
-local now = ARGV[3]
-
--- 1. 检索所有 Score 小于等于当前时间戳的任务
-local raw_tasks = redis.call('ZRANGEBYSCORE', pending_key, 0, max_score, 'LIMIT', 0, limit)
-
-if #raw_tasks > 0 then
-    for i, raw_json in ipairs(raw_tasks) do
-        -- 2. 解析 TaskID (Redis 内置 cjson 库)
-        -- 注意：这里假设 raw_json 是合法的 JSON 字符串
-        local task = cjson.decode(raw_json)
-        local id = task.id
-
-        -- 3. 从 Pending 移除
-        redis.call('ZREM', pending_key, raw_json)
-
-        -- 4. 构造 Running 记录 (包装一下，记录开始时间)
-        -- 格式: {"start": 1700000000, "task": {...}}
-        local running_data = cjson.encode({start = tonumber(now), task = task})
-        
-        -- 5. 写入 Running Hash
-        redis.call('HSET', running_key, id, running_data)
-    end
-    return raw_tasks
-else
-    return {}
+local index_key = KEYS[3]
+local deadline_key = KEYS[4]
+local ready_key = KEYS[5]
+local tasks_key = KEYS[6]
+local quarantine_key = KEYS[7]
+local stats_key = KEYS[8]
+local limit = tonumber(ARGV[1])
+local topic = ARGV[2]
+local nonce = ARGV[3]
+local aging = tonumber(ARGV[5])
+-- 小于该值的 Score 是升级前写入的秒级时间戳 (1e11 秒约为公元 5138 年，1e11 毫秒约为 1973 年)
+local legacy_score_limit = 100000000000
+
+-- 1. 将 Score 小于等于当前时间戳的任务提升到 Ready ZSet，按有效优先级排序
+local due = redis.call('ZRANGEBYSCORE', pending_key, 0, now_ms, 'WITHSCORES', 'LIMIT', 0, ARGV[6])
+for i = 1, #due, 2 do
+    local member = due[i]
+    local score = tonumber(due[i+1])
+    if score < legacy_score_limit then
+        score = score * 1000
+    end
+    if score > now_ms then
+        -- 未到期的秒级任务：换算为毫秒 Score 后留在 Pending
+        redis.call('ZADD', pending_key, score, member)
+    else
+        local id, legacy = parse_member(member)
+        redis.call('ZREM', pending_key, member)
+        local task, _, data, err = try_load(tasks_key, id, legacy)
+        if task then
+            if legacy then
+                redis.call('HSET', tasks_key, id, data)
+            end
+            local priority = tonumber(task.priority) or 0
+            redis.call('ZADD', ready_key, score - priority * aging, id)
+        else
+            redis.call('HDEL', tasks_key, id)
+            redis.call('HDEL', index_key, id)
+            quarantine(quarantine_key, stats_key, topic, id, data, err, 'fetch')
+        end
+    end
 end
+
+-- 2. 取出有效优先级最高的 limit 个任务
+local members = redis.call('ZRANGE', ready_key, 0, limit - 1)
+
+local result = {}
+for i, member in ipairs(members) do
+    -- 3. 从 Ready 移除 (升级前提升的任务成员仍是整段 JSON)
+    redis.call('ZREM', ready_key, member)
+    local id, legacy = parse_member(member)
+    local task, _, data, err = try_load(tasks_key, id, legacy)
+    if not task then
+        redis.call('HDEL', tasks_key, id)
+        redis.call('HDEL', index_key, id)
+        quarantine(quarantine_key, stats_key, topic, id, data, err, 'fetch')
+    else
+        if legacy then
+            redis.call('HSET', tasks_key, id, data)
+        end
+
+        -- 4. 构造 Running 记录，记录开始时间与本次投递的租约令牌
+        -- 格式: {"start": 1700000000, "lease": "<nonce>-1"}
+        local lease = nonce .. '-' .. i
+        local entry = {start = now_sec, lease = lease}
+
+        -- 5. 写入 Running Hash 与 Deadline ZSet，并更新索引状态
+        redis.call('HSET', running_key, id, cjson.encode(entry))
+        redis.call('ZADD', deadline_key, lease_deadline(entry, task, ARGV[4]), id)
+        redis.call('HSET', index_key, id, cjson.encode({topic = topic, state = 'running'}))
+
+        result[#result + 1] = id
+        result[#result + 1] = lease
+        result[#result + 1] = data
+    end
+end
+return result
+`
+
+// luaQuarantineRunning 隔离一个刚被 FetchAndHold 取出、但调用方无法解码的执行中任务。
+// @Description Lua 只解析任务数据的头部，protobuf 正文损坏要到 Go 侧解码时才能发现；
+// 此时任务已进入 Running，需原子地撤销其执行中状态再转入隔离区，而不是等 Watchdog 超时后重试直至死信。
+// 仅当 Running 记录中的租约令牌与 ARGV[2] 一致时生效；Running 记录本身无法解码时无从校验，同样转入隔离区。
+//
+// KEYS[1]: Running Hash (ddq:<topic>:running)
+// KEYS[2]: Deadline ZSet (ddq:<topic>:deadlines)
+// KEYS[3]: Tasks Hash (ddq:<topic>:tasks)
+// KEYS[4]: ID 索引 Hash (ddq:index)
+// KEYS[5]: 隔离 Hash (ddq:<topic>:quarantine)
+// KEYS[6]: 隔离计数 Hash (ddq:quarantine:stats)
+// ARGV[1]: 任务 ID
+// ARGV[2]: 本次投递的租约令牌
+// ARGV[3]: Topic 名称
+// ARGV[4]: 解码失败原因
+//
+// @Returns
+// number: 1 表示已隔离；0 表示任务已不在执行中或租约不匹配。
+const luaQuarantineRunning = luaServerTime + luaTaskCodec + luaQuarantine + `
+local k = {running = KEYS[1], deadline = KEYS[2], tasks = KEYS[3], index = KEYS[4], quarantine = KEYS[5], stats = KEYS[6]}
+local id = ARGV[1]
+
+local raw = redis.call('HGET', k.running, id)
+if not raw then
+    return 0
+end
+local entry = try_decode(raw)
+if entry and entry.lease ~= ARGV[2] then
+    return 0
+end
+
+quarantine_running(k, ARGV[3], id, nil, ARGV[4], 'fetch')
+return 1
 `
 
 // luaAck 确认任务完成
-// KEYS[1]: Running Hash (ddq:running)
+// @Logic 校验租约令牌后移除 Running 记录、Deadline 条目、任务数据与 ID 索引，并将去重标记的有效期刷新为完整的去重窗口，
+// 使任务完成后客户端的迟到重试仍能被吸收。
+// @Fencing: 令牌不匹配说明任务已超时被重新投递给其他 Worker，拒绝本次确认以免删除新的执行记录。
+// 升级前写入、不含 lease 字段的 Running 记录不做校验。
+// 确认只需 Running 记录，不解码任务数据；Running 记录无法解码时无从校验租约，任务转入隔离区 (luaQuarantine)。
+//
+// KEYS[1]: Running Hash (ddq:<topic>:running)
+// KEYS[2]: ID 索引 Hash (ddq:index)
+// KEYS[3]: 去重标记 (ddq:dedup:<id>)
+// KEYS[4]: Deadline ZSet (ddq:<topic>:deadlines)
+// KEYS[5]: Tasks Hash (ddq:<topic>:tasks)
+// KEYS[6]: 隔离 Hash (ddq:<topic>:quarantine)
+// KEYS[7]: 隔离计数 Hash (ddq:quarantine:stats)
 // ARGV[1]: TaskID
-const luaAck = `
-return redis.call('HDEL', KEYS[1], ARGV[1])
+// ARGV[2]: 去重窗口 (秒)，<=0 表示不保留标记
+// ARGV[3]: Topic 名称
+// ARGV[4]: 租约令牌
+//
+// @Returns 1: 成功; 0: 任务不在执行中（含已被隔离）; -1: 租约令牌不匹配
+const luaAck = luaServerTime + luaTaskCodec + luaQuarantine + `
+local raw = redis.call('HGET', KEYS[1], ARGV[1])
+if not raw then
+    return 0
+end
+local entry, err = try_decode(raw)
+if not entry then
+    local k = {running = KEYS[1], deadline = KEYS[4], tasks = KEYS[5], index = KEYS[2], quarantine = KEYS[6], stats = KEYS[7]}
+    quarantine_running(k, ARGV[3], ARGV[1], nil, err, 'ack')
+    return 0
+end
+if type(entry.lease) == 'string' and entry.lease ~= ARGV[4] then
+    return -1
+end
+
+redis.call('HDEL', KEYS[1], ARGV[1])
+redis.call('ZREM', KEYS[4], ARGV[1])
+redis.call('HDEL', KEYS[5], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+if tonumber(ARGV[2]) > 0 then
+    redis.call('SET', KEYS[3], ARGV[3], 'EX', ARGV[2])
+end
+return 1
 `
 
 // luaNack 任务失败重试
 // @Logic
-// 1. 从 Running 移除
-// 2. 判断是否超过最大重试次数
-// 3. 没超过 -> 更新 retry_count -> ZADD 回 Pending
-// 4. 超过了 -> LPUSH 到 DLQ (死信队列)
+// 1. 读取服务端保存的任务数据（不信任客户端回传的任务内容），并校验 Running 记录中的租约令牌（规则同 luaAck）
+// 2. 更新 retry_count 与 last_error，并从 Running 与 Deadline ZSet 移除
+// 3. 没超过最大重试次数 -> 任务 ID ZADD 回 Pending，Score 为重试时间 (毫秒)：
+// 调用方显式指定了等待时长时使用该值，否则按任务的 retry_policy 计算 (luaBackoff)
+// 4. 超过了 -> 记录 dead_reason/dead_at 后将任务 ID LPUSH 到 DLQ (死信队列)
+// 5. Running 记录或任务数据无法解码时无法重试，撤销执行中状态后转入隔离区 (luaQuarantine)
 //
 // @Parameters
-// KEYS[1]: Running Hash (ddq:running)
-// KEYS[2]: Pending ZSet (ddq:tasks)
-// KEYS[3]: Dead Letter Queue (ddq:dlq)
+// KEYS[1]: Running Hash (ddq:<topic>:running)
+// KEYS[2]: Pending ZSet (ddq:<topic>:pending)
+// KEYS[3]: Dead Letter Queue (ddq:<topic>:dlq)
+// KEYS[4]: ID 索引 Hash (ddq:index)
+// KEYS[5]: Deadline ZSet (ddq:<topic>:deadlines)
+// KEYS[6]: Tasks Hash (ddq:<topic>:tasks)
+// KEYS[7]: 隔离 Hash (ddq:<topic>:quarantine)
+// KEYS[8]: 隔离计数 Hash (ddq:quarantine:stats)
 // ARGV[1]: TaskID
-// ARGV[2]: JSON Payload (包含更新后的 retry_count 的完整 task 结构)
-// ARGV[3]: Next Execute Time (重试的执行时间，通常是现在)
-// ARGV[4]: Is Dead (1=进死信, 0=重试)
-const luaNack = `
+// ARGV[2]: 失败原因 (为空则保留原有 last_error)
+// ARGV[3]: Topic 名称
+// ARGV[4]: 就绪通知频道 (ddq:<topic>:notify)
+// ARGV[5]: 租约令牌
+// ARGV[6]: 显式重试等待毫秒数，<=0 表示按 retry_policy 计算
+// ARGV[7]: 随机数种子 (jitter 策略使用)
+// ARGV[8]: 默认最大重试次数，任务未设置 max_retries (<=0) 时使用，规则同 luaRecover
+//
+// @Returns
+// number: 0=任务不在执行中（含已被隔离）, 1=已重新入队, 2=已进入死信队列, -1=租约令牌不匹配
+const luaNack = luaServerTime + luaTaskCodec + luaQuarantine + luaBackoff + `
 local running_key = KEYS[1]
 local pending_key = KEYS[2]
 local dlq_key = KEYS[3]
+local index_key = KEYS[4]
+local tasks_key = KEYS[6]
 
 local id = ARGV[1]
-local task_json = ARGV[2]
-local score = ARGV[3]
-local is_dead = tonumber(ARGV[4])
+local reason = ARGV[2]
+local topic = ARGV[3]
+local explicit_delay = tonumber(ARGV[6])
+local k = {running = running_key, deadline = KEYS[5], tasks = tasks_key, index = index_key, quarantine = KEYS[7], stats = KEYS[8]}
 
--- 1. 无论如何，先从正在运行列表移除
+-- 1. 读取执行中记录
+local raw = redis.call('HGET', running_key, id)
+if not raw then
+    return 0
+end
+local entry, entry_err = try_decode(raw)
+if not entry then
+    quarantine_running(k, topic, id, nil, entry_err, 'nack')
+    return 0
+end
+if type(entry.lease) == 'string' and entry.lease ~= ARGV[5] then
+    return -1
+end
+-- 升级前的 Running 记录直接携带任务快照
+local task, body, data, err = try_load(tasks_key, id, entry.task)
+if not task then
+    -- 5. 任务数据损坏，转入隔离区
+    quarantine_running(k, topic, id, data, err, 'nack')
+    return 0
+end
+
+-- 2. 更新元数据并从正在运行列表移除
+task.retry_count = (task.retry_count or 0) + 1
+if reason ~= '' then
+    task.last_error = reason
+end
 redis.call('HDEL', running_key, id)
+redis.call('ZREM', KEYS[5], id)
 
-if is_dead == 1 then
-    -- 2. 超过重试次数，进死信队列
-    redis.call('LPUSH', dlq_key, task_json)
-else
-    -- 3. 没超过，放回等待队列重试
-    redis.call('ZADD', pending_key, score, task_json)
+local max_retries = tonumber(task.max_retries) or 0
+if max_retries <= 0 then
+    max_retries = tonumber(ARGV[8]) or 0
+end
+if task.retry_count >= max_retries then
+    -- 3. 超过重试次数，记录死信原因与时间后进死信队列
+    task.dead_reason = 'retries_exhausted'
+    task.dead_at = now_sec
+    redis.call('HSET', tasks_key, id, encode_task(task, body))
+    redis.call('LPUSH', dlq_key, id)
+    redis.call('HSET', index_key, id, cjson.encode({topic = topic, state = 'dead'}))
+    return 2
+end
+
+-- 4. 没超过，按退避策略计算等待时长后放回等待队列重试
+local delay_ms = explicit_delay
+if delay_ms <= 0 then
+    delay_ms = backoff(task, new_rand(ARGV[7])) * 1000
+end
+task.last_backoff = math.floor(delay_ms / 1000)
+redis.call('HSET', tasks_key, id, encode_task(task, body))
+redis.call('ZADD', pending_key, now_ms + delay_ms, id)
+redis.call('HSET', index_key, id, cjson.encode({topic = topic, state = 'pending'}))
+redis.call('PUBLISH', ARGV[4], topic)
+return 1
+`
+
+// luaExtend 延长执行中任务的可见性超时（心跳续租）
+// @Logic 校验租约令牌后，将任务在 Deadline ZSet 中的超时时刻推迟到 now + extra；只会延长不会缩短。
+// 尚未建立 Deadline 条目的升级前记录先按 lease_deadline 补齐，再与新的截止时间比较。
+// 已有 Deadline 条目时不解码任务数据；Running 记录或补齐时读取的任务数据无法解码时，任务转入隔离区 (luaQuarantine)。
+//
+// KEYS[1]: Running Hash (ddq:<topic>:running)
+// KEYS[2]: Deadline ZSet (ddq:<topic>:deadlines)
+// KEYS[3]: Tasks Hash (ddq:<topic>:tasks)
+// KEYS[4]: ID 索引 Hash (ddq:index)
+// KEYS[5]: 隔离 Hash (ddq:<topic>:quarantine)
+// KEYS[6]: 隔离计数 Hash (ddq:quarantine:stats)
+// ARGV[1]: TaskID
+// ARGV[2]: 租约令牌
+// ARGV[3]: 延长的秒数 (extra，调用方已向上取整)，新的截止时间以 Redis 服务端时间为基准
+// ARGV[4]: 默认可见性超时 (秒)
+// ARGV[5]: Topic 名称
+//
+// @Returns
+// number: 1=成功, 0=任务不在执行中（含已被隔离）, -1=租约令牌不匹配
+const luaExtend = luaServerTime + luaTaskCodec + luaQuarantine + luaLeaseDeadline + `
+local k = {running = KEYS[1], deadline = KEYS[2], tasks = KEYS[3], index = KEYS[4], quarantine = KEYS[5], stats = KEYS[6]}
+local raw = redis.call('HGET', KEYS[1], ARGV[1])
+if not raw then
+    return 0
+end
+local entry, entry_err = try_decode(raw)
+if not entry then
+    quarantine_running(k, ARGV[5], ARGV[1], nil, entry_err, 'extend')
+    return 0
+end
+if type(entry.lease) == 'string' and entry.lease ~= ARGV[2] then
+    return -1
 end
 
+local current = tonumber(redis.call('ZSCORE', KEYS[2], ARGV[1]))
+if not current then
+    local task, _, data, err = try_load(KEYS[3], ARGV[1], entry.task)
+    if not task then
+        quarantine_running(k, ARGV[5], ARGV[1], data, err, 'extend')
+        return 0
+    end
+    current = lease_deadline(entry, task, ARGV[4])
+end
+local deadline = now_sec + tonumber(ARGV[3])
+if current > deadline then
+    deadline = current
+end
+redis.call('ZADD', KEYS[2], deadline, ARGV[1])
 return 1
 `
 
-// luaRecover 扫描并恢复超时任务
+// luaRecover 恢复单个 Topic 下一批已超时的执行中任务
 // 逻辑：
-// 1. 获取所有 Running 任务 (HGETALL)
-// 2. 遍历检查：如果 (now - start_time) > visibility_timeout
-// 3. 执行 NACK 逻辑 (retry++ -> ZADD/LPUSH -> HDEL)
-//
-// KEYS[1]: Running Hash
-// KEYS[2]: Pending ZSet
-// KEYS[3]: Dead Letter Queue
-// ARGV[1]: Now Timestamp
-// ARGV[2]: Visibility Timeout
-// ARGV[3]: Max Retries
-const luaRecover = `
+// 1. ZRANGEBYSCORE 从 Deadline ZSet 中取出至多 batch 个超时时刻早于 now 的任务 ID，只触及已超时的条目，
+// 耗时与本批数量成正比，而不是与执行中任务总数成正比
+// 2. 逐个 ZREM Deadline 条目并读取 Running 记录；记录已不存在（已被 Ack/Nack）的残留条目直接丢弃
+// 3. 执行 NACK 逻辑 (retry++ -> ZADD/LPUSH -> HDEL)，重新入队的等待时长与 luaNack 一致按 retry_policy 计算
+// 4. 无法解码 Running 记录或无法读取/解码任务数据的条目转入隔离区 (luaQuarantine)，不影响同批其他任务的回收
+// @Note: 超时时刻已在 FetchAndHold/Extend 时按任务自身的 visibility_timeout 计算；
+// max_retries 优先使用任务自身的取值，未设置 (<=0) 时才使用 ARGV 中的全局默认值。
+//
+// KEYS[1]: Running Hash (ddq:<topic>:running)
+// KEYS[2]: Pending ZSet (ddq:<topic>:pending)
+// KEYS[3]: Dead Letter Queue (ddq:<topic>:dlq)
+// KEYS[4]: ID 索引 Hash (ddq:index)
+// KEYS[5]: Deadline ZSet (ddq:<topic>:deadlines)
+// KEYS[6]: Tasks Hash (ddq:<topic>:tasks)
+// KEYS[7]: 隔离 Hash (ddq:<topic>:quarantine)
+// KEYS[8]: 隔离计数 Hash (ddq:quarantine:stats)
+// ARGV[1]: 单批次最大条目数
+// ARGV[2]: 默认 Max Retries
+// ARGV[3]: Topic 名称
+// ARGV[4]: 就绪通知频道 (ddq:<topic>:notify)，有任务重新入队时发布一次
+// ARGV[5]: 随机数种子 (jitter 策略使用)
+//
+// @Returns
+// table: {重新入队数, 进入死信数, 本批扫描的 Deadline 条目数, 隔离数}；扫描数等于 batch 说明可能还有剩余。
+const luaRecover = luaServerTime + luaTaskCodec + luaQuarantine + luaBackoff + `
 local running_key = KEYS[1]
 local pending_key = KEYS[2]
 local dlq_key = KEYS[3]
-local now = tonumber(ARGV[1])
-local timeout = tonumber(ARGV[2])
-local max_retries = tonumber(ARGV[3])
-
--- 1. 获取所有正在运行的任务 (注意：生产环境若 Hash 巨大，应用 HSCAN 代替)
-local all_running = redis.call('HGETALL', running_key)
-
--- HGETALL 返回的是 [key1, val1, key2, val2...] 的数组
-for i = 1, #all_running, 2 do
-    local id = all_running[i]
-    local val_str = all_running[i+1]
-    
-    local entry = cjson.decode(val_str)
-    local start_time = tonumber(entry.start)
-    local task = entry.task
-
-    -- 2. 检查是否超时
-    if (now - start_time) > timeout then
-        -- 3. 超时了！执行恢复逻辑
-        
-        -- a. 更新元数据
-        task.retry_count = (task.retry_count or 0) + 1
-        -- 为了存储，重新 encode task
-        local task_json = cjson.encode(task)
-
-        -- b. 从 Running 移除
+local index_key = KEYS[4]
+local deadline_key = KEYS[5]
+local tasks_key = KEYS[6]
+local batch = tonumber(ARGV[1])
+local default_max_retries = tonumber(ARGV[2])
+local topic = ARGV[3]
+local requeued = 0
+local dead = 0
+local quarantined = 0
+local rand = new_rand(ARGV[5])
+
+-- 1. 只取超时时刻严格早于 now 的条目 (与旧版 now > deadline 的判定一致)
+local ids = redis.call('ZRANGEBYSCORE', deadline_key, '-inf', '(' .. now_sec, 'LIMIT', 0, batch)
+
+for _, id in ipairs(ids) do
+    redis.call('ZREM', deadline_key, id)
+
+    -- 2. 读取执行中记录，已完成的任务只会残留 Deadline 条目
+    local raw = redis.call('HGET', running_key, id)
+    if raw then
+        local entry, err = try_decode(raw)
+        local task, body, data
+        if entry then
+            task, body, data, err = try_load(tasks_key, id, entry.task)
+        else
+            data = redis.call('HGET', tasks_key, id) or ''
+        end
         redis.call('HDEL', running_key, id)
 
-        -- c. 判断去向
-        if task.retry_count >= max_retries then
-            -- 进死信
-            redis.call('LPUSH', dlq_key, task_json)
+        if not task then
+            -- 4. 任务数据损坏，无法重试，转入隔离区
+            redis.call('HDEL', tasks_key, id)
+            redis.call('HDEL', index_key, id)
+            quarantine(KEYS[7], KEYS[8], topic, id, data, err, 'recover')
+            quarantined = quarantined + 1
+        else
+            local max_retries = tonumber(task.max_retries) or 0
+            if max_retries <= 0 then
+                max_retries = default_max_retries
+            end
+
+            -- 3. 超时了！执行恢复逻辑
+            task.retry_count = (task.retry_count or 0) + 1
+
+            if task.retry_count >= max_retries then
+                -- 进死信，记录死信原因与时间
+                task.dead_reason = 'visibility_timeout'
+                task.dead_at = now_sec
+                redis.call('HSET', tasks_key, id, encode_task(task, body))
+                redis.call('LPUSH', dlq_key, id)
+                redis.call('HSET', index_key, id, cjson.encode({topic = topic, state = 'dead'}))
+                dead = dead + 1
+            else
+                -- 重新进队列，按任务的退避策略延后 (未设置策略时立即重试)
+                local delay = backoff(task, rand)
+                task.last_backoff = delay
+                redis.call('HSET', tasks_key, id, encode_task(task, body))
+                redis.call('ZADD', pending_key, now_ms + delay * 1000, id)
+                redis.call('HSET', index_key, id, cjson.encode({topic = topic, state = 'pending'}))
+                requeued = requeued + 1
+            end
+        end
+    end
+end
+
+if requeued > 0 then
+    redis.call('PUBLISH', ARGV[4], topic)
+end
+return {requeued, dead, #ids, quarantined}
+`
+
+// luaIndexRunning 为尚未建立 Deadline 条目的执行中任务补齐超时索引。
+// @Description 升级前（或经 MigrateLegacy 迁入）的 Running 记录没有对应的 Deadline 条目，Watchdog 不会扫描到它们。
+// 调用方以 HSCAN 分批取出 Running Hash 的 Field 后调用本脚本，已有条目的任务保持不变 (幂等)。
+//
+// KEYS[1]: Running Hash (ddq:<topic>:running)
+// KEYS[2]: Deadline ZSet (ddq:<topic>:deadlines)
+// KEYS[3]: Tasks Hash (ddq:<topic>:tasks)
+// ARGV[1]: 默认可见性超时 (秒)
+// ARGV[2...]: 任务 ID 列表
+//
+// @Returns number: 本次补齐的条目数
+const luaIndexRunning = luaTaskCodec + luaLeaseDeadline + `
+local added = 0
+for i = 2, #ARGV do
+    local id = ARGV[i]
+    if not redis.call('ZSCORE', KEYS[2], id) then
+        local raw = redis.call('HGET', KEYS[1], id)
+        if raw then
+            local ok, entry = pcall(cjson.decode, raw)
+            local data = ok and type(entry) == 'table' and load_task(KEYS[3], id, entry.task)
+            if data then
+                local decoded, task = pcall(decode_task, data)
+                if decoded and type(task) == 'table' then
+                    redis.call('ZADD', KEYS[2], lease_deadline(entry, task, ARGV[1]), id)
+                    added = added + 1
+                end
+            end
+        end
+    end
+end
+return added
+`
+
+// luaMigrateLegacy 将旧版全局 Key 中的数据按 Topic 搬迁到分区 Key（单批次）。
+// @Logic
+// 1. Pending: ZRANGE 取出一批成员，按 task.topic 写入 <prefix>:<topic>:pending 并保留原 Score。
+// 2. DLQ: 从尾部 RPOP（最旧的条目）后 LPUSH 到新 List，保持原有先后顺序。
+// 3. Running: HSCAN 取出一批条目写入 <prefix>:<topic>:running，start 时间原样保留。
+// 4. 每迁移一个条目都会 SADD Topic 注册表、写入 ID 索引，并从旧 Key 中删除。
+// 5. 无法解析的条目原样转存到 Unparsed List，避免阻塞后续批次，留待人工处理。
+//
+// @Constraints
+// - 目标 Key 由脚本根据 Topic 动态拼接，仅适用于单实例 Redis，迁移期间应停止读写流量。
+// - 迁移后的条目仍是整段 JSON 成员（v1 格式），由 luaTaskCodec 透明读取，并在下一次状态变化时转存到 Tasks Hash。
+//
+// @Parameters
+// KEYS[1]: 旧 Pending ZSet (ddq:tasks)
+// KEYS[2]: 旧 Running Hash (ddq:running)
+// KEYS[3]: 旧 Dead Letter Queue (ddq:dlq)
+// KEYS[4]: Topic 注册表 Set (ddq:topics)
+// KEYS[5]: 无法解析条目的转存 List (ddq:legacy:unparsed)
+// KEYS[6]: ID 索引 Hash (ddq:index)
+// ARGV[1]: Key 前缀 (ddq)
+// ARGV[2]: 单批次最大条目数
+// ARGV[3]: 缺少 topic 字段时使用的默认 Topic
+//
+// @Returns
+// number: 本批次处理的条目数；返回 0 表示迁移完成。
+const luaMigrateLegacy = `
+local old_pending = KEYS[1]
+local old_running = KEYS[2]
+local old_dlq = KEYS[3]
+local topics_key = KEYS[4]
+local unparsed_key = KEYS[5]
+local index_key = KEYS[6]
+local prefix = ARGV[1]
+local batch = tonumber(ARGV[2])
+local default_topic = ARGV[3]
+
+local function topic_of(task)
+    if type(task.topic) == 'string' and task.topic ~= '' then
+        return task.topic
+    end
+    return default_topic
+end
+
+local function index(id, topic, state, data)
+    if type(id) == 'string' and id ~= '' then
+        redis.call('HSET', index_key, id, cjson.encode({topic = topic, state = state, data = data}))
+    end
+end
+
+local moved = 0
+
+-- 1. Pending ZSet
+local members = redis.call('ZRANGE', old_pending, 0, batch - 1, 'WITHSCORES')
+for i = 1, #members, 2 do
+    local raw = members[i]
+    local ok, task = pcall(cjson.decode, raw)
+    if ok and type(task) == 'table' then
+        local topic = topic_of(task)
+        redis.call('ZADD', prefix .. ':' .. topic .. ':pending', members[i+1], raw)
+        redis.call('SADD', topics_key, topic)
+        index(task.id, topic, 'pending', raw)
+    else
+        redis.call('RPUSH', unparsed_key, raw)
+    end
+    redis.call('ZREM', old_pending, raw)
+    moved = moved + 1
+end
+
+-- 2. Dead Letter Queue
+while moved < batch do
+    local raw = redis.call('RPOP', old_dlq)
+    if not raw then
+        break
+    end
+    local ok, task = pcall(cjson.decode, raw)
+    if ok and type(task) == 'table' then
+        local topic = topic_of(task)
+        redis.call('LPUSH', prefix .. ':' .. topic .. ':dlq', raw)
+        redis.call('SADD', topics_key, topic)
+        index(task.id, topic, 'dead', raw)
+    else
+        redis.call('RPUSH', unparsed_key, raw)
+    end
+    moved = moved + 1
+end
+
+-- 3. Running Hash
+if moved < batch then
+    local scan = redis.call('HSCAN', old_running, 0, 'COUNT', batch - moved)
+    local entries = scan[2]
+    for i = 1, #entries, 2 do
+        local id = entries[i]
+        local raw = entries[i+1]
+        local ok, entry = pcall(cjson.decode, raw)
+        if ok and type(entry) == 'table' and type(entry.task) == 'table' then
+            local topic = topic_of(entry.task)
+            redis.call('HSET', prefix .. ':' .. topic .. ':running', id, raw)
+            redis.call('SADD', topics_key, topic)
+            index(id, topic, 'running', cjson.encode(entry.task))
         else
-            -- 重新进队列 (立即重试，Score = Now)
-            redis.call('ZADD', pending_key, now, task_json)
+            redis.call('RPUSH', unparsed_key, raw)
         end
+        redis.call('HDEL', old_running, id)
+        moved = moved + 1
     end
 end
 
+return moved
+`
+
+// luaRemove 按 ID 撤销任务，行为取决于索引中记录的当前状态。
+// @Logic
+// 1. pending: ZREM 移出等待队列（含已提升到 Ready ZSet 的任务）并删除任务数据与索引，任务不会再被下发。
+// 2. dead: LREM 移出死信队列并删除任务数据与索引（相当于清理该死信）。
+// 3. running: 任务已被 Worker 持有，无法保证撤销生效，拒绝删除。
+// 4. 删除成功时同时清除去重标记，允许客户端以相同 ID 重新提交。
+// 5. KEYS 由调用方按事先读到的 Topic (ARGV[2]) 拼接，索引中的 Topic 与之不符（期间被覆盖写入到其他 Topic）时返回 -2，
+// 由调用方重新读取索引后重试；索引条目无法解码时无从判断任务所处的结构，返回 -3，均不做任何修改。
+//
+// @Parameters
+// KEYS[1]: Pending ZSet (ddq:<topic>:pending)
+// KEYS[2]: Dead Letter Queue (ddq:<topic>:dlq)
+// KEYS[3]: ID 索引 Hash (ddq:index)
+// KEYS[4]: 去重标记 (ddq:dedup:<id>)
+// KEYS[5]: Ready ZSet (ddq:<topic>:ready)
+// KEYS[6]: Tasks Hash (ddq:<topic>:tasks)
+// ARGV[1]: TaskID
+// ARGV[2]: 调用方读到的任务所在 Topic
+//
+// @Returns
+// number: 1=已删除, 0=ID 不存在, -1=任务正在执行, -2=任务所在 Topic 已变化, -3=索引条目无法解码
+const luaRemove = `
+local pending_key = KEYS[1]
+local dlq_key = KEYS[2]
+local index_key = KEYS[3]
+local id = ARGV[1]
+
+local raw = redis.call('HGET', index_key, id)
+if not raw then
+    return 0
+end
+
+local ok, entry = pcall(cjson.decode, raw)
+if not ok or type(entry) ~= 'table' then
+    return -3
+end
+if entry.topic ~= ARGV[2] then
+    return -2
+end
+if entry.state == 'running' then
+    return -1
+end
+
+if entry.state == 'pending' then
+    redis.call('ZREM', pending_key, entry.data or id)
+    redis.call('ZREM', KEYS[5], entry.data or id)
+elseif entry.state == 'dead' then
+    redis.call('LREM', dlq_key, 1, entry.data or id)
+end
+redis.call('HDEL', KEYS[6], id)
+redis.call('HDEL', index_key, id)
+redis.call('DEL', KEYS[4])
 return 1
 `
+
+// luaRedrive 将死信任务重新投递到等待队列。
+// @Logic
+// 1. ids 模式: 逐个校验索引状态为 dead 且属于该 Topic，LREM 移出死信队列。
+// 2. all 模式: 从尾部 RPOP 最多 limit 条（最旧的死信优先），调用方按 LLEN 控制总量，避免与新产生的死信无限循环。
+// 3. 重置 retry_count/last_backoff，清除 dead_reason/dead_at（保留 last_error 便于排查），以新的执行时间写回 Pending 并更新索引。
+// 升级前以整段 JSON 为成员的死信在重投时转存到 Tasks Hash。
+// 4. 任务数据无法解码的死信移出死信队列并转入隔离区 (luaQuarantine)，不影响同批其他死信的重投；
+// 任务数据已不存在的成员直接丢弃；ids 模式下索引条目无法解码的 ID 跳过。
+//
+// @Parameters
+// KEYS[1]: Dead Letter Queue (ddq:<topic>:dlq)
+// KEYS[2]: Pending ZSet (ddq:<topic>:pending)
+// KEYS[3]: ID 索引 Hash (ddq:index)
+// KEYS[4]: Tasks Hash (ddq:<topic>:tasks)
+// KEYS[5]: 隔离 Hash (ddq:<topic>:quarantine)
+// KEYS[6]: 隔离计数 Hash (ddq:quarantine:stats)
+// ARGV[1]: 重投后的等待毫秒数，执行时间以 Redis 服务端时间为基准
+// ARGV[2]: Topic 名称
+// ARGV[3]: 就绪通知频道 (ddq:<topic>:notify)
+// ARGV[4]: 模式 "ids" / "all"
+// ARGV[5...]: ids 模式为任务 ID 列表；all 模式 ARGV[5] 为本批最大条数
+//
+// @Returns
+// table: {重投数量, 本批扫描的条目数}
+const luaRedrive = luaServerTime + luaTaskCodec + luaQuarantine + `
+local dlq_key = KEYS[1]
+local pending_key = KEYS[2]
+local index_key = KEYS[3]
+local tasks_key = KEYS[4]
+local execute_time_ms = now_ms + tonumber(ARGV[1])
+local topic = ARGV[2]
+local mode = ARGV[4]
+
+local redriven = 0
+local scanned = 0
+
+local function requeue(id, legacy)
+    local task, body, data, err = try_load(tasks_key, id, legacy)
+    if not task then
+        redis.call('HDEL', tasks_key, id)
+        redis.call('HDEL', index_key, id)
+        if data ~= '' then
+            quarantine(KEYS[5], KEYS[6], topic, id, data, err, 'redrive')
+        end
+        return
+    end
+    task.retry_count = 0
+    task.last_backoff = nil
+    task.dead_reason = nil
+    task.dead_at = nil
+    task.execute_time = math.floor(execute_time_ms / 1000)
+    task.execute_time_ms = execute_time_ms
+    redis.call('HSET', tasks_key, id, encode_task(task, body))
+    redis.call('ZADD', pending_key, execute_time_ms, id)
+    redis.call('HSET', index_key, id, cjson.encode({topic = topic, state = 'pending'}))
+    redriven = redriven + 1
+end
+
+if mode == 'ids' then
+    for i = 5, #ARGV do
+        scanned = scanned + 1
+        local id = ARGV[i]
+        local raw = redis.call('HGET', index_key, id)
+        local entry = raw and try_decode(raw)
+        if entry and entry.state == 'dead' and entry.topic == topic then
+            redis.call('LREM', dlq_key, 1, entry.data or id)
+            requeue(id, entry.data)
+        end
+    end
+else
+    for i = 1, tonumber(ARGV[5]) do
+        local member = redis.call('RPOP', dlq_key)
+        if not member then
+            break
+        end
+        scanned = scanned + 1
+        requeue(parse_member(member))
+    end
+end
+
+if redriven > 0 then
+    redis.call('PUBLISH', ARGV[3], topic)
+end
+return {redriven, scanned}
+`
+
+// luaPurge 永久删除死信任务，同时清理 ID 索引与去重标记（允许以相同 ID 重新提交）。
+// @Logic ids / all 两种模式的选取规则同 luaRedrive；all 模式下无法解析的条目直接丢弃，ids 模式下索引条目无法解码的 ID 跳过。
+//
+// @Parameters
+// KEYS[1]: Dead Letter Queue (ddq:<topic>:dlq)
+// KEYS[2]: ID 索引 Hash (ddq:index)
+// KEYS[3]: Tasks Hash (ddq:<topic>:tasks)
+// ARGV[1]: Key 前缀 (ddq)，用于拼接去重标记 Key
+// ARGV[2]: Topic 名称
+// ARGV[3]: 模式 "ids" / "all"
+// ARGV[4...]: ids 模式为任务 ID 列表；all 模式 ARGV[4] 为本批最大条数
+//
+// @Returns
+// table: {删除数量, 本批扫描的条目数}
+const luaPurge = luaTaskCodec + `
+local dlq_key = KEYS[1]
+local index_key = KEYS[2]
+local tasks_key = KEYS[3]
+local prefix = ARGV[1]
+local topic = ARGV[2]
+local mode = ARGV[3]
+
+local purged = 0
+local scanned = 0
+
+local function forget(id)
+    redis.call('HDEL', tasks_key, id)
+    redis.call('HDEL', index_key, id)
+    redis.call('DEL', prefix .. ':dedup:' .. id)
+    purged = purged + 1
+end
+
+if mode == 'ids' then
+    for i = 4, #ARGV do
+        scanned = scanned + 1
+        local raw = redis.call('HGET', index_key, ARGV[i])
+        local ok, entry = pcall(cjson.decode, raw or '')
+        if ok and type(entry) == 'table' and entry.state == 'dead' and entry.topic == topic then
+            redis.call('LREM', dlq_key, 1, entry.data or ARGV[i])
+            forget(ARGV[i])
+        end
+    end
+else
+    for i = 1, tonumber(ARGV[4]) do
+        local member = redis.call('RPOP', dlq_key)
+        if not member then
+            break
+        end
+        scanned = scanned + 1
+        local id, legacy = parse_member(member)
+        if legacy or redis.call('HEXISTS', tasks_key, id) == 1 then
+            forget(id)
+        end
+    end
+end
+
+return {purged, scanned}
+`
+
+// luaCreateSchedule 保存新的周期任务并登记其下一次触发时间。
+// @Parameters
+// KEYS[1]: 周期任务 Hash (ddq:schedules)
+// KEYS[2]: 触发时间 ZSet (ddq:schedules:due)
+// ARGV[1]: 周期任务 ID
+// ARGV[2]: 周期任务 JSON
+// ARGV[3]: 下一次触发时间戳，0 表示不再触发
+//
+// @Returns
+// number: 1=写入成功, 0=ID 已存在
+const luaCreateSchedule = `
+if redis.call('HSETNX', KEYS[1], ARGV[1], ARGV[2]) == 0 then
+    return 0
+end
+local next_run = tonumber(ARGV[3])
+if next_run > 0 then
+    redis.call('ZADD', KEYS[2], next_run, ARGV[1])
+end
+return 1
+`
+
+// luaPauseSchedule 暂停或恢复周期任务。
+// @Logic
+// 1. 暂停: 标记 paused 并从触发时间 ZSet 中移除，调度器不再看到该周期任务。
+// 2. 恢复: 清除 paused，以调用方计算的触发时间重新登记。
+//
+// @Parameters
+// KEYS[1]: 周期任务 Hash (ddq:schedules)
+// KEYS[2]: 触发时间 ZSet (ddq:schedules:due)
+// ARGV[1]: 周期任务 ID
+// ARGV[2]: 1=暂停, 0=恢复
+// ARGV[3]: 恢复后的下一次触发时间戳，0 表示不再触发（暂停时忽略）
+//
+// @Returns
+// string: 更新后的周期任务 JSON；ID 不存在时返回 nil
+const luaPauseSchedule = `
+local raw = redis.call('HGET', KEYS[1], ARGV[1])
+if not raw then
+    return nil
+end
+local sched = cjson.decode(raw)
+if ARGV[2] == '1' then
+    sched.paused = true
+    redis.call('ZREM', KEYS[2], ARGV[1])
+else
+    local next_run = tonumber(ARGV[3])
+    sched.paused = nil
+    if next_run > 0 then
+        sched.next_run_time = next_run
+        redis.call('ZADD', KEYS[2], next_run, ARGV[1])
+    else
+        sched.next_run_time = nil
+        redis.call('ZREM', KEYS[2], ARGV[1])
+    end
+end
+local sched_json = cjson.encode(sched)
+redis.call('HSET', KEYS[1], ARGV[1], sched_json)
+return sched_json
+`
+
+// luaFireSchedule 投递周期任务本轮到期的任务，并推进其下一次触发时间。
+// @Logic
+// 1. CAS: 周期任务不存在、已暂停或 next_run_time 与调度器读取时不一致（已被其他副本推进）时直接返回 -1。
+// 2. 逐个写入任务：与 luaAdd 相同，ID 索引或去重标记已存在的任务视为已投递并跳过。
+// 3. 更新周期任务的 next_run_time/last_run_time，并同步触发时间 ZSet。
+// 去重标记的有效期同 luaAdd，以 Redis 服务端时间 (luaServerTime) 计算。
+//
+// @Parameters
+// KEYS[1]: 周期任务 Hash (ddq:schedules)
+// KEYS[2]: 触发时间 ZSet (ddq:schedules:due)
+// KEYS[3]: 目标 Topic 的 Pending ZSet (ddq:<topic>:pending)
+// KEYS[4]: Topic 注册表 Set (ddq:topics)
+// KEYS[5]: ID 索引 Hash (ddq:index)
+// KEYS[6]: 目标 Topic 的 Tasks Hash (ddq:<topic>:tasks)
+// ARGV[1]: 周期任务 ID
+// ARGV[2]: 期望的当前 next_run_time
+// ARGV[3]: 推进后的 next_run_time，0 表示不再触发
+// ARGV[4]: 本次投递对应的触发时间戳，0 表示不更新 last_run_time
+// ARGV[5]: Topic 名称
+// ARGV[6]: 就绪通知频道 (ddq:<topic>:notify)
+// ARGV[7]: Key 前缀，用于拼接去重标记 (<prefix>:dedup:<id>)
+// ARGV[8]: 去重窗口 (秒)，<=0 表示不写入去重标记
+// ARGV[9...]: 每个任务依次为 ID、编码后的任务数据、执行时间戳 (毫秒)
+//
+// @Returns
+// number: 实际写入的任务数；CAS 失败时返回 -1
+const luaFireSchedule = luaServerTime + `
+local schedules_key = KEYS[1]
+local due_key = KEYS[2]
+local pending_key = KEYS[3]
+local index_key = KEYS[5]
+local id = ARGV[1]
+local expected = tonumber(ARGV[2])
+local next_run = tonumber(ARGV[3])
+local last_run = tonumber(ARGV[4])
+local topic = ARGV[5]
+local window = tonumber(ARGV[8])
+
+-- 1. CAS
+local raw = redis.call('HGET', schedules_key, id)
+if not raw then
+    return -1
+end
+local sched = cjson.decode(raw)
+if sched.paused == true or tonumber(sched.next_run_time or 0) ~= expected then
+    return -1
+end
+
+-- 2. 投递任务
+local added = 0
+for i = 9, #ARGV, 3 do
+    local task_id = ARGV[i]
+    local score = tonumber(ARGV[i + 2])
+    local dedup_key = ARGV[7] .. ':dedup:' .. task_id
+    if redis.call('HEXISTS', index_key, task_id) == 0 and redis.call('EXISTS', dedup_key) == 0 then
+        redis.call('HSET', index_key, task_id, cjson.encode({topic = topic, state = 'pending'}))
+        redis.call('HSET', KEYS[6], task_id, ARGV[i + 1])
+        redis.call('ZADD', pending_key, score, task_id)
+        if window > 0 then
+            redis.call('SET', dedup_key, topic, 'EX', window + math.max(math.floor(score / 1000) - now_sec, 0))
+        end
+        added = added + 1
+    end
+end
+if added > 0 then
+    redis.call('SADD', KEYS[4], topic)
+    redis.call('PUBLISH', ARGV[6], topic)
+end
+
+-- 3. 推进触发时间
+if last_run > 0 then
+    sched.last_run_time = last_run
+end
+if next_run > 0 then
+    sched.next_run_time = next_run
+    redis.call('ZADD', due_key, next_run, id)
+else
+    sched.next_run_time = nil
+    redis.call('ZREM', due_key, id)
+end
+redis.call('HSET', schedules_key, id, cjson.encode(sched))
+return added
+`
@@ -0,0 +1,208 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/alicebob/miniredis/v2"
+)
+
+// TestCorruptRunningEntry 验证 Running 记录无法解码时，持有租约的脚本将任务转入隔离区而不是整体报错。
+func TestCorruptRunningEntry(t *testing.T) {
+	ops := []struct {
+		name string
+		call func(ctx context.Context, s *Store, task *pb.Task) error
+	}{
+		{"ack", func(ctx context.Context, s *Store, task *pb.Task) error {
+			return s.Ack(ctx, task.Topic, task.Id, task.Lease)
+		}},
+		{"nack", func(ctx context.Context, s *Store, task *pb.Task) error {
+			return s.Nack(ctx, task.Topic, task.Id, task.Lease, storage.NackOptions{Reason: "boom"})
+		}},
+		{"extend", func(ctx context.Context, s *Store, task *pb.Task) error {
+			return s.Extend(ctx, task.Topic, task.Id, task.Lease, time.Minute)
+		}},
+	}
+
+	for _, op := range ops {
+		t.Run(op.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := NewStore(miniredis.RunT(t).Addr())
+			t.Cleanup(func() { _ = s.client.Close() })
+
+			if err := s.Add(ctx, &pb.Task{Id: "t-1", Topic: "corrupt", Payload: "p", MaxRetries: 3}); err != nil {
+				t.Fatalf("Add: %v", err)
+			}
+			held, err := s.FetchAndHold(ctx, "corrupt", 1)
+			if err != nil || len(held) != 1 {
+				t.Fatalf("FetchAndHold = %v, %v", held, err)
+			}
+			if err := s.client.HSet(ctx, s.runningKey("corrupt"), "t-1", "not an entry").Err(); err != nil {
+				t.Fatalf("corrupt running entry: %v", err)
+			}
+
+			if err := op.call(ctx, s, held[0]); !errors.Is(err, errno.ErrTaskNotFound) {
+				t.Fatalf("%s = %v, want ErrTaskNotFound", op.name, err)
+			}
+
+			entries, _, err := s.ListQuarantined(ctx, "corrupt", 0, 10)
+			if err != nil {
+				t.Fatalf("ListQuarantined: %v", err)
+			}
+			if len(entries) != 1 || entries[0].Id != "t-1" || entries[0].Source != op.name {
+				t.Fatalf("quarantined = %v, want t-1 from %s", entries, op.name)
+			}
+			if n, _ := s.client.HLen(ctx, s.runningKey("corrupt")).Result(); n != 0 {
+				t.Errorf("running entries = %d, want 0", n)
+			}
+			if n, _ := s.client.ZCard(ctx, s.deadlineKey("corrupt")).Result(); n != 0 {
+				t.Errorf("deadline entries = %d, want 0", n)
+			}
+		})
+	}
+}
+
+// TestRemoveCorruptIndexEntry 验证 Remove 遇到无法解码的索引条目时返回错误，且不修改任何数据。
+func TestRemoveCorruptIndexEntry(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(miniredis.RunT(t).Addr())
+	t.Cleanup(func() { _ = s.client.Close() })
+
+	if err := s.client.HSet(ctx, s.indexKey(), "t-1", "not an entry").Err(); err != nil {
+		t.Fatalf("corrupt index entry: %v", err)
+	}
+	if err := s.Remove(ctx, "t-1"); err == nil || errors.Is(err, errno.ErrTaskNotFound) {
+		t.Fatalf("Remove = %v, want corrupt index error", err)
+	}
+	if raw, _ := s.client.HGet(ctx, s.indexKey(), "t-1").Result(); raw != "not an entry" {
+		t.Errorf("index entry = %q, want it untouched", raw)
+	}
+}
+
+// TestStaleIndexTopic 模拟 Replace/Remove 读取索引后、脚本执行前任务被并发改写到其他 Topic：
+// 脚本按调用方读到的旧 Topic 校验索引，不符时返回 -2 且不修改任何数据。
+func TestStaleIndexTopic(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(miniredis.RunT(t).Addr())
+	t.Cleanup(func() { _ = s.client.Close() })
+
+	if err := s.Add(ctx, &pb.Task{Id: "t-1", Topic: "moved", Payload: "p"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	res, err := s.client.Eval(ctx, luaRemove,
+		[]string{s.pendingKey("stale"), s.dlqKey("stale"), s.indexKey(), s.dedupKey("t-1"), s.readyKey("stale"), s.tasksKey("stale")},
+		"t-1", "stale").Int64()
+	if err != nil || res != -2 {
+		t.Fatalf("luaRemove with stale topic = %d, %v, want -2", res, err)
+	}
+
+	data, err := encodeTask(&pb.Task{Id: "t-1", Topic: "other", Payload: "q"})
+	if err != nil {
+		t.Fatalf("encodeTask: %v", err)
+	}
+	res, err = s.client.Eval(ctx, luaAdd,
+		[]string{s.pendingKey("other"), s.topicsKey(), s.indexKey(), s.dedupKey("t-1"),
+			s.pendingKey("stale"), s.dlqKey("stale"), s.readyKey("stale"), s.tasksKey("other"), s.tasksKey("stale")},
+		0, data, "other", "t-1", 1, 0, s.notifyChannel("other"), "stale").Int64()
+	if err != nil || res != -2 {
+		t.Fatalf("luaAdd replace with stale topic = %d, %v, want -2", res, err)
+	}
+
+	if n, _ := s.client.ZCard(ctx, s.pendingKey("moved")).Result(); n != 1 {
+		t.Errorf("pending entries in moved = %d, want 1", n)
+	}
+	if n, _ := s.client.ZCard(ctx, s.pendingKey("other")).Result(); n != 0 {
+		t.Errorf("pending entries in other = %d, want 0", n)
+	}
+
+	// 通过 Store 的方法调用会重新读取索引，定位到正确的 Topic
+	if err := s.Replace(ctx, &pb.Task{Id: "t-1", Topic: "other", Payload: "q"}); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+	if n, _ := s.client.ZCard(ctx, s.pendingKey("moved")).Result(); n != 0 {
+		t.Errorf("pending entries in moved after Replace = %d, want 0", n)
+	}
+	if err := s.Remove(ctx, "t-1"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+}
+
+// TestExtendRoundsUp 验证不足整秒的续租向上取整：超时索引以秒为单位，截断会使续租少算最多一秒，不足一秒时完全失效。
+func TestExtendRoundsUp(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	s := NewStore(mr.Addr())
+	t.Cleanup(func() { _ = s.client.Close() })
+
+	now := time.Unix(1700000000, 500*int64(time.Millisecond))
+	mr.SetTime(now)
+	if err := s.Add(ctx, &pb.Task{Id: "t-1", Topic: "extend", Payload: "p", ExecuteTimeMs: now.UnixMilli(), VisibilityTimeout: 1}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	held, err := s.FetchAndHold(ctx, "extend", 1)
+	if err != nil || len(held) != 1 {
+		t.Fatalf("FetchAndHold = %v, %v", held, err)
+	}
+
+	tests := []struct {
+		extra time.Duration
+		want  float64
+	}{
+		{500 * time.Millisecond, float64(now.Unix() + 1)},
+		{1500 * time.Millisecond, float64(now.Unix() + 2)},
+		{3 * time.Second, float64(now.Unix() + 3)},
+	}
+	for _, tt := range tests {
+		if err := s.Extend(ctx, "extend", "t-1", held[0].Lease, tt.extra); err != nil {
+			t.Fatalf("Extend(%v): %v", tt.extra, err)
+		}
+		got, err := s.client.ZScore(ctx, s.deadlineKey("extend"), "t-1").Result()
+		if err != nil || got != tt.want {
+			t.Errorf("deadline after Extend(%v) = %v, %v, want %v", tt.extra, got, err, tt.want)
+		}
+	}
+}
+
+// TestDedupTTLServerTime 验证去重标记的有效期以 Redis 服务端时间计算：服务端时钟与本地时钟相差一小时，
+// 按本地时钟计算会把距执行时间的等待时长算成负数，只剩下去重窗口。
+func TestDedupTTLServerTime(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	s := NewStore(mr.Addr(), WithDedupWindow(time.Minute))
+	t.Cleanup(func() { _ = s.client.Close() })
+
+	server := time.Now().Add(-time.Hour).Truncate(time.Second)
+	mr.SetTime(server)
+	at := server.Add(100 * time.Second)
+	want := 100*time.Second + time.Minute
+
+	if err := s.Add(ctx, &pb.Task{Id: "added", Topic: "dedup", Payload: "p", ExecuteTimeMs: at.UnixMilli()}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if got := mr.TTL(s.dedupKey("added")); got != want {
+		t.Errorf("Add dedup TTL = %v, want %v", got, want)
+	}
+
+	if err := s.CreateSchedule(ctx, &pb.Schedule{Id: "cron", Topic: "dedup", NextRunTime: server.Unix()}); err != nil {
+		t.Fatalf("CreateSchedule: %v", err)
+	}
+	fired, err := s.FireSchedule(ctx, storage.ScheduleFiring{
+		ScheduleID: "cron",
+		Topic:      "dedup",
+		Expected:   server,
+		LastRun:    server,
+		Tasks:      []*pb.Task{{Id: "fired", Topic: "dedup", Payload: "p", ExecuteTimeMs: at.UnixMilli()}},
+	})
+	if err != nil || !fired {
+		t.Fatalf("FireSchedule = %v, %v", fired, err)
+	}
+	if got := mr.TTL(s.dedupKey("fired")); got != want {
+		t.Errorf("FireSchedule dedup TTL = %v, want %v", got, want)
+	}
+}
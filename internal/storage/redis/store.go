@@ -1,86 +1,318 @@
 // Package redis 提供了基于 Redis 数据结构的 JobStore 接口实现。
 // 核心设计：利用 Redis ZSet 结构实现延时优先级队列，并结合 Lua 脚本保障消费原子性。
+//
+// Key 布局（按 Topic 分区）：
+//   - ddq:<topic>:tasks   (Hash): 任务数据，Field 为任务 ID，Value 为 encodeTask 编码的头部 + protobuf 正文
+//   - ddq:<topic>:pending (ZSet): 待执行任务 ID，Score 为毫秒执行时间戳（升级前写入的任务为秒，拉取时换算）
+//   - ddq:<topic>:ready   (ZSet): 已到期、等待拉取的任务 ID，Score 为 execute_time - priority * aging (毫秒)，越小越先拉取
+//   - ddq:<topic>:running (Hash): 执行中任务，Field 为任务 ID，Value 为开始时间与租约令牌
+//   - ddq:<topic>:deadlines (ZSet): 执行中任务的超时索引，Score 为超时时间戳，供 Watchdog 只扫描已超时的任务
+//   - ddq:<topic>:dlq     (List): 死信队列，元素为任务 ID
+//   - ddq:<topic>:quarantine (Hash): 无法解码的任务条目，Field 为任务 ID，Value 为元信息 + 原始字节
+//   - ddq:quarantine:stats (Hash): 各 Topic 累计隔离的条目数
+//   - ddq:topics          (Set) : 已出现过的 Topic 注册表，供 Watchdog 遍历
+//   - ddq:index           (Hash): 任务 ID 索引，记录任务所属 Topic、当前状态及原始成员
+//   - ddq:dedup:<id>      (String): 幂等去重标记，带 TTL，任务完成后仍保留一个去重窗口
+//   - ddq:<topic>:notify  (Pub/Sub 频道): 任务写入/重新入队时发布，供订阅流唤醒
+//   - ddq:schedules       (Hash): 周期任务定义，Field 为周期任务 ID
+//   - ddq:schedules:due   (ZSet): 周期任务的下一次触发时间，供调度器扫描
+//
+// 升级前写入的任务以整段 JSON 作为 ZSet/List 成员并直接保存在 Running 记录中，脚本读取时透明兼容，
+// 并在任务下一次状态变化时把 JSON 转存到 Tasks Hash。
 package redis
 
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync"
 	"time"
 
 	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
 	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
+// 旧版（MVP）全局 Key，仅用于 MigrateLegacy 一次性迁移。
+const (
+	legacyPendingKey = "ddq:tasks"
+	legacyRunningKey = "ddq:running"
+	legacyDLQKey     = "ddq:dlq"
+	// legacyUnparsedKey 存放迁移时无法解析的旧数据，供人工排查。
+	legacyUnparsedKey = "ddq:legacy:unparsed"
+)
+
+// migrateBatchSize 单次迁移脚本处理的最大条目数，避免长时间阻塞 Redis。
+const migrateBatchSize = 500
+
+// legacyScoreLimit 小于该值的 Pending Score 是升级前写入的秒级时间戳，与 luaFetchAndHold 中的判定一致。
+const legacyScoreLimit = 1e11
+
+const (
+	// recoverBatchSize 单次恢复脚本处理的最大超时任务数，限制单个脚本阻塞 Redis 的时长。
+	recoverBatchSize = 200
+	// recoverMaxBatches 单次 CheckAndMoveExpired 对每个 Topic 最多执行的恢复批次，剩余部分留给下一轮扫描。
+	recoverMaxBatches = 10
+	// promoteBatchSize 单次 FetchAndHold 从 Pending 提升到 Ready 的最大到期任务数。
+	promoteBatchSize = 1000
+	// indexRetries Replace/Remove 读到的 Topic 在脚本执行前被并发改写时的最大重试次数。
+	indexRetries = 3
+)
+
 // Store 实现了 storage.JobStore 接口，作为任务持久化的 Redis 适配器。
 // @ThreadSafe: redis.Client 本身并发安全，Store 实例支持多协程共用。
 type Store struct {
-	client     *redis.Client // Redis 官方 Golang 客户端
-	pendingKey string        // 待处理任务的 Key 名称（业务隔离前缀）ZSet: ddq tasks
-	runningKey string        // 正在处理任务的 Key 名称（业务隔离前缀）Hash: ddq running
-	dlqKey     string        // 死信队列 Key 名称（业务隔离前缀）List: ddq dlq
+	client            *redis.Client // Redis 官方 Golang 客户端
+	prefix            string        // Key 命名空间前缀（业务隔离），默认 "ddq"
+	dedupWindow       time.Duration // 任务完成后去重标记的保留时长，<=0 表示仅在任务存续期间去重
+	visibilityTimeout time.Duration // 任务未设置 visibility_timeout 时使用的默认值，用于计算超时索引
+	priorityAging     time.Duration // 到期任务每等待该时长，有效优先级提升 1 级
+
+	indexed sync.Map // 已补齐超时索引的 Topic，每个进程只需补齐一次
+}
+
+// Option 定义 Store 的可选配置项。
+type Option func(*Store)
+
+// WithDedupWindow 设置幂等去重窗口。
+// @Description 任务 Ack 后其 ID 仍会在该窗口内被视为已存在，用于吸收客户端的迟到重试。
+func WithDedupWindow(d time.Duration) Option {
+	return func(s *Store) {
+		s.dedupWindow = d
+	}
+}
+
+// WithVisibilityTimeout 设置默认可见性超时。
+// @Description 任务被拉取时按 start + visibility_timeout 写入超时索引，任务未单独设置时使用该值；
+// 应与 Watchdog 使用的默认值保持一致。
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(s *Store) {
+		s.visibilityTimeout = d
+	}
+}
+
+// WithPriorityAging 设置优先级老化周期。
+// @Description 到期任务按 priority 由高到低拉取，任务每超过 execute_time 等待 d，其有效优先级提升 1 级，
+// 使持续涌入的高优先级任务无法让低优先级任务无限期等待。d <= 0 时保持默认值 60 秒。
+func WithPriorityAging(d time.Duration) Option {
+	return func(s *Store) {
+		if d > 0 {
+			s.priorityAging = d
+		}
+	}
 }
 
 // GetClient 返回底层的 Redis 客户端实例。
-// @Warning: 仅用于测试脚本直接操作 Redis，生产代码应通过 JobStore 接口。
+// @Warning: 仅用于测试脚本直接操作 Redis，或供选主租约锁等协调组件共用连接池；任务读写应通过 JobStore 接口。
 func (s *Store) GetClient() *redis.Client {
 	return s.client
 }
 
-// 编译期校验：确保 Store 结构体完整实现了 JobStore 定义的所有契约。
-var _ storage.JobStore = (*Store)(nil)
+// 编译期校验：确保 Store 结构体完整实现了 JobStore 定义的所有契约，并支持就绪通知、服务端时钟与隔离区管理。
+var (
+	_ storage.JobStore        = (*Store)(nil)
+	_ storage.Notifier        = (*Store)(nil)
+	_ storage.Clock           = (*Store)(nil)
+	_ storage.QuarantineStore = (*Store)(nil)
+)
 
 // NewStore 初始化并返回 Redis 存储实例。
 // @Param addr: 格式为 "host:port" 的 Redis 连接地址。
-func NewStore(addr string) *Store {
+// @Param opts: 可选配置项，如 WithDedupWindow。
+func NewStore(addr string, opts ...Option) *Store {
 	rdb := redis.NewClient(&redis.Options{
 		Addr: addr,
 	})
-	return &Store{
-		client:     rdb,
-		pendingKey: "ddq:tasks",   // Default namespace
-		runningKey: "ddq:running", // Default namespace
-		dlqKey:     "ddq:dlq",
+	s := &Store{
+		client:            rdb,
+		prefix:            "ddq", // Default namespace
+		visibilityTimeout: 60 * time.Second,
+		priorityAging:     time.Minute,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
+}
+
+// pendingKey 返回指定 Topic 的待处理任务 ZSet 键名。
+func (s *Store) pendingKey(topic string) string {
+	return s.prefix + ":" + topic + ":pending"
+}
+
+// tasksKey 返回指定 Topic 的任务数据 Hash 键名。
+func (s *Store) tasksKey(topic string) string {
+	return s.prefix + ":" + topic + ":tasks"
+}
+
+// runningKey 返回指定 Topic 的执行中任务 Hash 键名。
+func (s *Store) runningKey(topic string) string {
+	return s.prefix + ":" + topic + ":running"
+}
+
+// deadlineKey 返回指定 Topic 的执行中任务超时索引 ZSet 键名。
+func (s *Store) deadlineKey(topic string) string {
+	return s.prefix + ":" + topic + ":deadlines"
+}
+
+// readyKey 返回指定 Topic 的已到期任务 ZSet 键名。
+func (s *Store) readyKey(topic string) string {
+	return s.prefix + ":" + topic + ":ready"
+}
+
+// dlqKey 返回指定 Topic 的死信队列 List 键名。
+func (s *Store) dlqKey(topic string) string {
+	return s.prefix + ":" + topic + ":dlq"
 }
 
-// Add 将延时任务持久化至 Redis。
-// @Algorithm: 基于 ZSet(Sorted Set) 实现，Score 为任务预定的执行 Unix 时间戳。
-// @Complexity: O(log(N))，N 为队列中待处理任务的总数。
+// quarantineKey 返回指定 Topic 的隔离 Hash 键名。
+func (s *Store) quarantineKey(topic string) string {
+	return s.prefix + ":" + topic + ":quarantine"
+}
+
+// quarantineStatsKey 返回各 Topic 累计隔离计数 Hash 的键名。
+func (s *Store) quarantineStatsKey() string {
+	return s.prefix + ":quarantine:stats"
+}
+
+// topicsKey 返回 Topic 注册表 Set 的键名。
+func (s *Store) topicsKey() string {
+	return s.prefix + ":topics"
+}
+
+// indexKey 返回任务 ID 索引 Hash 的键名。
+func (s *Store) indexKey() string {
+	return s.prefix + ":index"
+}
+
+// dedupKey 返回任务 ID 对应的幂等去重标记键名。
+func (s *Store) dedupKey(id string) string {
+	return s.prefix + ":dedup:" + id
+}
+
+// notifyChannel 返回 Topic 的任务就绪通知频道名。
+func (s *Store) notifyChannel(topic string) string {
+	return s.prefix + ":" + topic + ":notify"
+}
+
+// indexEntry 对应 ddq:index 中单个任务的索引条目，由 Lua 脚本写入。
+type indexEntry struct {
+	Topic string `json:"topic"` // 任务所属 Topic，用于定位分区 Key
+	State string `json:"state"` // pending / running / dead
+	Data  string `json:"data"`  // 升级前写入的条目才有：任务在 ZSet/List 中的原始 JSON 成员
+}
+
+// Add 将延时任务持久化至 Redis（ID 不存在才写入）。
+// @Algorithm: 任务数据以 encodeTask 编码写入 Tasks Hash，任务 ID 写入 ZSet(Sorted Set)，Score 为任务预定的执行毫秒时间戳。
+// @Complexity: O(log(N))，N 为该 Topic 下待处理任务的总数。
+// @Return: ID 仍存在或处于去重窗口内时返回 errno.ErrTaskAlreadyExist，不做任何修改。
 func (s *Store) Add(ctx context.Context, task *pb.Task) error {
-	// 1. 序列化：使用标准 JSON 格式。
-	// @Note: 追求性能时可替换为 Protobuf 二进制序列化以减少 Redis 内存占用。
-	bytes, err := json.Marshal(task)
+	return s.add(ctx, task, false)
+}
+
+// Replace 以新任务覆盖同 ID 的旧任务；旧任务不存在时等同于 Add。
+// @Return: 旧任务正在执行时返回 errno.ErrTaskRunning。
+func (s *Store) Replace(ctx context.Context, task *pb.Task) error {
+	return s.add(ctx, task, true)
+}
+
+// add 是 Add 与 Replace 的共同实现。
+func (s *Store) add(ctx context.Context, task *pb.Task, replace bool) error {
+	if task.Topic == "" {
+		return fmt.Errorf("task topic is required")
+	}
+
+	// 1. 序列化：调度字段编码为 JSON 头部供 Lua 读写，其余字段编码为 protobuf 正文。
+	data, err := encodeTask(task)
 	if err != nil {
-		return fmt.Errorf("marshal task: %w", err)
+		return err
 	}
 
-	// 2. 构造有序集合成员。
-	member := redis.Z{
-		Score:  float64(task.ExecuteTime), // 排序权重：执行时间戳
-		Member: bytes,                     // 存储载体：任务快照
+	mode := 0
+	if replace {
+		mode = 1
 	}
+	for attempt := 0; ; attempt++ {
+		// 2. 覆盖模式下旧任务可能位于其他 Topic，需先通过索引定位其所在分区；脚本内会校验该 Topic 是否仍然成立。
+		oldTopic := task.Topic
+		if replace {
+			topic, ok, err := s.indexTopic(ctx, task.Id)
+			if err != nil {
+				return err
+			}
+			if ok {
+				oldTopic = topic
+			}
+		}
+
+		// 3. 执行写入：去重检查、注册 Topic、写入 ZSet、维护 ID 索引与去重标记在同一脚本内完成，
+		// 去重标记的有效期（执行时间之后再保留一个去重窗口）以 Redis 服务端时间计算。
+		// 若写入失败需向上层抛出 Error 由 Service 层决定重试逻辑。
+		res, err := s.client.Eval(ctx, luaAdd,
+			[]string{s.pendingKey(task.Topic), s.topicsKey(), s.indexKey(), s.dedupKey(task.Id),
+				s.pendingKey(oldTopic), s.dlqKey(oldTopic), s.readyKey(oldTopic), s.tasksKey(task.Topic), s.tasksKey(oldTopic)},
+			storage.ExecuteAt(task).UnixMilli(), data, task.Topic, task.Id, mode, int64(s.dedupWindow/time.Second), s.notifyChannel(task.Topic), oldTopic,
+		).Int64()
+		if err != nil {
+			return fmt.Errorf("redis add failed: %w", err)
+		}
 
-	// 3. 执行写入：若写入失败需向上层抛出 Error 由 Service 层决定重试逻辑。
-	if err := s.client.ZAdd(ctx, s.pendingKey, member).Err(); err != nil {
-		return fmt.Errorf("redis zadd failed: %w", err)
+		switch res {
+		case 0:
+			return errno.ErrTaskAlreadyExist
+		case -1:
+			return errno.ErrTaskRunning
+		case -2:
+			if attempt < indexRetries {
+				continue
+			}
+			return fmt.Errorf("task %s kept moving between topics, giving up after %d attempts", task.Id, attempt+1)
+		case -3:
+			return fmt.Errorf("index entry of task %s is corrupt", task.Id)
+		}
+		return nil
 	}
+}
 
-	return nil
+// indexTopic 通过 ID 索引查询任务当前所在的 Topic，索引中不存在时 ok 为 false。
+func (s *Store) indexTopic(ctx context.Context, id string) (topic string, ok bool, err error) {
+	raw, err := s.client.HGet(ctx, s.indexKey(), id).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("redis hget index failed: %w", err)
+	}
+	var entry indexEntry
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+		return "", false, fmt.Errorf("unmarshal index entry failed: %w", err)
+	}
+	return entry.Topic, true, nil
 }
 
-// FetchAndHold 批量获取并从队列中弹出已到期的待执行任务。
+// FetchAndHold 批量获取并从指定 Topic 队列中弹出已到期的待执行任务。
 // @Description 利用 Lua 脚本实现“查询+删除”的原子语义，确保在分布式水平扩展时，同一任务仅被下发一次。
-// @Return: 返回解析成功的任务列表。若解析失败，将跳过损坏条目并继续处理，保障队列可用性。
+// 已到期的任务按有效优先级（priority 加上等待时长带来的老化加成）由高到低返回，未到期的任务不会被提前返回。
+// 是否到期以 Redis 服务端时间为准，调用方进程的时钟偏差不会导致任务被提前或推迟拉取。
+// @Return: 返回解析成功的任务列表。无法解码的条目转入隔离区（ddq:<topic>:quarantine）并继续处理，保障队列可用性。
 func (s *Store) FetchAndHold(ctx context.Context, topic string, limit int64) ([]*pb.Task, error) {
-	now := time.Now().Unix()
+	if topic == "" {
+		return nil, fmt.Errorf("topic is required")
+	}
+	// 租约令牌 = 随机前缀 + 批内序号，保证每次投递唯一
+	nonce := uuid.New().String()
 
-	// 1. 调用 Lua 脚本进行原子弹出。
-	// @Optimization: MVP 版本暂不支持按 Topic 分片，全局共用一个 ZSet。
+	// 1. 调用 Lua 脚本进行原子弹出，仅作用于该 Topic 的分区 Key。
 	val, err := s.client.Eval(ctx, luaFetchAndHold,
-		[]string{s.pendingKey, s.runningKey},
-		now, limit, now).Result()
+		[]string{s.pendingKey(topic), s.runningKey(topic), s.indexKey(), s.deadlineKey(topic), s.readyKey(topic), s.tasksKey(topic),
+			s.quarantineKey(topic), s.quarantineStatsKey()},
+		limit, topic, nonce, int64(s.visibilityTimeout/time.Second),
+		s.priorityAging.Milliseconds(), promoteBatchSize).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return []*pb.Task{}, nil
@@ -89,86 +321,328 @@ func (s *Store) FetchAndHold(ctx context.Context, topic string, limit int64) ([]
 	}
 
 	// 2. 返回值解析与反序列化。
-	// Redis Lua 返回的是 interface{} 类型的 Slice。
+	// Redis Lua 返回的是 interface{} 类型的 Slice，按 {id, lease, task_data} 三个一组平铺。
 	rawTasks, ok := val.([]interface{})
 	if !ok {
 		return []*pb.Task{}, nil
 	}
 
-	tasks := make([]*pb.Task, 0, len(rawTasks))
-	for _, item := range rawTasks {
-		str, ok := item.(string)
-		if !ok {
+	tasks := make([]*pb.Task, 0, len(rawTasks)/3)
+	for i := 0; i+2 < len(rawTasks); i += 3 {
+		id, ok1 := rawTasks[i].(string)
+		lease, ok2 := rawTasks[i+1].(string)
+		str, ok3 := rawTasks[i+2].(string)
+		if !ok1 || !ok2 || !ok3 {
 			continue // 数据污染防御：跳过非字符串成员
 		}
 
-		var task pb.Task
-		if err := json.Unmarshal([]byte(str), &task); err != nil {
-			// @Security: 记录反序列化失败，防止单个异常数据造成整体消费阻塞（Poison Pill）。
+		task, err := decodeTask([]byte(str))
+		if err != nil {
+			// @Security: Lua 只校验了头部，正文损坏的任务在此转入隔离区，防止单个异常数据造成整体消费阻塞（Poison Pill）。
+			if qerr := s.quarantineRunning(ctx, topic, id, lease, err); qerr != nil {
+				log.Printf("quarantine task %s/%s failed: %v (decode error: %v)", topic, id, qerr, err)
+			}
 			continue
 		}
-		tasks = append(tasks, &task)
+		task.Lease = lease
+		tasks = append(tasks, task)
 	}
 
 	return tasks, nil
 }
 
-// Remove 根据任务 ID 删除未到期的任务。
-// @Status: Unimplemented (MVP Phase)
-// @Warning: 当前 ZSet 结构不支持基于 ID 的 O(1) 检索，需引入 ID->Payload 的索引映射。
+// Remove 根据任务 ID 撤销任务。
+// @Description 先通过 ID 索引定位任务所属 Topic，再由 Lua 脚本依据索引中的最新状态原子地执行删除：
+//   - pending: 从等待队列移除，任务不会再被下发。
+//   - dead: 从死信队列移除。
+//   - running: 任务已被 Worker 持有，返回 errno.ErrTaskRunning。
+//
+// @Return: ID 不存在（或已 Ack 完成）时返回 errno.ErrTaskNotFound。
 func (s *Store) Remove(ctx context.Context, id string) error {
-	return fmt.Errorf("not implemented")
+	for attempt := 0; ; attempt++ {
+		// 1. 查询索引获取 Topic。Replace 可能将任务并发改写到其他 Topic，脚本内会校验 Topic 与状态。
+		topic, ok, err := s.indexTopic(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errno.ErrTaskNotFound
+		}
+
+		// 2. 原子删除
+		res, err := s.client.Eval(ctx, luaRemove,
+			[]string{s.pendingKey(topic), s.dlqKey(topic), s.indexKey(), s.dedupKey(id), s.readyKey(topic), s.tasksKey(topic)},
+			id, topic,
+		).Int64()
+		if err != nil {
+			return fmt.Errorf("remove failed: %w", err)
+		}
+
+		switch res {
+		case 0:
+			return errno.ErrTaskNotFound
+		case -1:
+			return errno.ErrTaskRunning
+		case -2:
+			if attempt < indexRetries {
+				continue
+			}
+			return fmt.Errorf("task %s kept moving between topics, giving up after %d attempts", id, attempt+1)
+		case -3:
+			return fmt.Errorf("index entry of task %s is corrupt", id)
+		}
+		return nil
+	}
 }
 
-// Ack 实现
-func (s *Store) Ack(ctx context.Context, id string) error {
-	// 简单直接：从 Hash 中删除即可
-	return s.client.Eval(ctx, luaAck, []string{s.runningKey}, id).Err()
+// Ack 确认任务完成，将其从所属 Topic 的 Running 集合及 ID 索引中移除。
+// @Note: 任务完成后去重标记仍保留 dedupWindow，防止客户端迟到的重复提交导致任务再次执行。
+// @Return: 任务不在执行中（已 Ack、已被 Watchdog 回收或因 Running 记录损坏被隔离）时返回 errno.ErrTaskNotFound；
+// 任务已被重新投递（租约令牌不匹配）时返回 errno.ErrLeaseMismatch。
+func (s *Store) Ack(ctx context.Context, topic, id, lease string) error {
+	res, err := s.client.Eval(ctx, luaAck,
+		[]string{s.runningKey(topic), s.indexKey(), s.dedupKey(id), s.deadlineKey(topic), s.tasksKey(topic),
+			s.quarantineKey(topic), s.quarantineStatsKey()},
+		id, int64(s.dedupWindow/time.Second), topic, lease,
+	).Int64()
+	if err != nil {
+		return fmt.Errorf("ack failed: %w", err)
+	}
+	return leaseResult(res)
+}
+
+// Nack 报告任务处理失败。
+// @Description 重试计数基于 Running 记录中的任务快照在 Lua 内递增，超过 max_retries（任务未设置时使用 opts.MaxRetries）进入死信队列，
+// 否则在 opts.RetryDelay（毫秒精度）之后重新可见；未指定 RetryDelay 时按任务的 retry_policy 在 Lua 内计算退避时长。
+// 重试时间以 Redis 服务端时间为基准。
+// 任务数据无法解码时无法重试，任务转入隔离区。
+// @Return: 任务不在执行中（含被隔离）时返回 errno.ErrTaskNotFound；租约令牌不匹配时返回 errno.ErrLeaseMismatch。
+func (s *Store) Nack(ctx context.Context, topic, id, lease string, opts storage.NackOptions) error {
+	res, err := s.client.Eval(ctx, luaNack,
+		[]string{s.runningKey(topic), s.pendingKey(topic), s.dlqKey(topic), s.indexKey(), s.deadlineKey(topic), s.tasksKey(topic),
+			s.quarantineKey(topic), s.quarantineStatsKey()}, // KEYS
+		id, opts.Reason, topic, s.notifyChannel(topic), lease, opts.RetryDelay.Milliseconds(), rand.Int64N(1<<31), opts.MaxRetries, // ARGV
+	).Int64()
+	if err != nil {
+		return fmt.Errorf("nack failed: %w", err)
+	}
+	return leaseResult(res)
 }
 
-// Nack 实现
-func (s *Store) Nack(ctx context.Context, task *pb.Task) error {
-	// 1. 更新重试计数
-	task.RetryCount++
+// Extend 延长执行中任务的可见性超时，将其在超时索引中的超时时刻推迟到 now + extra。
+// @Note: 超时索引以秒为单位，extra 向上取整到秒，不足一秒的续租不会被截断为 0 而失效。
+// @Return: 任务不在执行中（含因数据损坏被隔离）时返回 errno.ErrTaskNotFound；租约令牌不匹配时返回 errno.ErrLeaseMismatch。
+func (s *Store) Extend(ctx context.Context, topic, id, lease string, extra time.Duration) error {
+	res, err := s.client.Eval(ctx, luaExtend,
+		[]string{s.runningKey(topic), s.deadlineKey(topic), s.tasksKey(topic), s.indexKey(),
+			s.quarantineKey(topic), s.quarantineStatsKey()},
+		id, lease, int64((extra+time.Second-1)/time.Second), int64(s.visibilityTimeout/time.Second), topic,
+	).Int64()
+	if err != nil {
+		return fmt.Errorf("extend failed: %w", err)
+	}
+	return leaseResult(res)
+}
 
-	isDead := 0
-	// 2. 检查是否超过最大重试次数
-	if task.RetryCount >= task.MaxRetries {
-		isDead = 1
+// leaseResult 将持有租约的 Lua 脚本返回值转换为 errno 错误：0 表示任务不在执行中，-1 表示租约令牌不匹配。
+func leaseResult(res int64) error {
+	switch res {
+	case 0:
+		return errno.ErrTaskNotFound
+	case -1:
+		return errno.ErrLeaseMismatch
+	default:
+		return nil
 	}
+}
 
-	// 3. 序列化更新后的 Task
-	bytes, err := json.Marshal(task)
+// CheckAndMoveExpired 遍历 Topic 注册表，逐个 Topic 恢复可见性超时的任务。
+// @Description 通过超时索引 (ddq:<topic>:deadlines) 只取出已超时的任务，每批最多 recoverBatchSize 个，
+// 每个 Topic 最多 recoverMaxBatches 批，积压的超时任务在后续轮次中继续回收，单次扫描的开销与执行中任务总量无关。
+// 任务自身设置的 max_retries 优先，maxRetries 仅作为未设置时的默认值。是否超时以 Redis 服务端时间为准。
+// @Note: 单个 Topic 失败不影响其余 Topic 的恢复，所有错误合并后返回。
+func (s *Store) CheckAndMoveExpired(ctx context.Context, visibilityTimeout int64, maxRetries int32) (storage.RecoverStats, error) {
+	var stats storage.RecoverStats
+	topics, err := s.client.SMembers(ctx, s.topicsKey()).Result()
 	if err != nil {
-		return fmt.Errorf("marshal task failed: %w", err)
+		return stats, fmt.Errorf("list topics failed: %w", err)
 	}
 
-	// 4. 计算下次重试时间 (这里简单策略：立即重试，即 Now。工业级可以做指数退避)
-	retryTime := time.Now().Unix()
+	var errs []error
+	for _, topic := range topics {
+		if err := s.indexRunning(ctx, topic, visibilityTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("index running tasks of topic %s failed: %w", topic, err))
+			continue
+		}
+		if err := s.recoverTopic(ctx, topic, maxRetries, &stats); err != nil {
+			errs = append(errs, fmt.Errorf("recover topic %s failed: %w", topic, err))
+		}
+	}
+	return stats, errors.Join(errs...)
+}
 
-	// 5. 执行 Lua
-	err = s.client.Eval(ctx, luaNack,
-		[]string{s.runningKey, s.pendingKey, s.dlqKey}, // KEYS
-		task.Id, bytes, retryTime, isDead, // ARGV
-	).Err()
+// recoverTopic 分批恢复单个 Topic 下的超时任务，并将结果累加到 stats。
+func (s *Store) recoverTopic(ctx context.Context, topic string, maxRetries int32, stats *storage.RecoverStats) error {
+	for range recoverMaxBatches {
+		res, err := s.client.Eval(ctx, luaRecover,
+			[]string{s.runningKey(topic), s.pendingKey(topic), s.dlqKey(topic), s.indexKey(), s.deadlineKey(topic), s.tasksKey(topic),
+				s.quarantineKey(topic), s.quarantineStatsKey()}, // KEYS
+			recoverBatchSize, maxRetries, topic, s.notifyChannel(topic), rand.Int64N(1<<31), // ARGV
+		).Int64Slice()
+		if err != nil {
+			return err
+		}
+		if len(res) != 4 {
+			return fmt.Errorf("unexpected recover result %v", res)
+		}
+		stats.Requeued += res[0]
+		stats.Dead += res[1]
+		stats.Quarantined += res[3]
+		if res[2] < recoverBatchSize {
+			return nil
+		}
+	}
+	return nil
+}
 
-	if err != nil {
-		return fmt.Errorf("nack failed: %w", err)
+// indexRunning 为升级前写入、尚无超时索引的执行中任务补齐索引。
+// @Description 以 HSCAN 分批遍历 Running Hash，每个 Topic 在进程生命周期内只需成功执行一次；
+// 此后所有执行中任务均由 FetchAndHold 写入索引。
+// @Param visibilityTimeout: 任务未设置 visibility_timeout 时使用的默认值（秒）。
+func (s *Store) indexRunning(ctx context.Context, topic string, visibilityTimeout int64) error {
+	if _, ok := s.indexed.Load(topic); ok {
+		return nil
 	}
+
+	var cursor uint64
+	for {
+		kvs, next, err := s.client.HScan(ctx, s.runningKey(topic), cursor, "", recoverBatchSize).Result()
+		if err != nil {
+			return err
+		}
+		if len(kvs) > 0 {
+			args := make([]interface{}, 0, len(kvs)/2+1)
+			args = append(args, visibilityTimeout)
+			for i := 0; i < len(kvs); i += 2 {
+				args = append(args, kvs[i])
+			}
+			if err := s.client.Eval(ctx, luaIndexRunning,
+				[]string{s.runningKey(topic), s.deadlineKey(topic), s.tasksKey(topic)}, args...).Err(); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	s.indexed.Store(topic, struct{}{})
 	return nil
 }
 
-// CheckAndMoveExpired 实现接口
-func (s *Store) CheckAndMoveExpired(ctx context.Context, visibilityTimeout int64, maxRetries int32) error {
-	now := time.Now().Unix()
+// Notifications 实现 storage.Notifier，基于 Redis Pub/Sub 订阅各 Topic 的就绪通知频道。
+// @Description 每次调用占用一条独立的 Redis 连接；输出通道容量为 1，连续通知会被合并。
+// @Warning: Pub/Sub 不保证送达（断线期间的消息会丢失），调用方必须保留兜底轮询。
+func (s *Store) Notifications(ctx context.Context, topics []string) (<-chan string, error) {
+	channels := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		channels = append(channels, s.notifyChannel(topic))
+	}
+
+	ps := s.client.Subscribe(ctx, channels...)
+	// 等待订阅确认，确保返回后发布的通知不会遗漏
+	if _, err := ps.Receive(ctx); err != nil {
+		_ = ps.Close()
+		return nil, fmt.Errorf("subscribe notify channels failed: %w", err)
+	}
+
+	out := make(chan string, 1)
+	go func() {
+		defer close(out)
+		defer func() { _ = ps.Close() }()
 
-	err := s.client.Eval(ctx, luaRecover,
-		[]string{s.runningKey, s.pendingKey, s.dlqKey}, // KEYS
-		now, visibilityTimeout, maxRetries, // ARGV
-	).Err()
+		msgs := ps.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				select {
+				case out <- msg.Payload:
+				default: // 已有未消费的唤醒信号，合并
+				}
+			}
+		}
+	}()
+	return out, nil
+}
 
+// NextDueTime 实现 storage.Notifier，返回 Topic 中 Score 最小的待执行任务的执行时间。
+// @Note: Ready ZSet 中仍有已提升但未被拉取的任务时，直接返回当前时间。
+func (s *Store) NextDueTime(ctx context.Context, topic string) (time.Time, bool, error) {
+	ready, err := s.client.ZCard(ctx, s.readyKey(topic)).Result()
 	if err != nil {
-		return fmt.Errorf("recover failed: %w", err)
+		return time.Time{}, false, fmt.Errorf("redis zcard failed: %w", err)
+	}
+	if ready > 0 {
+		return time.Now(), true, nil
+	}
+
+	res, err := s.client.ZRangeWithScores(ctx, s.pendingKey(topic), 0, 0).Result()
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("redis zrange failed: %w", err)
+	}
+	if len(res) == 0 {
+		return time.Time{}, false, nil
+	}
+	return scoreTime(res[0].Score), true, nil
+}
+
+// ServerTime 实现 storage.Clock，通过 TIME 命令读取 Redis 服务端的当前时间。
+// @Note: 返回值包含一次网络往返的延迟，调用方比较本地时钟时应考虑往返耗时。
+func (s *Store) ServerTime(ctx context.Context) (time.Time, error) {
+	t, err := s.client.Time(ctx).Result()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("redis time failed: %w", err)
+	}
+	return t, nil
+}
+
+// scoreTime 将 Pending Score 转换为时间，兼容升级前写入的秒级 Score。
+func scoreTime(score float64) time.Time {
+	if score < legacyScoreLimit {
+		return time.Unix(int64(score), 0)
+	}
+	return time.UnixMilli(int64(score))
+}
+
+// MigrateLegacy 将 MVP 版本全局 Key（ddq:tasks / ddq:running / ddq:dlq）中的存量数据
+// 一次性迁移到按 Topic 分区的新布局。
+// @Description 以批次方式反复调用迁移脚本直到旧 Key 清空，每批最多 migrateBatchSize 条，避免长时间阻塞 Redis。
+// 缺少 topic 字段的历史任务归入 defaultTopic；无法解析的条目转存至 ddq:legacy:unparsed。
+// 该操作幂等，可安全重复执行。
+// @Return: 本次迁移的条目总数。
+func (s *Store) MigrateLegacy(ctx context.Context, defaultTopic string) (int64, error) {
+	if defaultTopic == "" {
+		return 0, fmt.Errorf("default topic is required")
+	}
+
+	var total int64
+	for {
+		moved, err := s.client.Eval(ctx, luaMigrateLegacy,
+			[]string{legacyPendingKey, legacyRunningKey, legacyDLQKey, s.topicsKey(), legacyUnparsedKey, s.indexKey()},
+			s.prefix, migrateBatchSize, defaultTopic,
+		).Int64()
+		if err != nil {
+			return total, fmt.Errorf("migrate legacy keys failed: %w", err)
+		}
+		if moved == 0 {
+			return total, nil
+		}
+		total += moved
 	}
-	return nil
 }
@@ -0,0 +1,78 @@
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect 标识底层数据库的 SQL 方言。
+// @Description 各方言的差异仅限于占位符、二进制列类型与行锁语法，查询本身使用三者共有的 SQL 子集编写。
+type Dialect string
+
+const (
+	// Postgres PostgreSQL 9.5+，占位符为 $1, $2 ...
+	Postgres Dialect = "postgres"
+	// MySQL MySQL 8.0+ (SKIP LOCKED 自 8.0 起支持)
+	MySQL Dialect = "mysql"
+	// SQLite 本地开发与测试用的回退方言：不支持行锁，由 Store 在进程内串行化事务，只适用于单进程。
+	// 连接池应限制为单连接 (db.SetMaxOpenConns(1))，否则事务外的读取可能遇到 SQLITE_BUSY，":memory:" 数据库也会按连接各自独立
+	SQLite Dialect = "sqlite"
+)
+
+// ParseDialect 解析配置中的方言名称。
+// @Return: 未知方言返回 error。
+func ParseDialect(name string) (Dialect, error) {
+	switch d := Dialect(strings.ToLower(name)); d {
+	case Postgres, MySQL, SQLite:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unknown sql dialect %q", name)
+	}
+}
+
+// blobType 返回保存 protobuf 编码数据的二进制列类型。
+func (d Dialect) blobType() string {
+	switch d {
+	case Postgres:
+		return "BYTEA"
+	case MySQL:
+		return "LONGBLOB"
+	default:
+		return "BLOB"
+	}
+}
+
+// lockClause 返回 SELECT 末尾的行锁子句。
+// @Param skipLocked: 为 true 时跳过已被其他事务锁定的行，用于多个消费者并发拉取互不阻塞。
+// @Return: SQLite 没有行锁，返回空串，由 Store.withTx 串行化事务保证原子性。
+func (d Dialect) lockClause(skipLocked bool) string {
+	if d == SQLite {
+		return ""
+	}
+	if skipLocked {
+		return " FOR UPDATE SKIP LOCKED"
+	}
+	return " FOR UPDATE"
+}
+
+// rebind 将查询中的 ? 占位符转换为方言的占位符格式。
+// @Note: 查询中不包含字符串字面量里的 ?，因此无需处理引号。
+func (d Dialect) rebind(query string) string {
+	if d != Postgres {
+		return query
+	}
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] != '?' {
+			b.WriteByte(query[i])
+			continue
+		}
+		n++
+		b.WriteByte('$')
+		b.WriteString(strconv.Itoa(n))
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package sql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDialect_Rebind(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		query   string
+		want    string
+	}{
+		{Postgres, "SELECT 1 FROM t WHERE a = ? AND b < ? LIMIT ?", "SELECT 1 FROM t WHERE a = $1 AND b < $2 LIMIT $3"},
+		{Postgres, "SELECT 1", "SELECT 1"},
+		{MySQL, "SELECT 1 FROM t WHERE a = ?", "SELECT 1 FROM t WHERE a = ?"},
+		{SQLite, "SELECT 1 FROM t WHERE a = ?", "SELECT 1 FROM t WHERE a = ?"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			if got := tt.dialect.rebind(tt.query); got != tt.want {
+				t.Errorf("rebind(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDialect(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Dialect
+		wantErr bool
+	}{
+		{"postgres", Postgres, false},
+		{"MySQL", MySQL, false},
+		{"sqlite", SQLite, false},
+		{"oracle", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDialect(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDialect(%q) = %q, %v, want %q (error %v)", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestStore_SQLTemplates(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		blobType string
+		lock     string
+	}{
+		{Postgres, "BYTEA", " FOR UPDATE SKIP LOCKED"},
+		{MySQL, "LONGBLOB", " FOR UPDATE SKIP LOCKED"},
+		{SQLite, "BLOB", ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			s := NewStore(nil, tt.dialect, WithTablePrefix("app"))
+			if got := s.sql("SELECT id FROM {tasks} WHERE topic = ?"); !strings.Contains(got, "app_tasks") {
+				t.Errorf("table name not replaced: %q", got)
+			}
+			if got := tt.dialect.lockClause(true); got != tt.lock {
+				t.Errorf("lockClause(true) = %q, want %q", got, tt.lock)
+			}
+
+			for _, m := range migrations {
+				for _, stmt := range m.stmts {
+					got := s.sql(stmt)
+					if strings.ContainsAny(got, "{}") {
+						t.Errorf("migration %d has unreplaced placeholders: %s", m.version, firstLine(got))
+					}
+					if strings.HasPrefix(got, "CREATE TABLE app_tasks") && !strings.Contains(got, tt.blobType) {
+						t.Errorf("tasks table does not use %s: %s", tt.blobType, got)
+					}
+				}
+			}
+		})
+	}
+}
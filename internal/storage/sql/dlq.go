@@ -0,0 +1,225 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"google.golang.org/protobuf/proto"
+)
+
+// ListDead 分页扫描 Topic 的死信队列，按进入死信的先后由新到旧排列。
+// @Description Offset 为由新到旧的下标，语义与 Redis 实现的 LRANGE 下标一致；按 dead_at 过滤直到凑满 q.Limit 条。
+// 无法解码的死信条目被跳过。
+func (s *Store) ListDead(ctx context.Context, q storage.DeadLetterQuery) ([]*pb.Task, int64, error) {
+	if q.Topic == "" {
+		return nil, 0, fmt.Errorf("topic is required")
+	}
+
+	tasks := make([]*pb.Task, 0, q.Limit)
+	offset := max(q.Offset, 0)
+	page := max(q.Limit, 1)
+	for int64(len(tasks)) < q.Limit {
+		rows, err := s.db.QueryContext(ctx,
+			s.sql(`SELECT body FROM {tasks} WHERE topic = ? AND state = ? ORDER BY dead_seq DESC LIMIT ? OFFSET ?`),
+			q.Topic, stateDead, page, offset)
+		if err != nil {
+			return nil, 0, fmt.Errorf("query dead letters failed: %w", err)
+		}
+		bodies, err := scanBodies(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		for _, body := range bodies {
+			offset++
+			task := &pb.Task{}
+			if err := proto.Unmarshal(body, task); err != nil {
+				continue // 跳过无法解析的死信条目
+			}
+			if !q.Since.IsZero() && task.DeadAt < q.Since.Unix() {
+				continue
+			}
+			if !q.Until.IsZero() && task.DeadAt > q.Until.Unix() {
+				continue
+			}
+			tasks = append(tasks, task)
+			if int64(len(tasks)) == q.Limit {
+				break
+			}
+		}
+		if int64(len(bodies)) < page {
+			break
+		}
+	}
+
+	// 判断是否还有后续数据，避免返回一个必然为空的下一页
+	var n int64
+	if err := s.db.QueryRowContext(ctx, s.sql(`SELECT COUNT(*) FROM {tasks} WHERE topic = ? AND state = ?`), q.Topic, stateDead).Scan(&n); err != nil {
+		return nil, 0, fmt.Errorf("count dead letters failed: %w", err)
+	}
+	if offset >= n {
+		return tasks, 0, nil
+	}
+	return tasks, offset, nil
+}
+
+// scanBodies 读出只有 body 一列的结果集并关闭。
+func scanBodies(rows *sql.Rows) ([][]byte, error) {
+	defer rows.Close()
+	var bodies [][]byte
+	for rows.Next() {
+		var body []byte
+		if err := rows.Scan(&body); err != nil {
+			return nil, fmt.Errorf("scan task failed: %w", err)
+		}
+		bodies = append(bodies, body)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tasks failed: %w", err)
+	}
+	return bodies, nil
+}
+
+// GetDead 按 ID 查询死信任务。
+// @Return: ID 不存在或不在死信状态时返回 errno.ErrTaskNotFound。
+func (s *Store) GetDead(ctx context.Context, id string) (*pb.Task, error) {
+	var (
+		st   int
+		body []byte
+	)
+	err := s.db.QueryRowContext(ctx, s.sql(`SELECT state, body FROM {tasks} WHERE id = ?`), id).Scan(&st, &body)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && st != stateDead) {
+		return nil, errno.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read task failed: %w", err)
+	}
+
+	task := &pb.Task{}
+	if err := proto.Unmarshal(body, task); err != nil {
+		return nil, fmt.Errorf("unmarshal task %s failed: %w", id, err)
+	}
+	return task, nil
+}
+
+// Redrive 将选中的死信任务重新放回等待队列，在 delay 之后执行，逻辑同 luaRedrive。
+// @Description 重置 retry_count/last_backoff 并清除死信信息，保留 last_error；无法解码的死信条目转入隔离表，不计入重投数量。
+func (s *Store) Redrive(ctx context.Context, sel storage.DeadLetterSelector, delay time.Duration) (int64, error) {
+	return s.applyDead(ctx, sel, func(tx *sql.Tx, row taskRow) (bool, error) {
+		task := &pb.Task{}
+		if err := proto.Unmarshal(row.body, task); err != nil {
+			return false, s.quarantine(ctx, tx, row.topic, row.id, row.body, err, "redrive", s.now())
+		}
+		executeAt := s.now().Add(delay)
+		task.RetryCount = 0
+		task.LastBackoff = 0
+		task.DeadReason = ""
+		task.DeadAt = 0
+		task.ExecuteTime = executeAt.Unix()
+		task.ExecuteTimeMs = executeAt.UnixMilli()
+		return true, s.requeue(ctx, tx, task, executeAt)
+	})
+}
+
+// Purge 永久删除选中的死信任务，并清除其去重标记（允许以相同 ID 重新提交）。选取规则同 Redrive。
+func (s *Store) Purge(ctx context.Context, sel storage.DeadLetterSelector) (int64, error) {
+	return s.applyDead(ctx, sel, func(tx *sql.Tx, row taskRow) (bool, error) {
+		return true, s.delete(ctx, tx, row.id)
+	})
+}
+
+// applyDead 按 DeadLetterSelector 锁定死信任务并逐个调用 fn，返回 fn 报告已处理的数量。
+// @Description All 模式按 dead_seq 由旧到新分批处理，每批一个事务；否则在一个事务内处理 IDs 中处于死信状态的任务，
+// 此时 Topic 非空则只匹配该 Topic。
+func (s *Store) applyDead(ctx context.Context, sel storage.DeadLetterSelector, fn func(tx *sql.Tx, row taskRow) (bool, error)) (int64, error) {
+	if !sel.All {
+		var total int64
+		err := s.withTx(ctx, func(tx *sql.Tx) error {
+			for _, id := range sel.IDs {
+				var (
+					row taskRow
+					st  int
+				)
+				row.id = id
+				err := tx.QueryRowContext(ctx, s.sql(`SELECT topic, state, body FROM {tasks} WHERE id = ?`+s.dialect.lockClause(false)), id).
+					Scan(&row.topic, &st, &row.body)
+				if errors.Is(err, sql.ErrNoRows) {
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("read task failed: %w", err)
+				}
+				if st != stateDead || (sel.Topic != "" && row.topic != sel.Topic) {
+					continue
+				}
+				done, err := fn(tx, row)
+				if err != nil {
+					return err
+				}
+				if done {
+					total++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		return total, nil
+	}
+
+	if sel.Topic == "" {
+		return 0, fmt.Errorf("topic is required")
+	}
+	var total int64
+	var lastSeq int64
+	for {
+		var n, processed int
+		err := s.withTx(ctx, func(tx *sql.Tx) error {
+			rows, err := tx.QueryContext(ctx,
+				s.sql(`SELECT id, topic, body, dead_seq FROM {tasks} WHERE topic = ? AND state = ? AND dead_seq > ? ORDER BY dead_seq LIMIT ?`+s.dialect.lockClause(false)),
+				sel.Topic, stateDead, lastSeq, dlqBatchSize)
+			if err != nil {
+				return fmt.Errorf("query dead letters failed: %w", err)
+			}
+			var batch []taskRow
+			for rows.Next() {
+				var row taskRow
+				if err := rows.Scan(&row.id, &row.topic, &row.body, &lastSeq); err != nil {
+					_ = rows.Close()
+					return fmt.Errorf("scan task failed: %w", err)
+				}
+				batch = append(batch, row)
+			}
+			_ = rows.Close()
+			if err := rows.Err(); err != nil {
+				return fmt.Errorf("iterate dead letters failed: %w", err)
+			}
+
+			n = len(batch)
+			for _, row := range batch {
+				done, err := fn(tx, row)
+				if err != nil {
+					return err
+				}
+				if done {
+					processed++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+		total += int64(processed)
+		if n < dlqBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+)
+
+// quarantine 将无法解码的任务行连同原始字节与解码错误移入隔离表，并累加 Topic 的隔离计数，逻辑同 Lua 的 quarantine。
+// @Param source: 发现位置，fetch (拉取) / recover (超时回收) / ack / nack / extend (执行中操作) / redrive (死信重投)。
+func (s *Store) quarantine(ctx context.Context, tx *sql.Tx, topic, id string, raw []byte, decodeErr error, source string, now time.Time) error {
+	if _, err := tx.ExecContext(ctx, s.sql(`DELETE FROM {tasks} WHERE id = ?`), id); err != nil {
+		return fmt.Errorf("delete quarantined task failed: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, s.sql(`DELETE FROM {quarantine} WHERE topic = ? AND id = ?`), topic, id); err != nil {
+		return fmt.Errorf("replace quarantine entry failed: %w", err)
+	}
+	_, err := tx.ExecContext(ctx,
+		s.sql(`INSERT INTO {quarantine} (topic, id, raw, decode_error, source, quarantined_at) VALUES (?, ?, ?, ?, ?, ?)`),
+		topic, id, raw, decodeErr.Error(), source, now.Unix())
+	if err != nil {
+		return fmt.Errorf("insert quarantine entry failed: %w", err)
+	}
+
+	var total int64
+	err = tx.QueryRowContext(ctx, s.sql(`SELECT total FROM {quarantine_stats} WHERE topic = ?`+s.dialect.lockClause(false)), topic).Scan(&total)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, err = tx.ExecContext(ctx, s.sql(`INSERT INTO {quarantine_stats} (topic, total) VALUES (?, 1)`), topic)
+	case err == nil:
+		_, err = tx.ExecContext(ctx, s.sql(`UPDATE {quarantine_stats} SET total = total + 1 WHERE topic = ?`), topic)
+	}
+	if err != nil {
+		return fmt.Errorf("update quarantine stats failed: %w", err)
+	}
+	return nil
+}
+
+// ListQuarantined 实现 storage.QuarantineStore，按任务 ID 顺序分页查询 Topic 的隔离条目。
+// @Description cursor 为已返回的条目数，next 为下一页的 cursor；本页不足 limit 条时 next 为 0。
+func (s *Store) ListQuarantined(ctx context.Context, topic string, cursor uint64, limit int64) ([]*pb.QuarantinedEntry, uint64, error) {
+	if topic == "" {
+		return nil, 0, fmt.Errorf("topic is required")
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		s.sql(`SELECT id, raw, decode_error, source, quarantined_at FROM {quarantine} WHERE topic = ? ORDER BY id LIMIT ? OFFSET ?`),
+		topic, limit, cursor)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query quarantine failed: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*pb.QuarantinedEntry, 0, limit)
+	for rows.Next() {
+		e := &pb.QuarantinedEntry{Topic: topic}
+		if err := rows.Scan(&e.Id, &e.Raw, &e.Error, &e.Source, &e.QuarantinedAt); err != nil {
+			return nil, 0, fmt.Errorf("scan quarantine entry failed: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate quarantine failed: %w", err)
+	}
+
+	if int64(len(entries)) < limit {
+		return entries, 0, nil
+	}
+	return entries, cursor + uint64(len(entries)), nil
+}
+
+// QuarantineStats 实现 storage.QuarantineStore。
+// @Return: current 为隔离表中该 Topic 的条目数；total 为累计隔离计数。
+func (s *Store) QuarantineStats(ctx context.Context, topic string) (int64, int64, error) {
+	var current, total int64
+	if err := s.db.QueryRowContext(ctx, s.sql(`SELECT COUNT(*) FROM {quarantine} WHERE topic = ?`), topic).Scan(&current); err != nil {
+		return 0, 0, fmt.Errorf("count quarantine failed: %w", err)
+	}
+	err := s.db.QueryRowContext(ctx, s.sql(`SELECT total FROM {quarantine_stats} WHERE topic = ?`), topic).Scan(&total)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, fmt.Errorf("read quarantine total failed: %w", err)
+	}
+	return current, total, nil
+}
+
+// DeleteQuarantined 实现 storage.QuarantineStore，累计计数不受影响。
+func (s *Store) DeleteQuarantined(ctx context.Context, topic string, ids []string, all bool) (int64, error) {
+	if topic == "" {
+		return 0, fmt.Errorf("topic is required")
+	}
+
+	if all {
+		res, err := s.db.ExecContext(ctx, s.sql(`DELETE FROM {quarantine} WHERE topic = ?`), topic)
+		if err != nil {
+			return 0, fmt.Errorf("delete quarantine failed: %w", err)
+		}
+		return res.RowsAffected()
+	}
+
+	var deleted int64
+	for _, id := range ids {
+		res, err := s.db.ExecContext(ctx, s.sql(`DELETE FROM {quarantine} WHERE topic = ? AND id = ?`), topic, id)
+		if err != nil {
+			return deleted, fmt.Errorf("delete quarantine entry failed: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return deleted, fmt.Errorf("delete quarantine entry failed: %w", err)
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
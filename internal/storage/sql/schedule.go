@@ -0,0 +1,238 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"google.golang.org/protobuf/proto"
+)
+
+// CreateSchedule 保存新的周期任务。
+// @Return: ID 已存在时返回 errno.ErrScheduleAlreadyExist。
+func (s *Store) CreateSchedule(ctx context.Context, sched *pb.Schedule) error {
+	body, err := proto.Marshal(sched)
+	if err != nil {
+		return fmt.Errorf("marshal schedule failed: %w", err)
+	}
+	if _, err := s.GetSchedule(ctx, sched.Id); err == nil {
+		return errno.ErrScheduleAlreadyExist
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		s.sql(`INSERT INTO {schedules} (id, topic, paused, next_run_time, body) VALUES (?, ?, ?, ?, ?)`),
+		sched.Id, sched.Topic, boolInt(sched.Paused), sched.NextRunTime, body)
+	if err != nil {
+		// 并发创建同一 ID 时后写入的一方主键冲突
+		if _, getErr := s.GetSchedule(ctx, sched.Id); getErr == nil {
+			return errno.ErrScheduleAlreadyExist
+		}
+		return fmt.Errorf("insert schedule failed: %w", err)
+	}
+	return nil
+}
+
+// GetSchedule 按 ID 查询周期任务。
+// @Return: ID 不存在时返回 errno.ErrScheduleNotFound。
+func (s *Store) GetSchedule(ctx context.Context, id string) (*pb.Schedule, error) {
+	var body []byte
+	err := s.db.QueryRowContext(ctx, s.sql(`SELECT body FROM {schedules} WHERE id = ?`), id).Scan(&body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errno.ErrScheduleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read schedule failed: %w", err)
+	}
+	return unmarshalSchedule(body)
+}
+
+// ListSchedules 查询周期任务，topic 为空表示全部，结果按 ID 排序。
+func (s *Store) ListSchedules(ctx context.Context, topic string) ([]*pb.Schedule, error) {
+	query, args := `SELECT body FROM {schedules} ORDER BY id`, []any(nil)
+	if topic != "" {
+		query, args = `SELECT body FROM {schedules} WHERE topic = ? ORDER BY id`, []any{topic}
+	}
+	return s.querySchedules(ctx, query, args...)
+}
+
+// DeleteSchedule 删除周期任务，已投递的任务不受影响。
+// @Return: ID 不存在时返回 errno.ErrScheduleNotFound。
+func (s *Store) DeleteSchedule(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, s.sql(`DELETE FROM {schedules} WHERE id = ?`), id)
+	if err != nil {
+		return fmt.Errorf("delete schedule failed: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete schedule failed: %w", err)
+	}
+	if n == 0 {
+		return errno.ErrScheduleNotFound
+	}
+	return nil
+}
+
+// SetSchedulePaused 暂停或恢复周期任务，逻辑同 luaPauseSchedule。
+// @Return: 更新后的周期任务；ID 不存在时返回 errno.ErrScheduleNotFound。
+func (s *Store) SetSchedulePaused(ctx context.Context, id string, paused bool, next time.Time) (*pb.Schedule, error) {
+	var sched *pb.Schedule
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if sched, err = s.lockSchedule(ctx, tx, id); err != nil {
+			return err
+		}
+		sched.Paused = paused
+		if !paused {
+			sched.NextRunTime = 0
+			if !next.IsZero() {
+				sched.NextRunTime = next.Unix()
+			}
+		}
+		return s.updateSchedule(ctx, tx, sched)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sched, nil
+}
+
+// DueSchedules 返回 next_run_time 不晚于 now 且未暂停的周期任务，按触发时间先后排列，最多 limit 个。
+func (s *Store) DueSchedules(ctx context.Context, now time.Time, limit int64) ([]*pb.Schedule, error) {
+	return s.querySchedules(ctx,
+		`SELECT body FROM {schedules} WHERE paused = 0 AND next_run_time > 0 AND next_run_time <= ? ORDER BY next_run_time, id LIMIT ?`,
+		now.Unix(), limit)
+}
+
+// FireSchedule 原子地投递 f.Tasks 并推进周期任务的触发时间，逻辑同 luaFireSchedule。
+// @Return: 周期任务已被推进、暂停或删除时返回 false 且不做任何修改。
+func (s *Store) FireSchedule(ctx context.Context, f storage.ScheduleFiring) (bool, error) {
+	for _, task := range f.Tasks {
+		if task.Topic != f.Topic {
+			return false, fmt.Errorf("task %s topic %q does not match schedule topic %q", task.Id, task.Topic, f.Topic)
+		}
+	}
+
+	fired := false
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		sched, err := s.lockSchedule(ctx, tx, f.ScheduleID)
+		if errors.Is(err, errno.ErrScheduleNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if sched.Paused || sched.NextRunTime != f.Expected.Unix() {
+			return nil
+		}
+
+		now := s.now()
+		for _, task := range f.Tasks {
+			var one int
+			err := tx.QueryRowContext(ctx, s.sql(`SELECT 1 FROM {tasks} WHERE id = ?`), task.Id).Scan(&one)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("read task failed: %w", err)
+			}
+			deduped, err := s.deduped(ctx, tx, task.Id, now)
+			if err != nil {
+				return err
+			}
+			if deduped {
+				continue
+			}
+			if err := s.insert(ctx, tx, task, now); err != nil {
+				return err
+			}
+		}
+
+		if !f.LastRun.IsZero() {
+			sched.LastRunTime = f.LastRun.Unix()
+		}
+		sched.NextRunTime = 0
+		if !f.Next.IsZero() {
+			sched.NextRunTime = f.Next.Unix()
+		}
+		if err := s.updateSchedule(ctx, tx, sched); err != nil {
+			return err
+		}
+		fired = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return fired, nil
+}
+
+// lockSchedule 锁定并读取周期任务。
+// @Return: ID 不存在时返回 errno.ErrScheduleNotFound。
+func (s *Store) lockSchedule(ctx context.Context, tx *sql.Tx, id string) (*pb.Schedule, error) {
+	var body []byte
+	err := tx.QueryRowContext(ctx, s.sql(`SELECT body FROM {schedules} WHERE id = ?`+s.dialect.lockClause(false)), id).Scan(&body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errno.ErrScheduleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read schedule failed: %w", err)
+	}
+	return unmarshalSchedule(body)
+}
+
+// updateSchedule 写回周期任务及其查询列。
+func (s *Store) updateSchedule(ctx context.Context, tx *sql.Tx, sched *pb.Schedule) error {
+	body, err := proto.Marshal(sched)
+	if err != nil {
+		return fmt.Errorf("marshal schedule failed: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, s.sql(`UPDATE {schedules} SET paused = ?, next_run_time = ?, body = ? WHERE id = ?`),
+		boolInt(sched.Paused), sched.NextRunTime, body, sched.Id)
+	if err != nil {
+		return fmt.Errorf("update schedule failed: %w", err)
+	}
+	return nil
+}
+
+// querySchedules 执行只返回 body 一列的周期任务查询。
+func (s *Store) querySchedules(ctx context.Context, query string, args ...any) ([]*pb.Schedule, error) {
+	rows, err := s.db.QueryContext(ctx, s.sql(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query schedules failed: %w", err)
+	}
+	bodies, err := scanBodies(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]*pb.Schedule, 0, len(bodies))
+	for _, body := range bodies {
+		sched, err := unmarshalSchedule(body)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, sched)
+	}
+	return schedules, nil
+}
+
+func unmarshalSchedule(body []byte) (*pb.Schedule, error) {
+	sched := &pb.Schedule{}
+	if err := proto.Unmarshal(body, sched); err != nil {
+		return nil, fmt.Errorf("unmarshal schedule failed: %w", err)
+	}
+	return sched, nil
+}
+
+// boolInt 将布尔值转换为 SMALLINT 列的取值。
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,125 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// tableNames Store 使用的全部表（不含前缀），实际表名为 <prefix>_<name>。
+var tableNames = []string{"tasks", "dedup", "sequences", "quarantine", "quarantine_stats", "schedules", "migrations"}
+
+// migration 一个版本的 Schema 变更。
+// @Description 语句中的 {table} 替换为带前缀的表名，{blob} 替换为方言的二进制列类型，{aging} 替换为优先级老化周期的毫秒数。
+// 已发布的版本不可修改，Schema 变更只能追加新版本。
+type migration struct {
+	version int
+	stmts   []string
+}
+
+// migrations 按版本号升序排列。
+//
+// tasks 表每个任务一行，body 为 protobuf 编码的完整任务，其余列是查询与排序所需的调度字段：
+//   - state:        1 等待中 / 2 执行中 / 3 死信，对应 Redis 实现的 ddq:index
+//   - execute_time: 等待中任务重新可见的毫秒时间戳，对应 pending ZSet 的分数；Nack/回收重试时更新，body 中的 execute_time 保持不变
+//   - score:        有效优先级 execute_time - priority * aging，对应 ready ZSet 的分数，与 execute_time 同时写入 (版本 2)；
+//     FetchAndHold 沿 (topic, state, score) 索引顺序读取，无需对全部到期任务排序
+//   - lease / deadline: 执行中任务的租约令牌与超时时刻 (毫秒)，对应 running Hash 与 deadlines ZSet
+//   - dead_seq:     进入死信队列的序号，由 sequences 表分配，越大越新，对应 dlq List 的顺序
+var migrations = []migration{
+	{version: 1, stmts: []string{
+		`CREATE TABLE {tasks} (
+	id VARCHAR(191) NOT NULL PRIMARY KEY,
+	topic VARCHAR(191) NOT NULL,
+	state SMALLINT NOT NULL,
+	execute_time BIGINT NOT NULL,
+	priority INTEGER NOT NULL DEFAULT 0,
+	lease VARCHAR(64) NOT NULL DEFAULT '',
+	deadline BIGINT NOT NULL DEFAULT 0,
+	dead_seq BIGINT NOT NULL DEFAULT 0,
+	body {blob} NOT NULL
+)`,
+		`CREATE INDEX {tasks}_due ON {tasks} (topic, state, execute_time)`,
+		`CREATE INDEX {tasks}_deadline ON {tasks} (state, deadline)`,
+		`CREATE INDEX {tasks}_dead ON {tasks} (topic, state, dead_seq)`,
+		`CREATE TABLE {dedup} (
+	id VARCHAR(191) NOT NULL PRIMARY KEY,
+	expires_at BIGINT NOT NULL
+)`,
+		`CREATE INDEX {dedup}_expires ON {dedup} (expires_at)`,
+		`CREATE TABLE {sequences} (
+	name VARCHAR(64) NOT NULL PRIMARY KEY,
+	seq BIGINT NOT NULL
+)`,
+		`INSERT INTO {sequences} (name, seq) VALUES ('dlq', 0)`,
+		`CREATE TABLE {quarantine} (
+	topic VARCHAR(191) NOT NULL,
+	id VARCHAR(191) NOT NULL,
+	raw {blob},
+	decode_error TEXT NOT NULL,
+	source VARCHAR(16) NOT NULL,
+	quarantined_at BIGINT NOT NULL,
+	PRIMARY KEY (topic, id)
+)`,
+		`CREATE TABLE {quarantine_stats} (
+	topic VARCHAR(191) NOT NULL PRIMARY KEY,
+	total BIGINT NOT NULL
+)`,
+		`CREATE TABLE {schedules} (
+	id VARCHAR(191) NOT NULL PRIMARY KEY,
+	topic VARCHAR(191) NOT NULL,
+	paused SMALLINT NOT NULL,
+	next_run_time BIGINT NOT NULL,
+	body {blob} NOT NULL
+)`,
+		`CREATE INDEX {schedules}_due ON {schedules} (paused, next_run_time)`,
+	}},
+	{version: 2, stmts: []string{
+		`ALTER TABLE {tasks} ADD COLUMN score BIGINT NOT NULL DEFAULT 0`,
+		`UPDATE {tasks} SET score = execute_time - priority * {aging}`,
+		`CREATE INDEX {tasks}_ready ON {tasks} (topic, state, score, id)`,
+	}},
+}
+
+// Migrate 创建或升级 Store 使用的表，已执行过的版本会被跳过，可在每次启动时调用。
+// @Description 版本记录在 <prefix>_migrations 表中，每个版本在一个事务中执行。
+// @Warning: MySQL 的 DDL 会隐式提交事务，版本执行到一半失败时需要人工清理；多个副本首次启动时应避免同时执行迁移。
+func (s *Store) Migrate(ctx context.Context) error {
+	create := s.sql(`CREATE TABLE IF NOT EXISTS {migrations} (version INTEGER NOT NULL PRIMARY KEY)`)
+	if _, err := s.db.ExecContext(ctx, create); err != nil {
+		return fmt.Errorf("create migrations table failed: %w", err)
+	}
+
+	var current int
+	if err := s.db.QueryRowContext(ctx, s.sql(`SELECT COALESCE(MAX(version), 0) FROM {migrations}`)).Scan(&current); err != nil {
+		return fmt.Errorf("read schema version failed: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		err := s.withTx(ctx, func(tx *sql.Tx) error {
+			for _, stmt := range m.stmts {
+				if _, err := tx.ExecContext(ctx, s.sql(stmt)); err != nil {
+					return fmt.Errorf("%w: %s", err, firstLine(stmt))
+				}
+			}
+			_, err := tx.ExecContext(ctx, s.sql(`INSERT INTO {migrations} (version) VALUES (?)`), m.version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migrate to version %d failed: %w", m.version, err)
+		}
+	}
+	return nil
+}
+
+// firstLine 返回语句的第一行，用于错误信息。
+func firstLine(stmt string) string {
+	if i := strings.IndexByte(stmt, '\n'); i >= 0 {
+		return stmt[:i]
+	}
+	return stmt
+}
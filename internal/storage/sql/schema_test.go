@@ -0,0 +1,59 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestMigrate_ScoreBackfill 验证从版本 1 升级时按 execute_time 与 priority 回填 score，升级前写入的任务仍按有效优先级拉取。
+func TestMigrate_ScoreBackfill(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", "file:ddqmigrate?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	s := NewStore(db, SQLite)
+
+	all := migrations
+	migrations = all[:1]
+	err = s.Migrate(ctx)
+	migrations = all
+	if err != nil {
+		t.Fatalf("Migrate() to version 1 error = %v", err)
+	}
+
+	// 低优先级任务先到期一秒，高优先级任务的老化加成为 2 分钟，应先被拉取
+	due := time.Now().Add(-time.Minute).UnixMilli()
+	for _, task := range []*pb.Task{
+		{Id: "early", Topic: "upgrade", ExecuteTimeMs: due - 1000},
+		{Id: "urgent", Topic: "upgrade", ExecuteTimeMs: due, Priority: 2},
+	} {
+		body, err := proto.Marshal(task)
+		if err != nil {
+			t.Fatalf("marshal %s: %v", task.Id, err)
+		}
+		_, err = db.ExecContext(ctx, s.sql(`INSERT INTO {tasks} (id, topic, state, execute_time, priority, body) VALUES (?, ?, ?, ?, ?, ?)`),
+			task.Id, task.Topic, statePending, task.ExecuteTimeMs, task.Priority, body)
+		if err != nil {
+			t.Fatalf("insert %s: %v", task.Id, err)
+		}
+	}
+
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	held, err := s.FetchAndHold(ctx, "upgrade", 1)
+	if err != nil {
+		t.Fatalf("FetchAndHold() error = %v", err)
+	}
+	if len(held) != 1 || held[0].Id != "urgent" {
+		t.Fatalf("FetchAndHold() = %v, want [urgent]", held)
+	}
+}
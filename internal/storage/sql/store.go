@@ -0,0 +1,644 @@
+// Package sql 提供了基于 database/sql 的 JobStore 接口实现，支持 PostgreSQL、MySQL 与 SQLite。
+// 适用场景：希望以关系型数据库保证持久性、不想额外运维 Redis 的部署。
+//
+// 设计要点：
+//   - 每个任务在 tasks 表中占一行，调度字段为独立列，完整任务以 protobuf 编码保存在 body 列
+//   - FetchAndHold 以 SELECT ... FOR UPDATE SKIP LOCKED 取出到期任务，多个消费者并发拉取互不阻塞且不会重复投递
+//   - Ack、Nack、Extend、超时回收与死信操作均在单个事务内完成，语义与 Redis 实现的 Lua 脚本一致
+//   - SQLite 没有行锁，作为本地开发与测试的回退方言，由 Store 在进程内串行化事务
+//
+// 本包不注册任何数据库驱动，调用方需自行导入驱动（如 github.com/jackc/pgx/v5/stdlib）并通过 sql.Open 创建连接池。
+// 时间取自 Store 所在进程的本地时钟，不实现 storage.Clock；不支持就绪通知，订阅方退化为定时轮询。
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// recoverBatchSize 单个回收事务最多处理的超时任务数
+	recoverBatchSize = 200
+	// recoverMaxBatches 单次 CheckAndMoveExpired 最多执行的回收事务数，剩余部分留给下一轮扫描
+	recoverMaxBatches = 10
+	// dlqBatchSize 死信批量操作 (all 模式) 单个事务处理的条数
+	dlqBatchSize = 500
+)
+
+// 任务状态，对应 tasks.state 列。
+const (
+	statePending = 1 // 等待执行
+	stateRunning = 2 // 执行中
+	stateDead    = 3 // 已进入死信队列
+)
+
+// Store 实现了 storage.JobStore 接口，所有数据保存在关系型数据库中。
+// @ThreadSafe: 并发安全由数据库事务与行锁保证，可在多个进程间共享同一数据库（SQLite 除外）。
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+	prefix  string            // 表名前缀，默认 "ddq"
+	tables  *strings.Replacer // 将查询中的 {name} 替换为带前缀的表名
+	txMu    sync.Mutex        // SQLite 方言下串行化事务
+
+	dedupWindow       time.Duration // 任务完成后去重标记的保留时长，<=0 表示仅在任务存续期间去重
+	visibilityTimeout time.Duration // 任务未设置 visibility_timeout 时使用的默认值
+	priorityAging     time.Duration // 到期任务每等待该时长，有效优先级提升 1 级
+
+	now func() time.Time // 当前时间，测试中可替换
+}
+
+// Option 定义 Store 的可选配置项。
+type Option func(*Store)
+
+// WithDedupWindow 设置幂等去重窗口，含义同 Redis 实现的 WithDedupWindow。
+func WithDedupWindow(d time.Duration) Option {
+	return func(s *Store) {
+		s.dedupWindow = d
+	}
+}
+
+// WithVisibilityTimeout 设置默认可见性超时，任务未单独设置时使用；应与 Watchdog 使用的默认值保持一致。
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(s *Store) {
+		s.visibilityTimeout = d
+	}
+}
+
+// WithPriorityAging 设置优先级老化周期，含义同 Redis 实现的 WithPriorityAging。d <= 0 时保持默认值 60 秒。
+func WithPriorityAging(d time.Duration) Option {
+	return func(s *Store) {
+		if d > 0 {
+			s.priorityAging = d
+		}
+	}
+}
+
+// WithTablePrefix 设置表名前缀（业务隔离），表名为 <prefix>_tasks 等。默认 "ddq"。
+func WithTablePrefix(prefix string) Option {
+	return func(s *Store) {
+		if prefix != "" {
+			s.prefix = prefix
+		}
+	}
+}
+
+// 编译期校验：确保 Store 完整实现了 JobStore 定义的所有契约，并支持隔离区管理与周期任务存储。
+var (
+	_ storage.JobStore        = (*Store)(nil)
+	_ storage.QuarantineStore = (*Store)(nil)
+	_ storage.ScheduleStore   = (*Store)(nil)
+)
+
+// NewStore 基于已打开的连接池创建存储实例，连接池的生命周期由调用方管理。
+// @Param db: 由 sql.Open 创建的连接池，驱动须与 dialect 一致。
+// @Param dialect: SQL 方言，决定占位符、列类型与行锁语法。
+// @Param opts: 可选配置项，如 WithDedupWindow。
+// @Note: 使用前须调用 Migrate 创建表结构。
+func NewStore(db *sql.DB, dialect Dialect, opts ...Option) *Store {
+	s := &Store{
+		db:                db,
+		dialect:           dialect,
+		prefix:            "ddq",
+		visibilityTimeout: 60 * time.Second,
+		priorityAging:     time.Minute,
+		now:               time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	pairs := make([]string, 0, 2*len(tableNames)+4)
+	for _, name := range tableNames {
+		pairs = append(pairs, "{"+name+"}", s.prefix+"_"+name)
+	}
+	pairs = append(pairs, "{blob}", dialect.blobType(), "{aging}", strconv.FormatInt(s.priorityAging.Milliseconds(), 10))
+	s.tables = strings.NewReplacer(pairs...)
+	return s
+}
+
+// Open 打开数据库连接池、校验连通性并执行 Migrate，返回可直接使用的存储实例。
+// @Param driverName: 已注册的 database/sql 驱动名，为空时与方言同名。
+// @Note: SQLite 方言下连接池限制为单连接。
+func Open(ctx context.Context, dialect Dialect, driverName, dsn string, opts ...Option) (*Store, error) {
+	if driverName == "" {
+		driverName = string(dialect)
+	}
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open database failed: %w", err)
+	}
+	if dialect == SQLite {
+		db.SetMaxOpenConns(1)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("ping database failed: %w", err)
+	}
+
+	s := NewStore(db, dialect, opts...)
+	if err := s.Migrate(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// sql 将查询模板中的表名与占位符替换为当前前缀与方言的形式。
+func (s *Store) sql(query string) string {
+	return s.dialect.rebind(s.tables.Replace(query))
+}
+
+// withTx 在事务中执行 fn，fn 返回错误时回滚。
+func (s *Store) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if s.dialect == SQLite {
+		s.txMu.Lock()
+		defer s.txMu.Unlock()
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+	return nil
+}
+
+// visibilityOf 返回任务的可见性超时，任务自带值优先。
+func (s *Store) visibilityOf(task *pb.Task) time.Duration {
+	if task.VisibilityTimeout > 0 {
+		return time.Duration(task.VisibilityTimeout) * time.Second
+	}
+	return s.visibilityTimeout
+}
+
+// deduped 判断 ID 是否仍处于去重窗口内。
+func (s *Store) deduped(ctx context.Context, tx *sql.Tx, id string, now time.Time) (bool, error) {
+	var expiresAt int64
+	err := tx.QueryRowContext(ctx, s.sql(`SELECT expires_at FROM {dedup} WHERE id = ?`), id).Scan(&expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read dedup marker failed: %w", err)
+	}
+	return expiresAt > now.UnixMilli(), nil
+}
+
+// markDedup 写入去重标记，有效期覆盖到 from 之后的去重窗口。
+// @Note: 先查询再更新或插入，不依赖 UPDATE 的影响行数（MySQL 默认只统计实际发生变化的行）。
+func (s *Store) markDedup(ctx context.Context, tx *sql.Tx, id string, from time.Time) error {
+	if s.dedupWindow <= 0 {
+		return nil
+	}
+	expiresAt := from.Add(s.dedupWindow).UnixMilli()
+
+	var current int64
+	err := tx.QueryRowContext(ctx, s.sql(`SELECT expires_at FROM {dedup} WHERE id = ?`+s.dialect.lockClause(false)), id).Scan(&current)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, err = tx.ExecContext(ctx, s.sql(`INSERT INTO {dedup} (id, expires_at) VALUES (?, ?)`), id, expiresAt)
+	case err == nil:
+		_, err = tx.ExecContext(ctx, s.sql(`UPDATE {dedup} SET expires_at = ? WHERE id = ?`), expiresAt, id)
+	}
+	if err != nil {
+		return fmt.Errorf("write dedup marker failed: %w", err)
+	}
+	return nil
+}
+
+// insert 写入一个新的等待中任务并设置去重标记，调用方须已确认 ID 不存在。
+func (s *Store) insert(ctx context.Context, tx *sql.Tx, task *pb.Task, now time.Time) error {
+	body, err := proto.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("marshal task failed: %w", err)
+	}
+	executeAt := storage.ExecuteAt(task)
+	_, err = tx.ExecContext(ctx,
+		s.sql(`INSERT INTO {tasks} (id, topic, state, execute_time, priority, score, body) VALUES (?, ?, ?, ?, ?, ?, ?)`),
+		task.Id, task.Topic, statePending, executeAt.UnixMilli(), task.Priority, s.score(task, executeAt), body)
+	if err != nil {
+		return &insertError{err: err}
+	}
+	// 去重标记覆盖到执行时间之后再保留一个去重窗口
+	return s.markDedup(ctx, tx, task.Id, maxTime(executeAt, now))
+}
+
+// insertError 包装写入任务行的错误，用于区分并发写入同一 ID 导致的主键冲突。
+type insertError struct {
+	err error
+}
+
+func (e *insertError) Error() string { return "insert task failed: " + e.err.Error() }
+
+func (e *insertError) Unwrap() error { return e.err }
+
+// exists 判断任务行是否存在（不加锁，仅用于写入失败后的冲突判定）。
+func (s *Store) exists(ctx context.Context, id string) bool {
+	var one int
+	err := s.db.QueryRowContext(ctx, s.sql(`SELECT 1 FROM {tasks} WHERE id = ?`), id).Scan(&one)
+	return err == nil
+}
+
+// Add 写入待执行任务（ID 不存在才写入）。
+// @Return: ID 仍存在或处于去重窗口内时返回 errno.ErrTaskAlreadyExist，不做任何修改。
+func (s *Store) Add(ctx context.Context, task *pb.Task) error {
+	return s.add(ctx, task, false)
+}
+
+// Replace 以新任务覆盖同 ID 的旧任务；旧任务不存在时等同于 Add。
+// @Return: 旧任务正在执行时返回 errno.ErrTaskRunning。
+func (s *Store) Replace(ctx context.Context, task *pb.Task) error {
+	return s.add(ctx, task, true)
+}
+
+// add 是 Add 与 Replace 的共同实现，逻辑同 luaAdd。
+// @Description 两个事务并发写入同一个新 ID 时，后提交的一方因主键冲突失败，此时按 ID 已存在处理。
+func (s *Store) add(ctx context.Context, task *pb.Task, replace bool) error {
+	if task.Topic == "" {
+		return fmt.Errorf("task topic is required")
+	}
+
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		now := s.now()
+		var st int
+		err := tx.QueryRowContext(ctx, s.sql(`SELECT state FROM {tasks} WHERE id = ?`+s.dialect.lockClause(false)), task.Id).Scan(&st)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("read task failed: %w", err)
+		}
+		exists := err == nil
+		deduped, err := s.deduped(ctx, tx, task.Id, now)
+		if err != nil {
+			return err
+		}
+
+		if exists || deduped {
+			if !replace {
+				return errno.ErrTaskAlreadyExist
+			}
+			if exists {
+				if st == stateRunning {
+					return errno.ErrTaskRunning
+				}
+				if _, err := tx.ExecContext(ctx, s.sql(`DELETE FROM {tasks} WHERE id = ?`), task.Id); err != nil {
+					return fmt.Errorf("delete replaced task failed: %w", err)
+				}
+			}
+		}
+		return s.insert(ctx, tx, task, now)
+	})
+
+	var ie *insertError
+	if errors.As(err, &ie) && s.exists(ctx, task.Id) {
+		return errno.ErrTaskAlreadyExist
+	}
+	return err
+}
+
+// FetchAndHold 批量取出 Topic 中已到期的任务并标记为执行中，逻辑同 luaFetchAndHold。
+// @Description 以 SELECT ... FOR UPDATE SKIP LOCKED 按有效优先级（score 列，见 Store.score）取出至多 limit 个到期任务，
+// 其他事务已锁定的行被跳过；每个任务获得唯一的租约令牌，超时时刻为 now + visibility_timeout。
+// @Return: 无法解码的任务转入隔离区，不返回给调用方，也不影响同批其他任务。
+func (s *Store) FetchAndHold(ctx context.Context, topic string, limit int64) ([]*pb.Task, error) {
+	if topic == "" {
+		return nil, fmt.Errorf("topic is required")
+	}
+
+	var tasks []*pb.Task
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		now := s.now()
+		query := `SELECT id, topic, body FROM {tasks} WHERE topic = ? AND state = ? AND execute_time <= ? ORDER BY score, id LIMIT ?` +
+			s.dialect.lockClause(true)
+		rows, err := s.scanRows(ctx, tx, query, topic, statePending, now.UnixMilli(), limit)
+		if err != nil {
+			return err
+		}
+
+		tasks = make([]*pb.Task, 0, len(rows))
+		nonce := uuid.New().String()
+		for i, row := range rows {
+			task := &pb.Task{}
+			if decodeErr := proto.Unmarshal(row.body, task); decodeErr != nil {
+				if err := s.quarantine(ctx, tx, topic, row.id, row.body, decodeErr, "fetch", now); err != nil {
+					return err
+				}
+				continue
+			}
+			task.Lease = nonce + "-" + strconv.Itoa(i+1)
+			deadline := now.Add(s.visibilityOf(task)).UnixMilli()
+			_, err := tx.ExecContext(ctx, s.sql(`UPDATE {tasks} SET state = ?, lease = ?, deadline = ? WHERE id = ?`),
+				stateRunning, task.Lease, deadline, row.id)
+			if err != nil {
+				return fmt.Errorf("hold task failed: %w", err)
+			}
+			tasks = append(tasks, task)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// taskRow 是查询返回的任务 ID、Topic 与编码数据。
+type taskRow struct {
+	id    string
+	topic string
+	body  []byte
+}
+
+// scanRows 执行返回 (id, topic, body) 三列的查询并读出全部结果。
+// @Note: 先读完再处理，避免在同一连接上打开结果集的同时执行其他语句（MySQL 驱动不支持）。
+func (s *Store) scanRows(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]taskRow, error) {
+	rows, err := tx.QueryContext(ctx, s.sql(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query tasks failed: %w", err)
+	}
+	defer rows.Close()
+
+	var out []taskRow
+	for rows.Next() {
+		var r taskRow
+		if err := rows.Scan(&r.id, &r.topic, &r.body); err != nil {
+			return nil, fmt.Errorf("scan task failed: %w", err)
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tasks failed: %w", err)
+	}
+	return out, nil
+}
+
+// Remove 按 ID 撤销等待中或已死信的任务，并清除去重标记，允许以相同 ID 重新提交。
+// @Return: ID 不存在时返回 errno.ErrTaskNotFound；任务执行中返回 errno.ErrTaskRunning。
+func (s *Store) Remove(ctx context.Context, id string) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		var st int
+		err := tx.QueryRowContext(ctx, s.sql(`SELECT state FROM {tasks} WHERE id = ?`+s.dialect.lockClause(false)), id).Scan(&st)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errno.ErrTaskNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("read task failed: %w", err)
+		}
+		if st == stateRunning {
+			return errno.ErrTaskRunning
+		}
+		return s.delete(ctx, tx, id)
+	})
+}
+
+// delete 删除任务行及其去重标记。
+func (s *Store) delete(ctx context.Context, tx *sql.Tx, id string) error {
+	if _, err := tx.ExecContext(ctx, s.sql(`DELETE FROM {tasks} WHERE id = ?`), id); err != nil {
+		return fmt.Errorf("delete task failed: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, s.sql(`DELETE FROM {dedup} WHERE id = ?`), id); err != nil {
+		return fmt.Errorf("delete dedup marker failed: %w", err)
+	}
+	return nil
+}
+
+// running 锁定 Topic 下持有指定租约的执行中任务并返回其序列化数据，由需要任务内容的调用方自行解码。
+// @Return: 任务不在执行中时返回 errno.ErrTaskNotFound；租约令牌不匹配时返回 errno.ErrLeaseMismatch。
+func (s *Store) running(ctx context.Context, tx *sql.Tx, topic, id, lease string) ([]byte, error) {
+	var (
+		rowTopic, rowLease string
+		st                 int
+		body               []byte
+	)
+	err := tx.QueryRowContext(ctx, s.sql(`SELECT topic, state, lease, body FROM {tasks} WHERE id = ?`+s.dialect.lockClause(false)), id).
+		Scan(&rowTopic, &st, &rowLease, &body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errno.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read task failed: %w", err)
+	}
+	if st != stateRunning || rowTopic != topic {
+		return nil, errno.ErrTaskNotFound
+	}
+	if rowLease != lease {
+		return nil, errno.ErrLeaseMismatch
+	}
+	return body, nil
+}
+
+// Ack 确认任务完成并删除任务，去重标记保留 dedupWindow。不解码任务数据。
+// @Return: 任务不在执行中时返回 errno.ErrTaskNotFound；租约令牌不匹配时返回 errno.ErrLeaseMismatch。
+func (s *Store) Ack(ctx context.Context, topic, id, lease string) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if _, err := s.running(ctx, tx, topic, id, lease); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, s.sql(`DELETE FROM {tasks} WHERE id = ?`), id); err != nil {
+			return fmt.Errorf("delete task failed: %w", err)
+		}
+		return s.markDedup(ctx, tx, id, s.now())
+	})
+}
+
+// Nack 报告任务处理失败，逻辑同 luaNack。
+// @Description 重试计数递增后达到 max_retries 进入死信队列，否则在 opts.RetryDelay（未指定则按 retry_policy 退避）之后重新可见。
+// max_retries 优先使用任务自身的取值，未设置 (<=0) 时使用 opts.MaxRetries。
+// 任务数据无法解码时无法重试，任务转入隔离表。
+// @Return: 任务不在执行中（含被隔离）时返回 errno.ErrTaskNotFound；租约令牌不匹配时返回 errno.ErrLeaseMismatch。
+func (s *Store) Nack(ctx context.Context, topic, id, lease string, opts storage.NackOptions) error {
+	var quarantined bool
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		body, err := s.running(ctx, tx, topic, id, lease)
+		if err != nil {
+			return err
+		}
+
+		now := s.now()
+		task := &pb.Task{}
+		if err := proto.Unmarshal(body, task); err != nil {
+			quarantined = true
+			return s.quarantine(ctx, tx, topic, id, body, err, "nack", now)
+		}
+		task.RetryCount++
+		if opts.Reason != "" {
+			task.LastError = opts.Reason
+		}
+		limit := task.MaxRetries
+		if limit <= 0 {
+			limit = opts.MaxRetries
+		}
+		if task.RetryCount >= limit {
+			return s.bury(ctx, tx, task, "retries_exhausted", now)
+		}
+
+		delay := opts.RetryDelay
+		if delay <= 0 {
+			delay = storage.Backoff(task, rand.Float64)
+		}
+		task.LastBackoff = int64(delay / time.Second)
+		return s.requeue(ctx, tx, task, now.Add(delay))
+	})
+	if err == nil && quarantined {
+		return errno.ErrTaskNotFound
+	}
+	return err
+}
+
+// score 计算任务在 at 可见时的有效优先级分数，越小越先被拉取，规则同 luaFetchAndHold 写入 ready ZSet 的分数。
+// @Note: 分数在写入时按当前的 priorityAging 固化，修改 WithPriorityAging 只影响之后写入或重试的任务。
+func (s *Store) score(task *pb.Task, at time.Time) int64 {
+	return at.UnixMilli() - int64(task.Priority)*s.priorityAging.Milliseconds()
+}
+
+// requeue 将任务写回等待状态，at 之后可被拉取。
+func (s *Store) requeue(ctx context.Context, tx *sql.Tx, task *pb.Task, at time.Time) error {
+	body, err := proto.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("marshal task failed: %w", err)
+	}
+	_, err = tx.ExecContext(ctx,
+		s.sql(`UPDATE {tasks} SET state = ?, execute_time = ?, score = ?, lease = '', deadline = 0, body = ? WHERE id = ?`),
+		statePending, at.UnixMilli(), s.score(task, at), body, task.Id)
+	if err != nil {
+		return fmt.Errorf("requeue task failed: %w", err)
+	}
+	return nil
+}
+
+// bury 将任务放入死信队列，记录死信原因与时间。
+func (s *Store) bury(ctx context.Context, tx *sql.Tx, task *pb.Task, reason string, now time.Time) error {
+	task.DeadReason = reason
+	task.DeadAt = now.Unix()
+	body, err := proto.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("marshal task failed: %w", err)
+	}
+
+	// 死信序号由 sequences 表分配，UPDATE 锁定计数行，保证并发进入死信的任务序号唯一且递增
+	if _, err := tx.ExecContext(ctx, s.sql(`UPDATE {sequences} SET seq = seq + 1 WHERE name = 'dlq'`)); err != nil {
+		return fmt.Errorf("allocate dead-letter sequence failed: %w", err)
+	}
+	var seq int64
+	if err := tx.QueryRowContext(ctx, s.sql(`SELECT seq FROM {sequences} WHERE name = 'dlq'`)).Scan(&seq); err != nil {
+		return fmt.Errorf("read dead-letter sequence failed: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		s.sql(`UPDATE {tasks} SET state = ?, lease = '', deadline = 0, dead_seq = ?, body = ? WHERE id = ?`),
+		stateDead, seq, body, task.Id)
+	if err != nil {
+		return fmt.Errorf("bury task failed: %w", err)
+	}
+	return nil
+}
+
+// Extend 将执行中任务的超时时刻推迟到 now + extra，只会延长不会缩短。不解码任务数据。
+// @Return: 任务不在执行中时返回 errno.ErrTaskNotFound；租约令牌不匹配时返回 errno.ErrLeaseMismatch。
+func (s *Store) Extend(ctx context.Context, topic, id, lease string, extra time.Duration) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if _, err := s.running(ctx, tx, topic, id, lease); err != nil {
+			return err
+		}
+		deadline := s.now().Add(extra).UnixMilli()
+		_, err := tx.ExecContext(ctx, s.sql(`UPDATE {tasks} SET deadline = ? WHERE id = ? AND deadline < ?`), deadline, id, deadline)
+		if err != nil {
+			return fmt.Errorf("extend task failed: %w", err)
+		}
+		return nil
+	})
+}
+
+// CheckAndMoveExpired 回收超时的执行中任务，逻辑同 luaRecover。
+// @Description 按 (state, deadline) 索引只读取已超时的任务，每个事务处理 recoverBatchSize 个，
+// 单次调用最多 recoverMaxBatches 个事务；多个副本并发回收时以 SKIP LOCKED 互相避让。顺带清理已过期的去重标记。
+// 超时时刻在 FetchAndHold 时按任务自身或 WithVisibilityTimeout 的可见性超时计算，visibilityTimeout 参数不再参与判定。
+// max_retries 优先使用任务自身的取值，未设置 (<=0) 时使用 maxRetries。
+func (s *Store) CheckAndMoveExpired(ctx context.Context, visibilityTimeout int64, maxRetries int32) (storage.RecoverStats, error) {
+	var stats storage.RecoverStats
+	for range recoverMaxBatches {
+		var n int
+		err := s.withTx(ctx, func(tx *sql.Tx) error {
+			now := s.now()
+			rows, err := s.scanRows(ctx, tx,
+				`SELECT id, topic, body FROM {tasks} WHERE state = ? AND deadline < ? ORDER BY deadline LIMIT ?`+s.dialect.lockClause(true),
+				stateRunning, now.UnixMilli(), recoverBatchSize)
+			if err != nil {
+				return err
+			}
+			n = len(rows)
+
+			var batch storage.RecoverStats
+			for _, row := range rows {
+				task := &pb.Task{}
+				if decodeErr := proto.Unmarshal(row.body, task); decodeErr != nil {
+					if err := s.quarantine(ctx, tx, row.topic, row.id, row.body, decodeErr, "recover", now); err != nil {
+						return err
+					}
+					batch.Quarantined++
+					continue
+				}
+
+				limit := task.MaxRetries
+				if limit <= 0 {
+					limit = maxRetries
+				}
+				task.RetryCount++
+				if task.RetryCount >= limit {
+					if err := s.bury(ctx, tx, task, "visibility_timeout", now); err != nil {
+						return err
+					}
+					batch.Dead++
+					continue
+				}
+				delay := storage.Backoff(task, rand.Float64)
+				task.LastBackoff = int64(delay / time.Second)
+				if err := s.requeue(ctx, tx, task, now.Add(delay)); err != nil {
+					return err
+				}
+				batch.Requeued++
+			}
+			stats.Requeued += batch.Requeued
+			stats.Dead += batch.Dead
+			stats.Quarantined += batch.Quarantined
+			return nil
+		})
+		if err != nil {
+			return stats, err
+		}
+		if n < recoverBatchSize {
+			break
+		}
+	}
+
+	if _, err := s.db.ExecContext(ctx, s.sql(`DELETE FROM {dedup} WHERE expires_at <= ?`), s.now().UnixMilli()); err != nil {
+		return stats, fmt.Errorf("delete expired dedup markers failed: %w", err)
+	}
+	return stats, nil
+}
+
+// maxTime 返回两个时刻中较晚的一个。
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
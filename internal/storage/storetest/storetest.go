@@ -0,0 +1,692 @@
+// Package storetest 提供 storage.JobStore 的一致性测试套件。
+// 职责：以同一组用例约束所有存储实现的行为（到期顺序、租约、重试、死信、超时回收、删除与损坏数据处理），
+// 防止不同后端之间的语义漂移。新的存储实现应在自己的测试中调用 RunConformance。
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+)
+
+// topic 用例使用的业务主题。每个用例都拿到独立的存储，因此无需区分。
+const topic = "conformance"
+
+// recoverWait 超时回收用例的等待时长。
+// @Note: 可见性超时以秒为单位，Redis 实现按秒级截止时刻判定"严格早于 now"，最坏需要等待接近 2 秒。
+const recoverWait = 2100 * time.Millisecond
+
+// Harness 描述一个被测的存储实例。
+type Harness struct {
+	// Store 被测存储，使用默认的去重窗口、可见性超时与优先级老化配置。
+	Store storage.JobStore
+	// Corrupt 将 Topic 下任务 id 的存储数据改写为无法解码的内容，为 nil 表示该实现不存在损坏数据，跳过相关用例。
+	Corrupt func(t *testing.T, topic, id string)
+}
+
+// Factory 为每个用例创建一个互不影响的空存储，资源清理由 Factory 通过 t.Cleanup 注册。
+type Factory func(t *testing.T) Harness
+
+// RunConformance 对 factory 创建的存储运行全部一致性用例。
+// @Description 可选能力（Notifier、ScheduleStore、QuarantineStore）仅在实现支持时测试。
+// 超时回收用例依赖真实时钟，整体耗时约 2 秒。
+func RunConformance(t *testing.T, factory Factory) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, h Harness)
+	}{
+		{"DueOrdering", testDueOrdering},
+		{"Priority", testPriority},
+		{"FetchLimit", testFetchLimit},
+		{"TopicIsolation", testTopicIsolation},
+		{"Lease", testLease},
+		{"ConcurrentFetch", testConcurrentFetch},
+		{"NackRetry", testNackRetry},
+		{"RetriesExhausted", testRetriesExhausted},
+		{"DefaultMaxRetries", testDefaultMaxRetries},
+		{"VisibilityTimeout", testVisibilityTimeout},
+		{"Dedup", testDedup},
+		{"Remove", testRemove},
+		{"DeadLetters", testDeadLetters},
+		{"MalformedRequests", testMalformedRequests},
+		{"CorruptData", testCorruptData},
+		{"Notifier", testNotifier},
+		{"Schedules", testSchedules},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, factory(t))
+		})
+	}
+}
+
+// newTask 构造在 at 执行、最多重试 3 次的任务。
+func newTask(id string, at time.Time) *pb.Task {
+	return &pb.Task{
+		Id:            id,
+		Topic:         topic,
+		Payload:       "payload-" + id,
+		ExecuteTime:   at.Unix(),
+		ExecuteTimeMs: at.UnixMilli(),
+		MaxRetries:    3,
+		CreatedAt:     time.Now().Unix(),
+	}
+}
+
+func mustAdd(t *testing.T, s storage.JobStore, tasks ...*pb.Task) {
+	t.Helper()
+	for _, task := range tasks {
+		if err := s.Add(context.Background(), task); err != nil {
+			t.Fatalf("Add(%s) error = %v", task.Id, err)
+		}
+	}
+}
+
+func mustFetch(t *testing.T, s storage.JobStore, limit int64) []*pb.Task {
+	t.Helper()
+	tasks, err := s.FetchAndHold(context.Background(), topic, limit)
+	if err != nil {
+		t.Fatalf("FetchAndHold() error = %v", err)
+	}
+	return tasks
+}
+
+// mustDead 添加一个只允许执行一次的任务，拉取后 Nack 使其进入死信队列。
+func mustDead(t *testing.T, s storage.JobStore, id string) {
+	t.Helper()
+	task := newTask(id, time.Now().Add(-time.Second))
+	task.MaxRetries = 1
+	mustAdd(t, s, task)
+	held := mustFetch(t, s, 1)
+	if len(held) != 1 || held[0].Id != id {
+		t.Fatalf("FetchAndHold() = %v, want [%s]", ids(held), id)
+	}
+	if err := s.Nack(context.Background(), topic, id, held[0].Lease, storage.NackOptions{Reason: "boom"}); err != nil {
+		t.Fatalf("Nack(%s) error = %v", id, err)
+	}
+}
+
+func ids(tasks []*pb.Task) []string {
+	out := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		out = append(out, task.Id)
+	}
+	return out
+}
+
+func assertIDs(t *testing.T, what string, tasks []*pb.Task, want ...string) {
+	t.Helper()
+	got := ids(tasks)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("%s = %v, want %v", what, got, want)
+	}
+}
+
+func assertErr(t *testing.T, what string, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Errorf("%s error = %v, want %v", what, err, want)
+	}
+}
+
+func testDueOrdering(t *testing.T, h Harness) {
+	now := time.Now()
+	mustAdd(t, h.Store,
+		newTask("late", now.Add(time.Hour)),
+		newTask("t3", now.Add(-1*time.Second)),
+		newTask("t1", now.Add(-3*time.Second)),
+		newTask("t2", now.Add(-2*time.Second)),
+	)
+
+	assertIDs(t, "FetchAndHold()", mustFetch(t, h.Store, 10), "t1", "t2", "t3")
+	assertIDs(t, "FetchAndHold() again", mustFetch(t, h.Store, 10))
+}
+
+func testPriority(t *testing.T, h Harness) {
+	now := time.Now()
+	urgent := newTask("urgent", now)
+	urgent.Priority = 10
+	mustAdd(t, h.Store, newTask("normal", now.Add(-2*time.Second)), urgent)
+
+	assertIDs(t, "FetchAndHold()", mustFetch(t, h.Store, 10), "urgent", "normal")
+}
+
+func testFetchLimit(t *testing.T, h Harness) {
+	now := time.Now()
+	for i := range 5 {
+		mustAdd(t, h.Store, newTask(fmt.Sprintf("t%d", i), now.Add(time.Duration(i-10)*time.Second)))
+	}
+
+	assertIDs(t, "FetchAndHold(2)", mustFetch(t, h.Store, 2), "t0", "t1")
+	assertIDs(t, "FetchAndHold(10)", mustFetch(t, h.Store, 10), "t2", "t3", "t4")
+}
+
+func testTopicIsolation(t *testing.T, h Harness) {
+	ctx := context.Background()
+	other := newTask("other", time.Now().Add(-time.Second))
+	other.Topic = "conformance-other"
+	mustAdd(t, h.Store, other, newTask("mine", time.Now().Add(-time.Second)))
+
+	held := mustFetch(t, h.Store, 10)
+	assertIDs(t, "FetchAndHold()", held, "mine")
+	assertErr(t, "Ack() with wrong topic", h.Store.Ack(ctx, other.Topic, "mine", held[0].Lease), errno.ErrTaskNotFound)
+
+	got, err := h.Store.FetchAndHold(ctx, other.Topic, 10)
+	if err != nil {
+		t.Fatalf("FetchAndHold(other) error = %v", err)
+	}
+	assertIDs(t, "FetchAndHold(other)", got, "other")
+}
+
+func testLease(t *testing.T, h Harness) {
+	ctx := context.Background()
+	now := time.Now()
+	mustAdd(t, h.Store, newTask("a", now.Add(-time.Second)), newTask("b", now.Add(-time.Second)))
+
+	held := mustFetch(t, h.Store, 10)
+	if len(held) != 2 {
+		t.Fatalf("FetchAndHold() = %v, want 2 tasks", ids(held))
+	}
+	if held[0].Lease == "" || held[0].Lease == held[1].Lease {
+		t.Fatalf("leases %q and %q must be non-empty and unique", held[0].Lease, held[1].Lease)
+	}
+	a := held[0]
+
+	assertErr(t, "Ack() with other lease", h.Store.Ack(ctx, topic, a.Id, held[1].Lease), errno.ErrLeaseMismatch)
+	assertErr(t, "Nack() with stale lease", h.Store.Nack(ctx, topic, a.Id, "stale", storage.NackOptions{}), errno.ErrLeaseMismatch)
+	assertErr(t, "Extend() with stale lease", h.Store.Extend(ctx, topic, a.Id, "stale", time.Minute), errno.ErrLeaseMismatch)
+
+	if err := h.Store.Extend(ctx, topic, a.Id, a.Lease, time.Minute); err != nil {
+		t.Errorf("Extend() error = %v", err)
+	}
+	if err := h.Store.Ack(ctx, topic, a.Id, a.Lease); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	assertErr(t, "Ack() twice", h.Store.Ack(ctx, topic, a.Id, a.Lease), errno.ErrTaskNotFound)
+	assertErr(t, "Extend() after Ack", h.Store.Extend(ctx, topic, a.Id, a.Lease, time.Minute), errno.ErrTaskNotFound)
+	assertErr(t, "Nack() after Ack", h.Store.Nack(ctx, topic, a.Id, a.Lease, storage.NackOptions{}), errno.ErrTaskNotFound)
+}
+
+func testConcurrentFetch(t *testing.T, h Harness) {
+	ctx := context.Background()
+	const total = 200
+	due := time.Now().Add(-time.Second)
+	for i := range total {
+		mustAdd(t, h.Store, newTask(fmt.Sprintf("t%03d", i), due))
+	}
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]int)
+		wg   sync.WaitGroup
+	)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				held, err := h.Store.FetchAndHold(ctx, topic, 7)
+				if err != nil {
+					t.Errorf("FetchAndHold() error = %v", err)
+					return
+				}
+				if len(held) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, task := range held {
+					seen[task.Id]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != total {
+		t.Errorf("delivered %d distinct tasks, want %d", len(seen), total)
+	}
+	for id, n := range seen {
+		if n > 1 {
+			t.Errorf("task %s delivered %d times", id, n)
+		}
+	}
+}
+
+func testNackRetry(t *testing.T, h Harness) {
+	ctx := context.Background()
+	mustAdd(t, h.Store, newTask("a", time.Now().Add(-time.Second)))
+
+	// 未设置退避策略且未指定等待时长：立即重新可见
+	first := mustFetch(t, h.Store, 1)[0]
+	if err := h.Store.Nack(ctx, topic, "a", first.Lease, storage.NackOptions{Reason: "boom"}); err != nil {
+		t.Fatalf("Nack() error = %v", err)
+	}
+	assertErr(t, "Ack() after Nack", h.Store.Ack(ctx, topic, "a", first.Lease), errno.ErrTaskNotFound)
+
+	held := mustFetch(t, h.Store, 1)
+	assertIDs(t, "FetchAndHold() after Nack", held, "a")
+	if len(held) != 1 {
+		return
+	}
+	second := held[0]
+	if second.RetryCount != 1 || second.LastError != "boom" || second.Payload != "payload-a" {
+		t.Errorf("retried task = %v, want retry_count 1, last_error boom and the original payload", second)
+	}
+	if second.Lease == first.Lease {
+		t.Errorf("redelivery reused lease %q", first.Lease)
+	}
+	assertErr(t, "Ack() with previous delivery's lease", h.Store.Ack(ctx, topic, "a", first.Lease), errno.ErrLeaseMismatch)
+
+	// 显式等待时长：期间不可见
+	if err := h.Store.Nack(ctx, topic, "a", second.Lease, storage.NackOptions{RetryDelay: time.Hour}); err != nil {
+		t.Fatalf("Nack() with delay error = %v", err)
+	}
+	assertIDs(t, "FetchAndHold() during retry delay", mustFetch(t, h.Store, 1))
+}
+
+func testRetriesExhausted(t *testing.T, h Harness) {
+	ctx := context.Background()
+	task := newTask("a", time.Now().Add(-time.Second))
+	task.MaxRetries = 2
+	mustAdd(t, h.Store, task)
+
+	for i := range 2 {
+		held := mustFetch(t, h.Store, 1)
+		if len(held) != 1 {
+			t.Fatalf("attempt %d: FetchAndHold() = %v", i+1, ids(held))
+		}
+		if err := h.Store.Nack(ctx, topic, "a", held[0].Lease, storage.NackOptions{Reason: fmt.Sprintf("fail %d", i+1)}); err != nil {
+			t.Fatalf("attempt %d: Nack() error = %v", i+1, err)
+		}
+	}
+
+	assertIDs(t, "FetchAndHold() after exhaustion", mustFetch(t, h.Store, 1))
+	dead, err := h.Store.GetDead(ctx, "a")
+	if err != nil {
+		t.Fatalf("GetDead() error = %v", err)
+	}
+	if dead.RetryCount != 2 || dead.DeadReason != "retries_exhausted" || dead.LastError != "fail 2" || dead.DeadAt == 0 {
+		t.Errorf("dead task = %v", dead)
+	}
+	list, _, err := h.Store.ListDead(ctx, storage.DeadLetterQuery{Topic: topic, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListDead() error = %v", err)
+	}
+	assertIDs(t, "ListDead()", list, "a")
+}
+
+// testDefaultMaxRetries 验证任务未设置 max_retries 时 Nack 使用 NackOptions.MaxRetries，而不是第一次失败就进入死信队列。
+func testDefaultMaxRetries(t *testing.T, h Harness) {
+	ctx := context.Background()
+	task := newTask("a", time.Now().Add(-time.Second))
+	task.MaxRetries = 0
+	mustAdd(t, h.Store, task)
+
+	for i := range 2 {
+		held := mustFetch(t, h.Store, 1)
+		if len(held) != 1 {
+			t.Fatalf("attempt %d: FetchAndHold() = %v", i+1, ids(held))
+		}
+		if err := h.Store.Nack(ctx, topic, "a", held[0].Lease, storage.NackOptions{MaxRetries: 2}); err != nil {
+			t.Fatalf("attempt %d: Nack() error = %v", i+1, err)
+		}
+		if _, err := h.Store.GetDead(ctx, "a"); i == 0 && !errors.Is(err, errno.ErrTaskNotFound) {
+			t.Fatalf("GetDead() after first Nack error = %v, want ErrTaskNotFound", err)
+		}
+	}
+
+	dead, err := h.Store.GetDead(ctx, "a")
+	if err != nil {
+		t.Fatalf("GetDead() error = %v", err)
+	}
+	if dead.RetryCount != 2 || dead.DeadReason != "retries_exhausted" {
+		t.Errorf("dead task = %v", dead)
+	}
+}
+
+func testVisibilityTimeout(t *testing.T, h Harness) {
+	ctx := context.Background()
+	due := time.Now().Add(-time.Second)
+	expired := newTask("expired", due)
+	last := newTask("last", due)
+	last.MaxRetries = 1
+	extended := newTask("extended", due)
+	brief := newTask("brief", due)
+	for _, task := range []*pb.Task{expired, last, extended, brief} {
+		task.VisibilityTimeout = 1
+	}
+	mustAdd(t, h.Store, expired, last, extended, brief)
+
+	leases := make(map[string]string)
+	for _, task := range mustFetch(t, h.Store, 10) {
+		leases[task.Id] = task.Lease
+	}
+	if err := h.Store.Extend(ctx, topic, "extended", leases["extended"], time.Hour); err != nil {
+		t.Fatalf("Extend() error = %v", err)
+	}
+	// 不足整秒的续租不能被截断：recoverWait 之后任务仍应处于执行中
+	if err := h.Store.Extend(ctx, topic, "brief", leases["brief"], recoverWait+800*time.Millisecond); err != nil {
+		t.Fatalf("Extend() with sub-second remainder error = %v", err)
+	}
+
+	// 未超时时不回收任何任务
+	stats, err := h.Store.CheckAndMoveExpired(ctx, 60, 3)
+	if err != nil || stats.Requeued != 0 || stats.Dead != 0 {
+		t.Fatalf("CheckAndMoveExpired() before timeout = %+v, %v", stats, err)
+	}
+
+	time.Sleep(recoverWait)
+	stats, err = h.Store.CheckAndMoveExpired(ctx, 60, 3)
+	if err != nil {
+		t.Fatalf("CheckAndMoveExpired() error = %v", err)
+	}
+	if stats.Requeued != 1 || stats.Dead != 1 {
+		t.Errorf("CheckAndMoveExpired() = %+v, want 1 requeued and 1 dead", stats)
+	}
+
+	held := mustFetch(t, h.Store, 10)
+	assertIDs(t, "FetchAndHold() after recovery", held, "expired")
+	if len(held) == 1 && held[0].RetryCount != 1 {
+		t.Errorf("recovered retry_count = %d, want 1", held[0].RetryCount)
+	}
+	assertErr(t, "Ack() with pre-timeout lease", h.Store.Ack(ctx, topic, "expired", leases["expired"]), errno.ErrLeaseMismatch)
+
+	dead, err := h.Store.GetDead(ctx, "last")
+	if err != nil {
+		t.Fatalf("GetDead() error = %v", err)
+	}
+	if dead.DeadReason != "visibility_timeout" {
+		t.Errorf("dead_reason = %q, want visibility_timeout", dead.DeadReason)
+	}
+	if err := h.Store.Ack(ctx, topic, "extended", leases["extended"]); err != nil {
+		t.Errorf("Ack() of extended task error = %v", err)
+	}
+	if err := h.Store.Ack(ctx, topic, "brief", leases["brief"]); err != nil {
+		t.Errorf("Ack() of task extended by a sub-second remainder error = %v", err)
+	}
+}
+
+func testDedup(t *testing.T, h Harness) {
+	ctx := context.Background()
+	mustAdd(t, h.Store, newTask("a", time.Now().Add(-time.Second)))
+	assertErr(t, "Add() duplicate", h.Store.Add(ctx, newTask("a", time.Now())), errno.ErrTaskAlreadyExist)
+
+	replaced := newTask("a", time.Now().Add(-time.Second))
+	replaced.Payload = "v2"
+	if err := h.Store.Replace(ctx, replaced); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	held := mustFetch(t, h.Store, 10)
+	if len(held) != 1 || held[0].Payload != "v2" {
+		t.Fatalf("FetchAndHold() after Replace = %v, want the replacement only", held)
+	}
+	assertErr(t, "Replace() of running task", h.Store.Replace(ctx, newTask("a", time.Now())), errno.ErrTaskRunning)
+
+	if err := h.Store.Replace(ctx, newTask("fresh", time.Now().Add(-time.Second))); err != nil {
+		t.Errorf("Replace() of unknown ID error = %v", err)
+	}
+	assertIDs(t, "FetchAndHold() after Replace of unknown ID", mustFetch(t, h.Store, 10), "fresh")
+}
+
+func testRemove(t *testing.T, h Harness) {
+	ctx := context.Background()
+	mustAdd(t, h.Store, newTask("running", time.Now().Add(-time.Second)))
+	assertIDs(t, "FetchAndHold()", mustFetch(t, h.Store, 1), "running")
+	mustDead(t, h.Store, "dead")
+	mustAdd(t, h.Store, newTask("pending", time.Now().Add(time.Hour)))
+
+	if err := h.Store.Remove(ctx, "pending"); err != nil {
+		t.Errorf("Remove(pending) error = %v", err)
+	}
+	assertErr(t, "Remove(pending) twice", h.Store.Remove(ctx, "pending"), errno.ErrTaskNotFound)
+	if err := h.Store.Remove(ctx, "dead"); err != nil {
+		t.Errorf("Remove(dead) error = %v", err)
+	}
+	assertErr(t, "GetDead() after Remove", func() error { _, err := h.Store.GetDead(ctx, "dead"); return err }(), errno.ErrTaskNotFound)
+	assertErr(t, "Remove(running)", h.Store.Remove(ctx, "running"), errno.ErrTaskRunning)
+	assertErr(t, "Remove(unknown)", h.Store.Remove(ctx, "unknown"), errno.ErrTaskNotFound)
+
+	// 删除释放 ID，可以重新提交
+	if err := h.Store.Add(ctx, newTask("pending", time.Now().Add(-time.Second))); err != nil {
+		t.Errorf("Add() after Remove error = %v", err)
+	}
+	assertIDs(t, "FetchAndHold() after re-Add", mustFetch(t, h.Store, 10), "pending")
+}
+
+func testDeadLetters(t *testing.T, h Harness) {
+	ctx := context.Background()
+	for _, id := range []string{"d1", "d2", "d3"} {
+		mustDead(t, h.Store, id)
+	}
+
+	page, next, err := h.Store.ListDead(ctx, storage.DeadLetterQuery{Topic: topic, Limit: 2})
+	if err != nil {
+		t.Fatalf("ListDead() error = %v", err)
+	}
+	assertIDs(t, "ListDead() first page", page, "d3", "d2")
+	page, next, err = h.Store.ListDead(ctx, storage.DeadLetterQuery{Topic: topic, Offset: next, Limit: 2})
+	if err != nil {
+		t.Fatalf("ListDead() error = %v", err)
+	}
+	assertIDs(t, "ListDead() second page", page, "d1")
+	if next != 0 {
+		t.Errorf("ListDead() next = %d, want 0 at the end", next)
+	}
+
+	n, err := h.Store.Redrive(ctx, storage.DeadLetterSelector{Topic: topic, IDs: []string{"d1", "missing"}}, 0)
+	if err != nil || n != 1 {
+		t.Fatalf("Redrive() = %d, %v, want 1", n, err)
+	}
+	held := mustFetch(t, h.Store, 10)
+	assertIDs(t, "FetchAndHold() after Redrive", held, "d1")
+	if len(held) == 1 && (held[0].RetryCount != 0 || held[0].DeadReason != "" || held[0].DeadAt != 0 || held[0].LastError != "boom") {
+		t.Errorf("redriven task = %v, want retry and dead-letter fields reset and last_error kept", held[0])
+	}
+
+	n, err = h.Store.Purge(ctx, storage.DeadLetterSelector{Topic: topic, All: true})
+	if err != nil || n != 2 {
+		t.Fatalf("Purge(all) = %d, %v, want 2", n, err)
+	}
+	assertErr(t, "GetDead() after Purge", func() error { _, err := h.Store.GetDead(ctx, "d2"); return err }(), errno.ErrTaskNotFound)
+	if err := h.Store.Add(ctx, newTask("d2", time.Now())); err != nil {
+		t.Errorf("Add() after Purge error = %v", err)
+	}
+}
+
+func testMalformedRequests(t *testing.T, h Harness) {
+	ctx := context.Background()
+	noTopic := newTask("no-topic", time.Now())
+	noTopic.Topic = ""
+	if err := h.Store.Add(ctx, noTopic); err == nil {
+		t.Error("Add() without topic should fail")
+	}
+	if _, err := h.Store.FetchAndHold(ctx, "", 10); err == nil {
+		t.Error("FetchAndHold() without topic should fail")
+	}
+
+	got, err := h.Store.FetchAndHold(ctx, "conformance-unknown", 10)
+	if err != nil || len(got) != 0 {
+		t.Errorf("FetchAndHold(unknown topic) = %v, %v, want empty", ids(got), err)
+	}
+	assertErr(t, "Ack(unknown)", h.Store.Ack(ctx, topic, "unknown", "lease"), errno.ErrTaskNotFound)
+	assertErr(t, "Nack(unknown)", h.Store.Nack(ctx, topic, "unknown", "lease", storage.NackOptions{}), errno.ErrTaskNotFound)
+	assertErr(t, "Extend(unknown)", h.Store.Extend(ctx, topic, "unknown", "lease", time.Minute), errno.ErrTaskNotFound)
+	assertErr(t, "GetDead(unknown)", func() error { _, err := h.Store.GetDead(ctx, "unknown"); return err }(), errno.ErrTaskNotFound)
+
+	// 等待中的任务不是死信
+	mustAdd(t, h.Store, newTask("pending", time.Now().Add(time.Hour)))
+	assertErr(t, "GetDead(pending)", func() error { _, err := h.Store.GetDead(ctx, "pending"); return err }(), errno.ErrTaskNotFound)
+	if n, err := h.Store.Purge(ctx, storage.DeadLetterSelector{IDs: []string{"pending"}}); err != nil || n != 0 {
+		t.Errorf("Purge(pending) = %d, %v, want 0", n, err)
+	}
+
+	if _, _, err := h.Store.ListDead(ctx, storage.DeadLetterQuery{Limit: 10}); err == nil {
+		t.Error("ListDead() without topic should fail")
+	}
+	if _, err := h.Store.Purge(ctx, storage.DeadLetterSelector{All: true}); err == nil {
+		t.Error("Purge(all) without topic should fail")
+	}
+	if _, err := h.Store.Redrive(ctx, storage.DeadLetterSelector{All: true}, 0); err == nil {
+		t.Error("Redrive(all) without topic should fail")
+	}
+}
+
+func testCorruptData(t *testing.T, h Harness) {
+	if h.Corrupt == nil {
+		t.Skip("store has no undecodable data")
+	}
+	ctx := context.Background()
+	due := time.Now().Add(-time.Second)
+	mustAdd(t, h.Store, newTask("bad", due.Add(-time.Second)), newTask("good", due))
+	h.Corrupt(t, topic, "bad")
+
+	// 损坏的条目不返回给调用方，也不能使整批拉取失败
+	assertIDs(t, "FetchAndHold()", mustFetch(t, h.Store, 10), "good")
+	assertIDs(t, "FetchAndHold() again", mustFetch(t, h.Store, 10))
+
+	// 执行中任务的数据在拉取后损坏：Ack/Extend 不依赖任务内容照常生效，Nack 无法重试，转入隔离区
+	mustAdd(t, h.Store, newTask("held-ack", due), newTask("held-extend", due), newTask("held-nack", due))
+	held := map[string]*pb.Task{}
+	for _, task := range mustFetch(t, h.Store, 10) {
+		held[task.Id] = task
+		h.Corrupt(t, topic, task.Id)
+	}
+	if len(held) != 3 {
+		t.Fatalf("FetchAndHold() held = %v, want 3 tasks", held)
+	}
+	if err := h.Store.Extend(ctx, topic, "held-extend", held["held-extend"].Lease, time.Minute); err != nil {
+		t.Errorf("Extend() on corrupt task error = %v", err)
+	}
+	if err := h.Store.Ack(ctx, topic, "held-ack", held["held-ack"].Lease); err != nil {
+		t.Errorf("Ack() on corrupt task error = %v", err)
+	}
+	err := h.Store.Nack(ctx, topic, "held-nack", held["held-nack"].Lease, storage.NackOptions{Reason: "boom"})
+	assertErr(t, "Nack() on corrupt task", err, errno.ErrTaskNotFound)
+
+	// 损坏的死信在重投时转入隔离区，不影响同批其他死信
+	mustDead(t, h.Store, "dead-bad")
+	mustDead(t, h.Store, "dead-good")
+	h.Corrupt(t, topic, "dead-bad")
+	if n, err := h.Store.Redrive(ctx, storage.DeadLetterSelector{Topic: topic, All: true}, 0); err != nil || n != 1 {
+		t.Errorf("Redrive(all) with a corrupt dead letter = %d, %v, want 1", n, err)
+	}
+
+	qs, ok := h.Store.(storage.QuarantineStore)
+	if !ok {
+		return
+	}
+	entries, _, err := qs.ListQuarantined(ctx, topic, 0, 10)
+	if err != nil {
+		t.Fatalf("ListQuarantined() error = %v", err)
+	}
+	got := map[string]string{}
+	for _, e := range entries {
+		if e.Error == "" {
+			t.Errorf("ListQuarantined() entry %s has no error", e.Id)
+		}
+		got[e.Id] = e.Source
+	}
+	want := map[string]string{"bad": "fetch", "held-nack": "nack", "dead-bad": "redrive"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ListQuarantined() sources = %v, want %v", got, want)
+	}
+	if n, err := qs.DeleteQuarantined(ctx, topic, nil, true); err != nil || n != 3 {
+		t.Errorf("DeleteQuarantined(all) = %d, %v, want 3", n, err)
+	}
+}
+
+func testNotifier(t *testing.T, h Harness) {
+	n, ok := h.Store.(storage.Notifier)
+	if !ok {
+		t.Skip("store does not implement storage.Notifier")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, ok, err := n.NextDueTime(ctx, topic); err != nil || ok {
+		t.Errorf("NextDueTime() on empty topic = %v, %v, want not ok", ok, err)
+	}
+
+	ch, err := n.Notifications(ctx, []string{topic})
+	if err != nil {
+		t.Fatalf("Notifications() error = %v", err)
+	}
+	at := time.UnixMilli(time.Now().Add(time.Hour).UnixMilli())
+	mustAdd(t, h.Store, newTask("a", at))
+	select {
+	case got := <-ch:
+		if got != topic {
+			t.Errorf("notification topic = %q, want %q", got, topic)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("no notification after Add")
+	}
+
+	due, ok, err := n.NextDueTime(ctx, topic)
+	if err != nil || !ok || !due.Equal(at) {
+		t.Errorf("NextDueTime() = %v, %v, %v, want %v", due, ok, err, at)
+	}
+}
+
+func testSchedules(t *testing.T, h Harness) {
+	ss, ok := h.Store.(storage.ScheduleStore)
+	if !ok {
+		t.Skip("store does not implement storage.ScheduleStore")
+	}
+	ctx := context.Background()
+	next := time.Unix(time.Now().Add(-time.Second).Unix(), 0)
+	if err := ss.CreateSchedule(ctx, &pb.Schedule{Id: "s1", Topic: topic, CronExpr: "* * * * *", NextRunTime: next.Unix()}); err != nil {
+		t.Fatalf("CreateSchedule() error = %v", err)
+	}
+	assertErr(t, "CreateSchedule() duplicate", ss.CreateSchedule(ctx, &pb.Schedule{Id: "s1", Topic: topic}), errno.ErrScheduleAlreadyExist)
+
+	due, err := ss.DueSchedules(ctx, time.Now(), 10)
+	if err != nil || len(due) != 1 || due[0].Id != "s1" {
+		t.Fatalf("DueSchedules() = %v, %v, want [s1]", due, err)
+	}
+
+	f := storage.ScheduleFiring{
+		ScheduleID: "s1",
+		Topic:      topic,
+		Expected:   next,
+		Next:       next.Add(time.Hour),
+		LastRun:    next,
+		Tasks:      []*pb.Task{newTask(fmt.Sprintf("s1@%d", next.Unix()), next)},
+	}
+	if fired, err := ss.FireSchedule(ctx, f); err != nil || !fired {
+		t.Fatalf("FireSchedule() = %v, %v, want true", fired, err)
+	}
+	// 其他副本以旧的 next_run_time 推进时不生效，任务也不会重复投递
+	if fired, err := ss.FireSchedule(ctx, f); err != nil || fired {
+		t.Errorf("FireSchedule() with stale expected = %v, %v, want false", fired, err)
+	}
+	assertIDs(t, "FetchAndHold() after FireSchedule", mustFetch(t, h.Store, 10), f.Tasks[0].Id)
+
+	got, err := ss.GetSchedule(ctx, "s1")
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	if got.NextRunTime != f.Next.Unix() || got.LastRunTime != next.Unix() {
+		t.Errorf("schedule after firing = %v", got)
+	}
+
+	if _, err := ss.SetSchedulePaused(ctx, "s1", true, time.Time{}); err != nil {
+		t.Fatalf("SetSchedulePaused() error = %v", err)
+	}
+	f.Expected = f.Next
+	if fired, _ := ss.FireSchedule(ctx, f); fired {
+		t.Error("FireSchedule() on paused schedule should not fire")
+	}
+	if err := ss.DeleteSchedule(ctx, "s1"); err != nil {
+		t.Errorf("DeleteSchedule() error = %v", err)
+	}
+	assertErr(t, "GetSchedule() after delete", func() error { _, err := ss.GetSchedule(ctx, "s1"); return err }(), errno.ErrScheduleNotFound)
+}
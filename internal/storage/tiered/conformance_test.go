@@ -0,0 +1,15 @@
+package tiered
+
+import (
+	"testing"
+
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/memory"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/storetest"
+)
+
+// TestConformance 以内存存储作为两层运行一致性测试。用例中的任务都在分层阈值之内，验证的是热存储路径与跨层删除。
+func TestConformance(t *testing.T) {
+	storetest.RunConformance(t, func(t *testing.T) storetest.Harness {
+		return storetest.Harness{Store: NewStore(memory.NewStore(), memory.NewStore())}
+	})
+}
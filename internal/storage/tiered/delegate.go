@@ -0,0 +1,117 @@
+package tiered
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+)
+
+// scheduleStore 返回热存储的周期任务能力。
+func (s *Store) scheduleStore() (storage.ScheduleStore, error) {
+	ss, ok := s.hot.(storage.ScheduleStore)
+	if !ok {
+		return nil, unsupported("ScheduleStore")
+	}
+	return ss, nil
+}
+
+// CreateSchedule 实现 storage.ScheduleStore，周期任务保存在热存储中。
+func (s *Store) CreateSchedule(ctx context.Context, sched *pb.Schedule) error {
+	ss, err := s.scheduleStore()
+	if err != nil {
+		return err
+	}
+	return ss.CreateSchedule(ctx, sched)
+}
+
+// GetSchedule 实现 storage.ScheduleStore。
+func (s *Store) GetSchedule(ctx context.Context, id string) (*pb.Schedule, error) {
+	ss, err := s.scheduleStore()
+	if err != nil {
+		return nil, err
+	}
+	return ss.GetSchedule(ctx, id)
+}
+
+// ListSchedules 实现 storage.ScheduleStore。
+func (s *Store) ListSchedules(ctx context.Context, topic string) ([]*pb.Schedule, error) {
+	ss, err := s.scheduleStore()
+	if err != nil {
+		return nil, err
+	}
+	return ss.ListSchedules(ctx, topic)
+}
+
+// DeleteSchedule 实现 storage.ScheduleStore。
+func (s *Store) DeleteSchedule(ctx context.Context, id string) error {
+	ss, err := s.scheduleStore()
+	if err != nil {
+		return err
+	}
+	return ss.DeleteSchedule(ctx, id)
+}
+
+// SetSchedulePaused 实现 storage.ScheduleStore。
+func (s *Store) SetSchedulePaused(ctx context.Context, id string, paused bool, next time.Time) (*pb.Schedule, error) {
+	ss, err := s.scheduleStore()
+	if err != nil {
+		return nil, err
+	}
+	return ss.SetSchedulePaused(ctx, id, paused, next)
+}
+
+// DueSchedules 实现 storage.ScheduleStore。
+func (s *Store) DueSchedules(ctx context.Context, now time.Time, limit int64) ([]*pb.Schedule, error) {
+	ss, err := s.scheduleStore()
+	if err != nil {
+		return nil, err
+	}
+	return ss.DueSchedules(ctx, now, limit)
+}
+
+// FireSchedule 实现 storage.ScheduleStore。投递的任务在触发时刻前后到期，与周期任务一起原子地写入热存储。
+func (s *Store) FireSchedule(ctx context.Context, f storage.ScheduleFiring) (bool, error) {
+	ss, err := s.scheduleStore()
+	if err != nil {
+		return false, err
+	}
+	return ss.FireSchedule(ctx, f)
+}
+
+// quarantineStore 返回热存储的隔离区能力。冷存储中的信封由本包写入，不会出现无法解码的条目。
+func (s *Store) quarantineStore() (storage.QuarantineStore, error) {
+	qs, ok := s.hot.(storage.QuarantineStore)
+	if !ok {
+		return nil, unsupported("QuarantineStore")
+	}
+	return qs, nil
+}
+
+// ListQuarantined 实现 storage.QuarantineStore。
+func (s *Store) ListQuarantined(ctx context.Context, topic string, cursor uint64, limit int64) ([]*pb.QuarantinedEntry, uint64, error) {
+	qs, err := s.quarantineStore()
+	if err != nil {
+		return nil, 0, err
+	}
+	return qs.ListQuarantined(ctx, topic, cursor, limit)
+}
+
+// QuarantineStats 实现 storage.QuarantineStore。
+func (s *Store) QuarantineStats(ctx context.Context, topic string) (int64, int64, error) {
+	qs, err := s.quarantineStore()
+	if err != nil {
+		return 0, 0, err
+	}
+	return qs.QuarantineStats(ctx, topic)
+}
+
+// DeleteQuarantined 实现 storage.QuarantineStore。
+func (s *Store) DeleteQuarantined(ctx context.Context, topic string, ids []string, all bool) (int64, error) {
+	qs, err := s.quarantineStore()
+	if err != nil {
+		return 0, err
+	}
+	return qs.DeleteQuarantined(ctx, topic, ids, all)
+}
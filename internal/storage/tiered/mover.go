@@ -0,0 +1,120 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+)
+
+// moveBatchSize 单次从冷存储拉取的信封数，一轮迁移会连续拉取直到没有到期的信封。
+const moveBatchSize = 100
+
+// Mover 定期将冷存储中即将到期的任务迁回热存储。
+// @Description 每轮先回收超时未确认的信封（上一轮迁移中途失败），再拉取到期的信封写入热存储并确认。
+// 多个副本同时运行是安全的：FetchAndHold 保证同一信封不会同时交给两个副本；迁移以 Replace 覆盖占位条目，重复迁移写入的是相同的任务。
+// @Note: 信封超时 (60 秒) 后才会被重新迁移，提前量应远大于此，避免已迁移并执行完毕的任务被重复写入。
+// @ThreadSafe: 内部状态受协程生命周期管理，支持跨协程安全启动/停止。
+type Mover struct {
+	store    *Store
+	interval time.Duration
+
+	cancel context.CancelFunc // 停止由 Start 启动的循环
+	wg     sync.WaitGroup     // 等待协程关闭
+}
+
+// NewMover 创建分层存储的迁移器。
+// @Param interval: 扫描周期，应明显小于 WithPromoteAhead 设置的提前量。
+func NewMover(store *Store, interval time.Duration) *Mover {
+	return &Mover{store: store, interval: interval}
+}
+
+// Start 异步启动迁移循环。
+func (m *Mover) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.Run(ctx)
+	}()
+}
+
+// Stop 停止迁移循环并等待协程安全退出。
+func (m *Mover) Stop() {
+	m.cancel()
+	m.wg.Wait()
+	log.Println("Tier mover stopped")
+}
+
+// Run 在当前协程内运行迁移循环，直到 ctx 取消。
+func (m *Mover) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	log.Printf("Tier mover started. Interval: %v, Horizon: %v, PromoteAhead: %v", m.interval, m.store.horizon, m.store.promoteAhead)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := m.MoveDue(ctx); err != nil {
+				log.Printf("Tier mover error: %v", err)
+			} else if n > 0 {
+				log.Printf("Tier mover promoted %d tasks to the hot store", n)
+			}
+		}
+	}
+}
+
+// MoveDue 执行一轮迁移。
+// @Return: 本轮迁回热存储的任务数（已迁移且正在执行而放弃的不计入）。
+func (m *Mover) MoveDue(ctx context.Context) (int64, error) {
+	hot, cold := m.store.hot, m.store.cold
+
+	if _, err := cold.CheckAndMoveExpired(ctx, envelopeVisibility, math.MaxInt32); err != nil {
+		return 0, err
+	}
+
+	var moved int64
+	for {
+		envs, err := cold.FetchAndHold(ctx, coldTopic, moveBatchSize)
+		if err != nil {
+			return moved, err
+		}
+		for _, env := range envs {
+			task, err := unwrap(env)
+			if err != nil {
+				// 信封由本包写入，无法解码只可能是数据损坏，延后重试并保留现场供排查
+				log.Printf("Tier mover skipped envelope: %v", err)
+				_ = cold.Nack(ctx, coldTopic, env.Id, env.Lease, storage.NackOptions{Reason: err.Error(), RetryDelay: time.Hour})
+				continue
+			}
+
+			err = hot.Replace(ctx, task)
+			switch {
+			case err == nil:
+				moved++
+			case errors.Is(err, errno.ErrTaskRunning):
+				// 上一次迁移已写入热存储但未确认，任务已开始执行
+			default:
+				// 热存储不可用：信封保持执行中，超时后由下一轮重新迁移
+				return moved, err
+			}
+			// 信封已超时被其他副本重新持有时，由持有方确认
+			err = cold.Ack(ctx, coldTopic, env.Id, env.Lease)
+			if err != nil && !errors.Is(err, errno.ErrTaskNotFound) && !errors.Is(err, errno.ErrLeaseMismatch) {
+				return moved, err
+			}
+		}
+		if len(envs) < moveBatchSize {
+			return moved, nil
+		}
+	}
+}
@@ -0,0 +1,338 @@
+// Package tiered 提供冷热分层的 JobStore：执行时间较近的任务写入热存储（通常为 Redis），
+// 执行时间晚于分层阈值 (horizon) 的任务写入冷存储（SQL 或本地磁盘），由 Mover 在到期前提前迁回热存储。
+// 适用场景：大量提前数周投递的任务长期占用 Redis 内存。
+//
+// 冷存储中的任务以“信封”形式保存在内部 Topic coldTopic 下：信封的 ID 与原任务相同，
+// 执行时间为原任务应迁回热存储的时刻，原任务经 protobuf 编码后放在 Payload 中。
+// 因此冷存储只需具备普通 JobStore 的能力，迁移本身就是一次 FetchAndHold → 热存储 Replace → Ack。
+//
+// 任务 ID 的唯一性只由热存储保证：写入冷存储之前，先在热存储的内部 Topic reservedTopic 下写入同 ID、不含负载的占位条目，
+// 借助热存储原子的"ID 不存在才写入"拒绝两层之间的重复 ID；迁移时原任务覆盖占位条目。
+// 占位条目所在的 Topic 不会被消费，代价是热存储中每个冷任务保留一个很小的条目。
+//
+// 消费相关的操作（拉取、确认、续租、超时回收、死信）只作用于热存储：任务在冷存储中不会被消费，也不会进入死信。
+// Remove 与 Replace 同时作用于两层，对调用方透明。
+//
+// 内部 Topic 均以 ReservedTopicPrefix 开头，业务任务不得使用该前缀（由 queue 服务层校验），否则会与占位条目和信封混在一起。
+package tiered
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"google.golang.org/protobuf/proto"
+)
+
+// ReservedTopicPrefix 分层存储内部 Topic 的前缀。
+const ReservedTopicPrefix = "_tiered_"
+
+// IsReservedTopic 报告 topic 是否属于分层存储的内部 Topic。
+func IsReservedTopic(topic string) bool {
+	return strings.HasPrefix(topic, ReservedTopicPrefix)
+}
+
+const (
+	// coldTopic 冷存储中信封所属的内部 Topic
+	coldTopic = ReservedTopicPrefix + "cold"
+	// reservedTopic 热存储中占位条目所属的内部 Topic
+	reservedTopic = ReservedTopicPrefix + "reserved"
+	// envelopeVisibility 信封被 Mover 持有的超时时长 (秒)，Mover 在迁移中途退出时，信封超时后由下一轮迁移重新处理
+	envelopeVisibility = 60
+	// removeAttempts 删除时遇到正在迁移的任务的重试次数
+	removeAttempts = 3
+)
+
+// Store 实现了 storage.JobStore 接口，将任务按执行时间分布在热、冷两个存储中。
+// @Description 可选接口 Notifier、ScheduleStore、QuarantineStore 委托给热存储，热存储不支持时返回 errno.ErrNotSupported；
+// 周期任务投递的任务总是即将到期，直接写入热存储。
+// @ThreadSafe: 自身无可变状态，并发安全性由两个底层存储保证。
+type Store struct {
+	hot  storage.JobStore
+	cold storage.JobStore
+
+	horizon      time.Duration // 执行时间晚于 now + horizon 的任务写入冷存储
+	promoteAhead time.Duration // 冷存储中的任务在执行时间之前多久迁回热存储
+
+	now func() time.Time // 当前时间，测试中可替换
+}
+
+// Option 定义 Store 的可选配置项。
+type Option func(*Store)
+
+// WithHorizon 设置分层阈值，默认 24 小时。d <= 0 时保持默认值。
+func WithHorizon(d time.Duration) Option {
+	return func(s *Store) {
+		if d > 0 {
+			s.horizon = d
+		}
+	}
+}
+
+// WithPromoteAhead 设置提前迁回热存储的时长，默认 1 小时，且不超过分层阈值。d <= 0 时保持默认值。
+// @Description 应明显大于 Mover 的扫描周期，保证任务在到期之前已位于热存储。
+func WithPromoteAhead(d time.Duration) Option {
+	return func(s *Store) {
+		if d > 0 {
+			s.promoteAhead = d
+		}
+	}
+}
+
+// 编译期校验：确保 Store 完整实现了 JobStore 定义的所有契约，并转发热存储的可选能力。
+var (
+	_ storage.JobStore        = (*Store)(nil)
+	_ storage.Notifier        = (*Store)(nil)
+	_ storage.ScheduleStore   = (*Store)(nil)
+	_ storage.QuarantineStore = (*Store)(nil)
+)
+
+// NewStore 以 hot 为热存储、cold 为冷存储创建分层存储。
+// @Param cold: 冷存储不应与热存储共用同一组 Key/表，且不需要去重窗口（去重由热存储中的占位条目保证）。
+func NewStore(hot, cold storage.JobStore, opts ...Option) *Store {
+	s := &Store{
+		hot:          hot,
+		cold:         cold,
+		horizon:      24 * time.Hour,
+		promoteAhead: time.Hour,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.promoteAhead = min(s.promoteAhead, s.horizon)
+	return s
+}
+
+// Hot 返回热存储，用于探测其专有能力（如 storage.Clock、选主所需的 Redis 客户端）。
+func (s *Store) Hot() storage.JobStore {
+	return s.hot
+}
+
+// isCold 判断任务是否应写入冷存储。
+func (s *Store) isCold(task *pb.Task) bool {
+	return storage.ExecuteAt(task).After(s.now().Add(s.horizon))
+}
+
+// envelope 将任务封装为冷存储中的信封，在执行时间之前 promoteAhead 到期。
+func (s *Store) envelope(task *pb.Task) (*pb.Task, error) {
+	body, err := proto.Marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("marshal task failed: %w", err)
+	}
+	promoteAt := storage.ExecuteAt(task).Add(-s.promoteAhead)
+	return &pb.Task{
+		Id:                task.Id,
+		Topic:             coldTopic,
+		Payload:           base64.StdEncoding.EncodeToString(body),
+		ExecuteTime:       promoteAt.Unix(),
+		ExecuteTimeMs:     promoteAt.UnixMilli(),
+		MaxRetries:        math.MaxInt32, // 迁移失败只会重试，不会进入冷存储的死信队列
+		VisibilityTimeout: envelopeVisibility,
+		CreatedAt:         task.CreatedAt,
+	}, nil
+}
+
+// placeholder 返回任务在热存储中的占位条目，与原任务同 ID、同执行时间，不含负载。
+func placeholder(task *pb.Task) *pb.Task {
+	return &pb.Task{
+		Id:            task.Id,
+		Topic:         reservedTopic,
+		ExecuteTime:   task.ExecuteTime,
+		ExecuteTimeMs: task.ExecuteTimeMs,
+		CreatedAt:     task.CreatedAt,
+	}
+}
+
+// unwrap 从信封中还原原任务。
+func unwrap(env *pb.Task) (*pb.Task, error) {
+	body, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decode envelope %s failed: %w", env.Id, err)
+	}
+	task := &pb.Task{}
+	if err := proto.Unmarshal(body, task); err != nil {
+		return nil, fmt.Errorf("unmarshal envelope %s failed: %w", env.Id, err)
+	}
+	return task, nil
+}
+
+// Add 按执行时间将任务写入热存储或冷存储。
+// @Description 写入冷存储前先在热存储中写入占位条目，ID 已存在于任意一层（或热存储的去重窗口内）时均被拒绝。
+// @Return: ID 重复时返回 errno.ErrTaskAlreadyExist。
+// @Note: 写入占位条目后冷存储写入失败且回滚也失败时，占位条目会占用该 ID，直到调用 Remove 删除。
+func (s *Store) Add(ctx context.Context, task *pb.Task) error {
+	if task.Topic == "" {
+		return fmt.Errorf("task topic is required")
+	}
+	if !s.isCold(task) {
+		return s.hot.Add(ctx, task)
+	}
+	env, err := s.envelope(task)
+	if err != nil {
+		return err
+	}
+	if err := s.hot.Add(ctx, placeholder(task)); err != nil {
+		return err
+	}
+	if err := s.cold.Add(ctx, env); err != nil {
+		_ = s.hot.Remove(ctx, task.Id)
+		return err
+	}
+	return nil
+}
+
+// Replace 以新任务覆盖同 ID 的旧任务，旧任务可能位于任意一层，新任务按执行时间写入对应的层。
+// @Return: 旧任务正在执行（或正被迁移）时返回 errno.ErrTaskRunning。
+func (s *Store) Replace(ctx context.Context, task *pb.Task) error {
+	if task.Topic == "" {
+		return fmt.Errorf("task topic is required")
+	}
+	if !s.isCold(task) {
+		// 先覆盖热存储中的旧任务或旧任务的占位条目，再删除冷存储中的旧信封：
+		// 反过来的顺序在热存储写入失败时会留下没有信封的占位条目，任务丢失且 ID 无法再提交。
+		if err := s.hot.Replace(ctx, task); err != nil {
+			return err
+		}
+		// 冷存储中的旧信封正被迁移时，迁移会以旧任务覆盖新任务，因此按执行中处理；
+		// 删除失败时旧信封仍会在到期前被迁回并覆盖新任务，调用方重试 Replace 即可收敛
+		if err := s.cold.Remove(ctx, task.Id); err != nil && !errors.Is(err, errno.ErrTaskNotFound) {
+			return err
+		}
+		return nil
+	}
+
+	env, err := s.envelope(task)
+	if err != nil {
+		return err
+	}
+	// 先以占位条目覆盖热存储中的旧任务：旧任务执行中时在此返回 errno.ErrTaskRunning，冷存储保持不变
+	if err := s.hot.Replace(ctx, placeholder(task)); err != nil {
+		return err
+	}
+	if err := s.cold.Replace(ctx, env); err != nil {
+		if !errors.Is(err, errno.ErrTaskRunning) {
+			// 正被迁移时迁移会覆盖占位条目，此外的失败须释放占位条目
+			_ = s.hot.Remove(ctx, task.Id)
+		}
+		return err
+	}
+	return nil
+}
+
+// Remove 从两层中删除任务。
+// @Description 先删除冷存储中的信封，再删除热存储中的任务或占位条目。任务正从冷存储迁往热存储时，
+// 等待迁移完成后在热存储中删除；迁移迟迟未完成时返回 errno.ErrTaskRunning，调用方可稍后重试。
+// @Return: 两层中都不存在时返回 errno.ErrTaskNotFound；任务执行中返回 errno.ErrTaskRunning。
+func (s *Store) Remove(ctx context.Context, id string) error {
+	for i := 0; ; i++ {
+		err := s.cold.Remove(ctx, id)
+		switch {
+		case err == nil:
+			// 释放占位条目
+			if err := s.hot.Remove(ctx, id); err != nil && !errors.Is(err, errno.ErrTaskNotFound) {
+				return err
+			}
+			return nil
+		case errors.Is(err, errno.ErrTaskNotFound):
+			return s.hot.Remove(ctx, id)
+		case !errors.Is(err, errno.ErrTaskRunning):
+			return err
+		case i == removeAttempts:
+			return errno.ErrTaskRunning
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// FetchAndHold 从热存储拉取到期任务。冷存储中的任务都远未到期，无需查询。
+func (s *Store) FetchAndHold(ctx context.Context, topic string, limit int64) ([]*pb.Task, error) {
+	return s.hot.FetchAndHold(ctx, topic, limit)
+}
+
+// Ack 确认热存储中执行中的任务。
+func (s *Store) Ack(ctx context.Context, topic, id, lease string) error {
+	return s.hot.Ack(ctx, topic, id, lease)
+}
+
+// Nack 报告热存储中执行中的任务失败，重试仍留在热存储中。
+func (s *Store) Nack(ctx context.Context, topic, id, lease string, opts storage.NackOptions) error {
+	return s.hot.Nack(ctx, topic, id, lease, opts)
+}
+
+// Extend 延长热存储中执行中任务的可见性超时。
+func (s *Store) Extend(ctx context.Context, topic, id, lease string, extra time.Duration) error {
+	return s.hot.Extend(ctx, topic, id, lease, extra)
+}
+
+// CheckAndMoveExpired 回收热存储中超时的任务。冷存储中超时的信封由 Mover 回收。
+func (s *Store) CheckAndMoveExpired(ctx context.Context, visibilityTimeout int64, maxRetries int32) (storage.RecoverStats, error) {
+	return s.hot.CheckAndMoveExpired(ctx, visibilityTimeout, maxRetries)
+}
+
+// ListDead 查询热存储的死信队列，冷存储中不存在死信。
+func (s *Store) ListDead(ctx context.Context, q storage.DeadLetterQuery) ([]*pb.Task, int64, error) {
+	return s.hot.ListDead(ctx, q)
+}
+
+// GetDead 按 ID 查询热存储中的死信任务。
+func (s *Store) GetDead(ctx context.Context, id string) (*pb.Task, error) {
+	return s.hot.GetDead(ctx, id)
+}
+
+// Redrive 将热存储中的死信任务重新入队，重投的任务留在热存储中。
+func (s *Store) Redrive(ctx context.Context, sel storage.DeadLetterSelector, delay time.Duration) (int64, error) {
+	return s.hot.Redrive(ctx, sel, delay)
+}
+
+// Purge 永久删除热存储中的死信任务。
+func (s *Store) Purge(ctx context.Context, sel storage.DeadLetterSelector) (int64, error) {
+	return s.hot.Purge(ctx, sel)
+}
+
+// Close 关闭实现了 io.Closer 的底层存储（如本地磁盘存储）。
+func (s *Store) Close() error {
+	var errs []error
+	for _, store := range []storage.JobStore{s.hot, s.cold} {
+		if c, ok := store.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// unsupported 返回热存储不支持某项可选能力时的错误，包装 errno.ErrNotSupported，由服务层映射为 Unimplemented。
+func unsupported(name string) error {
+	return fmt.Errorf("hot store does not implement storage.%s: %w", name, errno.ErrNotSupported)
+}
+
+// Notifications 实现 storage.Notifier，订阅热存储的就绪通知；迁回热存储的任务同样会触发通知。
+func (s *Store) Notifications(ctx context.Context, topics []string) (<-chan string, error) {
+	n, ok := s.hot.(storage.Notifier)
+	if !ok {
+		return nil, unsupported("Notifier")
+	}
+	return n.Notifications(ctx, topics)
+}
+
+// NextDueTime 实现 storage.Notifier，返回热存储中最早的执行时间。冷存储中的任务总是晚于热存储中即将迁入的任务。
+func (s *Store) NextDueTime(ctx context.Context, topic string) (time.Time, bool, error) {
+	n, ok := s.hot.(storage.Notifier)
+	if !ok {
+		return time.Time{}, false, unsupported("Notifier")
+	}
+	return n.NextDueTime(ctx, topic)
+}
@@ -0,0 +1,262 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/common/errno"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage/memory"
+)
+
+// tierLag 分层判定所用时钟落后于真实时钟的时长。
+// 两个内存存储按真实时钟判定到期，分层判定则按落后 tierLag 的时钟进行：
+// 执行时间为“真实的现在”的任务在分层看来远在阈值之外，写入冷存储，而它的信封在冷存储中已经到期，可以立即迁移。
+const tierLag = 10 * time.Hour
+
+func newTestStore() (*Store, *memory.Store, *memory.Store) {
+	hot, cold := memory.NewStore(), memory.NewStore()
+	s := NewStore(hot, cold, WithHorizon(time.Hour), WithPromoteAhead(time.Hour))
+	s.now = func() time.Time { return time.Now().Add(-tierLag) }
+	return s, hot, cold
+}
+
+// farTask 返回在分层时钟看来远超阈值、按真实时钟已经到期的任务。
+func farTask(id string) *pb.Task {
+	at := time.Now().Add(-time.Millisecond)
+	return &pb.Task{Id: id, Topic: "test", Payload: `{"far":true}`, ExecuteTime: at.Unix(), ExecuteTimeMs: at.UnixMilli(), MaxRetries: 3}
+}
+
+// nearTask 返回在分层时钟看来已经到期的任务。
+func nearTask(id string) *pb.Task {
+	at := time.Now().Add(-tierLag)
+	return &pb.Task{Id: id, Topic: "test", Payload: `{"near":true}`, ExecuteTime: at.Unix(), ExecuteTimeMs: at.UnixMilli(), MaxRetries: 3}
+}
+
+// fetchIDs 拉取热存储中的到期任务。
+func fetchIDs(t *testing.T, s *Store) map[string]*pb.Task {
+	t.Helper()
+	tasks, err := s.FetchAndHold(context.Background(), "test", 10)
+	if err != nil {
+		t.Fatalf("FetchAndHold failed: %v", err)
+	}
+	out := make(map[string]*pb.Task, len(tasks))
+	for _, task := range tasks {
+		out[task.Id] = task
+	}
+	return out
+}
+
+func TestStore_Promote(t *testing.T) {
+	ctx := context.Background()
+	s, _, cold := newTestStore()
+	m := NewMover(s, time.Second)
+
+	if err := s.Add(ctx, nearTask("near")); err != nil {
+		t.Fatalf("Add near failed: %v", err)
+	}
+	if err := s.Add(ctx, farTask("far")); err != nil {
+		t.Fatalf("Add far failed: %v", err)
+	}
+	// 远期任务只存在于冷存储，迁移之前不会被拉取
+	if got := fetchIDs(t, s); len(got) != 1 || got["near"] == nil {
+		t.Fatalf("fetched %v before promotion, want only near", got)
+	}
+	if held, _ := cold.FetchAndHold(ctx, "test", 10); len(held) != 0 {
+		t.Errorf("cold store exposes %d tasks under the task topic, want 0", len(held))
+	}
+
+	moved, err := m.MoveDue(ctx)
+	if err != nil || moved != 1 {
+		t.Fatalf("MoveDue = %d, %v; want 1", moved, err)
+	}
+	got := fetchIDs(t, s)
+	if len(got) != 1 || got["far"] == nil {
+		t.Fatalf("fetched %v after promotion, want far", got)
+	}
+	if got["far"].Payload != `{"far":true}` || got["far"].MaxRetries != 3 {
+		t.Errorf("promoted task = %+v, want the original task", got["far"])
+	}
+
+	// 信封已确认，再次迁移不会重复投递
+	if moved, err := m.MoveDue(ctx); err != nil || moved != 0 {
+		t.Errorf("second MoveDue = %d, %v; want 0", moved, err)
+	}
+}
+
+func TestStore_DuplicateAcrossTiers(t *testing.T) {
+	tests := []struct {
+		name          string
+		first, second func(id string) *pb.Task
+	}{
+		{"far then far", farTask, farTask},
+		{"far then near", farTask, nearTask},
+		{"near then far", nearTask, farTask},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, _, _ := newTestStore()
+			m := NewMover(s, time.Second)
+
+			first := tt.first("dup")
+			if err := s.Add(ctx, first); err != nil {
+				t.Fatalf("first Add failed: %v", err)
+			}
+			if err := s.Add(ctx, tt.second("dup")); !errors.Is(err, errno.ErrTaskAlreadyExist) {
+				t.Errorf("second Add error = %v, want ErrTaskAlreadyExist", err)
+			}
+
+			// 先写入的一方被完整保留并投递
+			if _, err := m.MoveDue(ctx); err != nil {
+				t.Fatalf("MoveDue failed: %v", err)
+			}
+			got := fetchIDs(t, s)
+			if len(got) != 1 || got["dup"].Payload != first.Payload {
+				t.Errorf("fetched %v, want only the first copy", got)
+			}
+		})
+	}
+}
+
+func TestStore_Placeholder(t *testing.T) {
+	ctx := context.Background()
+	s, hot, _ := newTestStore()
+
+	if err := s.Add(ctx, farTask("far")); err != nil {
+		t.Fatalf("Add far failed: %v", err)
+	}
+	// 占位条目占用热存储中的 ID，但不会被投递
+	if err := hot.Add(ctx, nearTask("far")); !errors.Is(err, errno.ErrTaskAlreadyExist) {
+		t.Errorf("hot Add error = %v, want ErrTaskAlreadyExist", err)
+	}
+	if held, err := hot.FetchAndHold(ctx, "test", 10); err != nil || len(held) != 0 {
+		t.Errorf("hot FetchAndHold = %v, %v; want no tasks before promotion", held, err)
+	}
+
+	// Remove 同时释放占位条目
+	if err := s.Remove(ctx, "far"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := hot.Remove(ctx, "far"); !errors.Is(err, errno.ErrTaskNotFound) {
+		t.Errorf("hot Remove after Remove error = %v, want ErrTaskNotFound", err)
+	}
+}
+
+func TestStore_RemoveAcrossTiers(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestStore()
+	m := NewMover(s, time.Second)
+
+	if err := s.Add(ctx, nearTask("near")); err != nil {
+		t.Fatalf("Add near failed: %v", err)
+	}
+	if err := s.Add(ctx, farTask("far")); err != nil {
+		t.Fatalf("Add far failed: %v", err)
+	}
+	for _, id := range []string{"near", "far"} {
+		if err := s.Remove(ctx, id); err != nil {
+			t.Errorf("Remove %s failed: %v", id, err)
+		}
+		if err := s.Remove(ctx, id); !errors.Is(err, errno.ErrTaskNotFound) {
+			t.Errorf("second Remove %s error = %v, want ErrTaskNotFound", id, err)
+		}
+	}
+	if moved, err := m.MoveDue(ctx); err != nil || moved != 0 {
+		t.Errorf("MoveDue = %d, %v; want 0", moved, err)
+	}
+	if got := fetchIDs(t, s); len(got) != 0 {
+		t.Errorf("fetched %v after Remove, want none", got)
+	}
+
+	// 删除后可以相同 ID 重新提交到任意一层
+	if err := s.Add(ctx, farTask("near")); err != nil {
+		t.Errorf("re-Add after Remove failed: %v", err)
+	}
+}
+
+func TestStore_ReplaceAcrossTiers(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestStore()
+	m := NewMover(s, time.Second)
+
+	// 热 → 冷：热存储中的旧任务被删除
+	if err := s.Add(ctx, nearTask("a")); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := s.Replace(ctx, farTask("a")); err != nil {
+		t.Fatalf("Replace to cold failed: %v", err)
+	}
+	if got := fetchIDs(t, s); len(got) != 0 {
+		t.Fatalf("fetched %v after moving to cold, want none", got)
+	}
+
+	// 冷 → 热：冷存储中的旧信封被删除，不会再被迁移
+	if err := s.Replace(ctx, nearTask("a")); err != nil {
+		t.Fatalf("Replace to hot failed: %v", err)
+	}
+	if moved, err := m.MoveDue(ctx); err != nil || moved != 0 {
+		t.Errorf("MoveDue = %d, %v; want 0", moved, err)
+	}
+	got := fetchIDs(t, s)
+	if len(got) != 1 || got["a"].Payload != `{"near":true}` {
+		t.Errorf("fetched %v, want the replacement", got)
+	}
+
+	// 执行中的任务不能被替换
+	if err := s.Replace(ctx, farTask("a")); !errors.Is(err, errno.ErrTaskRunning) {
+		t.Errorf("Replace running task error = %v, want ErrTaskRunning", err)
+	}
+}
+
+// failingReplace 使热存储的 Replace 失败，模拟写入热存储时 Redis 不可用。
+type failingReplace struct {
+	storage.JobStore
+	err error
+}
+
+func (f *failingReplace) Replace(ctx context.Context, task *pb.Task) error {
+	if f.err != nil {
+		return f.err
+	}
+	return f.JobStore.Replace(ctx, task)
+}
+
+func TestStore_ReplaceToHotFailure(t *testing.T) {
+	ctx := context.Background()
+	hot := &failingReplace{JobStore: memory.NewStore()}
+	s := NewStore(hot, memory.NewStore(), WithHorizon(time.Hour), WithPromoteAhead(time.Hour))
+	s.now = func() time.Time { return time.Now().Add(-tierLag) }
+	m := NewMover(s, time.Second)
+
+	if err := s.Add(ctx, farTask("a")); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// 热存储写入失败时冷存储中的旧信封保持不变，任务不会丢失
+	hot.err = errors.New("hot store unavailable")
+	if err := s.Replace(ctx, nearTask("a")); err == nil {
+		t.Fatal("Replace succeeded while the hot store was failing")
+	}
+	hot.err = nil
+
+	if moved, err := m.MoveDue(ctx); err != nil || moved != 1 {
+		t.Fatalf("MoveDue = %d, %v; want 1", moved, err)
+	}
+	got := fetchIDs(t, s)
+	if len(got) != 1 || got["a"].Payload != `{"far":true}` {
+		t.Errorf("fetched %v, want the original task", got)
+	}
+}
+
+func TestStore_UnsupportedCapability(t *testing.T) {
+	s, _, _ := newTestStore()
+
+	// 内存存储不支持隔离区
+	if _, _, err := s.ListQuarantined(context.Background(), "test", 0, 10); !errors.Is(err, errno.ErrNotSupported) {
+		t.Errorf("ListQuarantined error = %v, want ErrNotSupported", err)
+	}
+}
@@ -0,0 +1,183 @@
+// Package client 是延迟队列的 Go 客户端 SDK。
+// 适用场景：业务方通过 Client.Enqueue 投递任务，或通过 Consumer 注册 Handler 消费任务，无需手写 gRPC 调用。
+//
+// Client 内部维护一组 gRPC 连接并轮询使用，每次调用自动附加超时，
+// 遇到 Unavailable 等瞬时错误时按指数退避重试；Client 可被多个协程并发使用。
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// 默认配置。
+const (
+	defaultPoolSize    = 1
+	defaultCallTimeout = 5 * time.Second
+	defaultMaxAttempts = 3
+	defaultBackoff     = 100 * time.Millisecond
+	maxBackoff         = 2 * time.Second
+)
+
+// Client 延迟队列客户端，持有到 Server 的连接池。
+// @ThreadSafe: 所有方法均可并发调用。
+type Client struct {
+	conns   []*grpc.ClientConn
+	stubs   []pb.DelayQueueServiceClient
+	next    atomic.Uint64 // 轮询计数
+	timeout time.Duration // 单次调用超时，0 表示不额外设置
+	retry   retryPolicy
+	dial    []grpc.DialOption
+	size    int
+}
+
+// retryPolicy 瞬时错误的重试参数。
+type retryPolicy struct {
+	maxAttempts int           // 含首次调用在内的最大尝试次数
+	backoff     time.Duration // 首次重试前的等待时长，之后逐次翻倍 (带随机抖动)
+}
+
+// Option 定义 Client 的可选配置项。
+type Option func(*Client)
+
+// WithPoolSize 设置连接池大小，调用在各连接间轮询。
+// @Note: 单条 gRPC 连接即可多路复用；仅在单连接吞吐成为瓶颈时才需要调大。
+func WithPoolSize(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.size = n
+		}
+	}
+}
+
+// WithCallTimeout 设置单次调用的超时，ctx 自带更早的截止时间时以 ctx 为准；0 表示不设置。
+func WithCallTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d >= 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithRetry 设置瞬时错误的重试策略。
+// @Param maxAttempts: 含首次调用在内的最大尝试次数，1 表示不重试。
+// @Param backoff: 首次重试前的等待时长，之后逐次翻倍，上限 2 秒。
+func WithRetry(maxAttempts int, backoff time.Duration) Option {
+	return func(c *Client) {
+		if maxAttempts > 0 {
+			c.retry.maxAttempts = maxAttempts
+		}
+		if backoff > 0 {
+			c.retry.backoff = backoff
+		}
+	}
+}
+
+// WithDialOptions 追加 gRPC 连接参数（如 TLS 凭据、拦截器）；未指定凭据时默认使用明文连接。
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *Client) {
+		c.dial = append(c.dial, opts...)
+	}
+}
+
+// New 创建客户端并初始化连接池。连接是惰性建立的，Server 暂不可用不会导致创建失败。
+// @Param addr: Server 地址，格式同 grpc.NewClient (如 "localhost:9090")。
+// @Param opts: 可选配置项，如 WithPoolSize、WithCallTimeout。
+func New(addr string, opts ...Option) (*Client, error) {
+	c := &Client{
+		size:    defaultPoolSize,
+		timeout: defaultCallTimeout,
+		retry:   retryPolicy{maxAttempts: defaultMaxAttempts, backoff: defaultBackoff},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	dial := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, c.dial...)
+	for range c.size {
+		conn, err := grpc.NewClient(addr, dial...)
+		if err != nil {
+			_ = c.Close()
+			return nil, fmt.Errorf("dial %s: %w", addr, err)
+		}
+		c.conns = append(c.conns, conn)
+		c.stubs = append(c.stubs, pb.NewDelayQueueServiceClient(conn))
+	}
+	return c, nil
+}
+
+// Close 关闭连接池中的所有连接。
+func (c *Client) Close() error {
+	var errs []error
+	for _, conn := range c.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Service 返回连接池中的下一个原始 gRPC 客户端，用于 SDK 尚未封装的接口 (如死信管理)。
+// @Note: 通过该客户端发起的调用不会自动设置超时或重试。
+func (c *Client) Service() pb.DelayQueueServiceClient {
+	return c.stubs[c.next.Add(1)%uint64(len(c.stubs))]
+}
+
+// call 执行一次带超时与重试的 gRPC 调用。
+// @Description 每次尝试使用连接池中的下一条连接并单独计算超时；只有瞬时错误 (见 retryable) 才会重试，
+// 且 ctx 已取消或已到截止时间时立即返回。
+// @Param idempotent: 请求被 Server 处理多次是否无害 (如携带固定 ID 的 Enqueue、Delete)。
+// 为 false 时 (如 Retrieve、Ack、Nack) 不重试单次调用超时，因为超时的请求可能已被 Server 处理。
+func (c *Client) call(ctx context.Context, idempotent bool, fn func(ctx context.Context, stub pb.DelayQueueServiceClient) error) error {
+	backoff := c.retry.backoff
+	for attempt := 1; ; attempt++ {
+		err := c.attempt(ctx, fn)
+		if err == nil || attempt >= c.retry.maxAttempts || !retryable(err, idempotent) || ctx.Err() != nil {
+			return err
+		}
+
+		// Equal Jitter: 在 [backoff/2, backoff] 之间随机等待，避免大量客户端同时重试
+		wait := backoff/2 + rand.N(backoff/2+1)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// attempt 在下一条连接上执行一次调用，并附加单次调用超时。
+func (c *Client) attempt(ctx context.Context, fn func(ctx context.Context, stub pb.DelayQueueServiceClient) error) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	return fn(ctx, c.Service())
+}
+
+// retryable 判断错误是否为可重试的瞬时错误。
+// @Description Unavailable (连接断开、Server 重启) 与 ResourceExhausted (限流) 时请求未被处理，总是可重试；
+// 单次调用超时 (DeadlineExceeded) 时请求可能已被处理，仅幂等的请求可重试。
+// 参数错误、ID 冲突、租约失效等业务错误重试也不会成功，直接返回。
+func retryable(err error, idempotent bool) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted:
+		return true
+	case codes.DeadlineExceeded:
+		return idempotent
+	default:
+		return false
+	}
+}
@@ -0,0 +1,295 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeServer 记录收到的请求，并按预设依次返回错误。
+type fakeServer struct {
+	pb.UnimplementedDelayQueueServiceServer
+
+	mu       sync.Mutex
+	errs     []error // Enqueue 依次返回的错误，耗尽后成功
+	enqueues []*pb.EnqueueRequest
+	tasks    []*pb.Task // Retrieve 返回一次后清空
+	acks     []*pb.AckRequest
+	nacks    []*pb.NackRequest
+}
+
+func (s *fakeServer) Enqueue(_ context.Context, req *pb.EnqueueRequest) (*pb.EnqueueResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enqueues = append(s.enqueues, req)
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return nil, err
+	}
+	return &pb.EnqueueResponse{Id: req.Id}, nil
+}
+
+func (s *fakeServer) Retrieve(_ context.Context, _ *pb.RetrieveRequest) (*pb.RetrieveResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks := s.tasks
+	s.tasks = nil
+	return &pb.RetrieveResponse{Tasks: tasks}, nil
+}
+
+func (s *fakeServer) Ack(_ context.Context, req *pb.AckRequest) (*pb.AckResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.acks = append(s.acks, req)
+	return &pb.AckResponse{Success: true}, nil
+}
+
+func (s *fakeServer) Nack(_ context.Context, req *pb.NackRequest) (*pb.NackResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nacks = append(s.nacks, req)
+	return &pb.NackResponse{Success: true}, nil
+}
+
+// newTestClient 在内存连接上启动 fakeServer 并返回连接它的 Client。
+func newTestClient(t *testing.T, srv *fakeServer, opts ...Option) *Client {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	pb.RegisterDelayQueueServiceServer(s, srv)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }
+	opts = append([]Option{WithRetry(3, time.Millisecond), WithDialOptions(grpc.WithContextDialer(dialer))}, opts...)
+	c, err := New("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestClient_Enqueue(t *testing.T) {
+	executeAt := time.UnixMilli(1700000000123)
+
+	tests := []struct {
+		name      string
+		errs      []error
+		opts      []EnqueueOption
+		wantCode  codes.Code
+		wantCalls int
+		check     func(t *testing.T, req *pb.EnqueueRequest)
+	}{
+		{
+			name:      "Options Mapped",
+			opts:      []EnqueueOption{WithID("order-1"), WithDelay(1500 * time.Millisecond), WithMaxRetries(5), WithPriority(10)},
+			wantCalls: 1,
+			check: func(t *testing.T, req *pb.EnqueueRequest) {
+				if req.Id != "order-1" || req.MaxRetries != 5 || req.Priority != 10 {
+					t.Errorf("request = %v", req)
+				}
+				if req.Delay.AsDuration() != 1500*time.Millisecond || req.ExecuteAt != nil {
+					t.Errorf("delay = %v, execute_at = %v", req.Delay, req.ExecuteAt)
+				}
+			},
+		},
+		{
+			name:      "ExecuteAt Overrides Delay",
+			opts:      []EnqueueOption{WithDelay(time.Second), WithExecuteAt(executeAt)},
+			wantCalls: 1,
+			check: func(t *testing.T, req *pb.EnqueueRequest) {
+				if !req.ExecuteAt.AsTime().Equal(executeAt) || req.Delay != nil {
+					t.Errorf("delay = %v, execute_at = %v", req.Delay, req.ExecuteAt)
+				}
+			},
+		},
+		{
+			name:      "Retries Unavailable With Stable ID",
+			errs:      []error{status.Error(codes.Unavailable, "down"), status.Error(codes.ResourceExhausted, "busy")},
+			wantCalls: 3,
+		},
+		{
+			name:      "Gives Up After Max Attempts",
+			errs:      []error{status.Error(codes.Unavailable, "down"), status.Error(codes.Unavailable, "down"), status.Error(codes.Unavailable, "down")},
+			wantCode:  codes.Unavailable,
+			wantCalls: 3,
+		},
+		{
+			name:      "No Retry On InvalidArgument",
+			errs:      []error{status.Error(codes.InvalidArgument, "topic is required")},
+			wantCode:  codes.InvalidArgument,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &fakeServer{errs: tt.errs}
+			c := newTestClient(t, srv)
+
+			id, err := c.Enqueue(context.Background(), "test", "payload", tt.opts...)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Enqueue() error = %v, want code %v", err, tt.wantCode)
+			}
+			if len(srv.enqueues) != tt.wantCalls {
+				t.Fatalf("server calls = %d, want %d", len(srv.enqueues), tt.wantCalls)
+			}
+
+			first := srv.enqueues[0]
+			if first.Id == "" {
+				t.Error("request ID should be generated")
+			}
+			for _, req := range srv.enqueues[1:] {
+				if req.Id != first.Id {
+					t.Errorf("retry used ID %q, want %q", req.Id, first.Id)
+				}
+			}
+			if err == nil && id != first.Id {
+				t.Errorf("Enqueue() id = %q, want %q", id, first.Id)
+			}
+			if tt.check != nil {
+				tt.check(t, first)
+			}
+		})
+	}
+}
+
+func TestClient_CallTimeout(t *testing.T) {
+	c := newTestClient(t, &fakeServer{}, WithCallTimeout(time.Millisecond), WithRetry(1, 0))
+
+	err := c.call(context.Background(), true, func(ctx context.Context, _ pb.DelayQueueServiceClient) error {
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("call() error = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestClient_Nack(t *testing.T) {
+	tests := []struct {
+		name       string
+		cause      error
+		wantReason string
+		wantDelay  int64
+	}{
+		{"Nil Cause", nil, "", 0},
+		{"Plain Error", errors.New("boom"), "boom", 0},
+		{"RetryAfter Rounds Up", RetryAfter(errors.New("busy"), 1500*time.Millisecond), "busy", 2},
+		{"RetryAfter Nil Cause", RetryAfter(nil, time.Second), "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &fakeServer{}
+			c := newTestClient(t, srv)
+
+			if err := c.Nack(context.Background(), &pb.Task{Id: "a", Topic: "test", Lease: "l"}, tt.cause); err != nil {
+				t.Fatalf("Nack() error = %v", err)
+			}
+			if len(srv.nacks) != 1 {
+				t.Fatalf("server nacks = %d, want 1", len(srv.nacks))
+			}
+			if req := srv.nacks[0]; req.Reason != tt.wantReason || req.RetryDelaySeconds != tt.wantDelay {
+				t.Errorf("request = %v, want reason %q, delay %d", req, tt.wantReason, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		code       codes.Code
+		idempotent bool
+		want       bool
+	}{
+		{codes.Unavailable, false, true},
+		{codes.ResourceExhausted, false, true},
+		{codes.DeadlineExceeded, true, true},
+		{codes.DeadlineExceeded, false, false},
+		{codes.NotFound, true, false},
+		{codes.InvalidArgument, true, false},
+	}
+	for _, tt := range tests {
+		if got := retryable(status.Error(tt.code, "x"), tt.idempotent); got != tt.want {
+			t.Errorf("retryable(%v, idempotent=%v) = %v, want %v", tt.code, tt.idempotent, got, tt.want)
+		}
+	}
+}
+
+func TestConsumer_Run(t *testing.T) {
+	srv := &fakeServer{tasks: []*pb.Task{
+		{Id: "ok", Topic: "test", Lease: "l1"},
+		{Id: "fail", Topic: "test", Lease: "l2"},
+		{Id: "retry", Topic: "test", Lease: "l3"},
+		{Id: "panic", Topic: "test", Lease: "l4"},
+	}}
+	c := newTestClient(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	handled := 0
+	consumer := c.NewConsumer("test", func(_ context.Context, task *pb.Task) error {
+		handled++
+		if handled == 4 {
+			defer close(done)
+		}
+		switch task.Id {
+		case "fail":
+			return errors.New("boom")
+		case "retry":
+			return RetryAfter(errors.New("later"), 1500*time.Millisecond)
+		case "panic":
+			panic("bad payload")
+		}
+		return nil
+	}, WithPollInterval(time.Millisecond))
+
+	finished := make(chan struct{})
+	go func() {
+		consumer.Run(ctx)
+		close(finished)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler was not called for every task")
+	}
+	cancel()
+	<-finished
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if len(srv.acks) != 1 || srv.acks[0].Id != "ok" || srv.acks[0].Lease != "l1" {
+		t.Errorf("acks = %v", srv.acks)
+	}
+	if len(srv.nacks) != 3 {
+		t.Fatalf("nacks = %v", srv.nacks)
+	}
+	want := []struct {
+		id     string
+		reason string
+		delay  int64
+	}{
+		{"fail", "boom", 0},
+		{"retry", "later", 2},
+		{"panic", "handler panic: bad payload", 0},
+	}
+	for i, w := range want {
+		got := srv.nacks[i]
+		if got.Id != w.id || got.Reason != w.reason || got.RetryDelaySeconds != w.delay {
+			t.Errorf("nack[%d] = %v, want %+v", i, got, w)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+)
+
+// Consumer 默认配置。
+const (
+	defaultBatchSize    = 10
+	defaultPollInterval = time.Second
+)
+
+// Handler 处理一个任务。返回 nil 时 Ack；返回 error 时 Nack，任务按重试策略重新入队或进入死信队列。
+// @Description ctx 在 Consumer 停止时取消。handler 应在任务的可见性超时内返回，否则任务可能被重复投递。
+type Handler func(ctx context.Context, task *pb.Task) error
+
+// retryAfterError 携带 Handler 指定的重试等待时长。
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	if e.err == nil {
+		return ""
+	}
+	return e.err.Error()
+}
+
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+// RetryAfter 包装 Handler 返回的错误，要求任务在 delay 之后重试，覆盖任务自身的退避策略。
+// @Note: Server 以秒为单位记录重试等待时长，不足 1 秒的部分向上取整。
+func RetryAfter(err error, delay time.Duration) error {
+	return &retryAfterError{err: err, delay: delay}
+}
+
+// Consumer 通过 Retrieve/Ack/Nack 轮询消费单个 Topic 的任务，业务代码只需提供 Handler。
+// @Description 每批任务按顺序同步处理；需要并发处理时可为同一 Topic 启动多个 Consumer。
+type Consumer struct {
+	client       *Client
+	topic        string
+	handler      Handler
+	batchSize    int32
+	pollInterval time.Duration
+}
+
+// ConsumerOption 定义 Consumer 的可选配置项。
+type ConsumerOption func(*Consumer)
+
+// WithBatchSize 设置单次 Retrieve 拉取的任务数上限，Server 端上限为 100。
+func WithBatchSize(n int32) ConsumerOption {
+	return func(c *Consumer) {
+		if n > 0 {
+			c.batchSize = n
+		}
+	}
+}
+
+// WithPollInterval 设置队列为空或拉取失败后再次拉取前的等待时长。
+func WithPollInterval(d time.Duration) ConsumerOption {
+	return func(c *Consumer) {
+		if d > 0 {
+			c.pollInterval = d
+		}
+	}
+}
+
+// NewConsumer 创建消费指定 Topic 的 Consumer，调用 Run 后开始消费。
+func (c *Client) NewConsumer(topic string, handler Handler, opts ...ConsumerOption) *Consumer {
+	consumer := &Consumer{
+		client:       c,
+		topic:        topic,
+		handler:      handler,
+		batchSize:    defaultBatchSize,
+		pollInterval: defaultPollInterval,
+	}
+	for _, opt := range opts {
+		opt(consumer)
+	}
+	return consumer
+}
+
+// Run 在当前协程内循环拉取并处理任务，直到 ctx 取消。
+// @Description 拉到任务时立即进入下一轮拉取，队列为空或拉取失败时等待 pollInterval；
+// ctx 取消时等待当前任务处理完毕并完成 Ack/Nack 后返回，已拉取但尚未处理的任务由 Watchdog 超时回收。
+func (c *Consumer) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		tasks, err := c.retrieve(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("[CONSUMER] Retrieve from topic %s failed: %v", c.topic, err)
+		}
+
+		for _, task := range tasks {
+			if ctx.Err() != nil {
+				return
+			}
+			c.process(ctx, task)
+		}
+
+		if len(tasks) == 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(c.pollInterval):
+			}
+		}
+	}
+}
+
+// retrieve 拉取一批到期任务。
+func (c *Consumer) retrieve(ctx context.Context) ([]*pb.Task, error) {
+	var tasks []*pb.Task
+	err := c.client.call(ctx, false, func(ctx context.Context, stub pb.DelayQueueServiceClient) error {
+		resp, err := stub.Retrieve(ctx, &pb.RetrieveRequest{Topic: c.topic, BatchSize: c.batchSize})
+		if err != nil {
+			return err
+		}
+		tasks = resp.Tasks
+		return nil
+	})
+	return tasks, err
+}
+
+// process 执行 Handler 并回传结果。
+// @Description handler panic 视为失败并 Nack；Ack/Nack 不随 ctx 取消，保证已完成的任务得到确认。
+func (c *Consumer) process(ctx context.Context, task *pb.Task) {
+	err := c.invoke(ctx, task)
+
+	ackCtx := context.WithoutCancel(ctx)
+	if err == nil {
+		if err := c.client.Ack(ackCtx, task); err != nil {
+			log.Printf("[CONSUMER] Ack task %s failed: %v", task.Id, err)
+		}
+		return
+	}
+	if nackErr := c.client.Nack(ackCtx, task, err); nackErr != nil {
+		log.Printf("[CONSUMER] Nack task %s failed: %v", task.Id, nackErr)
+	}
+}
+
+// invoke 调用 Handler，并将 panic 转换为错误。
+func (c *Consumer) invoke(ctx context.Context, task *pb.Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return c.handler(ctx, task)
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// EnqueueOption 定义单次投递的可选参数。
+type EnqueueOption func(*pb.EnqueueRequest)
+
+// WithDelay 设置相对延迟 (毫秒精度)，与 WithExecuteAt 互斥，后设置的生效。
+func WithDelay(d time.Duration) EnqueueOption {
+	return func(req *pb.EnqueueRequest) {
+		req.Delay = durationpb.New(d)
+		req.ExecuteAt = nil
+	}
+}
+
+// WithExecuteAt 设置绝对执行时间 (毫秒精度)，与 WithDelay 互斥，后设置的生效。
+func WithExecuteAt(t time.Time) EnqueueOption {
+	return func(req *pb.EnqueueRequest) {
+		req.ExecuteAt = timestamppb.New(t)
+		req.Delay = nil
+	}
+}
+
+// WithID 指定任务 ID。同一 ID 的重复投递按 Server 的 queue.dedup_policy 处理。
+func WithID(id string) EnqueueOption {
+	return func(req *pb.EnqueueRequest) {
+		req.Id = id
+	}
+}
+
+// WithMaxRetries 设置最大重试次数，不设置时使用 Server 的 queue.max_retries。
+func WithMaxRetries(n int32) EnqueueOption {
+	return func(req *pb.EnqueueRequest) {
+		req.MaxRetries = n
+	}
+}
+
+// WithPriority 设置优先级 [0, 1000]，只影响已到期任务之间的拉取顺序。
+func WithPriority(p int32) EnqueueOption {
+	return func(req *pb.EnqueueRequest) {
+		req.Priority = p
+	}
+}
+
+// Enqueue 投递一个任务，默认立即可执行。
+// @Description 未通过 WithID 指定 ID 时由客户端生成 UUID，使超时重试命中 Server 的幂等去重，
+// 而不是重复入队 (需保持默认的 return_existing 去重策略)。
+// @Return: 任务 ID；参数非法时返回 InvalidArgument 状态错误，瞬时错误在重试耗尽后返回最后一次的错误。
+func (c *Client) Enqueue(ctx context.Context, topic, payload string, opts ...EnqueueOption) (string, error) {
+	req := &pb.EnqueueRequest{Topic: topic, Payload: payload}
+	for _, opt := range opts {
+		opt(req)
+	}
+	if req.Id == "" {
+		req.Id = uuid.New().String()
+	}
+
+	var id string
+	err := c.call(ctx, true, func(ctx context.Context, stub pb.DelayQueueServiceClient) error {
+		resp, err := stub.Enqueue(ctx, req)
+		if err != nil {
+			return err
+		}
+		id = resp.Id
+		return nil
+	})
+	return id, err
+}
+
+// Delete 取消一个等待中或已死信的任务。
+// @Return: ID 不存在时返回 NotFound 状态错误；任务执行中返回 FailedPrecondition。
+func (c *Client) Delete(ctx context.Context, id string) error {
+	return c.call(ctx, true, func(ctx context.Context, stub pb.DelayQueueServiceClient) error {
+		_, err := stub.Delete(ctx, &pb.DeleteRequest{Id: id})
+		return err
+	})
+}
+
+// Ack 确认任务执行成功，task 须为 Retrieve/Subscribe 下发的任务 (携带租约)。
+// @Return: 任务已不在执行中返回 NotFound；任务已被重新投递返回 Aborted。
+func (c *Client) Ack(ctx context.Context, task *pb.Task) error {
+	return c.call(ctx, false, func(ctx context.Context, stub pb.DelayQueueServiceClient) error {
+		_, err := stub.Ack(ctx, &pb.AckRequest{Topic: task.Topic, Id: task.Id, Lease: task.Lease})
+		return err
+	})
+}
+
+// Nack 报告任务执行失败，cause 的错误文本记录为失败原因 (cause 为 nil 时不记录)。
+// @Description cause 由 RetryAfter 包装时按其指定的时长重试，否则按任务的重试策略退避。
+func (c *Client) Nack(ctx context.Context, task *pb.Task, cause error) error {
+	req := &pb.NackRequest{Topic: task.Topic, Id: task.Id, Lease: task.Lease}
+	if cause != nil {
+		req.Reason = cause.Error()
+	}
+	var retry *retryAfterError
+	if errors.As(cause, &retry) && retry.delay > 0 {
+		req.RetryDelaySeconds = int64((retry.delay + time.Second - 1) / time.Second)
+	}
+	return c.call(ctx, false, func(ctx context.Context, stub pb.DelayQueueServiceClient) error {
+		_, err := stub.Nack(ctx, req)
+		return err
+	})
+}
@@ -0,0 +1,60 @@
+// Package worker 提供 Worker 侧的任务执行运行时。
+// 适用场景：业务 Worker 通过 Worker.Handle 按 Topic 注册 Handler，由运行时负责订阅、并发控制、
+// Ack/Nack、续租与优雅退出；也可以只使用 Heartbeat 为自行编写的消费循环维持长耗时任务的租约。
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Heartbeat 在任务执行期间周期性调用 ExtendLease 续租，防止长耗时任务被 Watchdog 回收重投。
+// @Description 每隔 interval 将任务的超时时刻推迟到 extend 之后，直到调用返回的 stop 或 ctx 取消。
+// 任务已不在执行中 (NotFound) 或已被重新投递 (Aborted) 时说明租约已丢失，心跳自动停止；
+// 其余错误（如网络抖动）仅记录日志，下一个周期继续重试。
+// @Param interval: 心跳间隔，应明显小于可见性超时，通常取其 1/3。
+// @Param extend: 每次续租后至少再保留的时长，通常取可见性超时；按秒向上取整。
+// @Return: stop 函数，handler 执行结束后调用；阻塞至心跳协程退出，可重复调用。
+func Heartbeat(ctx context.Context, client pb.DelayQueueServiceClient, task *pb.Task, interval, extend time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		req := &pb.ExtendLeaseRequest{
+			Topic:         task.Topic,
+			Id:            task.Id,
+			Lease:         task.Lease,
+			ExtendSeconds: int64((extend + time.Second - 1) / time.Second),
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_, err := client.ExtendLease(ctx, req)
+				switch status.Code(err) {
+				case codes.OK, codes.Canceled:
+				case codes.NotFound, codes.Aborted:
+					log.Printf("[HEARTBEAT] Lease of task %s lost: %v", task.Id, err)
+					return
+				default:
+					log.Printf("[HEARTBEAT] Extend lease of task %s failed: %v", task.Id, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,79 @@
+package worker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeLeaseClient 仅实现 ExtendLease，记录调用次数并按预设返回错误。
+type fakeLeaseClient struct {
+	pb.DelayQueueServiceClient
+	calls       atomic.Int32
+	unexpected  atomic.Int32 // 参数不符合预期的调用次数
+	err         error
+	wantSeconds int64 // 期望请求携带的 ExtendSeconds
+}
+
+func (f *fakeLeaseClient) ExtendLease(_ context.Context, req *pb.ExtendLeaseRequest, _ ...grpc.CallOption) (*pb.ExtendLeaseResponse, error) {
+	f.calls.Add(1)
+	if req.Lease != "lease-1" || req.ExtendSeconds != f.wantSeconds {
+		f.unexpected.Add(1)
+		return nil, status.Error(codes.InvalidArgument, "unexpected request")
+	}
+	return &pb.ExtendLeaseResponse{Success: f.err == nil}, f.err
+}
+
+func TestHeartbeat(t *testing.T) {
+	task := &pb.Task{Id: "task-1", Topic: "test", Lease: "lease-1"}
+
+	tests := []struct {
+		name        string
+		err         error
+		extend      time.Duration
+		wantSeconds int64
+		wantCalls   func(n int32) bool
+	}{
+		{
+			name:        "Keeps Extending",
+			extend:      30 * time.Second,
+			wantSeconds: 30,
+			wantCalls:   func(n int32) bool { return n >= 3 },
+		},
+		{
+			name:        "Sub-Second Extend Rounds Up",
+			extend:      200 * time.Millisecond,
+			wantSeconds: 1,
+			wantCalls:   func(n int32) bool { return n >= 3 },
+		},
+		{
+			name:        "Stops When Lease Lost",
+			err:         status.Error(codes.Aborted, "lease token mismatch"),
+			extend:      30 * time.Second,
+			wantSeconds: 30,
+			wantCalls:   func(n int32) bool { return n == 1 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeLeaseClient{err: tt.err, wantSeconds: tt.wantSeconds}
+			stop := Heartbeat(context.Background(), client, task, 10*time.Millisecond, tt.extend)
+			time.Sleep(60 * time.Millisecond)
+			stop()
+
+			if n := client.calls.Load(); !tt.wantCalls(n) {
+				t.Errorf("ExtendLease calls = %d", n)
+			}
+			if n := client.unexpected.Load(); n > 0 {
+				t.Errorf("ExtendLease received %d unexpected requests, want ExtendSeconds %d", n, tt.wantSeconds)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/pkg/client"
+)
+
+// Worker 默认配置。
+const (
+	defaultConcurrency   = 1
+	defaultDrainTimeout  = 30 * time.Second
+	defaultReconnectWait = time.Second
+)
+
+// Handler 处理一个任务。返回 nil 时 Ack；返回 error 或 panic 时 Nack，
+// 返回 client.RetryAfter 包装的错误时按其指定的时长重试。
+type Handler = client.Handler
+
+// Worker 按 Topic 注册 Handler 并发消费任务的运行时。
+// @Description 每个 Topic 持有一条 Subscribe 流，只在有空闲并发槽位时向 Server 授予 credit，
+// 因此同一 Topic 同时执行的任务数不会超过其并发度；Ack/Nack 通过 client 的一元调用回传 (带超时与重试)，
+// 订阅流断开或停止拉取后已在执行的任务仍能得到确认。
+type Worker struct {
+	client       *client.Client
+	topics       []*topicRunner
+	drainTimeout time.Duration
+	heartbeat    time.Duration // 续租间隔，0 表示不发送心跳
+	visibility   time.Duration // 任务未设置 visibility_timeout 时使用的可见性超时，0 表示未知
+	reconnect    time.Duration
+
+	inflight sync.WaitGroup // 执行中的 Handler
+	running  atomic.Bool
+}
+
+// topicRunner 单个 Topic 的 Handler 与并发槽位。
+type topicRunner struct {
+	topic   string
+	handler Handler
+	// slots 的每个元素代表一个被占用的并发槽位：已授予 Server 但尚未收到任务的 credit，或执行中的任务
+	slots chan struct{}
+}
+
+// Option 定义 Worker 的可选配置项。
+type Option func(*Worker)
+
+// WithDrainTimeout 设置优雅退出时等待执行中任务完成的最长时间，默认 30 秒。
+func WithDrainTimeout(d time.Duration) Option {
+	return func(w *Worker) {
+		if d > 0 {
+			w.drainTimeout = d
+		}
+	}
+}
+
+// WithHeartbeat 开启执行期间的自动续租，每隔 interval 调用一次 ExtendLease (见 Heartbeat)。
+// @Note: 开启后任务不再受可见性超时约束，Handler 的 ctx 不会因此设置截止时间。
+func WithHeartbeat(interval time.Duration) Option {
+	return func(w *Worker) {
+		if interval > 0 {
+			w.heartbeat = interval
+		}
+	}
+}
+
+// WithVisibilityTimeout 设置任务未携带 visibility_timeout 时的可见性超时，应与 Server 的 queue.visibility_timeout 一致。
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(w *Worker) {
+		if d > 0 {
+			w.visibility = d
+		}
+	}
+}
+
+// New 创建 Worker，通过 Handle 注册 Handler 后调用 Run 开始消费。
+func New(c *client.Client, opts ...Option) *Worker {
+	w := &Worker{
+		client:       c,
+		drainTimeout: defaultDrainTimeout,
+		reconnect:    defaultReconnectWait,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Handle 为 Topic 注册 Handler。
+// @Param concurrency: 该 Topic 同时执行的任务数上限，<=0 时为 1。
+// @Warning: 必须在 Run 之前调用；Topic 为空、重复注册或 Run 之后注册会 panic。
+func (w *Worker) Handle(topic string, handler Handler, concurrency int) {
+	if w.running.Load() {
+		panic("worker: Handle called after Run")
+	}
+	if topic == "" || handler == nil {
+		panic("worker: topic and handler are required")
+	}
+	for _, r := range w.topics {
+		if r.topic == topic {
+			panic("worker: multiple registrations for topic " + topic)
+		}
+	}
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	w.topics = append(w.topics, &topicRunner{
+		topic:   topic,
+		handler: handler,
+		slots:   make(chan struct{}, concurrency),
+	})
+}
+
+// Run 为每个已注册的 Topic 建立订阅并消费任务，阻塞至 ctx 取消且执行中的任务排空。
+// @Description ctx 取消后立即停止拉取新任务，等待执行中的 Handler 完成并 Ack/Nack，最长等待 drainTimeout；
+// 超时后取消剩余 Handler 的 ctx 并返回，这些任务在未确认时由 Watchdog 超时回收。
+// @Return: 正常排空返回 nil；排空超时返回错误；未注册任何 Handler 时直接返回错误。
+func (w *Worker) Run(ctx context.Context) error {
+	if len(w.topics) == 0 {
+		return fmt.Errorf("worker: no handler registered")
+	}
+	if !w.running.CompareAndSwap(false, true) {
+		return fmt.Errorf("worker: already running")
+	}
+
+	// Handler 的 ctx 不随 Run 的 ctx 取消，优雅退出时由 abort 在排空超时后统一取消
+	handlerCtx, abort := context.WithCancel(context.WithoutCancel(ctx))
+	defer abort()
+
+	var loops sync.WaitGroup
+	for _, r := range w.topics {
+		loops.Add(1)
+		go func() {
+			defer loops.Done()
+			w.consume(ctx, handlerCtx, r)
+		}()
+	}
+
+	<-ctx.Done()
+	// 拉取循环全部退出后不会再有新任务开始执行，此时才能安全地等待 inflight
+	loops.Wait()
+
+	drained := make(chan struct{})
+	go func() {
+		w.inflight.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-time.After(w.drainTimeout):
+		abort()
+		return fmt.Errorf("worker: drain timeout after %v, in-flight handlers cancelled", w.drainTimeout)
+	}
+}
+
+// consume 维持 Topic 的订阅流，断开后间隔 reconnect 重连，直到 ctx 取消。
+func (w *Worker) consume(ctx, handlerCtx context.Context, r *topicRunner) {
+	for {
+		if err := w.session(ctx, handlerCtx, r); err != nil && ctx.Err() == nil {
+			log.Printf("[WORKER] Subscribe stream of topic %s broken: %v", r.topic, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.reconnect):
+		}
+	}
+}
+
+// session 在一条订阅流上消费任务，直到流断开或 ctx 取消。
+// @Description 以 0 credit 建立订阅，之后每占用一个空闲槽位授予 1 个 credit；
+// 收到任务即在该槽位上执行，任务结束后释放槽位。流结束时归还已授予但未使用的 credit 对应的槽位。
+func (w *Worker) session(ctx, handlerCtx context.Context, r *topicRunner) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := w.client.Service().Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&pb.SubscribeRequest{Payload: &pb.SubscribeRequest_Open{
+		Open: &pb.SubscribeOpen{Topics: []string{r.topic}, Credit: 0},
+	}}); err != nil {
+		return err
+	}
+
+	var outstanding atomic.Int64 // 已授予但尚未收到任务的 credit
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			// 流内只会收到任务；Ack/Nack 走一元调用，不会产生 AckResult
+			if task := resp.GetTask(); task != nil {
+				outstanding.Add(-1)
+				w.inflight.Add(1)
+				go w.execute(handlerCtx, r, task)
+			}
+		}
+	}()
+
+	var sessionErr error
+	for sessionErr == nil {
+		select {
+		case r.slots <- struct{}{}:
+			outstanding.Add(1)
+			if err := stream.Send(&pb.SubscribeRequest{Payload: &pb.SubscribeRequest_Credit{Credit: 1}}); err != nil {
+				sessionErr = err
+			}
+		case err := <-recvErr:
+			recvErr <- err
+			sessionErr = err
+		case <-ctx.Done():
+			sessionErr = ctx.Err()
+		}
+	}
+
+	// 等待接收协程退出后 outstanding 不再变化，再归还未使用的槽位
+	cancel()
+	<-recvErr
+	for range outstanding.Load() {
+		<-r.slots
+	}
+	return sessionErr
+}
+
+// execute 执行单个任务并回传结果，结束后释放并发槽位。
+// @Description 未开启心跳时 Handler 的 ctx 截止于任务的可见性超时，超过后任务会被 Watchdog 重新投递，继续执行没有意义。
+func (w *Worker) execute(ctx context.Context, r *topicRunner, task *pb.Task) {
+	defer w.inflight.Done()
+	defer func() { <-r.slots }()
+
+	ackCtx := context.WithoutCancel(ctx)
+	stop := func() {}
+	if w.heartbeat > 0 {
+		// 可见性超时未知时按心跳间隔的 3 倍续租，与 Heartbeat 建议的间隔比例一致
+		extend := w.visibilityOf(task)
+		if extend <= 0 {
+			extend = 3 * w.heartbeat
+		}
+		stop = Heartbeat(ctx, w.client.Service(), task, w.heartbeat, extend)
+	} else if timeout := w.visibilityOf(task); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// 先停止心跳再回传结果，避免任务确认后心跳误报租约丢失
+	err := invoke(ctx, r.handler, task)
+	stop()
+	if err != nil {
+		if nackErr := w.client.Nack(ackCtx, task, err); nackErr != nil {
+			log.Printf("[WORKER] Nack task %s failed: %v", task.Id, nackErr)
+		}
+		return
+	}
+	if err := w.client.Ack(ackCtx, task); err != nil {
+		log.Printf("[WORKER] Ack task %s failed: %v", task.Id, err)
+	}
+}
+
+// visibilityOf 返回任务的可见性超时，任务自带值优先。
+func (w *Worker) visibilityOf(task *pb.Task) time.Duration {
+	if task.VisibilityTimeout > 0 {
+		return time.Duration(task.VisibilityTimeout) * time.Second
+	}
+	return w.visibility
+}
+
+// invoke 调用 Handler，并将 panic 转换为错误。
+func invoke(ctx context.Context, handler Handler, task *pb.Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+			log.Printf("[WORKER] Handler of task %s panicked: %v", task.Id, r)
+		}
+	}()
+	return handler(ctx, task)
+}
@@ -0,0 +1,286 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/pkg/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeQueue 按订阅流授予的 credit 推送 tasks 中的任务，并记录 Ack/Nack。
+type fakeQueue struct {
+	pb.UnimplementedDelayQueueServiceServer
+
+	tasks chan *pb.Task
+
+	mu    sync.Mutex
+	acks  []string
+	nacks map[string]string // 任务 ID -> 失败原因
+}
+
+func newFakeQueue(tasks ...*pb.Task) *fakeQueue {
+	q := &fakeQueue{tasks: make(chan *pb.Task, len(tasks)), nacks: make(map[string]string)}
+	for _, t := range tasks {
+		q.tasks <- t
+	}
+	return q
+}
+
+func (q *fakeQueue) Subscribe(stream pb.DelayQueueService_SubscribeServer) error {
+	if _, err := stream.Recv(); err != nil {
+		return err
+	}
+	credits := make(chan int32, 16)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				close(credits)
+				return
+			}
+			credits <- req.GetCredit()
+		}
+	}()
+
+	for n := range credits {
+		for range n {
+			select {
+			case t := <-q.tasks:
+				if err := stream.Send(&pb.SubscribeResponse{Payload: &pb.SubscribeResponse_Task{Task: t}}); err != nil {
+					return err
+				}
+			case <-stream.Context().Done():
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+func (q *fakeQueue) Ack(_ context.Context, req *pb.AckRequest) (*pb.AckResponse, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.acks = append(q.acks, req.Id)
+	return &pb.AckResponse{Success: true}, nil
+}
+
+func (q *fakeQueue) Nack(_ context.Context, req *pb.NackRequest) (*pb.NackResponse, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.nacks[req.Id] = req.Reason
+	return &pb.NackResponse{Success: true}, nil
+}
+
+// done 返回已确认 (Ack 或 Nack) 的任务数。
+func (q *fakeQueue) done() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.acks) + len(q.nacks)
+}
+
+// newTestWorker 在内存连接上启动 fakeQueue 并返回连接它的 Worker。
+func newTestWorker(t *testing.T, q *fakeQueue, opts ...Option) *Worker {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	pb.RegisterDelayQueueServiceServer(s, q)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }
+	c, err := client.New("passthrough:///bufnet", client.WithDialOptions(grpc.WithContextDialer(dialer)))
+	if err != nil {
+		t.Fatalf("client.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return New(c, opts...)
+}
+
+// waitFor 轮询直到 cond 成立，超时则测试失败。
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWorker_AckNack(t *testing.T) {
+	q := newFakeQueue(
+		&pb.Task{Id: "ok", Topic: "test"},
+		&pb.Task{Id: "fail", Topic: "test"},
+		&pb.Task{Id: "panic", Topic: "test"},
+	)
+	w := newTestWorker(t, q)
+	w.Handle("test", func(_ context.Context, task *pb.Task) error {
+		switch task.Id {
+		case "fail":
+			return errors.New("boom")
+		case "panic":
+			panic("bad payload")
+		}
+		return nil
+	}, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- w.Run(ctx) }()
+	waitFor(t, func() bool { return q.done() == 3 })
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(q.acks) != 1 || q.acks[0] != "ok" {
+		t.Errorf("acks = %v", q.acks)
+	}
+	if q.nacks["fail"] != "boom" || q.nacks["panic"] != "handler panic: bad payload" {
+		t.Errorf("nacks = %v", q.nacks)
+	}
+}
+
+func TestWorker_Concurrency(t *testing.T) {
+	const total, concurrency = 8, 3
+	tasks := make([]*pb.Task, total)
+	for i := range tasks {
+		tasks[i] = &pb.Task{Id: string(rune('a' + i)), Topic: "test"}
+	}
+	q := newFakeQueue(tasks...)
+	w := newTestWorker(t, q)
+
+	var running, peak atomic.Int32
+	w.Handle("test", func(_ context.Context, _ *pb.Task) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	}, concurrency)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- w.Run(ctx) }()
+	waitFor(t, func() bool { return q.done() == total })
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if p := peak.Load(); p != concurrency {
+		t.Errorf("peak concurrency = %d, want %d", p, concurrency)
+	}
+}
+
+func TestWorker_Drain(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  func(ctx context.Context, release <-chan struct{}) error
+		wantErr  bool
+		wantDone int // 最终 Ack/Nack 的任务数
+	}{
+		{
+			name: "Waits For In-Flight Handler",
+			handler: func(_ context.Context, release <-chan struct{}) error {
+				<-release
+				return nil
+			},
+			wantDone: 1,
+		},
+		{
+			name: "Cancels Handler After Timeout",
+			handler: func(ctx context.Context, _ <-chan struct{}) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			wantErr:  true,
+			wantDone: 1, // Handler 的 ctx 被取消后返回错误，任务被 Nack
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newFakeQueue(&pb.Task{Id: "slow", Topic: "test"})
+			w := newTestWorker(t, q, WithDrainTimeout(100*time.Millisecond))
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			w.Handle("test", func(ctx context.Context, _ *pb.Task) error {
+				close(started)
+				return tt.handler(ctx, release)
+			}, 1)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			errCh := make(chan error, 1)
+			go func() { errCh <- w.Run(ctx) }()
+			<-started
+			cancel()
+
+			select {
+			case err := <-errCh:
+				if !tt.wantErr {
+					t.Fatalf("Run() returned before handler finished: %v", err)
+				}
+			case <-time.After(30 * time.Millisecond):
+				close(release)
+				if err := <-errCh; (err != nil) != tt.wantErr {
+					t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+			waitFor(t, func() bool { return q.done() == tt.wantDone })
+		})
+	}
+}
+
+func TestWorker_TaskDeadline(t *testing.T) {
+	q := newFakeQueue(
+		&pb.Task{Id: "own", Topic: "test", VisibilityTimeout: 5},
+		&pb.Task{Id: "default", Topic: "test"},
+	)
+	w := newTestWorker(t, q, WithVisibilityTimeout(time.Minute))
+
+	var mu sync.Mutex
+	remaining := make(map[string]time.Duration)
+	w.Handle("test", func(ctx context.Context, task *pb.Task) error {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return errors.New("no deadline")
+		}
+		mu.Lock()
+		remaining[task.Id] = time.Until(deadline)
+		mu.Unlock()
+		return nil
+	}, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- w.Run(ctx) }()
+	waitFor(t, func() bool { return q.done() == 2 })
+	cancel()
+	<-errCh
+
+	if len(q.nacks) != 0 {
+		t.Fatalf("nacks = %v", q.nacks)
+	}
+	if d := remaining["own"]; d <= 4*time.Second || d > 5*time.Second {
+		t.Errorf("own deadline in %v, want ~5s", d)
+	}
+	if d := remaining["default"]; d <= 59*time.Second || d > time.Minute {
+		t.Errorf("default deadline in %v, want ~1m", d)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
 	"github.com/AkikoAkaki/async-task-platform/internal/storage/redis"
 )
 
@@ -42,7 +43,7 @@ func main() {
 
 	// 3. Nack (第一次失败)
 	log.Println("3. Nack (第一次)...")
-	if err := store.Nack(ctx, tasks[0]); err != nil {
+	if err := store.Nack(ctx, tasks[0].Topic, tasks[0].Id, tasks[0].Lease, storage.NackOptions{Reason: "simulated failure"}); err != nil {
 		log.Fatalf("Nack 失败: %v", err)
 	}
 
@@ -56,13 +57,13 @@ func main() {
 
 	// 5. Nack (第二次失败，进 DLQ)
 	log.Println("5. Nack (第二次，进 DLQ)...")
-	if err := store.Nack(ctx, tasks[0]); err != nil {
+	if err := store.Nack(ctx, tasks[0].Topic, tasks[0].Id, tasks[0].Lease, storage.NackOptions{Reason: "simulated failure"}); err != nil {
 		log.Fatalf("Nack 失败: %v", err)
 	}
 
 	// 6. 验证 DLQ
 	log.Println("6. 验证 DLQ...")
-	res, err := store.GetClient().LRange(ctx, "ddq:dlq", 0, -1).Result()
+	res, err := store.GetClient().LRange(ctx, "ddq:test-topic:dlq", 0, -1).Result()
 	if err != nil {
 		log.Fatalf("LRange 失败: %v", err)
 	}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	pb "github.com/AkikoAkaki/async-task-platform/api/proto"
+	"github.com/AkikoAkaki/async-task-platform/internal/storage"
 	"github.com/AkikoAkaki/async-task-platform/internal/storage/redis"
 )
 
@@ -16,7 +17,7 @@ func main() {
 	store := redis.NewStore("localhost:6379")
 
 	// 清空测试数据
-	store.GetClient().Del(ctx, "ddq:tasks", "ddq:running", "ddq:dlq")
+	store.GetClient().Del(ctx, "ddq:test-topic:pending", "ddq:test-topic:running", "ddq:test-topic:dlq")
 
 	// --- 任务配置 ---
 	task := &pb.Task{
@@ -50,7 +51,7 @@ func main() {
 
 	// 3. 第一次失败
 	printHeader("阶段 3: 模拟第一次失败 (Nack)")
-	if err := store.Nack(ctx, t1); err != nil {
+	if err := store.Nack(ctx, t1.Topic, t1.Id, t1.Lease, storage.NackOptions{Reason: "simulated failure"}); err != nil {
 		log.Fatalf("Nack 失败: %v", err)
 	}
 	fmt.Printf(" Nack 成功，任务应重新入队\n")
@@ -70,14 +71,14 @@ func main() {
 
 	// 5. 第二次失败 (最终失败)
 	printHeader("阶段 5: 模拟第二次失败 (进入 DLQ)")
-	if err := store.Nack(ctx, t2); err != nil {
+	if err := store.Nack(ctx, t2.Topic, t2.Id, t2.Lease, storage.NackOptions{Reason: "simulated failure"}); err != nil {
 		log.Fatalf("Nack 失败: %v", err)
 	}
 	fmt.Printf(" Nack 成功，任务应进入死信队列\n")
 
 	// 6. 验证结果
 	printHeader("阶段 6: 验证死信队列")
-	res, err := store.GetClient().LRange(ctx, "ddq:dlq", 0, -1).Result()
+	res, err := store.GetClient().LRange(ctx, "ddq:test-topic:dlq", 0, -1).Result()
 	if err != nil {
 		log.Fatalf("LRange 失败: %v", err)
 	}